@@ -0,0 +1,12 @@
+module syrus-validate-blueprint
+
+go 1.21
+
+replace loros/syrus-models => ../../lib/go/models
+
+replace loros/syrus-validation => ../../lib/go/validation
+
+require (
+	loros/syrus-models v0.0.0
+	loros/syrus-validation v0.0.0
+)
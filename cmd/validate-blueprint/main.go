@@ -0,0 +1,110 @@
+// Command validate-blueprint runs the blueprinting lambda's validation pipeline on a local file,
+// so prompt changes can be checked without birthing a campaign:
+//
+//	go run . -blueprint candidate.json -seeds seeds.json
+//
+// The blueprint file may be a bare blueprint or a full model response ({"blueprint": ..., "intro": ...}).
+// Exits 0 when the blueprint passes, 1 when it fails validation, and 2 on usage or read errors.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	models "loros/syrus-models"
+	validation "loros/syrus-validation"
+)
+
+const (
+	exitPass  = 0
+	exitFail  = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run validates the files named by args, writing the verdict to stdout and validator warnings to stderr
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate-blueprint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	blueprintPath := flags.String("blueprint", "", "path to the blueprint JSON (bare blueprint or full model response)")
+	seedsPath := flags.String("seeds", "", "path to the campaign seeds JSON the blueprint was generated from")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *blueprintPath == "" || *seedsPath == "" {
+		fmt.Fprintln(stderr, "usage: validate-blueprint -blueprint <file> -seeds <file>")
+		return exitUsage
+	}
+
+	rawBlueprint, err := os.ReadFile(*blueprintPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read blueprint: %v\n", err)
+		return exitUsage
+	}
+	seeds, err := readSeeds(*seedsPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read seeds: %v\n", err)
+		return exitUsage
+	}
+
+	// The validator logs soft adventure-content warnings; keep them off the verdict
+	log.SetOutput(stderr)
+	log.SetFlags(0)
+
+	blueprint, err := validateFile(rawBlueprint, seeds)
+	if err != nil {
+		printFailure(stdout, err)
+		return exitFail
+	}
+
+	coverage := validation.ComputeCoverage(blueprint, seeds)
+	fmt.Fprintf(stdout, "PASS %s\n", blueprint.Title)
+	fmt.Fprintf(stdout, "  coverage: %s\n", coverage.Summary())
+	return exitPass
+}
+
+func readSeeds(path string) (models.CampaignSeeds, error) {
+	var seeds models.CampaignSeeds
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return seeds, err
+	}
+	if err := json.Unmarshal(data, &seeds); err != nil {
+		return seeds, fmt.Errorf("failed to parse seeds JSON: %w", err)
+	}
+	return seeds, nil
+}
+
+// validateFile accepts either a full model response or a bare blueprint
+func validateFile(raw []byte, seeds models.CampaignSeeds) (*models.Blueprint, error) {
+	var envelope map[string]json.RawMessage
+	if json.Unmarshal(raw, &envelope) == nil {
+		if _, ok := envelope["blueprint"]; ok {
+			blueprint, _, err := validation.ParseResponse(string(raw), seeds)
+			return blueprint, err
+		}
+	}
+	return validation.ParseBlueprint(raw, seeds)
+}
+
+// printFailure reports the outcome code and, for schema violations, every failing field
+func printFailure(w io.Writer, err error) {
+	fmt.Fprintf(w, "FAIL %s\n", validation.Outcome(err))
+
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) && len(validationErr.Violations) > 0 {
+		for _, violation := range validationErr.Violations {
+			fmt.Fprintf(w, "  %s\n", violation)
+		}
+		return
+	}
+	fmt.Fprintf(w, "  %v\n", err)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		exitCode  int
+		expected  []string
+		forbidden []string
+	}{
+		{
+			name:     "known-good blueprint",
+			args:     []string{"-blueprint", "testdata/good-blueprint.json", "-seeds", "testdata/seeds.json"},
+			exitCode: exitPass,
+			expected: []string{"PASS The Barrow King's Curse", "twists=1/1"},
+		},
+		{
+			name:     "schema violations listed per field",
+			args:     []string{"-blueprint", "testdata/bad-schema-blueprint.json", "-seeds", "testdata/seeds.json"},
+			exitCode: exitFail,
+			expected: []string{
+				"FAIL schema_violation",
+				"  blueprint.premise: required field missing",
+				"  blueprint.acts[1].actNumber: expected integer, got string",
+				"  blueprint.thematicPillars[0]: expected string, got object",
+			},
+			forbidden: []string{"PASS"},
+		},
+		{
+			name:     "full model response with too few acts",
+			args:     []string{"-blueprint", "testdata/act-count-response.json", "-seeds", "testdata/seeds.json"},
+			exitCode: exitFail,
+			expected: []string{"FAIL act_count", "acts count mismatch: expected 3, got 2"},
+		},
+		{
+			name:     "missing seeds flag",
+			args:     []string{"-blueprint", "testdata/good-blueprint.json"},
+			exitCode: exitUsage,
+		},
+		{
+			name:     "unreadable blueprint",
+			args:     []string{"-blueprint", "testdata/missing.json", "-seeds", "testdata/seeds.json"},
+			exitCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.exitCode {
+				t.Fatalf("Expected exit code %d, got %d (stdout %q, stderr %q)", tt.exitCode, code, stdout.String(), stderr.String())
+			}
+			for _, line := range tt.expected {
+				if !strings.Contains(stdout.String(), line) {
+					t.Errorf("Expected output to contain %q, got:\n%s", line, stdout.String())
+				}
+			}
+			for _, line := range tt.forbidden {
+				if strings.Contains(stdout.String(), line) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", line, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+
+	logging "loros/syrus-logging"
+	models "loros/syrus-models"
+)
+
+// defaultWindowMinutes is how recent a declaration must be for a campaign to count as being played
+const defaultWindowMinutes = 15
+
+// maxWindowMinutes bounds ad-hoc queries to one week
+const maxWindowMinutes = 7 * 24 * 60
+
+// metricsNamespace groups activity metrics in CloudWatch
+const metricsNamespace = "Syrus/Activity"
+
+// scheduledEventSource marks invocations from the EventBridge schedule
+const scheduledEventSource = "aws.events"
+
+var (
+	awsSession     *session.Session
+	dynamodbClient *dynamodb.DynamoDB
+	campaignsTable string
+	stage          string
+	windowMinutes  int
+
+	// metricsOutput receives EMF records; overridden in tests
+	metricsOutput io.Writer = os.Stdout
+)
+
+func init() {
+	awsSession = session.Must(session.NewSession())
+	dynamodbClient = dynamodb.New(awsSession)
+
+	campaignsTable = os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	stage = os.Getenv("SYRUS_STAGE")
+	windowMinutes = parseWindowMinutes(os.Getenv("SYRUS_ACTIVE_WINDOW_MINUTES"))
+}
+
+// ActivityRequest is the invocation payload. The schedule sends an EventBridge event (source "aws.events");
+// operators can invoke the function directly with {"windowMinutes": N} to list campaigns active in the last N minutes.
+type ActivityRequest struct {
+	Source        string `json:"source,omitempty"`
+	WindowMinutes int    `json:"windowMinutes,omitempty"`
+}
+
+// ActiveCampaign summarizes a campaign with a recent declaration
+type ActiveCampaign struct {
+	CampaignID        string    `json:"campaignId"`
+	ChannelID         string    `json:"channelId"`
+	Status            string    `json:"status"`
+	PlayStyle         string    `json:"playStyle"`
+	LastDeclarationAt time.Time `json:"lastDeclarationAt"`
+}
+
+// ActivityReport lists the campaigns being played within the window, most recent first
+type ActivityReport struct {
+	WindowMinutes   int              `json:"windowMinutes"`
+	GeneratedAt     time.Time        `json:"generatedAt"`
+	Count           int              `json:"count"`
+	ActiveCampaigns []ActiveCampaign `json:"activeCampaigns"`
+}
+
+// handler reports which campaigns are being played right now. Scheduled runs also emit the
+// ActiveCampaigns metric for dashboards; direct invocations only return the report.
+func handler(ctx context.Context, request ActivityRequest) (ActivityReport, error) {
+	now := time.Now().UTC()
+	window := windowMinutes
+	if request.WindowMinutes > 0 {
+		window = clampWindowMinutes(request.WindowMinutes)
+	}
+
+	campaigns, err := listRecentlyDeclaredCampaigns(now.Add(-time.Duration(window) * time.Minute))
+	if err != nil {
+		return ActivityReport{}, fmt.Errorf("failed to list active campaigns: %w", err)
+	}
+
+	report := buildActivityReport(campaigns, window, now)
+	if request.Source == scheduledEventSource {
+		emitActivityMetric(report)
+	}
+
+	log.Printf("Activity sweep complete: %d campaigns active in the last %d minutes", report.Count, window)
+	return report, nil
+}
+
+// parseWindowMinutes parses SYRUS_ACTIVE_WINDOW_MINUTES, falling back to the default when unset or invalid
+func parseWindowMinutes(raw string) int {
+	if raw == "" {
+		return defaultWindowMinutes
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Warning: invalid SYRUS_ACTIVE_WINDOW_MINUTES %q; using %d", raw, defaultWindowMinutes)
+		return defaultWindowMinutes
+	}
+	return clampWindowMinutes(value)
+}
+
+func clampWindowMinutes(minutes int) int {
+	if minutes > maxWindowMinutes {
+		return maxWindowMinutes
+	}
+	return minutes
+}
+
+// buildActivityReport keeps the campaigns whose last declaration falls within the window
+func buildActivityReport(campaigns []models.Campaign, window int, now time.Time) ActivityReport {
+	report := ActivityReport{
+		WindowMinutes:   window,
+		GeneratedAt:     now,
+		ActiveCampaigns: []ActiveCampaign{},
+	}
+
+	for i := range campaigns {
+		campaign := &campaigns[i]
+		if !campaign.ActiveWithin(time.Duration(window)*time.Minute, now) {
+			continue
+		}
+		report.ActiveCampaigns = append(report.ActiveCampaigns, ActiveCampaign{
+			CampaignID:        campaign.CampaignID,
+			ChannelID:         campaign.Meta.ChannelID,
+			Status:            string(campaign.Status),
+			PlayStyle:         string(campaign.EffectivePlayStyle()),
+			LastDeclarationAt: *campaign.Runtime.LastDeclarationAt,
+		})
+	}
+
+	sort.Slice(report.ActiveCampaigns, func(i, j int) bool {
+		return report.ActiveCampaigns[i].LastDeclarationAt.After(report.ActiveCampaigns[j].LastDeclarationAt)
+	})
+	report.Count = len(report.ActiveCampaigns)
+	return report
+}
+
+// buildActivityMetric builds the CloudWatch EMF record for an activity report
+func buildActivityMetric(stage string, report ActivityReport) map[string]interface{} {
+	synchronous, asynchronous := 0, 0
+	for _, campaign := range report.ActiveCampaigns {
+		if campaign.PlayStyle == string(models.PlayStyleAsynchronous) {
+			asynchronous++
+		} else {
+			synchronous++
+		}
+	}
+
+	return map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": report.GeneratedAt.UnixMilli(),
+			"CloudWatchMetrics": []map[string]interface{}{
+				{
+					"Namespace": metricsNamespace,
+					"Dimensions": [][]string{
+						{"Stage", "WindowMinutes"},
+					},
+					"Metrics": []map[string]string{
+						{"Name": "ActiveCampaigns", "Unit": "Count"},
+						{"Name": "ActiveSynchronousCampaigns", "Unit": "Count"},
+						{"Name": "ActiveAsynchronousCampaigns", "Unit": "Count"},
+					},
+				},
+			},
+		},
+		"Stage":                       stage,
+		"WindowMinutes":               strconv.Itoa(report.WindowMinutes),
+		"ActiveCampaigns":             report.Count,
+		"ActiveSynchronousCampaigns":  synchronous,
+		"ActiveAsynchronousCampaigns": asynchronous,
+	}
+}
+
+// emitActivityMetric writes the activity metric as an EMF log line
+func emitActivityMetric(report ActivityReport) {
+	record, err := json.Marshal(buildActivityMetric(stage, report))
+	if err != nil {
+		log.Printf("Warning: failed to marshal activity metric: %v", err)
+		return
+	}
+	fmt.Fprintln(metricsOutput, string(record))
+}
+
+// listRecentlyDeclaredCampaigns scans for campaigns whose last declaration is at or after since.
+// Timestamps are stored as RFC3339 strings, so the filter is a string comparison; buildActivityReport
+// re-checks each campaign against the exact window.
+func listRecentlyDeclaredCampaigns(since time.Time) ([]models.Campaign, error) {
+	var campaigns []models.Campaign
+	var scanErr error
+
+	err := dynamodbClient.ScanPages(&dynamodb.ScanInput{
+		TableName:            aws.String(campaignsTable),
+		FilterExpression:     aws.String("#runtime.#lastDeclarationAt >= :since"),
+		ProjectionExpression: aws.String("campaignId, meta.channelId, #status, playStyle, #runtime.#lastDeclarationAt"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":           aws.String("runtime"),
+			"#lastDeclarationAt": aws.String("lastDeclarationAt"),
+			"#status":            aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":since": {S: aws.String(since.UTC().Format(time.RFC3339))},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pageCampaigns []models.Campaign
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageCampaigns); err != nil {
+			scanErr = fmt.Errorf("failed to unmarshal campaigns: %w", err)
+			return false
+		}
+		campaigns = append(campaigns, pageCampaigns...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return campaigns, nil
+}
+
+func main() {
+	logging.Init("activity")
+	lambda.Start(handler)
+}
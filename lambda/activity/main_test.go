@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	models "loros/syrus-models"
+)
+
+func TestBuildActivityReport(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	minutesAgo := func(m int) *time.Time {
+		at := now.Add(-time.Duration(m) * time.Minute)
+		return &at
+	}
+
+	campaigns := []models.Campaign{
+		{CampaignID: "older", Status: models.CampaignStatusPlaying, Runtime: models.RuntimeState{LastDeclarationAt: minutesAgo(12)}},
+		{CampaignID: "stale", Status: models.CampaignStatusPlaying, Runtime: models.RuntimeState{LastDeclarationAt: minutesAgo(40)}},
+		{CampaignID: "never", Status: models.CampaignStatusActive},
+		{CampaignID: "newest", Status: models.CampaignStatusPlaying, PlayStyle: models.PlayStyleAsynchronous, Runtime: models.RuntimeState{LastDeclarationAt: minutesAgo(1)}},
+	}
+
+	report := buildActivityReport(campaigns, 15, now)
+	if report.Count != 2 || len(report.ActiveCampaigns) != 2 {
+		t.Fatalf("Expected 2 active campaigns, got %d", report.Count)
+	}
+	if report.ActiveCampaigns[0].CampaignID != "newest" || report.ActiveCampaigns[1].CampaignID != "older" {
+		t.Errorf("Expected most recent first, got %s, %s", report.ActiveCampaigns[0].CampaignID, report.ActiveCampaigns[1].CampaignID)
+	}
+	if report.ActiveCampaigns[0].PlayStyle != string(models.PlayStyleAsynchronous) || report.ActiveCampaigns[1].PlayStyle != string(models.PlayStyleSynchronous) {
+		t.Errorf("Expected effective play styles, got %+v", report.ActiveCampaigns)
+	}
+
+	wide := buildActivityReport(campaigns, 60, now)
+	if wide.Count != 3 {
+		t.Errorf("Expected 3 campaigns active within an hour, got %d", wide.Count)
+	}
+
+	empty := buildActivityReport(nil, 15, now)
+	if empty.ActiveCampaigns == nil || empty.Count != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %+v", empty)
+	}
+}
+
+func TestActivityMetricEmitted(t *testing.T) {
+	var buf bytes.Buffer
+	original := metricsOutput
+	metricsOutput = &buf
+	defer func() { metricsOutput = original }()
+
+	report := ActivityReport{
+		WindowMinutes: 15,
+		GeneratedAt:   time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Count:         3,
+		ActiveCampaigns: []ActiveCampaign{
+			{CampaignID: "a", PlayStyle: string(models.PlayStyleSynchronous)},
+			{CampaignID: "b", PlayStyle: string(models.PlayStyleAsynchronous)},
+			{CampaignID: "c", PlayStyle: string(models.PlayStyleSynchronous)},
+		},
+	}
+	emitActivityMetric(report)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON EMF record, got %q: %v", buf.String(), err)
+	}
+	if record["ActiveCampaigns"] != float64(3) || record["ActiveSynchronousCampaigns"] != float64(2) || record["ActiveAsynchronousCampaigns"] != float64(1) {
+		t.Errorf("Unexpected metric values: %v", record)
+	}
+	if record["WindowMinutes"] != "15" {
+		t.Errorf("Expected WindowMinutes dimension \"15\", got %v", record["WindowMinutes"])
+	}
+	if _, ok := record["_aws"]; !ok {
+		t.Error("Expected _aws metadata in EMF record")
+	}
+}
+
+func TestParseWindowMinutes(t *testing.T) {
+	tests := map[string]int{
+		"":       defaultWindowMinutes,
+		"30":     30,
+		"0":      defaultWindowMinutes,
+		"-5":     defaultWindowMinutes,
+		"soon":   defaultWindowMinutes,
+		"999999": maxWindowMinutes,
+	}
+	for raw, expected := range tests {
+		if got := parseWindowMinutes(raw); got != expected {
+			t.Errorf("parseWindowMinutes(%q) = %d, expected %d", raw, got, expected)
+		}
+	}
+}
+
+func TestActivityRequestParsing(t *testing.T) {
+	var scheduled ActivityRequest
+	if err := json.Unmarshal([]byte(`{"source": "aws.events", "detail-type": "Scheduled Event", "detail": {}}`), &scheduled); err != nil {
+		t.Fatalf("Failed to parse scheduled event: %v", err)
+	}
+	if scheduled.Source != scheduledEventSource || scheduled.WindowMinutes != 0 {
+		t.Errorf("Expected scheduled event with default window, got %+v", scheduled)
+	}
+
+	var query ActivityRequest
+	if err := json.Unmarshal([]byte(`{"windowMinutes": 60}`), &query); err != nil {
+		t.Fatalf("Failed to parse admin query: %v", err)
+	}
+	if query.Source == scheduledEventSource || query.WindowMinutes != 60 {
+		t.Errorf("Expected ad-hoc query for 60 minutes, got %+v", query)
+	}
+}
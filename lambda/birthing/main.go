@@ -4,23 +4,76 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"log"
 	"math/rand"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
 	models "loros/syrus-models"
+	sqsbatch "loros/syrus-sqsbatch"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sqs"
 )
 
+// dedupPrefix namespaces this lambda's records in the shared dedup table
+const dedupPrefix = "birthing"
+
+// claimLease holds a message while it is processed. It matches the 30 second lambda timeout and lapses
+// before the birthing queue's 60 second visibility timeout redelivers a crashed invocation's message.
+const claimLease = 30 * time.Second
+
+// AWS clients and configuration, created once per container and reused across invocations
+var (
+	awsSession         *session.Session
+	dynamodbClient     *dynamodb.DynamoDB
+	s3Client           *s3.S3
+	sqsClient          *sqs.SQS
+	campaignsTable     string
+	confirmationsTable string
+	selectionTable     string
+	messagingQueue     string
+	blueprintingQueue  string
+	promptBucket       string
+	stage              string
+	sqsConcurrency     int
+)
+
+func init() {
+	awsSession = session.Must(session.NewSession())
+	dynamodbClient = dynamodb.New(awsSession)
+	s3Client = s3.New(awsSession)
+	sqsClient = sqs.New(awsSession)
+
+	campaignsTable = os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	confirmationsTable = os.Getenv("SYRUS_CONFIRMATIONS_TABLE")
+	selectionTable = os.Getenv("SYRUS_HOST_SELECTION_TABLE")
+	messagingQueue = os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	blueprintingQueue = os.Getenv("SYRUS_BLUEPRINTING_QUEUE_URL")
+	promptBucket = os.Getenv("SYRUS_PROMPT_BUCKET")
+	stage = os.Getenv("SYRUS_STAGE")
+	if stage == "" {
+		stage = "dev"
+	}
+	sqsConcurrency = sqsbatch.ConcurrencyFromEnv()
+}
+
 //go:embed assets/campaign_seed_lambda_config.json
 var configJSON []byte
 
@@ -113,86 +166,191 @@ type AreaData struct {
 	Description string `json:"description"`
 }
 
-// SelectionContext tracks anti-bias state
+// SelectionContext tracks anti-bias state, persisted per host in the host selection table
 type SelectionContext struct {
-	UsedTerrainCategories map[string]int
-	UsedThreatCategories  map[string]int
-	LastCombination       string
+	HostID                string         `json:"hostId" dynamodbav:"hostId"`
+	UsedTerrainCategories map[string]int `json:"usedTerrainCategories" dynamodbav:"usedTerrainCategories"`
+	UsedThreatCategories  map[string]int `json:"usedThreatCategories" dynamodbav:"usedThreatCategories"`
+	LastCombination       string         `json:"lastCombination" dynamodbav:"lastCombination"`
+	RecentCombinations    []string       `json:"recentCombinations" dynamodbav:"recentCombinations"`
+	LastSeed              int64          `json:"lastSeed" dynamodbav:"lastSeed"`
+	UpdatedAt             time.Time      `json:"updatedAt" dynamodbav:"updatedAt"`
 }
 
-// checkDedup checks if a message has already been processed
-func checkDedup(interactionID string) (bool, error) {
-	dedupTable := os.Getenv("SYRUS_DEDUP_TABLE")
-	if dedupTable == "" {
-		return false, fmt.Errorf("SYRUS_DEDUP_TABLE environment variable not set")
+// maxRecentCombinations bounds how many of a host's past combinations are avoided
+const maxRecentCombinations = 10
+
+// maxCombinationAttempts bounds how many times a roll is redrawn to dodge a recent combination
+const maxCombinationAttempts = 5
+
+// Swappable for tests
+var (
+	loadSelectionHistory  = getSelectionContext
+	storeSelectionHistory = saveSelectionContext
+)
+
+// combinationKey identifies the core of a roll: map, objective and antagonists
+func combinationKey(seeds *models.CampaignSeeds) string {
+	antagonistIDs := make([]string, len(seeds.Antagonists))
+	for i, antagonist := range seeds.Antagonists {
+		antagonistIDs[i] = antagonist.AntagonistID
 	}
+	sort.Strings(antagonistIDs)
+	return fmt.Sprintf("%s|%s|%s", seeds.Map.MapID, seeds.Objective.ObjectiveID, strings.Join(antagonistIDs, ","))
+}
 
-	sess, err := session.NewSession()
-	if err != nil {
-		return false, fmt.Errorf("failed to create AWS session: %w", err)
+// Seen reports whether the combination was among the host's recent rolls
+func (c *SelectionContext) Seen(key string) bool {
+	if c == nil {
+		return false
+	}
+	for _, recent := range c.RecentCombinations {
+		if recent == key {
+			return true
+		}
 	}
+	return false
+}
 
-	svc := dynamodb.New(sess)
+// Record adds committed seeds to the history, keeping only the most recent combinations
+func (c *SelectionContext) Record(seeds *models.CampaignSeeds, now time.Time) {
+	if c.UsedTerrainCategories == nil {
+		c.UsedTerrainCategories = make(map[string]int)
+	}
+	if c.UsedThreatCategories == nil {
+		c.UsedThreatCategories = make(map[string]int)
+	}
 
-	dedupKey := fmt.Sprintf("birthing#%s", interactionID)
+	countCategories := func(terrain, threat string) {
+		if terrain != "" {
+			c.UsedTerrainCategories[terrain]++
+		}
+		if threat != "" {
+			c.UsedThreatCategories[threat]++
+		}
+	}
+	countCategories(seeds.Objective.TerrainCategory, seeds.Objective.PrimaryThreatCategory)
+	for _, antagonist := range seeds.Antagonists {
+		countCategories(antagonist.TerrainCategory, antagonist.PrimaryThreatCategory)
+	}
 
-	result, err := svc.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(dedupTable),
+	key := combinationKey(seeds)
+	c.LastCombination = key
+	c.RecentCombinations = append(c.RecentCombinations, key)
+	if len(c.RecentCombinations) > maxRecentCombinations {
+		c.RecentCombinations = c.RecentCombinations[len(c.RecentCombinations)-maxRecentCombinations:]
+	}
+	c.LastSeed = seeds.RandomSeed
+	c.UpdatedAt = now.UTC()
+}
+
+// deriveCampaignSeed derives a per-campaign RNG seed, so two campaigns born in the same instant still differ
+func deriveCampaignSeed(campaignID string, now time.Time) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(campaignID))
+	return int64(hash.Sum64()) ^ now.UnixNano()
+}
+
+// generateDistinctSeeds generates seeds, redrawing with successive seeds while the roll repeats one of the host's recent combinations
+func generateDistinctSeeds(campaign *models.Campaign, previous *models.CampaignSeeds, locked []models.SeedCategory, history *SelectionContext, baseSeed int64) (*models.CampaignSeeds, error) {
+	var seeds *models.CampaignSeeds
+	for attempt := 0; attempt < maxCombinationAttempts; attempt++ {
+		var err error
+		seeds, err = generateBlueprintSeeds(campaign, previous, locked, baseSeed+int64(attempt))
+		if err != nil {
+			return nil, err
+		}
+		if !history.Seen(combinationKey(seeds)) {
+			return seeds, nil
+		}
+		log.Printf("Seed %d repeats a recent combination for host %s, redrawing", seeds.RandomSeed, campaign.HostID)
+	}
+	// Small content pools can run out of fresh combinations; a repeat beats failing the birth
+	log.Printf("Warning: no fresh combination after %d attempts, keeping seed %d", maxCombinationAttempts, seeds.RandomSeed)
+	return seeds, nil
+}
+
+// getSelectionContext loads a host's selection history, returning an empty context if there is none
+func getSelectionContext(hostID string) (*SelectionContext, error) {
+	if selectionTable == "" {
+		return nil, fmt.Errorf("SYRUS_HOST_SELECTION_TABLE environment variable not set")
+	}
+
+	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(selectionTable),
 		Key: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {S: aws.String(dedupKey)},
+			"hostId": {S: aws.String(hostID)},
 		},
 	})
-
 	if err != nil {
-		return false, fmt.Errorf("failed to query dedup table: %w", err)
+		return nil, fmt.Errorf("failed to get selection history: %w", err)
 	}
 
-	return result.Item != nil, nil
+	selection := &SelectionContext{HostID: hostID}
+	if result.Item == nil {
+		return selection, nil
+	}
+	if err := dynamodbattribute.UnmarshalMap(result.Item, selection); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal selection history: %w", err)
+	}
+
+	return selection, nil
 }
 
-// writeDedup writes a deduplication record
-func writeDedup(interactionID string) error {
-	dedupTable := os.Getenv("SYRUS_DEDUP_TABLE")
-	if dedupTable == "" {
-		return fmt.Errorf("SYRUS_DEDUP_TABLE environment variable not set")
+// saveSelectionContext writes a host's selection history
+func saveSelectionContext(selection *SelectionContext) error {
+	if selectionTable == "" {
+		return fmt.Errorf("SYRUS_HOST_SELECTION_TABLE environment variable not set")
 	}
 
-	sess, err := session.NewSession()
+	item, err := dynamodbattribute.MarshalMap(selection)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
+		return fmt.Errorf("failed to marshal selection history: %w", err)
 	}
 
-	svc := dynamodb.New(sess)
-
-	dedupKey := fmt.Sprintf("birthing#%s", interactionID)
-	expiresAt := time.Now().Add(24 * time.Hour).Unix()
-
-	_, err = svc.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(dedupTable),
-		Item: map[string]*dynamodb.AttributeValue{
-			"dedupKey":  {S: aws.String(dedupKey)},
-			"expiresAt": {N: aws.String(fmt.Sprintf("%d", expiresAt))},
-		},
+	_, err = dynamodbClient.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(selectionTable),
+		Item:      item,
 	})
 
 	return err
 }
 
+// selectionHistoryFor loads the host's history, degrading to none so birthing never fails on it
+func selectionHistoryFor(hostID string) *SelectionContext {
+	if hostID == "" {
+		return nil
+	}
+	history, err := loadSelectionHistory(hostID)
+	if err != nil {
+		log.Printf("Warning: failed to load selection history for host %s: %v", hostID, err)
+		return nil
+	}
+	return history
+}
+
+// recordSelection persists committed seeds into the host's history
+func recordSelection(hostID string, seeds *models.CampaignSeeds) {
+	if hostID == "" {
+		return
+	}
+	history := selectionHistoryFor(hostID)
+	if history == nil {
+		history = &SelectionContext{HostID: hostID}
+	}
+	history.Record(seeds, time.Now())
+	if err := storeSelectionHistory(history); err != nil {
+		log.Printf("Warning: failed to save selection history for host %s: %v", hostID, err)
+	}
+}
+
 // getCampaignByID retrieves a campaign by ID
 func getCampaignByID(campaignID string) (*models.Campaign, error) {
-	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
 	if campaignsTable == "" {
 		return nil, fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
 	}
 
-	sess, err := session.NewSession()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create AWS session: %w", err)
-	}
-
-	svc := dynamodb.New(sess)
-
-	result, err := svc.GetItem(&dynamodb.GetItemInput{
+	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
 		TableName: aws.String(campaignsTable),
 		Key: map[string]*dynamodb.AttributeValue{
 			"campaignId": {S: aws.String(campaignID)},
@@ -217,18 +375,10 @@ func getCampaignByID(campaignID string) (*models.Campaign, error) {
 
 // sendToMessagingQueue sends a message to the messaging SQS queue
 func sendToMessagingQueue(channelID, content, interactionID string) error {
-	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
-	if queueURL == "" {
+	if messagingQueue == "" {
 		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
 	}
 
-	sess, err := session.NewSession()
-	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
-	}
-
-	svc := sqs.New(sess)
-
 	message := models.MessagingQueueMessage{
 		ChannelID: channelID,
 		Content:   content,
@@ -239,8 +389,8 @@ func sendToMessagingQueue(channelID, content, interactionID string) error {
 		return fmt.Errorf("failed to marshal message body: %w", err)
 	}
 
-	_, err = svc.SendMessage(&sqs.SendMessageInput{
-		QueueUrl:               aws.String(queueURL),
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(messagingQueue),
 		MessageBody:            aws.String(string(messageBodyJSON)),
 		MessageGroupId:         aws.String(channelID),                 // Group by campaignID
 		MessageDeduplicationId: aws.String(interactionID + "-seeded"), // Dedupe by interactionID
@@ -256,25 +406,17 @@ func sendToMessagingQueue(channelID, content, interactionID string) error {
 
 // sendToBlueprintingQueue sends a BlueprintMessage to the blueprinting SQS queue
 func sendToBlueprintingQueue(blueprintMsg models.BlueprintMessage) error {
-	queueURL := os.Getenv("SYRUS_BLUEPRINTING_QUEUE_URL")
-	if queueURL == "" {
+	if blueprintingQueue == "" {
 		return fmt.Errorf("SYRUS_BLUEPRINTING_QUEUE_URL environment variable not set")
 	}
 
-	sess, err := session.NewSession()
-	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
-	}
-
-	svc := sqs.New(sess)
-
 	messageBodyJSON, err := json.Marshal(blueprintMsg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal blueprint message: %w", err)
 	}
 
-	_, err = svc.SendMessage(&sqs.SendMessageInput{
-		QueueUrl:               aws.String(queueURL),
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(blueprintingQueue),
 		MessageBody:            aws.String(string(messageBodyJSON)),
 		MessageGroupId:         aws.String(blueprintMsg.CampaignID),
 		MessageDeduplicationId: aws.String(blueprintMsg.InteractionID + "-blueprint"),
@@ -289,7 +431,7 @@ func sendToBlueprintingQueue(blueprintMsg models.BlueprintMessage) error {
 }
 
 // selectRandomMap selects a random map from the maps data
-func selectRandomMap(mapsData map[string]MapData) (string, MapData) {
+func selectRandomMap(rng *rand.Rand, mapsData map[string]MapData) (string, MapData) {
 	keys := make([]string, 0, len(mapsData))
 	for k := range mapsData {
 		keys = append(keys, k)
@@ -297,20 +439,22 @@ func selectRandomMap(mapsData map[string]MapData) (string, MapData) {
 	if len(keys) == 0 {
 		return "", MapData{}
 	}
-	mapID := keys[rand.Intn(len(keys))]
+	// Map iteration order is randomized; sort so a fixed seed always picks the same map
+	sort.Strings(keys)
+	mapID := keys[rng.Intn(len(keys))]
 	return mapID, mapsData[mapID]
 }
 
 // selectFeaturedAreas selects random areas from a map
-func selectFeaturedAreas(mapData MapData, count int) []AreaData {
+func selectFeaturedAreas(rng *rand.Rand, mapData MapData, count int) []AreaData {
 	if count > len(mapData.Areas) {
 		count = len(mapData.Areas)
 	}
-	return selectRandomElements(mapData.Areas, count, count)
+	return selectRandomElements(rng, mapData.Areas, count, count)
 }
 
 // selectObjectiveWithBias selects an objective while avoiding recent patterns
-func selectObjectiveWithBias(objectives []models.ObjectiveSeed, profile LengthProfile) models.ObjectiveSeed {
+func selectObjectiveWithBias(rng *rand.Rand, objectives []models.ObjectiveSeed, profile LengthProfile) models.ObjectiveSeed {
 	eligible := make([]models.ObjectiveSeed, 0)
 	preferred := make([]models.ObjectiveSeed, 0)
 
@@ -347,8 +491,8 @@ func selectObjectiveWithBias(objectives []models.ObjectiveSeed, profile LengthPr
 	}
 
 	// If we have preferred actionable objectives, strongly bias towards them (80%)
-	if len(preferred) > 0 && rand.Float32() < 0.8 {
-		return preferred[rand.Intn(len(preferred))]
+	if len(preferred) > 0 && rng.Float32() < 0.8 {
+		return preferred[rng.Intn(len(preferred))]
 	}
 
 	// If no eligible, fall back to all non-excluded
@@ -365,14 +509,14 @@ func selectObjectiveWithBias(objectives []models.ObjectiveSeed, profile LengthPr
 		eligible = objectives
 	}
 
-	return eligible[rand.Intn(len(eligible))]
+	return eligible[rng.Intn(len(eligible))]
 }
 
 // selectAntagonistsWithBias selects antagonists while enforcing diversity
-func selectAntagonistsWithBias(antagonists []models.AntagonistSeed, profile LengthProfile, min, max int) []models.AntagonistSeed {
+func selectAntagonistsWithBias(rng *rand.Rand, antagonists []models.AntagonistSeed, profile LengthProfile, min, max int) []models.AntagonistSeed {
 	count := min
 	if max > min {
-		count = min + rand.Intn(max-min+1)
+		count = min + rng.Intn(max-min+1)
 	}
 	if count > len(antagonists) {
 		count = len(antagonists)
@@ -400,7 +544,7 @@ func selectAntagonistsWithBias(antagonists []models.AntagonistSeed, profile Leng
 
 	// FIRST: Ensure at least one direct antagonist
 	if len(directAntagonists) > 0 {
-		idx := rand.Intn(len(directAntagonists))
+		idx := rng.Intn(len(directAntagonists))
 		selected = append(selected, directAntagonists[idx])
 		if directAntagonists[idx].PrimaryThreatCategory == "metaphysical" {
 			metaphysicalCount++
@@ -421,7 +565,7 @@ func selectAntagonistsWithBias(antagonists []models.AntagonistSeed, profile Leng
 		}
 
 		for len(selected) < count && len(eligible) > 0 {
-			idx := rand.Intn(len(eligible))
+			idx := rng.Intn(len(eligible))
 			ant := eligible[idx]
 
 			// Skip if metaphysical and already at cap
@@ -446,7 +590,7 @@ func selectAntagonistsWithBias(antagonists []models.AntagonistSeed, profile Leng
 	}
 
 	// Standard random selection with metaphysical cap
-	rand.Shuffle(len(eligible), func(i, j int) { eligible[i], eligible[j] = eligible[j], eligible[i] })
+	rng.Shuffle(len(eligible), func(i, j int) { eligible[i], eligible[j] = eligible[j], eligible[i] })
 	for len(selected) < count && len(eligible) > 0 {
 		ant := eligible[0]
 		eligible = eligible[1:]
@@ -466,7 +610,7 @@ func selectAntagonistsWithBias(antagonists []models.AntagonistSeed, profile Leng
 }
 
 // generateVarianceInjectors creates sameness killers based on campaign type
-func generateVarianceInjectors(profile LengthProfile, config *CampaignConfig) (string, string, string, []string) {
+func generateVarianceInjectors(rng *rand.Rand, profile LengthProfile, config *CampaignConfig) (string, string, string, []string) {
 	var genreModifier, perspectiveBias, environmentalOddity string
 	excludedMotifs := make([]string, 0)
 
@@ -474,26 +618,26 @@ func generateVarianceInjectors(profile LengthProfile, config *CampaignConfig) (s
 
 	// Select genre modifier
 	if killersCount > 0 && len(config.SamenessKillers.GenreModifiers) > 0 {
-		genreModifier = config.SamenessKillers.GenreModifiers[rand.Intn(len(config.SamenessKillers.GenreModifiers))]
+		genreModifier = config.SamenessKillers.GenreModifiers[rng.Intn(len(config.SamenessKillers.GenreModifiers))]
 	}
 
 	// Select perspective bias
 	if profile.VarianceRules.RequirePerspectiveBias && len(config.SamenessKillers.PerspectiveBiases) > 0 {
-		perspectiveBias = config.SamenessKillers.PerspectiveBiases[rand.Intn(len(config.SamenessKillers.PerspectiveBiases))]
+		perspectiveBias = config.SamenessKillers.PerspectiveBiases[rng.Intn(len(config.SamenessKillers.PerspectiveBiases))]
 	}
 
 	// Randomly select environmental oddity
-	if killersCount > 1 && rand.Float32() < 0.4 && len(config.SamenessKillers.EnvironmentalOddities) > 0 {
-		environmentalOddity = config.SamenessKillers.EnvironmentalOddities[rand.Intn(len(config.SamenessKillers.EnvironmentalOddities))]
+	if killersCount > 1 && rng.Float32() < 0.4 && len(config.SamenessKillers.EnvironmentalOddities) > 0 {
+		environmentalOddity = config.SamenessKillers.EnvironmentalOddities[rng.Intn(len(config.SamenessKillers.EnvironmentalOddities))]
 	}
 
 	// Select 2-3 excluded motifs
 	if len(config.ExcludableMotifs) > 0 {
-		motifsCount := 2 + rand.Intn(2) // 2 or 3
+		motifsCount := 2 + rng.Intn(2) // 2 or 3
 		if motifsCount > len(config.ExcludableMotifs) {
 			motifsCount = len(config.ExcludableMotifs)
 		}
-		excludedMotifs = selectRandomElements(config.ExcludableMotifs, motifsCount, motifsCount)
+		excludedMotifs = selectRandomElements(rng, config.ExcludableMotifs, motifsCount, motifsCount)
 	}
 
 	// Add default excludes from variance rules
@@ -514,23 +658,23 @@ func generateVarianceInjectors(profile LengthProfile, config *CampaignConfig) (s
 }
 
 // generateExpectationViolation creates an expectation break for an act
-func generateExpectationViolation(beatProfile BeatProfile) *models.ExpectationBreak {
+func generateExpectationViolation(rng *rand.Rand, beatProfile BeatProfile) *models.ExpectationBreak {
 	if beatProfile.Acts < 2 {
 		return nil
 	}
 
 	types := []string{"inversion", "removal", "prematureResolution"}
-	actNumber := 2 + rand.Intn(beatProfile.Acts-1) // Acts 2-N
+	actNumber := 2 + rng.Intn(beatProfile.Acts-1) // Acts 2-N
 
 	return &models.ExpectationBreak{
 		ActNumber: actNumber,
-		Type:      types[rand.Intn(len(types))],
+		Type:      types[rng.Intn(len(types))],
 	}
 }
 
 // selectRandomElements selects a random number of elements from a slice
-func selectRandomElements[T any](items []T, min, max int) []T {
-	count := rand.Intn(max-min+1) + min
+func selectRandomElements[T any](rng *rand.Rand, items []T, min, max int) []T {
+	count := rng.Intn(max-min+1) + min
 	if count > len(items) {
 		count = len(items)
 	}
@@ -538,16 +682,28 @@ func selectRandomElements[T any](items []T, min, max int) []T {
 	// Shuffle and take first N
 	shuffled := make([]T, len(items))
 	copy(shuffled, items)
-	rand.Shuffle(len(shuffled), func(i, j int) {
+	rng.Shuffle(len(shuffled), func(i, j int) {
 		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
 	})
 
 	return shuffled[:count]
 }
 
+// constraintWeight is how heavily a constraint counts in selection: an unset weight counts as 1, and a
+// negative weight (rejected when seeds are loaded) never counts
+func constraintWeight(c models.ConstraintSeed) int {
+	switch {
+	case c.Weight == 0:
+		return 1 // Default weight
+	case c.Weight < 0:
+		return 0
+	}
+	return c.Weight
+}
+
 // selectWeightedConstraints selects constraints based on weight (D&D bias: attrition > politics)
-func selectWeightedConstraints(constraints []models.ConstraintSeed, min, max int) []models.ConstraintSeed {
-	count := rand.Intn(max-min+1) + min
+func selectWeightedConstraints(rng *rand.Rand, constraints []models.ConstraintSeed, min, max int) []models.ConstraintSeed {
+	count := rng.Intn(max-min+1) + min
 	if count > len(constraints) {
 		count = len(constraints)
 	}
@@ -555,16 +711,6 @@ func selectWeightedConstraints(constraints []models.ConstraintSeed, min, max int
 		return []models.ConstraintSeed{}
 	}
 
-	// Calculate total weight
-	totalWeight := 0
-	for _, c := range constraints {
-		weight := c.Weight
-		if weight == 0 {
-			weight = 1 // Default weight
-		}
-		totalWeight += weight
-	}
-
 	selected := make([]models.ConstraintSeed, 0, count)
 	remaining := make([]models.ConstraintSeed, len(constraints))
 	copy(remaining, constraints)
@@ -572,29 +718,25 @@ func selectWeightedConstraints(constraints []models.ConstraintSeed, min, max int
 	// Select 'count' constraints using weighted random selection
 	for i := 0; i < count && len(remaining) > 0; i++ {
 		// Recalculate total weight for remaining items
-		totalWeight = 0
+		totalWeight := 0
 		for _, c := range remaining {
-			weight := c.Weight
-			if weight == 0 {
-				weight = 1
-			}
-			totalWeight += weight
+			totalWeight += constraintWeight(c)
 		}
 
-		// Pick a random number and find which constraint it falls into
-		r := rand.Intn(totalWeight)
-		sum := 0
+		// With no weight left to draw on, every remaining constraint is equally likely
 		selectedIdx := 0
-
-		for idx, c := range remaining {
-			weight := c.Weight
-			if weight == 0 {
-				weight = 1
-			}
-			sum += weight
-			if r < sum {
-				selectedIdx = idx
-				break
+		if totalWeight <= 0 {
+			selectedIdx = rng.Intn(len(remaining))
+		} else {
+			// Pick a random number and find which constraint it falls into
+			r := rng.Intn(totalWeight)
+			sum := 0
+			for idx, c := range remaining {
+				sum += constraintWeight(c)
+				if r < sum {
+					selectedIdx = idx
+					break
+				}
 			}
 		}
 
@@ -606,23 +748,215 @@ func selectWeightedConstraints(constraints []models.ConstraintSeed, min, max int
 	return selected
 }
 
-// generateBlueprintSeeds generates random campaign seeds based on campaign type
-func generateBlueprintSeeds(campaign *models.Campaign) (*models.CampaignSeeds, error) {
-	// Parse configuration
-	var config CampaignConfig
-	if err := json.Unmarshal(configJSON, &config); err != nil {
+// contentCacheTTL bounds how long loaded seed content is reused before the bucket is checked again
+const contentCacheTTL = 5 * time.Minute
+
+// Seed content asset names, used as object names under <stage>/ in the prompt bucket
+const (
+	contentAssetConfig = "campaign_seed_lambda_config.json"
+	contentAssetSeeds  = "campaign-blueprint-seeds.json"
+	contentAssetMaps   = "arcanos_maps.json"
+)
+
+type cachedContent struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+var (
+	contentCacheMu sync.Mutex
+	contentCache   = map[string]cachedContent{}
+
+	// fetchContentOverride is swapped out in tests
+	fetchContentOverride = fetchContentOverrideFromS3
+)
+
+// loadContent decodes a seed content asset, preferring the stage override in SYRUS_PROMPT_BUCKET.
+// An override is only used if it decodes and passes validate; otherwise the embedded default is used.
+// Overrides (and fallbacks for absent/invalid overrides) are cached for contentCacheTTL.
+func loadContent[T any](name string, fallback []byte, validate func(T) error) (T, error) {
+	data := resolveContent(name, fallback, func(candidate []byte) error {
+		_, err := decodeContent(candidate, validate)
+		return err
+	})
+	return decodeContent(data, validate)
+}
+
+func decodeContent[T any](data []byte, validate func(T) error) (T, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+	if err := validate(value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// resolveContent returns the raw bytes to decode for an asset: a valid stage override, or the fallback
+func resolveContent(name string, fallback []byte, check func([]byte) error) []byte {
+	bucket := promptBucket
+	if bucket == "" {
+		return fallback
+	}
+
+	key := fmt.Sprintf("%s/%s", stage, name)
+
+	contentCacheMu.Lock()
+	defer contentCacheMu.Unlock()
+
+	if cached, ok := contentCache[key]; ok && time.Now().Before(cached.expiresAt) {
+		return cached.data
+	}
+
+	data := fallback
+	override, found, err := fetchContentOverride(bucket, key)
+	switch {
+	case err != nil:
+		// Don't cache failures - retry the bucket on the next call
+		log.Printf("Warning: failed to load content override s3://%s/%s, using embedded default: %v", bucket, key, err)
+		return fallback
+	case found:
+		if err := check(override); err != nil {
+			log.Printf("Warning: ignoring invalid content override s3://%s/%s, using embedded default: %v", bucket, key, err)
+		} else {
+			log.Printf("Using content override s3://%s/%s", bucket, key)
+			data = override
+		}
+	}
+
+	contentCache[key] = cachedContent{data: data, expiresAt: time.Now().Add(contentCacheTTL)}
+	return data
+}
+
+// s3ErrCodeNotFound is the code HeadObject reports for a missing key; HEAD responses have no body
+// to carry NoSuchKey, and the SDK defines no constant for it
+const s3ErrCodeNotFound = "NotFound"
+
+// isS3NotFound reports whether err is S3's missing-object error: NoSuchKey from GetObject, or NotFound from HeadObject
+func isS3NotFound(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	return awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == s3ErrCodeNotFound
+}
+
+func fetchContentOverrideFromS3(bucket, key string) ([]byte, bool, error) {
+	result, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// validateCampaignConfig checks that every campaign type has both a length and a beat profile
+func validateCampaignConfig(config CampaignConfig) error {
+	if len(config.CampaignLengthProfiles) == 0 {
+		return fmt.Errorf("no campaign length profiles")
+	}
+	for campaignType := range config.CampaignLengthProfiles {
+		if _, ok := config.BeatProfiles[campaignType]; !ok {
+			return fmt.Errorf("missing beat profile for campaign type %q", campaignType)
+		}
+	}
+	minActs, maxActs := actBounds(config)
+	for campaignType, beatProfile := range config.BeatProfiles {
+		if beatProfile.Acts < minActs || beatProfile.Acts > maxActs {
+			return fmt.Errorf("beat profile %q requests %d acts, outside the allowed %d-%d", campaignType, beatProfile.Acts, minActs, maxActs)
+		}
+	}
+	return nil
+}
+
+// Act-count sanity bounds, used when the config's globalLimits don't set minActs/maxActsHardCap
+const (
+	defaultMinActs = 1
+	defaultMaxActs = 7
+)
+
+// actBounds returns the allowed act range from the config's globalLimits.
+// Every act grows the blueprint prompt and cost, so a misconfigured profile is rejected rather than blueprinted.
+func actBounds(config CampaignConfig) (int, int) {
+	limit := func(name string, fallback int) int {
+		if value, ok := config.GlobalLimits[name].(float64); ok && value > 0 {
+			return int(value)
+		}
+		return fallback
+	}
+	return limit("minActs", defaultMinActs), limit("maxActsHardCap", defaultMaxActs)
+}
+
+// validateCampaignSeeds checks that every pool generation draws from is non-empty and that no constraint
+// carries a negative selection weight
+func validateCampaignSeeds(seeds CampaignSeeds) error {
+	pools := []struct {
+		name  string
+		count int
+	}{
+		{"objectiveSeeds", len(seeds.ObjectiveSeeds)},
+		{"twistCandidates", len(seeds.TwistCandidates)},
+		{"antagonistCandidates", len(seeds.AntagonistCandidates)},
+		{"setPieceCandidates", len(seeds.SetPieceCandidates)},
+		{"startingLocationSeeds", len(seeds.StartingLocationSeeds)},
+	}
+	for _, pool := range pools {
+		if pool.count == 0 {
+			return fmt.Errorf("%s is empty", pool.name)
+		}
+	}
+	for _, constraint := range seeds.OptionalConstraints {
+		if constraint.Weight < 0 {
+			return fmt.Errorf("optionalConstraints %s has negative weight %d", constraint.ConstraintID, constraint.Weight)
+		}
+	}
+	return nil
+}
+
+// validateMapsData checks that there is at least one map and every map has areas to feature
+func validateMapsData(mapsData map[string]MapData) error {
+	if len(mapsData) == 0 {
+		return fmt.Errorf("no maps")
+	}
+	for mapID, mapData := range mapsData {
+		if len(mapData.Areas) == 0 {
+			return fmt.Errorf("map %s has no areas", mapID)
+		}
+	}
+	return nil
+}
+
+// generateBlueprintSeeds generates random campaign seeds based on campaign type.
+// When previous seeds are given, the locked categories are carried over from them (used by rerolls).
+// The same seed reproduces the same roll for a given content set.
+func generateBlueprintSeeds(campaign *models.Campaign, previous *models.CampaignSeeds, locked []models.SeedCategory, seed int64) (*models.CampaignSeeds, error) {
+	// Parse configuration (stage override or embedded)
+	config, err := loadContent(contentAssetConfig, configJSON, validateCampaignConfig)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
 	// Parse seeds
-	var seeds CampaignSeeds
-	if err := json.Unmarshal(seedsJSON, &seeds); err != nil {
+	seeds, err := loadContent(contentAssetSeeds, seedsJSON, validateCampaignSeeds)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse seeds: %w", err)
 	}
 
 	// Parse maps
-	var mapsData map[string]MapData
-	if err := json.Unmarshal(mapsJSON, &mapsData); err != nil {
+	mapsData, err := loadContent(contentAssetMaps, mapsJSON, validateMapsData)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse maps: %w", err)
 	}
 
@@ -641,12 +975,12 @@ func generateBlueprintSeeds(campaign *models.Campaign) (*models.CampaignSeeds, e
 
 	log.Printf("Generating seeds for campaign type '%s' with profile: %+v", profileKey, profile.Selection)
 
-	// Seed random number generator
-	rand.Seed(time.Now().UnixNano())
+	// Draw from a source of this invocation's own, so concurrent invocations never share or reseed one
+	rng := rand.New(rand.NewSource(seed))
 
 	// Select map and featured areas
-	mapID, selectedMap := selectRandomMap(mapsData)
-	featuredAreas := selectFeaturedAreas(selectedMap, profile.Selection.FeaturedAreas.Min+rand.Intn(profile.Selection.FeaturedAreas.Max-profile.Selection.FeaturedAreas.Min+1))
+	mapID, selectedMap := selectRandomMap(rng, mapsData)
+	featuredAreas := selectFeaturedAreas(rng, selectedMap, profile.Selection.FeaturedAreas.Min+rng.Intn(profile.Selection.FeaturedAreas.Max-profile.Selection.FeaturedAreas.Min+1))
 
 	// Convert to model types
 	mapSeed := models.MapSeed{
@@ -666,43 +1000,44 @@ func generateBlueprintSeeds(campaign *models.Campaign) (*models.CampaignSeeds, e
 	}
 
 	// Generate variance injectors
-	genreModifier, perspectiveBias, environmentalOddity, excludedMotifs := generateVarianceInjectors(profile, &config)
+	genreModifier, perspectiveBias, environmentalOddity, excludedMotifs := generateVarianceInjectors(rng, profile, &config)
 
 	// Select objective with bias
-	objective := selectObjectiveWithBias(seeds.ObjectiveSeeds, profile)
+	objective := selectObjectiveWithBias(rng, seeds.ObjectiveSeeds, profile)
 
 	// Select antagonists with bias for diversity
-	antagonists := selectAntagonistsWithBias(seeds.AntagonistCandidates, profile, profile.Selection.Antagonists.Min, profile.Selection.Antagonists.Max)
+	antagonists := selectAntagonistsWithBias(rng, seeds.AntagonistCandidates, profile, profile.Selection.Antagonists.Min, profile.Selection.Antagonists.Max)
 
 	// Generate expectation violation if required
 	var expectationViolation *models.ExpectationBreak
 	if profile.VarianceRules.RequireExpectationViolation {
-		expectationViolation = generateExpectationViolation(beatProfile)
+		expectationViolation = generateExpectationViolation(rng, beatProfile)
 	}
 
 	// Select random starting location
 	if len(seeds.StartingLocationSeeds) == 0 {
 		return nil, fmt.Errorf("no starting location seeds available")
 	}
-	selectedLocation := seeds.StartingLocationSeeds[rand.Intn(len(seeds.StartingLocationSeeds))]
+	selectedLocation := seeds.StartingLocationSeeds[rng.Intn(len(seeds.StartingLocationSeeds))]
 
 	// Select random seeds based on profile rules
 	result := &models.CampaignSeeds{
 		Objective:            objective,
-		Twists:               selectRandomElements(seeds.TwistCandidates, profile.Selection.Twists.Min, profile.Selection.Twists.Max),
+		Twists:               selectRandomElements(rng, seeds.TwistCandidates, profile.Selection.Twists.Min, profile.Selection.Twists.Max),
 		Antagonists:          antagonists,
-		SetPieces:            selectRandomElements(seeds.SetPieceCandidates, profile.Selection.SetPieces.Min, profile.Selection.SetPieces.Max),
-		Constraints:          selectWeightedConstraints(seeds.OptionalConstraints, profile.Selection.Constraints.Min, profile.Selection.Constraints.Max),
+		SetPieces:            selectRandomElements(rng, seeds.SetPieceCandidates, profile.Selection.SetPieces.Min, profile.Selection.SetPieces.Max),
+		Constraints:          selectWeightedConstraints(rng, seeds.OptionalConstraints, profile.Selection.Constraints.Min, profile.Selection.Constraints.Max),
 		StartingLocation:     selectedLocation,
 		Map:                  mapSeed,
 		FeaturedAreas:        areaSeed,
 		MaxCombatScenes:      profile.MaxCombatScenes,
 		GenreModifier:        genreModifier,
 		PerspectiveBias:      perspectiveBias,
-		MoralAsymmetry:       rand.Float32() < 0.3, // 30% chance
+		MoralAsymmetry:       rng.Float32() < 0.3, // 30% chance
 		EnvironmentalOddity:  environmentalOddity,
 		ExcludedMotifs:       excludedMotifs,
 		ExpectationViolation: expectationViolation,
+		RandomSeed:           seed,
 		BeatProfile: models.BeatProfile{
 			Acts: beatProfile.Acts,
 			BeatsPerAct: models.MinMaxRange{
@@ -714,11 +1049,16 @@ func generateBlueprintSeeds(campaign *models.Campaign) (*models.CampaignSeeds, e
 		},
 	}
 
-	log.Printf("Selected seeds: map=%s, areas=%d, objective=%s, twists=%d, antagonists=%d, setPieces=%d, constraints=%d, maxCombat=%d",
-		mapID, len(featuredAreas), result.Objective.ObjectiveID, len(result.Twists), len(result.Antagonists), len(result.SetPieces), len(result.Constraints), result.MaxCombatScenes)
-	log.Printf("Variance: genre=%s, perspective=%s, oddity=%s, excludedMotifs=%v", genreModifier, perspectiveBias, environmentalOddity, excludedMotifs)
-	if expectationViolation != nil {
-		log.Printf("Expectation violation: act=%d, type=%s", expectationViolation.ActNumber, expectationViolation.Type)
+	if previous != nil && len(locked) > 0 {
+		applySeedLocks(result, previous, locked)
+		log.Printf("Locked seed categories carried over from previous roll: %v", locked)
+	}
+
+	log.Printf("Seed %d selected: map=%s, areas=%d, objective=%s, twists=%d, antagonists=%d, setPieces=%d, constraints=%d, maxCombat=%d",
+		seed, result.Map.MapID, len(result.FeaturedAreas), result.Objective.ObjectiveID, len(result.Twists), len(result.Antagonists), len(result.SetPieces), len(result.Constraints), result.MaxCombatScenes)
+	log.Printf("Variance: genre=%s, perspective=%s, oddity=%s, excludedMotifs=%v", result.GenreModifier, result.PerspectiveBias, result.EnvironmentalOddity, result.ExcludedMotifs)
+	if result.ExpectationViolation != nil {
+		log.Printf("Expectation violation: act=%d, type=%s", result.ExpectationViolation.ActNumber, result.ExpectationViolation.Type)
 	}
 	log.Printf("Beat profile: acts=%d, beatsPerAct=%d-%d, avgMinutesPerBeat=%d",
 		result.BeatProfile.Acts, result.BeatProfile.BeatsPerAct.Min, result.BeatProfile.BeatsPerAct.Max, result.BeatProfile.AvgMinutesPerBeat)
@@ -726,67 +1066,310 @@ func generateBlueprintSeeds(campaign *models.Campaign) (*models.CampaignSeeds, e
 	return result, nil
 }
 
-// processSQSMessage processes a single SQS message
-func processSQSMessage(message events.SQSMessage, stage string) error {
-	// Parse message body
-	var messageBody models.BirthingMessage
-	if err := json.Unmarshal([]byte(message.Body), &messageBody); err != nil {
-		return fmt.Errorf("failed to parse message body: %w", err)
+// seedsPreviewTTL is how long pending preview seeds wait for the host's decision
+const seedsPreviewTTL = 15 * time.Minute
+
+// maxSeedRerolls bounds how many times a host can reroll seeds for one campaign preview
+const maxSeedRerolls = 3
+
+// maxCampaignRerolls bounds how many times a host can reroll a campaign straight into a new blueprint.
+// Each one pays for another blueprint generation, so unlike preview rerolls the count never resets.
+const maxCampaignRerolls = 3
+
+// campaignRerollRefusal returns the in-character reason a campaign may not be rerolled into a new
+// blueprint, or "" when it may
+func campaignRerollRefusal(campaign *models.Campaign) string {
+	if campaign.Status != models.CampaignStatusConfiguring {
+		return "The weaving has already begun. These seeds are sown and cannot be cast again."
+	}
+	if campaign.SeedRerolls >= maxCampaignRerolls {
+		return "The seeds have been cast enough. Fate grows weary of indecision. Let the weaving take what shape it will."
 	}
+	return ""
+}
 
-	log.Printf("Processing birthing message for campaign %s", messageBody.CampaignID)
+// blueprintInteractionID gives each reroll its own blueprint interaction, so blueprinting's dedup
+// does not mistake it for the weaving it replaces
+func blueprintInteractionID(interactionID string, reroll int) string {
+	if reroll == 0 {
+		return interactionID
+	}
+	return fmt.Sprintf("%s-reroll-%d", interactionID, reroll)
+}
 
-	// Validate required fields
-	if messageBody.CampaignID == "" {
-		return fmt.Errorf("missing required field: campaignId")
+// claimCampaignReroll counts a reroll against the campaign and returns its number. The write is
+// conditional on the campaign still configuring and under the limit, so concurrent rerolls cannot
+// exceed it; claimed is false when the condition fails.
+func claimCampaignReroll(campaignID string) (reroll int, claimed bool, err error) {
+	if campaignsTable == "" {
+		return 0, false, fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
 	}
-	if messageBody.InteractionID == "" {
-		return fmt.Errorf("missing required field: interactionId")
+
+	result, err := dynamodbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:    aws.String("SET lastUpdatedAt = :lastUpdatedAt ADD seedRerolls :one"),
+		ConditionExpression: aws.String("#status = :configuring AND (attribute_not_exists(seedRerolls) OR seedRerolls < :max)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one":           {N: aws.String("1")},
+			":max":           {N: aws.String(fmt.Sprintf("%d", maxCampaignRerolls))},
+			":configuring":   {S: aws.String(string(models.CampaignStatusConfiguring))},
+			":lastUpdatedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		if dedup.IsConditionalCheckFailed(err) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to claim campaign reroll: %w", err)
+	}
+
+	if rerollsAttr, ok := result.Attributes["seedRerolls"]; ok && rerollsAttr.N != nil {
+		fmt.Sscanf(*rerollsAttr.N, "%d", &reroll)
 	}
+	return reroll, true, nil
+}
+
+// PendingSeeds is a previewed seed roll awaiting the host's decision
+type PendingSeeds struct {
+	Seeds   models.CampaignSeeds
+	Rerolls int
+}
+
+// pendingSeedsKey keys preview seeds in the confirmations table apart from other campaign confirmations
+func pendingSeedsKey(campaignID string) string {
+	return fmt.Sprintf("%s#seeds_preview", campaignID)
+}
 
-	// Check deduplication
-	alreadyProcessed, err := checkDedup(messageBody.InteractionID)
+// savePendingSeeds stores previewed seeds in the confirmations table until the host confirms or they expire
+func savePendingSeeds(campaignID string, seeds *models.CampaignSeeds, rerolls int) error {
+	if confirmationsTable == "" {
+		return fmt.Errorf("SYRUS_CONFIRMATIONS_TABLE environment variable not set")
+	}
+
+	seedsJSON, err := json.Marshal(seeds)
 	if err != nil {
-		log.Printf("Warning: failed to check dedup table: %v", err)
-		// Continue processing - don't fail on dedup check errors
-	} else if alreadyProcessed {
-		log.Printf("Message already processed (interaction %s), skipping", messageBody.InteractionID)
-		return nil // Successfully handled - already processed
+		return fmt.Errorf("failed to marshal seeds: %w", err)
 	}
 
-	// Load campaign from DynamoDB
-	campaign, err := getCampaignByID(messageBody.CampaignID)
+	expiresAt := time.Now().Add(seedsPreviewTTL).Unix()
+
+	_, err = dynamodbClient.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(confirmationsTable),
+		Item: map[string]*dynamodb.AttributeValue{
+			"campaignId":       {S: aws.String(pendingSeedsKey(campaignID))},
+			"confirmationType": {S: aws.String("seeds_preview")},
+			"expiresAt":        {N: aws.String(fmt.Sprintf("%d", expiresAt))},
+			"seeds":            {S: aws.String(string(seedsJSON))},
+			"rerolls":          {N: aws.String(fmt.Sprintf("%d", rerolls))},
+		},
+	})
+
+	return err
+}
+
+// getPendingSeeds loads previewed seeds, returning nil if none are pending or they have expired
+func getPendingSeeds(campaignID string) (*PendingSeeds, error) {
+	if confirmationsTable == "" {
+		return nil, fmt.Errorf("SYRUS_CONFIRMATIONS_TABLE environment variable not set")
+	}
+
+	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(confirmationsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(pendingSeedsKey(campaignID))},
+		},
+	})
 	if err != nil {
-		log.Printf("Failed to get campaign: %v", err)
-		if err := sendToMessagingQueue(messageBody.CampaignID, "The threads blur and tangle. I cannot see the campaign. Try again when the pattern settles.", messageBody.InteractionID); err != nil {
-			log.Printf("Failed to send error message: %v", err)
+		return nil, fmt.Errorf("failed to get pending seeds: %w", err)
+	}
+
+	if result.Item == nil || result.Item["seeds"] == nil || result.Item["seeds"].S == nil {
+		return nil, nil
+	}
+
+	// DynamoDB TTL deletion is lazy, so check expiry ourselves
+	if expiresAtAttr, ok := result.Item["expiresAt"]; ok && expiresAtAttr.N != nil {
+		var expiresAt int64
+		if _, err := fmt.Sscanf(*expiresAtAttr.N, "%d", &expiresAt); err == nil && time.Now().Unix() > expiresAt {
+			return nil, nil
 		}
-		return nil // Don't retry on infrastructure errors
 	}
 
-	if campaign == nil {
-		log.Printf("Campaign %s not found", messageBody.CampaignID)
-		if err := sendToMessagingQueue(messageBody.CampaignID, "I sense no campaign here. The threads have vanished.", messageBody.InteractionID); err != nil {
-			log.Printf("Failed to send error message: %v", err)
+	var pending PendingSeeds
+	if err := json.Unmarshal([]byte(*result.Item["seeds"].S), &pending.Seeds); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending seeds: %w", err)
+	}
+	if rerollsAttr, ok := result.Item["rerolls"]; ok && rerollsAttr.N != nil {
+		fmt.Sscanf(*rerollsAttr.N, "%d", &pending.Rerolls)
+	}
+
+	return &pending, nil
+}
+
+// deletePendingSeeds removes previewed seeds once they are committed
+func deletePendingSeeds(campaignID string) error {
+	if confirmationsTable == "" {
+		return fmt.Errorf("SYRUS_CONFIRMATIONS_TABLE environment variable not set")
+	}
+
+	_, err := dynamodbClient.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(confirmationsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(pendingSeedsKey(campaignID))},
+		},
+	})
+
+	return err
+}
+
+// renderSeedsPreview renders the chosen seeds as a human-readable summary for the host
+func renderSeedsPreview(seeds *models.CampaignSeeds, rerollsLeft int) string {
+	var b strings.Builder
+
+	b.WriteString("The seeds are drawn, but not yet sown. Behold what chance has offered:\n\n")
+	fmt.Fprintf(&b, "**Objective:** %s — %s\n", seeds.Objective.Name, seeds.Objective.Description)
+
+	if len(seeds.Antagonists) > 0 {
+		b.WriteString("**Antagonists:**\n")
+		for _, antagonist := range seeds.Antagonists {
+			fmt.Fprintf(&b, "• %s (%s, %s threat)\n", antagonist.Name, antagonist.Nature, antagonist.ThreatLevel)
 		}
-		return nil // Successfully handled - sent error message
 	}
 
-	// Generate blueprint seeds
-	blueprintSeeds, err := generateBlueprintSeeds(campaign)
+	if len(seeds.Twists) > 0 {
+		names := make([]string, len(seeds.Twists))
+		for i, twist := range seeds.Twists {
+			names[i] = twist.Name
+		}
+		fmt.Fprintf(&b, "**Twists:** %s\n", strings.Join(names, ", "))
+	}
+
+	if len(seeds.SetPieces) > 0 {
+		names := make([]string, len(seeds.SetPieces))
+		for i, setPiece := range seeds.SetPieces {
+			names[i] = setPiece.Name
+		}
+		fmt.Fprintf(&b, "**Set Pieces:** %s\n", strings.Join(names, ", "))
+	}
+
+	fmt.Fprintf(&b, "**Map:** %s\n", seeds.Map.Name)
+	if len(seeds.FeaturedAreas) > 0 {
+		names := make([]string, len(seeds.FeaturedAreas))
+		for i, area := range seeds.FeaturedAreas {
+			names[i] = area.Name
+		}
+		fmt.Fprintf(&b, "**Featured Areas:** %s\n", strings.Join(names, ", "))
+	}
+
+	// Variance injectors
+	var variance []string
+	if seeds.GenreModifier != "" {
+		variance = append(variance, "genre: "+seeds.GenreModifier)
+	}
+	if seeds.PerspectiveBias != "" {
+		variance = append(variance, "perspective: "+seeds.PerspectiveBias)
+	}
+	if seeds.EnvironmentalOddity != "" {
+		variance = append(variance, "oddity: "+seeds.EnvironmentalOddity)
+	}
+	if seeds.MoralAsymmetry {
+		variance = append(variance, "moral asymmetry")
+	}
+	if len(variance) > 0 {
+		fmt.Fprintf(&b, "**Variance:** %s\n", strings.Join(variance, "; "))
+	}
+
+	if rerollsLeft > 0 {
+		fmt.Fprintf(&b, "\nWhisper /campaign preview confirm to begin the weaving, or /campaign reroll to cast again (%d left, lock what you wish to keep). The seeds fade in %d minutes.", rerollsLeft, int(seedsPreviewTTL.Minutes()))
+	} else {
+		fmt.Fprintf(&b, "\nThe seeds will not be cast again. Whisper /campaign preview confirm to begin the weaving. The seeds fade in %d minutes.", int(seedsPreviewTTL.Minutes()))
+	}
+
+	return b.String()
+}
+
+// birthingAction is what the birthing lambda does with a message
+type birthingAction string
+
+const (
+	birthingActionGenerate birthingAction = "generate" // Generate seeds and blueprint immediately
+	birthingActionPreview  birthingAction = "preview"  // Generate seeds and hold them for approval
+	birthingActionConfirm  birthingAction = "confirm"  // Blueprint the pending previewed seeds
+	birthingActionReroll   birthingAction = "reroll"   // Replace the pending seeds keeping locked categories, or reweave when none are pending
+)
+
+// resolveBirthingAction maps a birthing message onto the action to take
+func resolveBirthingAction(messageBody models.BirthingMessage) birthingAction {
+	switch messageBody.PreviewAction {
+	case models.PreviewActionConfirm:
+		return birthingActionConfirm
+	case models.PreviewActionReroll:
+		return birthingActionReroll
+	}
+	if messageBody.Preview {
+		return birthingActionPreview
+	}
+	return birthingActionGenerate
+}
+
+// previewSeeds generates seeds, stores them pending approval, and posts the preview.
+// For rerolls, previous holds the pending roll whose locked categories are kept.
+func previewSeeds(messageBody models.BirthingMessage, campaign *models.Campaign, previous *PendingSeeds) error {
+	var previousSeeds *models.CampaignSeeds
+	rerolls := 0
+	if previous != nil {
+		previousSeeds = &previous.Seeds
+		rerolls = previous.Rerolls + 1
+	}
+
+	history := selectionHistoryFor(campaign.HostID)
+	blueprintSeeds, err := generateDistinctSeeds(campaign, previousSeeds, messageBody.Locked, history, deriveCampaignSeed(campaign.CampaignID, time.Now()))
 	if err != nil {
 		log.Printf("Failed to generate blueprint seeds: %v", err)
-		if err := sendToMessagingQueue(messageBody.CampaignID, "The pattern resists. I cannot cast the seeds. Try again.", messageBody.InteractionID); err != nil {
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The pattern resists. I cannot cast the seeds. Try again.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry after sending error message
+	}
+
+	if err := savePendingSeeds(messageBody.CampaignID, blueprintSeeds, rerolls); err != nil {
+		log.Printf("Failed to save pending seeds: %v", err)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The seeds scatter before I can hold them. Try again.", messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil // Don't retry after sending error message
 	}
 
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
+	}
+
+	if err := sendToMessagingQueue(messageBody.ReplyChannelID(), renderSeedsPreview(blueprintSeeds, maxSeedRerolls-rerolls), messageBody.InteractionID); err != nil {
+		log.Printf("Warning: failed to send seeds preview: %v", err)
+	}
+
+	log.Printf("Posted seeds preview for campaign %s", messageBody.CampaignID)
+	return nil
+}
+
+// commitSeeds sends seeds to blueprinting and announces that the weaving has begun.
+// A non-zero reroll marks seeds that replace an earlier weaving.
+func commitSeeds(messageBody models.BirthingMessage, blueprintSeeds *models.CampaignSeeds, reroll int) error {
 	// Create BlueprintMessage
 	blueprintMessage := models.BlueprintMessage{
 		CampaignID:    messageBody.CampaignID,
-		InteractionID: messageBody.InteractionID,
+		InteractionID: blueprintInteractionID(messageBody.InteractionID, reroll),
 		Seeds:         *blueprintSeeds,
+		Reroll:        reroll,
+		ModelOverride: messageBody.ModelOverride,
+		RequestedBy:   messageBody.RequestedBy,
 	}
 
 	// Send to blueprinting queue
@@ -796,7 +1379,7 @@ func processSQSMessage(message events.SQSMessage, stage string) error {
 	}
 
 	// Write to dedup table
-	if err := writeDedup(messageBody.InteractionID); err != nil {
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
 		log.Printf("Warning: failed to write to dedup table: %v", err)
 		// Don't fail the entire operation if dedup write fails
 	}
@@ -806,7 +1389,7 @@ func processSQSMessage(message events.SQSMessage, stage string) error {
 Foundations shimmer beneath the surface—objective, twists, forces in motion.
 The adventure is being woven. This may take a moment as the threads align...`
 
-	if err := sendToMessagingQueue(messageBody.CampaignID, successMessage, messageBody.InteractionID); err != nil {
+	if err := sendToMessagingQueue(messageBody.ReplyChannelID(), successMessage, messageBody.InteractionID); err != nil {
 		log.Printf("Warning: failed to send success message: %v", err)
 		// Don't fail if success message fails - seeds were generated
 	}
@@ -815,34 +1398,223 @@ The adventure is being woven. This may take a moment as the threads align...`
 	return nil
 }
 
-// handleSQSRequest handles incoming SQS events
-func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) error {
-	stage := os.Getenv("SYRUS_STAGE")
-	if stage == "" {
-		stage = "dev"
+// rerollBlueprint draws fresh seeds for a configuring campaign with no pending preview and
+// sends them to blueprinting again. Locks are ignored since there are no pending seeds to keep.
+func rerollBlueprint(messageBody models.BirthingMessage, campaign *models.Campaign) error {
+	if refusal := campaignRerollRefusal(campaign); refusal != "" {
+		log.Printf("Refusing reroll for campaign %s (status %s, %d rerolls)", campaign.CampaignID, campaign.Status, campaign.SeedRerolls)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), refusal, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
 	}
 
-	log.Printf("Received %d SQS message(s)", len(sqsEvent.Records))
+	history := selectionHistoryFor(campaign.HostID)
+	blueprintSeeds, err := generateDistinctSeeds(campaign, nil, nil, history, deriveCampaignSeed(campaign.CampaignID, time.Now()))
+	if err != nil {
+		log.Printf("Failed to generate blueprint seeds: %v", err)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The pattern resists. I cannot cast the seeds. Try again.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry after sending error message
+	}
 
-	var errors []error
-	for _, record := range sqsEvent.Records {
-		log.Printf("Processing message: %s", record.MessageId)
+	// Claim the reroll last, so a failed draw does not spend one
+	reroll, claimed, err := claimCampaignReroll(campaign.CampaignID)
+	if err != nil {
+		log.Printf("Failed to claim reroll: %v", err)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The threads blur and tangle. I cannot cast the seeds. Try again when the pattern settles.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors
+	}
+	if !claimed {
+		// The campaign moved on or another reroll took the last slot since it was loaded
+		log.Printf("Reroll no longer allowed for campaign %s", campaign.CampaignID)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The seeds will not be cast again. The weaving already underway must stand.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	log.Printf("Rerolling campaign %s into a new blueprint (reroll %d of %d)", campaign.CampaignID, reroll, maxCampaignRerolls)
+	if err := commitSeeds(messageBody, blueprintSeeds, reroll); err != nil {
+		return err
+	}
+	recordSelection(campaign.HostID, blueprintSeeds)
+	return nil
+}
 
-		if err := processSQSMessage(record, stage); err != nil {
-			log.Printf("Error processing message %s: %v", record.MessageId, err)
-			errors = append(errors, fmt.Errorf("message %s: %w", record.MessageId, err))
-			// Continue processing other messages
+// applySeedLocks copies the locked categories from the previous seeds over freshly generated ones
+func applySeedLocks(seeds, previous *models.CampaignSeeds, locked []models.SeedCategory) {
+	for _, category := range locked {
+		switch category {
+		case models.SeedCategoryObjective:
+			seeds.Objective = previous.Objective
+		case models.SeedCategoryTwists:
+			seeds.Twists = previous.Twists
+		case models.SeedCategoryAntagonists:
+			seeds.Antagonists = previous.Antagonists
+		case models.SeedCategorySetPieces:
+			seeds.SetPieces = previous.SetPieces
+		case models.SeedCategoryConstraints:
+			seeds.Constraints = previous.Constraints
+		case models.SeedCategoryLocation:
+			seeds.StartingLocation = previous.StartingLocation
+		case models.SeedCategoryMap:
+			seeds.Map = previous.Map
+			seeds.FeaturedAreas = previous.FeaturedAreas
+		case models.SeedCategoryVariance:
+			seeds.GenreModifier = previous.GenreModifier
+			seeds.PerspectiveBias = previous.PerspectiveBias
+			seeds.MoralAsymmetry = previous.MoralAsymmetry
+			seeds.EnvironmentalOddity = previous.EnvironmentalOddity
+			seeds.ExcludedMotifs = previous.ExcludedMotifs
+			seeds.ExpectationViolation = previous.ExpectationViolation
 		}
 	}
+}
 
-	// If any messages failed, return error (SQS will retry failed messages)
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to process %d message(s): %v", len(errors), errors)
+// processSQSMessage processes a single SQS message
+func processSQSMessage(message events.SQSMessage, stage string) (err error) {
+	// Parse message body
+	var messageBody models.BirthingMessage
+	if err := json.Unmarshal([]byte(message.Body), &messageBody); err != nil {
+		return fmt.Errorf("failed to parse message body: %w", err)
 	}
 
+	log.Printf("Processing birthing message for campaign %s", messageBody.CampaignID)
+
+	// Validate required fields
+	if messageBody.CampaignID == "" {
+		return fmt.Errorf("missing required field: campaignId")
+	}
+	if messageBody.InteractionID == "" {
+		return fmt.Errorf("missing required field: interactionId")
+	}
+
+	// Claim the interaction; the conditional write lets only one concurrent delivery through
+	claimed, err := dedup.Claim(dedupPrefix, messageBody.InteractionID, claimLease)
+	if err != nil {
+		log.Printf("Warning: failed to claim dedup record: %v", err)
+		// Continue processing - don't fail on dedup errors
+	} else if !claimed {
+		log.Printf("Message already processed (interaction %s), skipping", messageBody.InteractionID)
+		return nil // Successfully handled - already processed
+	}
+	// Drop the claim on failure so the SQS retry is not mistaken for a duplicate
+	defer func() {
+		if err != nil && claimed {
+			if releaseErr := dedup.Release(dedupPrefix, messageBody.InteractionID); releaseErr != nil {
+				log.Printf("Warning: failed to release dedup record: %v", releaseErr)
+			}
+		}
+	}()
+
+	// Load campaign from DynamoDB
+	campaign, err := getCampaignByID(messageBody.CampaignID)
+	if err != nil {
+		log.Printf("Failed to get campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The threads blur and tangle. I cannot see the campaign. Try again when the pattern settles.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors
+	}
+
+	if campaign == nil {
+		log.Printf("Campaign %s not found", messageBody.CampaignID)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "I sense no campaign here. The threads have vanished.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Successfully handled - sent error message
+	}
+
+	switch resolveBirthingAction(messageBody) {
+	case birthingActionPreview:
+		return previewSeeds(messageBody, campaign, nil)
+
+	case birthingActionReroll:
+		pending, err := getPendingSeeds(messageBody.CampaignID)
+		if err != nil {
+			log.Printf("Failed to load pending seeds: %v", err)
+			if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The threads blur and tangle. I cannot find the seeds. Try again when the pattern settles.", messageBody.InteractionID); err != nil {
+				log.Printf("Failed to send error message: %v", err)
+			}
+			return nil // Don't retry on infrastructure errors
+		}
+		if pending != nil && pending.Rerolls >= maxSeedRerolls {
+			log.Printf("Reroll limit reached for campaign %s", messageBody.CampaignID)
+			if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The seeds have been cast enough. Fate grows weary of indecision. Speak /campaign preview confirm to sow what lies before you.", messageBody.InteractionID); err != nil {
+				log.Printf("Failed to send error message: %v", err)
+			}
+			return nil
+		}
+		if pending == nil {
+			// Nothing awaits approval (never previewed, or expired): recast and weave again
+			return rerollBlueprint(messageBody, campaign)
+		}
+		return previewSeeds(messageBody, campaign, pending)
+
+	case birthingActionConfirm:
+		pending, err := getPendingSeeds(messageBody.CampaignID)
+		if err != nil {
+			log.Printf("Failed to load pending seeds: %v", err)
+			if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The threads blur and tangle. I cannot find the seeds. Try again when the pattern settles.", messageBody.InteractionID); err != nil {
+				log.Printf("Failed to send error message: %v", err)
+			}
+			return nil // Don't retry on infrastructure errors
+		}
+		if pending == nil {
+			log.Printf("No pending seeds for campaign %s", messageBody.CampaignID)
+			if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "No seeds await your word. They have faded, or were never cast. Speak /campaign preview reroll to draw anew.", messageBody.InteractionID); err != nil {
+				log.Printf("Failed to send error message: %v", err)
+			}
+			return nil
+		}
+
+		if err := commitSeeds(messageBody, &pending.Seeds, 0); err != nil {
+			return err
+		}
+		recordSelection(campaign.HostID, &pending.Seeds)
+
+		// Prevent the same seeds being blueprinted twice
+		if err := deletePendingSeeds(messageBody.CampaignID); err != nil {
+			log.Printf("Warning: failed to delete pending seeds: %v", err)
+		}
+		return nil
+	}
+
+	// Generate blueprint seeds
+	history := selectionHistoryFor(campaign.HostID)
+	blueprintSeeds, err := generateDistinctSeeds(campaign, nil, nil, history, deriveCampaignSeed(campaign.CampaignID, time.Now()))
+	if err != nil {
+		log.Printf("Failed to generate blueprint seeds: %v", err)
+		if err := sendToMessagingQueue(messageBody.ReplyChannelID(), "The pattern resists. I cannot cast the seeds. Try again.", messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry after sending error message
+	}
+
+	if err := commitSeeds(messageBody, blueprintSeeds, 0); err != nil {
+		return err
+	}
+	recordSelection(campaign.HostID, blueprintSeeds)
 	return nil
 }
 
+// handleSQSRequest handles incoming SQS events, reporting failed messages so SQS retries only those
+func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	log.Printf("Received %d SQS message(s)", len(sqsEvent.Records))
+
+	failures := sqsbatch.Process(ctx, sqsEvent.Records, sqsConcurrency, func(ctx context.Context, record events.SQSMessage) error {
+		log.Printf("Processing message: %s", record.MessageId)
+		return processSQSMessage(record, stage)
+	})
+
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
+}
+
 func main() {
+	logging.Init("birthing")
 	lambda.Start(handleSQSRequest)
 }
@@ -2,6 +2,9 @@ package main
 
 import (
 	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -128,48 +131,85 @@ func TestSelectRandomElements(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Run multiple times to test randomness
-			for i := 0; i < 10; i++ {
-				result := selectRandomElements(items, tt.min, tt.max)
-
-				// Verify count is within range
-				expectedMax := tt.max
-				if expectedMax > len(items) {
-					expectedMax = len(items)
-				}
-				expectedMin := tt.min
-				if expectedMin > len(items) {
-					expectedMin = len(items)
-				}
-				if len(result) < expectedMin || len(result) > expectedMax {
-					t.Errorf("Expected %d-%d items, got %d", expectedMin, expectedMax, len(result))
-				}
+			result := selectRandomElements(rand.New(rand.NewSource(1)), items, tt.min, tt.max)
 
-				// Verify no duplicates
-				seen := make(map[string]bool)
-				for _, item := range result {
-					if seen[item] {
-						t.Errorf("Duplicate item found: %s", item)
-					}
-					seen[item] = true
+			// Verify count is within range
+			expectedMax := tt.max
+			if expectedMax > len(items) {
+				expectedMax = len(items)
+			}
+			expectedMin := tt.min
+			if expectedMin > len(items) {
+				expectedMin = len(items)
+			}
+			if len(result) < expectedMin || len(result) > expectedMax {
+				t.Errorf("Expected %d-%d items, got %d", expectedMin, expectedMax, len(result))
+			}
+
+			// Verify no duplicates
+			seen := make(map[string]bool)
+			for _, item := range result {
+				if seen[item] {
+					t.Errorf("Duplicate item found: %s", item)
 				}
+				seen[item] = true
+			}
 
-				// Verify all items are from original slice
-				for _, item := range result {
-					found := false
-					for _, original := range items {
-						if item == original {
-							found = true
-							break
-						}
-					}
-					if !found {
-						t.Errorf("Item %s not in original slice", item)
+			// Verify all items are from original slice
+			for _, item := range result {
+				found := false
+				for _, original := range items {
+					if item == original {
+						found = true
+						break
 					}
 				}
+				if !found {
+					t.Errorf("Item %s not in original slice", item)
+				}
 			}
 		})
 	}
+
+	// A seeded source picks exactly the same elements every time
+	if result := selectRandomElements(rand.New(rand.NewSource(7)), items, 3, 3); !reflect.DeepEqual(result, []string{"c", "e", "g"}) {
+		t.Errorf("Expected seed 7 to select [c e g], got %v", result)
+	}
+}
+
+func TestSelectWeightedConstraints(t *testing.T) {
+	mixed := []models.ConstraintSeed{
+		{ConstraintID: "attrition", Weight: 5},
+		{ConstraintID: "politics"},
+		{ConstraintID: "weather", Weight: 1},
+	}
+	ids := func(constraints []models.ConstraintSeed) []string {
+		result := make([]string, 0, len(constraints))
+		for _, c := range constraints {
+			result = append(result, c.ConstraintID)
+		}
+		return result
+	}
+
+	if selected := ids(selectWeightedConstraints(rand.New(rand.NewSource(7)), mixed, 2, 2)); !reflect.DeepEqual(selected, []string{"weather", "attrition"}) {
+		t.Errorf("Expected seed 7 to select [weather attrition], got %v", selected)
+	}
+
+	// An unset weight counts as 1, so the heavy constraint dominates single draws
+	counts := map[string]int{}
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 1000; i++ {
+		counts[selectWeightedConstraints(rng, mixed, 1, 1)[0].ConstraintID]++
+	}
+	if counts["attrition"] != 704 || counts["politics"] != 145 || counts["weather"] != 151 {
+		t.Errorf("Expected seed 7 to draw attrition 704, politics 145, weather 151 times, got %v", counts)
+	}
+
+	// Negative weights are rejected when seeds load; selection must still not panic if one slips through
+	negative := []models.ConstraintSeed{{ConstraintID: "famine", Weight: -2}, {ConstraintID: "plague", Weight: -1}}
+	if selected := selectWeightedConstraints(rand.New(rand.NewSource(7)), negative, 2, 2); len(selected) != 2 {
+		t.Fatalf("Expected uniform selection of both constraints, got %v", selected)
+	}
 }
 
 // TestGenerateBlueprintSeeds tests the full blueprint seed generation
@@ -192,7 +232,7 @@ func TestGenerateBlueprintSeeds(t *testing.T) {
 
 			// Generate seeds multiple times to test consistency
 			for i := 0; i < 5; i++ {
-				seeds, err := generateBlueprintSeeds(campaign)
+				seeds, err := generateBlueprintSeeds(campaign, nil, nil, time.Now().UnixNano())
 				if err != nil {
 					t.Fatalf("Failed to generate blueprint seeds: %v", err)
 				}
@@ -377,34 +417,65 @@ func TestSelectRandomMap(t *testing.T) {
 		t.Fatalf("Failed to parse maps JSON: %v", err)
 	}
 
-	// Run selection multiple times to verify randomness
-	selectedMaps := make(map[string]int)
-	for i := 0; i < 100; i++ {
-		mapID, mapData := selectRandomMap(mapsData)
-		if mapID == "" {
-			t.Error("Empty map ID returned")
-		}
-		if mapData.Name == "" {
-			t.Error("Empty map data returned")
+	mapID, mapData := selectRandomMap(rand.New(rand.NewSource(1)), mapsData)
+	if mapID == "" {
+		t.Error("Empty map ID returned")
+	}
+	if mapData.Name == "" {
+		t.Error("Empty map data returned")
+	}
+
+	// A seeded source picks exactly the same map every time
+	maps := map[string]MapData{"a": {Name: "A"}, "b": {Name: "B"}, "c": {Name: "C"}}
+	if mapID, mapData := selectRandomMap(rand.New(rand.NewSource(7)), maps); mapID != "c" || mapData.Name != "C" {
+		t.Errorf("Expected seed 7 to select map c, got %s", mapID)
+	}
+	if mapID, _ := selectRandomMap(rand.New(rand.NewSource(7)), map[string]MapData{}); mapID != "" {
+		t.Errorf("Expected no map from empty maps data, got %s", mapID)
+	}
+}
+
+// TestSelectRandomMapDeterministic verifies a fixed seed always selects the same map and areas
+func TestSelectRandomMapDeterministic(t *testing.T) {
+	var mapsData map[string]MapData
+	if err := json.Unmarshal(mapsJSON, &mapsData); err != nil {
+		t.Fatalf("Failed to parse maps JSON: %v", err)
+	}
+
+	selectWithSeed := func(seed int64) (string, []int) {
+		rng := rand.New(rand.NewSource(seed))
+		mapID, mapData := selectRandomMap(rng, mapsData)
+		areaIDs := make([]int, 0)
+		for _, area := range selectFeaturedAreas(rng, mapData, 3) {
+			areaIDs = append(areaIDs, area.AreaID)
 		}
-		selectedMaps[mapID]++
+		return mapID, areaIDs
 	}
 
-	// Verify we got some variety (at least 50% of available maps selected)
-	if len(selectedMaps) < len(mapsData)/2 {
-		t.Errorf("Not enough variety in map selection: got %d unique maps out of %d", len(selectedMaps), len(mapsData))
+	for _, seed := range []int64{1, 42, 1337} {
+		expectedMap, expectedAreas := selectWithSeed(seed)
+		for run := 0; run < 20; run++ {
+			mapID, areaIDs := selectWithSeed(seed)
+			if mapID != expectedMap {
+				t.Fatalf("Seed %d run %d: expected map %s, got %s", seed, run, expectedMap, mapID)
+			}
+			if !reflect.DeepEqual(areaIDs, expectedAreas) {
+				t.Fatalf("Seed %d run %d: expected areas %v, got %v", seed, run, expectedAreas, areaIDs)
+			}
+		}
 	}
 }
 
 // TestSelectFeaturedAreas tests featured area selection
 func TestSelectFeaturedAreas(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	var mapsData map[string]MapData
 	if err := json.Unmarshal(mapsJSON, &mapsData); err != nil {
 		t.Fatalf("Failed to parse maps JSON: %v", err)
 	}
 
 	// Get a map for testing
-	_, testMap := selectRandomMap(mapsData)
+	_, testMap := selectRandomMap(rng, mapsData)
 
 	tests := []struct {
 		name  string
@@ -418,7 +489,7 @@ func TestSelectFeaturedAreas(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := selectFeaturedAreas(testMap, tt.count)
+			result := selectFeaturedAreas(rng, testMap, tt.count)
 
 			expectedCount := tt.count
 			if expectedCount > len(testMap.Areas) {
@@ -443,6 +514,7 @@ func TestSelectFeaturedAreas(t *testing.T) {
 
 // TestGenerateVarianceInjectors tests variance injector generation
 func TestGenerateVarianceInjectors(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	var config CampaignConfig
 	if err := json.Unmarshal(configJSON, &config); err != nil {
 		t.Fatalf("Failed to parse config JSON: %v", err)
@@ -461,7 +533,7 @@ func TestGenerateVarianceInjectors(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			profile := config.CampaignLengthProfiles[tt.profileKey]
-			genre, perspective, _, motifs := generateVarianceInjectors(profile, &config)
+			genre, perspective, _, motifs := generateVarianceInjectors(rng, profile, &config)
 
 			// Verify genre modifier is set for short+ campaigns
 			if tt.expectedKillers > 0 && genre == "" {
@@ -495,7 +567,7 @@ func TestGenerateVarianceInjectors(t *testing.T) {
 			// Run multiple times to verify randomness
 			genres := make(map[string]bool)
 			for i := 0; i < 20; i++ {
-				g, _, _, _ := generateVarianceInjectors(profile, &config)
+				g, _, _, _ := generateVarianceInjectors(rng, profile, &config)
 				if g != "" {
 					genres[g] = true
 				}
@@ -509,6 +581,7 @@ func TestGenerateVarianceInjectors(t *testing.T) {
 
 // TestSelectObjectiveWithBias tests biased objective selection
 func TestSelectObjectiveWithBias(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	var seeds CampaignSeeds
 	if err := json.Unmarshal(seedsJSON, &seeds); err != nil {
 		t.Fatalf("Failed to parse seeds JSON: %v", err)
@@ -525,7 +598,7 @@ func TestSelectObjectiveWithBias(t *testing.T) {
 
 		// Run multiple times to verify consistency
 		for i := 0; i < 20; i++ {
-			objective := selectObjectiveWithBias(seeds.ObjectiveSeeds, profile)
+			objective := selectObjectiveWithBias(rng, seeds.ObjectiveSeeds, profile)
 			if objective.PrimaryThreatCategory == "ecological" {
 				t.Error("Short campaign should exclude ecological threats")
 			}
@@ -538,7 +611,7 @@ func TestSelectObjectiveWithBias(t *testing.T) {
 		objectives := make(map[string]int)
 
 		for i := 0; i < 50; i++ {
-			objective := selectObjectiveWithBias(seeds.ObjectiveSeeds, profile)
+			objective := selectObjectiveWithBias(rng, seeds.ObjectiveSeeds, profile)
 			objectives[objective.ObjectiveID]++
 		}
 
@@ -551,6 +624,7 @@ func TestSelectObjectiveWithBias(t *testing.T) {
 
 // TestSelectAntagonistsWithBias tests biased antagonist selection
 func TestSelectAntagonistsWithBias(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	var seeds CampaignSeeds
 	if err := json.Unmarshal(seedsJSON, &seeds); err != nil {
 		t.Fatalf("Failed to parse seeds JSON: %v", err)
@@ -566,7 +640,7 @@ func TestSelectAntagonistsWithBias(t *testing.T) {
 		profile := config.CampaignLengthProfiles["epic"]
 
 		for i := 0; i < 10; i++ {
-			antagonists := selectAntagonistsWithBias(seeds.AntagonistCandidates, profile, 3, 4)
+			antagonists := selectAntagonistsWithBias(rng, seeds.AntagonistCandidates, profile, 3, 4)
 
 			// Count unique threat categories
 			categories := make(map[string]bool)
@@ -586,7 +660,7 @@ func TestSelectAntagonistsWithBias(t *testing.T) {
 		profile := config.CampaignLengthProfiles["long"]
 
 		for i := 0; i < 10; i++ {
-			antagonists := selectAntagonistsWithBias(seeds.AntagonistCandidates, profile, 2, 3)
+			antagonists := selectAntagonistsWithBias(rng, seeds.AntagonistCandidates, profile, 2, 3)
 
 			if len(antagonists) < 2 || len(antagonists) > 3 {
 				t.Errorf("Expected 2-3 antagonists, got %d", len(antagonists))
@@ -597,6 +671,7 @@ func TestSelectAntagonistsWithBias(t *testing.T) {
 
 // TestGenerateExpectationViolation tests expectation violation generation
 func TestGenerateExpectationViolation(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	var config CampaignConfig
 	if err := json.Unmarshal(configJSON, &config); err != nil {
 		t.Fatalf("Failed to parse config JSON: %v", err)
@@ -614,7 +689,7 @@ func TestGenerateExpectationViolation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			beatProfile := config.BeatProfiles[tt.profileKey]
-			violation := generateExpectationViolation(beatProfile)
+			violation := generateExpectationViolation(rng, beatProfile)
 
 			if violation == nil {
 				t.Fatal("Expected expectation violation to be generated")
@@ -635,17 +710,16 @@ func TestGenerateExpectationViolation(t *testing.T) {
 				t.Errorf("Invalid violation type: %s", violation.Type)
 			}
 
-			// Test randomness
-			types := make(map[string]bool)
-			for i := 0; i < 30; i++ {
-				v := generateExpectationViolation(beatProfile)
-				types[v.Type] = true
-			}
-			if len(types) < 2 {
-				t.Error("Not enough variety in violation types")
-			}
 		})
 	}
+
+	// A seeded source breaks the same act the same way every time
+	if violation := generateExpectationViolation(rand.New(rand.NewSource(7)), BeatProfile{Acts: 4}); violation == nil || *violation != (models.ExpectationBreak{ActNumber: 4, Type: "inversion"}) {
+		t.Errorf("Expected seed 7 to invert act 4, got %+v", violation)
+	}
+	if violation := generateExpectationViolation(rand.New(rand.NewSource(7)), BeatProfile{Acts: 1}); violation != nil {
+		t.Errorf("Expected no violation for a single-act profile, got %+v", violation)
+	}
 }
 
 // TestBlueprintSeedsIncludeAllVariance tests that generated seeds include all new fields
@@ -666,7 +740,7 @@ func TestBlueprintSeedsIncludeAllVariance(t *testing.T) {
 				LastUpdatedAt: time.Now().UTC(),
 			}
 
-			seeds, err := generateBlueprintSeeds(campaign)
+			seeds, err := generateBlueprintSeeds(campaign, nil, nil, time.Now().UnixNano())
 			if err != nil {
 				t.Fatalf("Failed to generate blueprint seeds: %v", err)
 			}
@@ -744,3 +818,411 @@ func TestSeedCategorization(t *testing.T) {
 	}
 }
 
+
+// TestRenderSeedsPreview tests the human-readable seeds preview
+func TestRenderSeedsPreview(t *testing.T) {
+	seeds := &models.CampaignSeeds{
+		Objective: models.ObjectiveSeed{Name: "Break the Curse", Description: "Lift the barrow's blight"},
+		Antagonists: []models.AntagonistSeed{
+			{Name: "The Barrow King", Nature: "undead_lord", ThreatLevel: "high"},
+		},
+		Twists:              []models.TwistSeed{{Name: "Guardian Was Containment"}, {Name: "Time Pressure"}},
+		SetPieces:           []models.SetPieceSeed{{Name: "Collapsing Ruins"}},
+		Map:                 models.MapSeed{Name: "The Sundered Vale"},
+		FeaturedAreas:       []models.AreaSeed{{Name: "Old Barrow"}, {Name: "Ashen Ford"}},
+		GenreModifier:       "gothic",
+		EnvironmentalOddity: "eternal dusk",
+		MoralAsymmetry:      true,
+	}
+
+	preview := renderSeedsPreview(seeds, 2)
+
+	expected := []string{
+		"**Objective:** Break the Curse — Lift the barrow's blight",
+		"• The Barrow King (undead_lord, high threat)",
+		"**Twists:** Guardian Was Containment, Time Pressure",
+		"**Set Pieces:** Collapsing Ruins",
+		"**Map:** The Sundered Vale",
+		"**Featured Areas:** Old Barrow, Ashen Ford",
+		"**Variance:** genre: gothic; oddity: eternal dusk; moral asymmetry",
+		"/campaign preview confirm",
+		"/campaign reroll",
+		"(2 left",
+	}
+	for _, want := range expected {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Expected preview to contain %q, got:\n%s", want, preview)
+		}
+	}
+
+	// Empty optional sections are omitted
+	minimal := renderSeedsPreview(&models.CampaignSeeds{Objective: models.ObjectiveSeed{Name: "Escort"}}, 0)
+	for _, absent := range []string{"**Antagonists:**", "**Twists:**", "**Set Pieces:**", "**Variance:**", "/campaign reroll"} {
+		if strings.Contains(minimal, absent) {
+			t.Errorf("Expected minimal preview to omit %q", absent)
+		}
+	}
+}
+
+// TestResolveBirthingAction tests routing of generate, preview, confirm, and reroll requests
+func TestResolveBirthingAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  models.BirthingMessage
+		expected birthingAction
+	}{
+		{"plain start generates immediately", models.BirthingMessage{}, birthingActionGenerate},
+		{"start with preview holds seeds", models.BirthingMessage{Preview: true}, birthingActionPreview},
+		{"reroll replaces pending seeds", models.BirthingMessage{PreviewAction: models.PreviewActionReroll}, birthingActionReroll},
+		{"confirm commits pending seeds", models.BirthingMessage{PreviewAction: models.PreviewActionConfirm}, birthingActionConfirm},
+		{"confirm wins over preview flag", models.BirthingMessage{Preview: true, PreviewAction: models.PreviewActionConfirm}, birthingActionConfirm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveBirthingAction(tt.message); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestPendingSeedsKey verifies previews don't collide with other campaign confirmations
+func TestPendingSeedsKey(t *testing.T) {
+	if key := pendingSeedsKey("123"); key != "123#seeds_preview" {
+		t.Errorf("Expected 123#seeds_preview, got %s", key)
+	}
+}
+
+// TestCampaignRerollRefusal verifies rerolls into a new blueprint are limited to configuring campaigns under the cap
+func TestCampaignRerollRefusal(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  models.CampaignStatus
+		rerolls int
+		refused bool
+	}{
+		{"first reroll while configuring", models.CampaignStatusConfiguring, 0, false},
+		{"last reroll while configuring", models.CampaignStatusConfiguring, maxCampaignRerolls - 1, false},
+		{"limit reached", models.CampaignStatusConfiguring, maxCampaignRerolls, true},
+		{"already active", models.CampaignStatusActive, 0, true},
+		{"ended", models.CampaignStatusEnded, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := &models.Campaign{Status: tt.status, SeedRerolls: tt.rerolls}
+			if refusal := campaignRerollRefusal(campaign); (refusal != "") != tt.refused {
+				t.Errorf("Expected refused=%v, got %q", tt.refused, refusal)
+			}
+		})
+	}
+}
+
+// TestBlueprintInteractionID verifies rerolls reach blueprinting under their own interaction
+func TestBlueprintInteractionID(t *testing.T) {
+	if id := blueprintInteractionID("abc", 0); id != "abc" {
+		t.Errorf("Expected the first weaving to keep its interaction ID, got %s", id)
+	}
+	if id := blueprintInteractionID("abc", 2); id != "abc-reroll-2" {
+		t.Errorf("Expected abc-reroll-2, got %s", id)
+	}
+}
+
+// TestRerollPreservesLockedCategories verifies locked categories survive repeated rerolls
+func TestRerollPreservesLockedCategories(t *testing.T) {
+	campaign := &models.Campaign{
+		CampaignID:   "test-campaign",
+		CampaignType: models.CampaignTypeLong,
+		Status:       models.CampaignStatusConfiguring,
+	}
+
+	original, err := generateBlueprintSeeds(campaign, nil, nil, time.Now().UnixNano())
+	if err != nil {
+		t.Fatalf("Failed to generate seeds: %v", err)
+	}
+
+	locked := []models.SeedCategory{models.SeedCategoryObjective, models.SeedCategoryAntagonists, models.SeedCategoryMap}
+
+	// Reroll several times, each time from the previous roll as a host would
+	previous := original
+	for i := 0; i < maxSeedRerolls; i++ {
+		rerolled, err := generateBlueprintSeeds(campaign, previous, locked, time.Now().UnixNano())
+		if err != nil {
+			t.Fatalf("Reroll %d failed: %v", i+1, err)
+		}
+
+		if !reflect.DeepEqual(rerolled.Objective, original.Objective) {
+			t.Errorf("Reroll %d: objective changed from %s to %s", i+1, original.Objective.ObjectiveID, rerolled.Objective.ObjectiveID)
+		}
+		if !reflect.DeepEqual(rerolled.Antagonists, original.Antagonists) {
+			t.Errorf("Reroll %d: antagonists changed", i+1)
+		}
+		if rerolled.Map.MapID != original.Map.MapID || !reflect.DeepEqual(rerolled.FeaturedAreas, original.FeaturedAreas) {
+			t.Errorf("Reroll %d: map or featured areas changed", i+1)
+		}
+
+		previous = rerolled
+	}
+}
+
+// TestGenerateBlueprintSeedsReproducible verifies the stored seed reproduces the roll
+func TestGenerateBlueprintSeedsReproducible(t *testing.T) {
+	campaign := &models.Campaign{
+		CampaignID:   "test-campaign",
+		CampaignType: models.CampaignTypeShort,
+		Status:       models.CampaignStatusConfiguring,
+	}
+
+	first, err := generateBlueprintSeeds(campaign, nil, nil, 42)
+	if err != nil {
+		t.Fatalf("Failed to generate seeds: %v", err)
+	}
+	second, err := generateBlueprintSeeds(campaign, nil, nil, first.RandomSeed)
+	if err != nil {
+		t.Fatalf("Failed to regenerate seeds: %v", err)
+	}
+
+	if first.RandomSeed != 42 {
+		t.Errorf("Expected RandomSeed 42, got %d", first.RandomSeed)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected identical seeds for the same random seed, got %s and %s", combinationKey(first), combinationKey(second))
+	}
+}
+
+// TestGenerateDistinctSeedsAvoidsPreviousCombination verifies consecutive births for a host never repeat the last combination
+func TestGenerateDistinctSeedsAvoidsPreviousCombination(t *testing.T) {
+	campaign := &models.Campaign{
+		CampaignID:   "test-campaign",
+		HostID:       "host-1",
+		CampaignType: models.CampaignTypeShort,
+		Status:       models.CampaignStatusConfiguring,
+	}
+
+	history := &SelectionContext{HostID: campaign.HostID}
+	for i := 0; i < 20; i++ {
+		seeds, err := generateDistinctSeeds(campaign, nil, nil, history, int64(i*maxCombinationAttempts))
+		if err != nil {
+			t.Fatalf("Generation %d failed: %v", i+1, err)
+		}
+
+		key := combinationKey(seeds)
+		if i > 0 && key == history.LastCombination {
+			t.Fatalf("Generation %d repeated the previous combination %s", i+1, key)
+		}
+		history.Record(seeds, time.Now())
+	}
+
+	if len(history.RecentCombinations) != maxRecentCombinations {
+		t.Errorf("Expected history capped at %d combinations, got %d", maxRecentCombinations, len(history.RecentCombinations))
+	}
+	if len(history.UsedThreatCategories) == 0 && len(history.UsedTerrainCategories) == 0 {
+		t.Error("Expected category usage to be recorded")
+	}
+}
+
+// TestApplySeedLocks verifies each category copies only its own fields
+func TestApplySeedLocks(t *testing.T) {
+	previous := &models.CampaignSeeds{
+		Objective:           models.ObjectiveSeed{ObjectiveID: "old_objective"},
+		Twists:              []models.TwistSeed{{TwistID: "old_twist"}},
+		StartingLocation:    models.StartingLocationSeed{LocationID: "old_location"},
+		GenreModifier:       "old_genre",
+		EnvironmentalOddity: "old_oddity",
+	}
+	fresh := &models.CampaignSeeds{
+		Objective:           models.ObjectiveSeed{ObjectiveID: "new_objective"},
+		Twists:              []models.TwistSeed{{TwistID: "new_twist"}},
+		StartingLocation:    models.StartingLocationSeed{LocationID: "new_location"},
+		GenreModifier:       "new_genre",
+		EnvironmentalOddity: "new_oddity",
+	}
+
+	applySeedLocks(fresh, previous, []models.SeedCategory{models.SeedCategoryTwists, models.SeedCategoryVariance})
+
+	if fresh.Twists[0].TwistID != "old_twist" {
+		t.Errorf("Expected locked twists kept, got %s", fresh.Twists[0].TwistID)
+	}
+	if fresh.GenreModifier != "old_genre" || fresh.EnvironmentalOddity != "old_oddity" {
+		t.Errorf("Expected locked variance kept, got genre=%s oddity=%s", fresh.GenreModifier, fresh.EnvironmentalOddity)
+	}
+	if fresh.Objective.ObjectiveID != "new_objective" {
+		t.Errorf("Expected unlocked objective rerolled, got %s", fresh.Objective.ObjectiveID)
+	}
+	if fresh.StartingLocation.LocationID != "new_location" {
+		t.Errorf("Expected unlocked location rerolled, got %s", fresh.StartingLocation.LocationID)
+	}
+}
+
+// TestLoadContentOverride tests the stage override, validation, and embedded fallback for seed content
+func TestLoadContentOverride(t *testing.T) {
+	originalFetch, originalBucket, originalStage := fetchContentOverride, promptBucket, stage
+	defer func() {
+		fetchContentOverride, promptBucket, stage = originalFetch, originalBucket, originalStage
+		contentCache = map[string]cachedContent{}
+	}()
+
+	overrides := map[string][]byte{}
+	var fetched []string
+	fetchContentOverride = func(bucket, key string) ([]byte, bool, error) {
+		fetched = append(fetched, bucket+"/"+key)
+		data, ok := overrides[key]
+		return data, ok, nil
+	}
+	reset := func(bucket string) {
+		promptBucket = bucket
+		stage = "dev"
+		contentCache = map[string]cachedContent{}
+		overrides = map[string][]byte{}
+		fetched = nil
+	}
+
+	var embedded map[string]MapData
+	if err := json.Unmarshal(mapsJSON, &embedded); err != nil {
+		t.Fatalf("Failed to parse embedded maps: %v", err)
+	}
+
+	t.Run("overrides disabled", func(t *testing.T) {
+		reset("")
+		mapsData, err := loadContent(contentAssetMaps, mapsJSON, validateMapsData)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(mapsData) != len(embedded) || len(fetched) != 0 {
+			t.Errorf("Expected embedded maps without fetching, got %d maps and fetches %v", len(mapsData), fetched)
+		}
+	})
+
+	t.Run("valid override is used and cached", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		overrides["dev/arcanos_maps.json"] = []byte(`{"test_map": {"name": "Test", "areas": [{"areaId": 1, "name": "Hall"}]}}`)
+		for i := 0; i < 2; i++ {
+			mapsData, err := loadContent(contentAssetMaps, mapsJSON, validateMapsData)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, ok := mapsData["test_map"]; !ok || len(mapsData) != 1 {
+				t.Errorf("Expected override maps, got %v", mapsData)
+			}
+		}
+		if len(fetched) != 1 || fetched[0] != "syrus-prompts-dev/dev/arcanos_maps.json" {
+			t.Errorf("Expected a single fetch of the stage key, got %v", fetched)
+		}
+	})
+
+	t.Run("invalid override falls back", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		overrides["dev/arcanos_maps.json"] = []byte(`{"empty_map": {"name": "Empty", "areas": []}}`)
+		overrides["dev/campaign-blueprint-seeds.json"] = []byte(`{not json`)
+
+		mapsData, err := loadContent(contentAssetMaps, mapsJSON, validateMapsData)
+		if err != nil || len(mapsData) != len(embedded) {
+			t.Errorf("Expected embedded maps for invalid override, got %d maps, err %v", len(mapsData), err)
+		}
+		seeds, err := loadContent(contentAssetSeeds, seedsJSON, validateCampaignSeeds)
+		if err != nil || len(seeds.ObjectiveSeeds) == 0 {
+			t.Errorf("Expected embedded seeds for malformed override, got err %v", err)
+		}
+	})
+
+	t.Run("absent override falls back", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		config, err := loadContent(contentAssetConfig, configJSON, validateCampaignConfig)
+		if err != nil || len(config.CampaignLengthProfiles) == 0 {
+			t.Errorf("Expected embedded config, got err %v", err)
+		}
+	})
+}
+
+// TestValidateContent tests the semantic checks applied to seed content overrides
+func TestValidateContent(t *testing.T) {
+	if err := validateCampaignConfig(CampaignConfig{
+		CampaignLengthProfiles: map[string]LengthProfile{"short": {}},
+		BeatProfiles:           map[string]BeatProfile{},
+	}); err == nil {
+		t.Error("Expected error for length profile without beat profile")
+	}
+	if err := validateCampaignSeeds(CampaignSeeds{}); err == nil {
+		t.Error("Expected error for empty seed pools")
+	}
+	seeds := CampaignSeeds{
+		ObjectiveSeeds:        []models.ObjectiveSeed{{ObjectiveID: "o"}},
+		TwistCandidates:       []models.TwistSeed{{TwistID: "t"}},
+		AntagonistCandidates:  []models.AntagonistSeed{{AntagonistID: "a"}},
+		SetPieceCandidates:    []models.SetPieceSeed{{SetPieceID: "s"}},
+		StartingLocationSeeds: []models.StartingLocationSeed{{LocationID: "l"}},
+		OptionalConstraints:   []models.ConstraintSeed{{ConstraintID: "attrition", Weight: 3}, {ConstraintID: "politics"}},
+	}
+	if err := validateCampaignSeeds(seeds); err != nil {
+		t.Errorf("Expected zero and positive weights accepted, got %v", err)
+	}
+	seeds.OptionalConstraints = append(seeds.OptionalConstraints, models.ConstraintSeed{ConstraintID: "famine", Weight: -2})
+	if err := validateCampaignSeeds(seeds); err == nil || !strings.Contains(err.Error(), "famine") {
+		t.Errorf("Expected a negative weight rejected, got %v", err)
+	}
+	if err := validateMapsData(map[string]MapData{}); err == nil {
+		t.Error("Expected error for no maps")
+	}
+}
+
+// TestValidateCampaignConfigActBounds tests that beat profiles with absurd act counts are rejected
+func TestValidateCampaignConfigActBounds(t *testing.T) {
+	configWithActs := func(acts int, globalLimits map[string]interface{}) CampaignConfig {
+		return CampaignConfig{
+			CampaignLengthProfiles: map[string]LengthProfile{"short": {}},
+			BeatProfiles:           map[string]BeatProfile{"short": {Acts: acts}},
+			GlobalLimits:           globalLimits,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		acts         int
+		globalLimits map[string]interface{}
+		expectErr    bool
+	}{
+		{"within default bounds", 3, nil, false},
+		{"default upper bound", defaultMaxActs, nil, false},
+		{"above default bound", 40, nil, true},
+		{"zero acts", 0, nil, true},
+		{"configured cap allows", 5, map[string]interface{}{"maxActsHardCap": float64(5)}, false},
+		{"configured cap rejects", 6, map[string]interface{}{"maxActsHardCap": float64(5)}, true},
+		{"configured minimum rejects", 2, map[string]interface{}{"minActs": float64(3)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCampaignConfig(configWithActs(tt.acts, tt.globalLimits))
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error=%v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+
+	// The embedded config must sit inside its own bounds
+	var embedded CampaignConfig
+	if err := json.Unmarshal(configJSON, &embedded); err != nil {
+		t.Fatalf("Failed to parse embedded config: %v", err)
+	}
+	if err := validateCampaignConfig(embedded); err != nil {
+		t.Errorf("Expected embedded config to be valid, got %v", err)
+	}
+}
+
+// Configuring validates /campaign start against models.CampaignTypes, so the embedded
+// profiles must cover exactly that set
+func TestConfigProfilesMatchCampaignTypes(t *testing.T) {
+	var config CampaignConfig
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if len(config.CampaignLengthProfiles) != len(models.CampaignTypes) {
+		t.Errorf("Expected %d length profiles, got %d", len(models.CampaignTypes), len(config.CampaignLengthProfiles))
+	}
+	for _, campaignType := range models.CampaignTypes {
+		if _, ok := config.CampaignLengthProfiles[string(campaignType)]; !ok {
+			t.Errorf("Missing length profile for campaign type %q", campaignType)
+		}
+	}
+}
@@ -4,28 +4,51 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/ssm"
 
+	capture "loros/syrus-capture"
+	costs "loros/syrus-costs"
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
 	models "loros/syrus-models"
+	sqsbatch "loros/syrus-sqsbatch"
+	ssmcache "loros/syrus-ssmcache"
+	tracing "loros/syrus-tracing"
+	validation "loros/syrus-validation"
 )
 
+// dedupPrefix namespaces this lambda's records in the shared dedup table
+const dedupPrefix = "blueprinting"
+
+// claimLease holds a message while it is processed. The blueprinting queue's 3 minute visibility timeout is
+// shorter than the 5 minute lambda timeout, so the lease is set by the queue: it lapses before a crashed
+// invocation's message is redelivered.
+const claimLease = 150 * time.Second
+
 //go:embed assets/blueprintPrompt.txt
 var blueprintPrompt string
 
@@ -46,13 +69,25 @@ var (
 	dynamodbClient   *dynamodb.DynamoDB
 	s3Client         *s3.S3
 	sqsClient        *sqs.SQS
-	ssmClient        *ssm.SSM
 	campaignsTable   string
-	dedupTable       string
 	messagingQueue   string
 	imageGenQueue    string
 	modelCacheBucket string
+	promptBucket     string
+	debugUsers       models.DebugUsers
+	promptExperiment string
+	imageFormat      string
 	stage            string
+	sqsConcurrency   int
+)
+
+// introImageModel generates intro images at the campaign's ModelPolicy image size and quality
+const introImageModel = "dall-e-3"
+
+// OpenAI image response formats
+const (
+	imageFormatB64JSON = "b64_json" // Image bytes inline - no download, no expiring URL
+	imageFormatURL     = "url"
 )
 
 func init() {
@@ -60,36 +95,43 @@ func init() {
 	dynamodbClient = dynamodb.New(awsSession)
 	s3Client = s3.New(awsSession)
 	sqsClient = sqs.New(awsSession)
-	ssmClient = ssm.New(awsSession)
 
 	campaignsTable = os.Getenv("SYRUS_CAMPAIGNS_TABLE")
-	dedupTable = os.Getenv("SYRUS_DEDUP_TABLE")
 	messagingQueue = os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
 	imageGenQueue = os.Getenv("SYRUS_IMAGEGEN_QUEUE_URL")
 	modelCacheBucket = os.Getenv("SYRUS_MODEL_CACHE_BUCKET")
+	promptBucket = os.Getenv("SYRUS_PROMPT_BUCKET")
+	debugUsers = models.ParseDebugUsers(os.Getenv("SYRUS_DEBUG_USERS"))
+	promptExperiment = os.Getenv(promptExperimentEnvVar)
+	imageFormat = os.Getenv("SYRUS_OPENAI_IMAGE_FORMAT")
+	if imageFormat != imageFormatURL {
+		imageFormat = imageFormatB64JSON
+	}
 	stage = os.Getenv("SYRUS_STAGE")
+	sqsConcurrency = sqsbatch.ConcurrencyFromEnv()
 }
 
 func handler(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
 	log.Printf("Received %d messages from blueprinting queue", len(event.Records))
 
-	var batchItemFailures []events.SQSBatchItemFailure
+	batchItemFailures := sqsbatch.Process(ctx, event.Records, sqsConcurrency, func(ctx context.Context, record events.SQSMessage) error {
+		err := processBlueprintMessage(ctx, record)
 
-	for _, record := range event.Records {
-		if err := processBlueprintMessage(ctx, record); err != nil {
-			log.Printf("Failed to process message %s: %v", record.MessageId, err)
-			batchItemFailures = append(batchItemFailures, events.SQSBatchItemFailure{
-				ItemIdentifier: record.MessageId,
-			})
+		// Shed load on Anthropic overload: hide the message for a backoff period instead of
+		// letting SQS redeliver it immediately
+		var overloaded *AnthropicOverloadedError
+		if errors.As(err, &overloaded) {
+			delayRetry(record)
 		}
-	}
+		return err
+	})
 
 	return events.SQSEventResponse{
 		BatchItemFailures: batchItemFailures,
 	}, nil
 }
 
-func processBlueprintMessage(ctx context.Context, record events.SQSMessage) error {
+func processBlueprintMessage(ctx context.Context, record events.SQSMessage) (err error) {
 	log.Printf("Processing blueprint message: %s", record.MessageId)
 
 	// Parse the blueprint message
@@ -100,50 +142,104 @@ func processBlueprintMessage(ctx context.Context, record events.SQSMessage) erro
 
 	log.Printf("Campaign ID: %s, Interaction ID: %s", blueprintMsg.CampaignID, blueprintMsg.InteractionID)
 
-	// Check dedup table
-	if isDuplicate, err := checkDedup(blueprintMsg.InteractionID); err != nil {
-		return fmt.Errorf("failed to check dedup: %w", err)
-	} else if isDuplicate {
+	// Log one latency breakdown per interaction, whatever the outcome
+	trace := tracing.New("blueprinting", blueprintMsg.InteractionID)
+	defer func() { trace.Log(err) }()
+
+	// Claim the interaction; the conditional write lets only one concurrent delivery through
+	if claimed, err := dedup.Claim(dedupPrefix, blueprintMsg.InteractionID, claimLease); err != nil {
+		return fmt.Errorf("failed to claim dedup: %w", err)
+	} else if !claimed {
 		log.Printf("Message already processed (interactionId: %s), skipping", blueprintMsg.InteractionID)
 		return nil
 	}
+	// Drop the claim on failure so the SQS retry is not mistaken for a duplicate
+	defer func() {
+		if err != nil {
+			if releaseErr := dedup.Release(dedupPrefix, blueprintMsg.InteractionID); releaseErr != nil {
+				log.Printf("Warning: failed to release dedup claim: %v", releaseErr)
+			}
+		}
+	}()
 
 	// Fetch campaign from DynamoDB
+	done := trace.Start(tracing.PhaseDynamoDBRead)
 	campaign, err := getCampaign(blueprintMsg.CampaignID)
+	done()
 	if err != nil {
 		return fmt.Errorf("failed to get campaign: %w", err)
 	}
 
+	// A reroll that lost the race to an earlier weaving must not replace an activated blueprint
+	if blueprintMsg.Reroll > 0 && isPastActivation(campaign.Status) {
+		log.Printf("Campaign %s is already %s, skipping reroll %d", blueprintMsg.CampaignID, campaign.Status, blueprintMsg.Reroll)
+		if err := dedup.Mark(dedupPrefix, blueprintMsg.InteractionID, dedup.DefaultTTL); err != nil {
+			log.Printf("Warning: failed to mark as processed: %v", err)
+		}
+		return nil
+	}
+
 	// Determine which model to use
-	modelName := determineModel(campaign)
+	modelName := applyModelOverride(determineModel(campaign), blueprintMsg)
 	log.Printf("Using model: %s", modelName)
+	promptVersion := selectPromptVersion(campaign, promptExperiment)
+	log.Printf("Using blueprint prompt version: %s", promptVersion.Name)
 
 	// Check S3 cache
-	cacheKey := fmt.Sprintf("%s/blueprint/%s/response.json", blueprintMsg.CampaignID, modelName)
+	cacheKey := blueprintCacheKey(blueprintMsg.CampaignID, modelName, promptVersion.Name, blueprintMsg.Reroll)
 	cachedResponse, found, err := checkCache(cacheKey)
 	if err != nil {
 		return fmt.Errorf("failed to check cache: %w", err)
 	}
 
 	var claudeResponse string
+	freshResponse := !found
 	if found {
 		log.Printf("Cache hit for campaign %s", blueprintMsg.CampaignID)
 		claudeResponse = cachedResponse
 	} else {
 		log.Printf("Cache miss for campaign %s, calling Claude API", blueprintMsg.CampaignID)
 
+		// Respect the campaign's soft limits: downgrade to a cheaper model, or stop with an in-character notice
+		affordable, ok := costs.Choose(campaign.CostTracking, models.Model(modelName))
+		if !ok {
+			log.Printf("Campaign %s has spent its blueprint budget", blueprintMsg.CampaignID)
+			if err := sendBudgetSpentMessage(campaign, blueprintMsg.InteractionID); err != nil {
+				return fmt.Errorf("failed to send budget message: %w", err)
+			}
+			if err := dedup.Mark(dedupPrefix, blueprintMsg.InteractionID, dedup.DefaultTTL); err != nil {
+				log.Printf("Warning: failed to mark as processed: %v", err)
+			}
+			return nil
+		}
+		if string(affordable) != modelName {
+			log.Printf("Sonnet budget spent for campaign %s, downgrading blueprint to %s", blueprintMsg.CampaignID, affordable)
+			modelName = string(affordable)
+			cacheKey = blueprintCacheKey(blueprintMsg.CampaignID, modelName, promptVersion.Name, blueprintMsg.Reroll)
+		}
+
 		// Get API key from SSM
+		done := trace.Start(tracing.PhaseSSMFetch)
 		apiKey, err := getAnthropicAPIKey()
+		done()
 		if err != nil {
 			return fmt.Errorf("failed to get API key: %w", err)
 		}
 
 		// Call Claude API
-		claudeResponse, err = callClaude(ctx, apiKey, modelName, blueprintMsg, campaign)
+		done = trace.Start(tracing.PhaseModelCall)
+		claudeResponse, err = callClaude(ctx, apiKey, modelName, promptVersion, blueprintMsg, campaign)
+		done()
 		if err != nil {
 			return fmt.Errorf("failed to call Claude: %w", err)
 		}
 
+		if class, billed := costs.ClassOf(models.Model(modelName)); billed {
+			if err := costs.Record(blueprintMsg.CampaignID, class); err != nil {
+				log.Printf("Warning: failed to record blueprint usage: %v", err)
+			}
+		}
+
 		// Save to cache
 		if err := saveToCache(cacheKey, claudeResponse); err != nil {
 			log.Printf("Warning: failed to save to cache: %v", err)
@@ -151,73 +247,67 @@ func processBlueprintMessage(ctx context.Context, record events.SQSMessage) erro
 	}
 
 	// Parse and validate blueprint
-	blueprint, introduction, err := parseAndValidateResponse(claudeResponse, blueprintMsg.Seeds)
+	done = trace.Start(tracing.PhaseValidation)
+	blueprint, introduction, err := parseAndValidateResponse(claudeResponse, campaign.CampaignType, blueprintMsg.Seeds)
+	done()
+
+	// Capture fresh prompt/response pairs for offline evaluation (cached responses were captured when generated)
+	if freshResponse {
+		captureBlueprintExchange(blueprintMsg, campaign, modelName, promptVersion, claudeResponse, err)
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to parse/validate response: %w", err)
 	}
 
 	log.Printf("Blueprint validated: %s", blueprint.Title)
 
-	// Update campaign with blueprint
-	if err := updateCampaignWithBlueprint(blueprintMsg.CampaignID, blueprint); err != nil {
-		return fmt.Errorf("failed to update campaign: %w", err)
-	}
+	// Keep the introduction with the blueprint so it can be re-sent to late joiners
+	blueprint.Introduction = introduction
 
-	// Check if campaign is already active (from a previous successful attempt)
-	campaign, err = getCampaign(blueprintMsg.CampaignID)
+	// Update campaign with blueprint; the write returns the stored campaign, so a
+	// retry that finds it already active needs no second read
+	campaign, err = updateCampaignWithBlueprint(blueprintMsg.CampaignID, blueprint, promptVersion.Name)
 	if err != nil {
-		return fmt.Errorf("failed to get campaign: %w", err)
+		return fmt.Errorf("failed to update campaign: %w", err)
 	}
 
-	if campaign.Status == models.CampaignStatusActive {
-		log.Printf("Campaign %s is already active, skipping message sends (likely a retry)", blueprintMsg.CampaignID)
+	// Activation is the final durable step, so a campaign past configuring has already had its intro sent
+	if isPastActivation(campaign.Status) {
+		log.Printf("Campaign %s is already %s, skipping message sends (likely a retry)", blueprintMsg.CampaignID, campaign.Status)
+		if err := dedup.Mark(dedupPrefix, blueprintMsg.InteractionID, dedup.DefaultTTL); err != nil {
+			log.Printf("Warning: failed to mark as processed: %v", err)
+		}
 		return nil
 	}
 
 	// Generate intro image if present in imagePlan
-	var introImageS3Key string
-	if blueprint.ImagePlan.IntroImage.Prompt != "" {
-		log.Printf("INFO: IntroImage prompt detected: %s", blueprint.ImagePlan.IntroImage.Prompt[:100]) // Log first 100 chars
-		log.Printf("INFO: Generating intro image for campaign %s", blueprintMsg.CampaignID)
-		s3Key, err := generateIntroImage(ctx, blueprintMsg.CampaignID, blueprint.ImagePlan.IntroImage.Prompt)
-		if err != nil {
-			log.Printf("ERROR: Failed to generate intro image: %v", err)
-			// Don't fail the entire blueprint if intro image fails
-		} else {
-			introImageS3Key = s3Key
-			log.Printf("SUCCESS: Intro image generated and stored at S3 key: %s", s3Key)
-			// Update blueprint with S3 key
-			if err := updateImagePlanIntroS3Key(blueprintMsg.CampaignID, s3Key); err != nil {
-				log.Printf("ERROR: Failed to update intro image S3 key in DynamoDB: %v", err)
-			} else {
-				log.Printf("SUCCESS: Updated blueprint with intro image S3 key")
-			}
-		}
-	} else {
-		log.Printf("WARNING: IntroImage prompt is empty - no image will be generated")
-	}
+	introImageS3Key := prepareIntroImage(ctx, blueprintMsg.CampaignID, blueprint, campaign.ModelPolicy.ImageOptions())
 
 	// Queue remaining images to imageGen queue
-	if err := queueMilestoneImages(blueprintMsg.CampaignID, blueprintMsg.InteractionID, blueprint); err != nil {
+	if err := queueMilestoneImages(blueprintMsg.CampaignID, blueprintMsg.InteractionID, campaign.ModelPolicy, blueprint); err != nil {
 		log.Printf("Warning: failed to queue milestone images: %v", err)
 		// Don't fail the entire blueprint if image queueing fails
 	}
 
 	// Send introduction to messaging queue
-	if err := sendIntroductionToMessaging(blueprintMsg.CampaignID, blueprintMsg.InteractionID, blueprint, introduction, introImageS3Key); err != nil {
+	done = trace.Start(tracing.PhaseSend)
+	err = sendIntroductionToMessaging(campaign, blueprintMsg.InteractionID, blueprint, introduction, introImageS3Key)
+	done()
+	if err != nil {
 		log.Printf("ERROR: Failed to send introduction messages: %v", err)
 		return fmt.Errorf("failed to send introduction: %w", err)
 	}
 
 	log.Printf("Successfully sent all introduction messages for campaign %s", blueprintMsg.CampaignID)
 
-	// Update campaign status to active
-	if err := updateCampaignStatus(blueprintMsg.CampaignID, "active"); err != nil {
+	// Activate the campaign (idempotent - safe if a previous attempt already activated it)
+	if err := activateCampaign(blueprintMsg.CampaignID); err != nil {
 		return fmt.Errorf("failed to update campaign status: %w", err)
 	}
 
 	// Mark as processed in dedup table
-	if err := markAsProcessed(blueprintMsg.InteractionID); err != nil {
+	if err := dedup.Mark(dedupPrefix, blueprintMsg.InteractionID, dedup.DefaultTTL); err != nil {
 		log.Printf("Warning: failed to mark as processed: %v", err)
 	}
 
@@ -225,33 +315,7 @@ func processBlueprintMessage(ctx context.Context, record events.SQSMessage) erro
 	return nil
 }
 
-func checkDedup(interactionID string) (bool, error) {
-	dedupKey := fmt.Sprintf("blueprinting#%s", interactionID)
-	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(dedupTable),
-		Key: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {S: aws.String(dedupKey)},
-		},
-	})
-	if err != nil {
-		return false, err
-	}
-	return result.Item != nil, nil
-}
 
-func markAsProcessed(interactionID string) error {
-	dedupKey := fmt.Sprintf("blueprinting#%s", interactionID)
-	ttl := time.Now().Add(24 * time.Hour).Unix()
-	_, err := dynamodbClient.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(dedupTable),
-		Item: map[string]*dynamodb.AttributeValue{
-			"dedupKey":    {S: aws.String(dedupKey)},
-			"expiresAt":   {N: aws.String(fmt.Sprintf("%d", ttl))},
-			"processedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
-		},
-	})
-	return err
-}
 
 func getCampaign(campaignID string) (*models.Campaign, error) {
 	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
@@ -281,13 +345,46 @@ func determineModel(campaign *models.Campaign) string {
 	return "sonnet" // default
 }
 
+// applyModelOverride swaps the policy model for a one-off debug override, honored only when
+// the message was requested by a debug user and names a known model
+func applyModelOverride(modelName string, blueprintMsg models.BlueprintMessage) string {
+	if blueprintMsg.ModelOverride == "" {
+		return modelName
+	}
+	if !debugUsers.Allows(blueprintMsg.RequestedBy) {
+		log.Printf("Ignoring model override %q from non-debug user %q", blueprintMsg.ModelOverride, blueprintMsg.RequestedBy)
+		return modelName
+	}
+	switch blueprintMsg.ModelOverride {
+	case models.ModelHaiku, models.ModelSonnet:
+		log.Printf("DEBUG: model override active for user %s: %s -> %s", blueprintMsg.RequestedBy, modelName, blueprintMsg.ModelOverride)
+		return string(blueprintMsg.ModelOverride)
+	default:
+		log.Printf("Ignoring unknown model override %q", blueprintMsg.ModelOverride)
+		return modelName
+	}
+}
+
+// s3ErrCodeNotFound is the code HeadObject reports for a missing key; HEAD responses have no body
+// to carry NoSuchKey, and the SDK defines no constant for it
+const s3ErrCodeNotFound = "NotFound"
+
+// isS3NotFound reports whether err is S3's missing-object error: NoSuchKey from GetObject, or NotFound from HeadObject
+func isS3NotFound(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	return awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == s3ErrCodeNotFound
+}
+
 func checkCache(cacheKey string) (string, bool, error) {
 	result, err := s3Client.GetObject(&s3.GetObjectInput{
 		Bucket: aws.String(modelCacheBucket),
 		Key:    aws.String(cacheKey),
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "NoSuchKey") {
+		if isS3NotFound(err) {
 			return "", false, nil
 		}
 		return "", false, err
@@ -313,35 +410,224 @@ func saveToCache(cacheKey, content string) error {
 	return err
 }
 
+// captureBlueprintExchange writes the blueprint prompt, raw response, and validation outcome to the
+// capture bucket. It is a no-op unless SYRUS_CAPTURE_BUCKET is set, and never fails the caller.
+func captureBlueprintExchange(blueprintMsg models.BlueprintMessage, campaign *models.Campaign, modelName string, version promptVersion, response string, validationErr error) {
+	if !capture.Enabled() {
+		return
+	}
+
+	prompt, err := buildPrompt(blueprintMsg, campaign)
+	if err != nil {
+		log.Printf("Warning: failed to rebuild prompt for capture: %v", err)
+		return
+	}
+
+	record := capture.Record{
+		Kind:              "blueprint",
+		CampaignType:      string(campaign.CampaignType),
+		Model:             modelName,
+		PromptVersion:     version.Name,
+		ValidationOutcome: capture.Outcome(validationErr),
+		SystemPrompt:      systemPrompt(version),
+		Prompt:            prompt,
+		Response:          response,
+	}
+	if validationErr != nil {
+		record.ValidationError = validationErr.Error()
+	}
+
+	key, err := capture.Write(record)
+	if err != nil {
+		log.Printf("Warning: failed to capture blueprint exchange: %v", err)
+		return
+	}
+
+	log.Printf("Captured blueprint exchange to %s", key)
+}
+
 func getAnthropicAPIKey() (string, error) {
 	paramName := fmt.Sprintf("/syrus/%s/anthropic/api-key", stage)
-	result, err := ssmClient.GetParameter(&ssm.GetParameterInput{
-		Name:           aws.String(paramName),
-		WithDecryption: aws.Bool(true),
+	return ssmcache.Get(paramName, true)
+}
+
+// promptCacheTTL bounds how long a loaded prompt asset is reused before the bucket is checked again
+const promptCacheTTL = 5 * time.Minute
+
+// Prompt asset names, used as object names under <stage>/ in the prompt bucket
+const (
+	promptAssetSystem      = "blueprintPrompt.txt"
+	promptAssetSampleShort = "sample-blueprint-short.json"
+	promptAssetSampleLong  = "sample-blueprint-long.json"
+	promptAssetSampleEpic  = "sample-blueprint-epic.json"
+)
+
+type cachedPromptAsset struct {
+	value     string
+	expiresAt time.Time
+}
+
+var (
+	promptCacheMu sync.Mutex
+	promptCache   = map[string]cachedPromptAsset{}
+
+	// fetchPromptOverride is swapped out in tests
+	fetchPromptOverride = fetchPromptOverrideFromS3
+)
+
+// promptOverrideKey is the prompt bucket key for an asset in the current stage
+func promptOverrideKey(name string) string {
+	return fmt.Sprintf("%s/%s", stage, name)
+}
+
+// loadPromptAsset returns the stage override for a prompt asset from SYRUS_PROMPT_BUCKET, falling back to
+// the embedded default when overrides are disabled, the object is absent or empty, or the fetch fails.
+// Results are cached for promptCacheTTL so prompt edits go live without a deploy.
+func loadPromptAsset(name, fallback string) string {
+	if promptBucket == "" {
+		return fallback
+	}
+
+	key := promptOverrideKey(name)
+	promptCacheMu.Lock()
+	defer promptCacheMu.Unlock()
+
+	if cached, ok := promptCache[key]; ok && time.Now().Before(cached.expiresAt) {
+		return cached.value
+	}
+
+	value := fallback
+	override, found, err := fetchPromptOverride(key)
+	switch {
+	case err != nil:
+		// Don't cache failures - retry the bucket on the next call
+		log.Printf("Warning: failed to load prompt override s3://%s/%s, using embedded default: %v", promptBucket, key, err)
+		return fallback
+	case found && strings.TrimSpace(override) != "":
+		log.Printf("Using prompt override s3://%s/%s", promptBucket, key)
+		value = override
+	}
+
+	promptCache[key] = cachedPromptAsset{value: value, expiresAt: time.Now().Add(promptCacheTTL)}
+	return value
+}
+
+func fetchPromptOverrideFromS3(key string) (string, bool, error) {
+	result, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(promptBucket),
+		Key:    aws.String(key),
 	})
 	if err != nil {
-		return "", err
+		if isS3NotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		return "", false, err
 	}
-	return *result.Parameter.Value, nil
+	return string(body), true, nil
 }
 
-func callClaude(ctx context.Context, apiKey, modelName string, blueprintMsg models.BlueprintMessage, campaign *models.Campaign) (string, error) {
+// promptVersion is one embedded version of the blueprint system prompt
+type promptVersion struct {
+	Name  string
+	Asset string // Prompt asset name, which also names its stage override in the prompt bucket
+	Text  string
+}
+
+// blueprintPromptVersions lists the embedded system prompts, oldest first; the last is the latest.
+// Add a version by embedding assets/blueprintPrompt-<name>.txt and appending it here.
+var blueprintPromptVersions = []promptVersion{
+	{Name: "v1", Asset: promptAssetSystem, Text: blueprintPrompt},
+}
+
+// promptExperimentEnvVar names the comma-separated prompt versions new campaigns are split across
+// for A/B comparison; unset, campaigns use the latest version
+const promptExperimentEnvVar = "SYRUS_BLUEPRINT_PROMPT_EXPERIMENT"
+
+// findPromptVersion looks up an embedded prompt version by name
+func findPromptVersion(name string) (promptVersion, bool) {
+	for _, version := range blueprintPromptVersions {
+		if strings.EqualFold(version.Name, strings.TrimSpace(name)) {
+			return version, true
+		}
+	}
+	return promptVersion{}, false
+}
+
+// selectPromptVersion picks the system prompt for a campaign: the version recorded on it, else an arm of
+// the experiment chosen by campaign ID so re-runs keep their arm, else the latest
+func selectPromptVersion(campaign *models.Campaign, experiment string) promptVersion {
+	if pinned := campaign.Meta.PromptVersion; pinned != "" {
+		if version, ok := findPromptVersion(pinned); ok {
+			return version
+		}
+		log.Printf("Warning: campaign %s names unknown prompt version %q, using the latest", campaign.CampaignID, pinned)
+	}
+
+	var arms []promptVersion
+	for _, name := range strings.Split(experiment, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		version, ok := findPromptVersion(name)
+		if !ok {
+			log.Printf("Warning: %s names unknown prompt version %q, leaving it out", promptExperimentEnvVar, name)
+			continue
+		}
+		arms = append(arms, version)
+	}
+	if len(arms) > 0 {
+		h := fnv.New32a()
+		h.Write([]byte(campaign.CampaignID))
+		return arms[h.Sum32()%uint32(len(arms))]
+	}
+	return blueprintPromptVersions[len(blueprintPromptVersions)-1]
+}
+
+// blueprintCacheKey is the model cache key for a campaign's blueprint response from a model and prompt version.
+// Rerolls weave fresh seeds, so each caches apart from the original blueprint.
+func blueprintCacheKey(campaignID, modelName, promptVersion string, reroll int) string {
+	if reroll > 0 {
+		return fmt.Sprintf("%s/blueprint/reroll-%d/%s/%s/response.json", campaignID, reroll, modelName, promptVersion)
+	}
+	return fmt.Sprintf("%s/blueprint/%s/%s/response.json", campaignID, modelName, promptVersion)
+}
+
+// systemPrompt returns a version of the blueprint system prompt, honoring any stage override
+func systemPrompt(version promptVersion) string {
+	return loadPromptAsset(version.Asset, version.Text)
+}
+
+// sampleBlueprintFor returns the example blueprint for a campaign type, honoring any stage override
+func sampleBlueprintFor(campaignType models.CampaignType) string {
+	switch campaignType {
+	case models.CampaignTypeShort:
+		return loadPromptAsset(promptAssetSampleShort, sampleBlueprintShort)
+	case models.CampaignTypeEpic:
+		return loadPromptAsset(promptAssetSampleEpic, sampleBlueprintEpic)
+	default:
+		return loadPromptAsset(promptAssetSampleLong, sampleBlueprintLong) // Default to long
+	}
+}
+
+func callClaude(ctx context.Context, apiKey, modelName string, version promptVersion, blueprintMsg models.BlueprintMessage, campaign *models.Campaign) (string, error) {
 	// Build the prompt
 	userPrompt, err := buildPrompt(blueprintMsg, campaign)
 	if err != nil {
 		return "", fmt.Errorf("failed to build prompt: %w", err)
 	}
 
-	// Determine model ID and max tokens
-	var modelID string
-	var maxTokens int
+	// Determine model ID; the call settings cap max tokens at what the model can produce
+	modelID := "claude-sonnet-4-20250514"
 	if modelName == "haiku" {
 		modelID = "claude-3-5-haiku-20241022"
-		maxTokens = 8000 // Haiku max is 8192, use 8000 for safety
-	} else {
-		modelID = "claude-sonnet-4-20250514"
-		maxTokens = 16000
 	}
+	settings := models.CallSettingsFor(models.CallBlueprint, models.Model(modelName))
 
 	// Call Anthropic API
 	// Note: This is a simplified implementation. In production, use the official SDK or HTTP client.
@@ -349,14 +635,14 @@ func callClaude(ctx context.Context, apiKey, modelName string, blueprintMsg mode
 
 	// For now, we'll use a placeholder since the actual API call requires HTTP client setup
 	// In production, implement proper Anthropic API call here
-	return callAnthropicAPI(ctx, apiKey, modelID, maxTokens, blueprintPrompt, userPrompt)
+	return callAnthropicAPI(ctx, apiKey, modelID, settings, systemPrompt(version), userPrompt)
 }
 
 func buildPrompt(blueprintMsg models.BlueprintMessage, campaign *models.Campaign) (string, error) {
 	// Build configuration section
 	configJSON, err := json.MarshalIndent(map[string]interface{}{
 		"campaignLength": campaign.CampaignType,
-		"playStyle":      "synchronous", // TODO: get from campaign config
+		"playStyle":      campaign.EffectivePlayStyle(),
 		"partySize":      len(campaign.Party.Members),
 		"difficulty":     "standard", // TODO: get from campaign config
 		"magicPresence":  "medium",   // TODO: get from campaign config
@@ -379,17 +665,7 @@ func buildPrompt(blueprintMsg models.BlueprintMessage, campaign *models.Campaign
 	}
 
 	// Select appropriate sample blueprint based on campaign type
-	var sampleBlueprint string
-	switch campaign.CampaignType {
-	case models.CampaignTypeShort:
-		sampleBlueprint = sampleBlueprintShort
-	case models.CampaignTypeLong:
-		sampleBlueprint = sampleBlueprintLong
-	case models.CampaignTypeEpic:
-		sampleBlueprint = sampleBlueprintEpic
-	default:
-		sampleBlueprint = sampleBlueprintLong // Default to long
-	}
+	sampleBlueprint := sampleBlueprintFor(campaign.CampaignType)
 
 	// Assemble full prompt
 	prompt := fmt.Sprintf(`Please generate a campaign blueprint.
@@ -423,328 +699,418 @@ func buildPrompt(blueprintMsg models.BlueprintMessage, campaign *models.Campaign
 	return prompt, nil
 }
 
-func callAnthropicAPI(ctx context.Context, apiKey, modelID string, maxTokens int, systemPrompt, userPrompt string) (string, error) {
-	log.Printf("Calling Anthropic API with model %s (max tokens: %d)", modelID, maxTokens)
+// statusOverloaded is the non-standard status Anthropic returns when its API is overloaded
+const statusOverloaded = 529
 
-	// Build request payload
-	payload := map[string]interface{}{
-		"model":       modelID,
-		"max_tokens":  maxTokens,
-		"temperature": 0.7,
-		"system":      systemPrompt,
-		"messages": []map[string]interface{}{
-			{
-				"role":    "user",
-				"content": userPrompt,
-			},
-		},
-	}
+// maxOverloadRetryDelay caps how long an overloaded message is hidden before redelivery
+const maxOverloadRetryDelay = 15 * time.Minute
 
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
+// AnthropicOverloadedError indicates the Anthropic API shed our request and it should be retried later
+type AnthropicOverloadedError struct {
+	Body string
+}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(payloadJSON))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
+func (e *AnthropicOverloadedError) Error() string {
+	return fmt.Sprintf("anthropic API overloaded (status %d): %s", statusOverloaded, e.Body)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+// anthropicMessagesURL is the Anthropic Messages API endpoint; overridden in tests
+var anthropicMessagesURL = "https://api.anthropic.com/v1/messages"
 
-	// Make API call
-	client := &http.Client{
-		Timeout: 4 * time.Minute, // Claude can take a while
-	}
+// Retry policy for Anthropic calls: attempts include the first call, and backoff doubles from
+// anthropicBaseBackoff with up to 50% jitter. A retry-after beyond anthropicMaxBackoff is left to
+// SQS redelivery rather than waited out in the lambda.
+const (
+	anthropicMaxAttempts = 4
+	anthropicBaseBackoff = 2 * time.Second
+	anthropicMaxBackoff  = 30 * time.Second
+)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+// waitForRetry sleeps for delay unless the context ends first; overridden in tests
+var waitForRetry = func(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	defer resp.Body.Close()
+}
 
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
+// isRetryableStatus reports whether an Anthropic response status is worth retrying (429 and 5xx, including 529)
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+// anthropicRetryDelay returns how long to wait before the next attempt. A retry-after header (in seconds)
+// takes precedence; otherwise the backoff is exponential with jitter in [0, 1). ok is false when the
+// server asks for a longer wait than anthropicMaxBackoff.
+func anthropicRetryDelay(attempt int, retryAfter string, jitter float64) (time.Duration, bool) {
+	if seconds, err := strconv.ParseFloat(strings.TrimSpace(retryAfter), 64); err == nil && seconds >= 0 {
+		delay := time.Duration(seconds * float64(time.Second))
+		return delay, delay <= anthropicMaxBackoff
 	}
 
-	// Parse response
-	var apiResponse struct {
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content"`
-		StopReason string `json:"stop_reason"`
+	delay := anthropicBaseBackoff << (attempt - 1)
+	delay += time.Duration(jitter * 0.5 * float64(delay))
+	if delay > anthropicMaxBackoff {
+		delay = anthropicMaxBackoff
 	}
+	return delay, true
+}
 
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+// classifyAnthropicError maps a non-200 Anthropic response to a typed error
+func classifyAnthropicError(statusCode int, body []byte) error {
+	if statusCode == statusOverloaded {
+		return &AnthropicOverloadedError{Body: string(body)}
 	}
 
-	if len(apiResponse.Content) == 0 {
-		return "", fmt.Errorf("API returned empty content")
+	var errorResponse struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error.Type == "overloaded_error" {
+		return &AnthropicOverloadedError{Body: string(body)}
 	}
 
-	responseText := apiResponse.Content[0].Text
-	log.Printf("Received response from Claude (length: %d characters, stop reason: %s)", len(responseText), apiResponse.StopReason)
-
-	return responseText, nil
+	return fmt.Errorf("API returned status %d: %s", statusCode, string(body))
 }
 
-func parseAndValidateResponse(response string, seeds models.CampaignSeeds) (*models.Blueprint, string, error) {
-	log.Printf("Parsing Claude response (length: %d chars)", len(response))
-
-	// Parse the JSON response from Claude
-	var claudeResponse struct {
-		Blueprint json.RawMessage `json:"blueprint"`
-		Intro     string          `json:"intro"`
-	}
-
-	if err := json.Unmarshal([]byte(response), &claudeResponse); err != nil {
-		// Log the first 500 chars for debugging
-		previewLen := 500
-		if len(response) < previewLen {
-			previewLen = len(response)
+// overloadRetryDelay returns the exponential backoff before an overloaded message is redelivered,
+// based on how many times SQS has already delivered it
+func overloadRetryDelay(receiveCount int) time.Duration {
+	if receiveCount < 1 {
+		receiveCount = 1
+	}
+	delay := 30 * time.Second
+	for i := 1; i < receiveCount; i++ {
+		delay *= 2
+		if delay >= maxOverloadRetryDelay {
+			return maxOverloadRetryDelay
 		}
-		log.Printf("Failed to parse JSON. Response preview: %s", response[:previewLen])
-		return nil, "", fmt.Errorf("failed to parse JSON response: %w", err)
 	}
+	return delay
+}
 
-	// Parse the blueprint
-	var blueprint models.Blueprint
-	if err := json.Unmarshal(claudeResponse.Blueprint, &blueprint); err != nil {
-		return nil, "", fmt.Errorf("failed to parse blueprint JSON: %w", err)
+// queueURLFromARN derives an SQS queue URL from its ARN (arn:aws:sqs:region:account:name)
+func queueURLFromARN(arn string) (string, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sqs" {
+		return "", fmt.Errorf("invalid SQS queue ARN: %s", arn)
 	}
+	return fmt.Sprintf("https://sqs.%s.amazonaws.com/%s/%s", parts[3], parts[4], parts[5]), nil
+}
 
-	// Validate blueprint
-	if err := validateBlueprint(&blueprint, seeds); err != nil {
-		return nil, "", fmt.Errorf("blueprint validation failed: %w", err)
+// delayRetry extends a message's visibility timeout so SQS redelivers it after a backoff period
+func delayRetry(record events.SQSMessage) {
+	receiveCount, _ := strconv.Atoi(record.Attributes["ApproximateReceiveCount"])
+	delay := overloadRetryDelay(receiveCount)
+
+	queueURL, err := queueURLFromARN(record.EventSourceARN)
+	if err != nil {
+		log.Printf("Warning: cannot delay retry for message %s: %v", record.MessageId, err)
+		return
 	}
 
-	log.Printf("Successfully parsed and validated blueprint: %s", blueprint.Title)
+	_, err = sqsClient.ChangeMessageVisibility(&sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     aws.String(record.ReceiptHandle),
+		VisibilityTimeout: aws.Int64(int64(delay.Seconds())),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to delay retry for message %s: %v", record.MessageId, err)
+		return
+	}
 
-	return &blueprint, claudeResponse.Intro, nil
+	log.Printf("Anthropic overloaded - message %s will be retried in %s (receive count %d)", record.MessageId, delay, receiveCount)
 }
 
-func validateBlueprint(blueprint *models.Blueprint, seeds models.CampaignSeeds) error {
-	// Required fields
-	if blueprint.Title == "" {
-		return fmt.Errorf("missing required field: title")
-	}
-	if blueprint.Premise == "" {
-		return fmt.Errorf("missing required field: premise")
-	}
-	if len(blueprint.ThematicPillars) != 3 {
-		return fmt.Errorf("thematicPillars must have exactly 3 elements, got %d", len(blueprint.ThematicPillars))
-	}
-	
-	// IntroImage validation (REQUIRED)
-	if blueprint.ImagePlan.IntroImage.Prompt == "" {
-		return fmt.Errorf("missing required field: imagePlan.introImage.prompt")
-	}
-	if blueprint.ImagePlan.IntroImage.SendWhen != "campaign_start" {
-		return fmt.Errorf("imagePlan.introImage.sendWhen must be 'campaign_start', got '%s'", blueprint.ImagePlan.IntroImage.SendWhen)
+func callAnthropicAPI(ctx context.Context, apiKey, modelID string, settings models.CallSettings, systemPrompt, userPrompt string) (string, error) {
+	log.Printf("Calling Anthropic API with model %s (max tokens: %d, temperature %.2f)", modelID, settings.MaxTokens, settings.Temperature)
+
+	// Build request payload
+	payload := map[string]interface{}{
+		"model":       modelID,
+		"max_tokens":  settings.MaxTokens,
+		"temperature": settings.Temperature,
+		"system":      systemPrompt,
+		"messages": []map[string]interface{}{
+			{
+				"role":    "user",
+				"content": userPrompt,
+			},
+		},
 	}
 
-	// Acts validation
-	expectedActs := seeds.BeatProfile.Acts
-	if len(blueprint.Acts) != expectedActs {
-		return fmt.Errorf("acts count mismatch: expected %d, got %d", expectedActs, len(blueprint.Acts))
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// D&D Sanity Check: Ensure at least one act has physical danger
-	// Check for: named monsters, physical lairs, or direct combat opportunities
-	hasFightableContent := false
-	for _, act := range blueprint.Acts {
-		primaryArea := strings.ToLower(act.PrimaryArea)
-		primaryDanger := strings.ToLower(act.PrimaryDanger)
-		narrativePurpose := strings.ToLower(act.NarrativePurpose)
+	// Make API call, retrying rate limits and server errors in place so a transient overload
+	// doesn't cost a full SQS redelivery and re-prompt
+	client := &http.Client{
+		Timeout: 4 * time.Minute, // Claude can take a while
+	}
 
-		// Check for physical locations/dangers
-		physicalKeywords := []string{
-			"lair", "ruins", "tomb", "dungeon", "stronghold", "tower", "cave",
-			"monster", "beast", "dragon", "undead", "creature", "guardian",
-			"fight", "combat", "assault", "siege", "defend", "battle",
+	var body []byte
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", anthropicMessagesURL, bytes.NewReader(payloadJSON))
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %w", err)
 		}
 
-		actText := primaryArea + " " + primaryDanger + " " + narrativePurpose
-		for _, keyword := range physicalKeywords {
-			if strings.Contains(actText, keyword) {
-				hasFightableContent = true
-				break
-			}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("API request failed: %w", err)
 		}
 
-		if hasFightableContent {
-			break
+		// Read response
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
 		}
-	}
 
-	// Check major forces for physical antagonists
-	if !hasFightableContent && len(blueprint.MajorForces) > 0 {
-		for range blueprint.MajorForces {
-			// Look for creature/monster types in the force data
-			// This is a simple check - we're looking for any indication of a physical threat
-			hasFightableContent = true // If there are major forces defined, assume they're physical
+		if resp.StatusCode == http.StatusOK {
 			break
 		}
-	}
-
-	if !hasFightableContent {
-		log.Printf("WARNING: Blueprint may lack physical threats - no monsters, lairs, or direct combat opportunities found")
-		// Note: We log a warning but don't fail validation to allow flexibility
-		// This is a sanity check, not a hard requirement
-	}
-
-	// Adventure Content Validation (soft checks - log warnings for monitoring)
-	adventureScore := 0
-	hasMonsterInEarlyActs := false
-	hasPhysicalLocation := false
-	hasCombatOpportunity := false
-	hasTangibleStakes := false
-
-	for i, act := range blueprint.Acts {
-		actText := strings.ToLower(act.PrimaryArea + " " + act.PrimaryDanger + " " + act.NarrativePurpose)
-
-		// Check for physical locations
-		locationKeywords := []string{"lair", "ruins", "tomb", "dungeon", "stronghold", "tower", "cave", "wilderness", "temple", "crypt", "vault", "barrow", "fortress", "citadel"}
-		for _, kw := range locationKeywords {
-			if strings.Contains(actText, kw) {
-				hasPhysicalLocation = true
-				adventureScore++
-				break
-			}
+		if !isRetryableStatus(resp.StatusCode) || attempt >= anthropicMaxAttempts {
+			return "", classifyAnthropicError(resp.StatusCode, body)
 		}
 
-		// Check for monsters/enemies (especially in acts 1-2)
-		monsterKeywords := []string{"monster", "beast", "dragon", "undead", "creature", "guardian", "enemy", "raiders", "cultists", "demon", "aberration", "wyrm", "lich", "necromancer", "goblin", "orc", "troll", "giant", "elemental"}
-		for _, kw := range monsterKeywords {
-			if strings.Contains(actText, kw) {
-				if i < 2 {
-					hasMonsterInEarlyActs = true
-				}
-				adventureScore++
-				break
-			}
+		delay, ok := anthropicRetryDelay(attempt, resp.Header.Get("retry-after"), rand.Float64())
+		if !ok {
+			log.Printf("Anthropic API returned status %d with retry-after %q beyond %s, giving up", resp.StatusCode, resp.Header.Get("retry-after"), anthropicMaxBackoff)
+			return "", classifyAnthropicError(resp.StatusCode, body)
 		}
-
-		// Check for action/danger
-		actionKeywords := []string{"fight", "combat", "battle", "chase", "escape", "siege", "assault", "infiltrate", "hunt", "pursue", "ambush", "defend", "attack", "confront"}
-		for _, kw := range actionKeywords {
-			if strings.Contains(actText, kw) {
-				hasCombatOpportunity = true
-				adventureScore++
-				break
-			}
+		log.Printf("Anthropic API returned status %d (attempt %d of %d), retrying in %s", resp.StatusCode, attempt, anthropicMaxAttempts, delay)
+		if err := waitForRetry(ctx, delay); err != nil {
+			return "", fmt.Errorf("gave up retrying Anthropic API after status %d: %w", resp.StatusCode, err)
 		}
 	}
 
-	// Check for tangible stakes in premise
-	premiseText := strings.ToLower(blueprint.Premise)
-	stakeKeywords := []string{"treasure", "power", "territory", "artifact", "relic", "destroy", "kill", "stop", "save", "claim", "steal", "recover", "reclaim"}
-	for _, kw := range stakeKeywords {
-		if strings.Contains(premiseText, kw) {
-			hasTangibleStakes = true
-			adventureScore++
-			break
-		}
+	// Parse response
+	var apiResponse struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
 	}
 
-	// Log warnings if adventure content is weak
-	if adventureScore < 3 {
-		log.Printf("WARNING: Low adventure score (%d/5) - campaign may lack physical danger and tangible threats", adventureScore)
-	}
-	if !hasMonsterInEarlyActs {
-		log.Printf("WARNING: No monsters detected in acts 1-2 - campaign may start slowly")
-	}
-	if !hasPhysicalLocation {
-		log.Printf("WARNING: No hostile physical locations detected - campaign may be too abstract")
-	}
-	if !hasCombatOpportunity {
-		log.Printf("WARNING: No combat/action opportunities detected - players may lack clear danger")
-	}
-	if !hasTangibleStakes {
-		log.Printf("WARNING: No tangible stakes detected in premise - goals may be too abstract")
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Log success for high adventure content
-	if adventureScore >= 4 {
-		log.Printf("✓ Strong adventure content detected (score: %d/5)", adventureScore)
+	if len(apiResponse.Content) == 0 {
+		return "", fmt.Errorf("API returned empty content")
 	}
 
-	// These are soft warnings - don't fail validation, just log for monitoring
+	responseText := apiResponse.Content[0].Text
+	log.Printf("Received response from Claude (length: %d characters, stop reason: %s)", len(responseText), apiResponse.StopReason)
 
-	// TODO: Add more validation as needed:
-	// - Validate area names match featured areas
-	// - Validate NPC firstAppearanceAct values
-	// - Validate boon names match available boons
-	// - Validate end states structure
+	return responseText, nil
+}
 
-	return nil
+// metricsNamespace groups blueprinting metrics in CloudWatch
+const metricsNamespace = "Syrus/Blueprinting"
+
+// metricsOutput receives CloudWatch Embedded Metric Format records; Lambda ships stdout to CloudWatch Logs
+var metricsOutput io.Writer = os.Stdout
+
+// buildValidationMetric builds an EMF record counting one validation outcome. BlueprintValidationFailed is 0/1
+// so its Average over a period is the failure rate the alarm watches.
+func buildValidationMetric(stage string, campaignType models.CampaignType, outcome string, at time.Time) map[string]interface{} {
+	failed := 0
+	if outcome != validation.OutcomeSuccess {
+		failed = 1
+	}
+	if campaignType == "" {
+		campaignType = "unknown"
+	}
+	return map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": at.UnixMilli(),
+			"CloudWatchMetrics": []map[string]interface{}{
+				{
+					"Namespace": metricsNamespace,
+					"Dimensions": [][]string{
+						{"Stage", "CampaignType", "Outcome"},
+					},
+					"Metrics": []map[string]string{
+						{"Name": "BlueprintValidation", "Unit": "Count"},
+					},
+				},
+				{
+					"Namespace": metricsNamespace,
+					"Dimensions": [][]string{
+						{"Stage"},
+						{"Stage", "CampaignType"},
+					},
+					"Metrics": []map[string]string{
+						{"Name": "BlueprintValidationFailed", "Unit": "Count"},
+					},
+				},
+			},
+		},
+		"Stage":                     stage,
+		"CampaignType":              string(campaignType),
+		"Outcome":                   outcome,
+		"BlueprintValidation":       1,
+		"BlueprintValidationFailed": failed,
+	}
 }
 
-func updateCampaignWithBlueprint(campaignID string, blueprint *models.Blueprint) error {
+// emitValidationMetric records a blueprint validation outcome for the failure-rate alarm
+func emitValidationMetric(campaignType models.CampaignType, outcome string) {
+	record, err := json.Marshal(buildValidationMetric(stage, campaignType, outcome, time.Now()))
+	if err != nil {
+		log.Printf("Warning: failed to marshal validation metric: %v", err)
+		return
+	}
+	fmt.Fprintln(metricsOutput, string(record))
+}
+
+// parseAndValidateResponse validates Claude's response and records the outcome for the failure-rate alarm
+func parseAndValidateResponse(response string, campaignType models.CampaignType, seeds models.CampaignSeeds) (*models.Blueprint, string, error) {
+	blueprint, intro, err := validation.ParseResponse(response, seeds)
+	emitValidationMetric(campaignType, validation.Outcome(err))
+	return blueprint, intro, err
+}
+
+// updateCampaignWithBlueprint stores the blueprint, records the prompt version that produced it,
+// and returns the campaign as written
+func updateCampaignWithBlueprint(campaignID string, blueprint *models.Blueprint, promptVersion string) (*models.Campaign, error) {
 	blueprintJSON, err := dynamodbattribute.MarshalMap(blueprint)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = dynamodbClient.UpdateItem(&dynamodb.UpdateItemInput{
+	result, err := dynamodbClient.UpdateItem(&dynamodb.UpdateItemInput{
 		TableName: aws.String(campaignsTable),
 		Key: map[string]*dynamodb.AttributeValue{
 			"campaignId": {S: aws.String(campaignID)},
 		},
-		UpdateExpression: aws.String("SET blueprint = :blueprint, lastUpdatedAt = :lastUpdatedAt"),
+		UpdateExpression: aws.String("SET blueprint = :blueprint, meta.promptVersion = :promptVersion, lastUpdatedAt = :lastUpdatedAt"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":blueprint":     {M: blueprintJSON},
+			":promptVersion": {S: aws.String(promptVersion)},
 			":lastUpdatedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
 		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	var campaign models.Campaign
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &campaign); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal updated campaign: %w", err)
+	}
+	return &campaign, nil
+}
+
+// isPastActivation reports whether a campaign has already been activated (or moved beyond active)
+func isPastActivation(status models.CampaignStatus) bool {
+	switch status {
+	case models.CampaignStatusActive, models.CampaignStatusPlaying, models.CampaignStatusEnded:
+		return true
+	}
+	return false
+}
+
+// resolveActivationError decides whether a failed configuring -> active transition is really a failure.
+// A conditional check failure on a campaign that is already past activation means a previous attempt won.
+func resolveActivationError(err error, current *models.Campaign) error {
+	if err == nil {
+		return nil
+	}
+	if dedup.IsConditionalCheckFailed(err) && current != nil && isPastActivation(current.Status) {
+		return nil
+	}
+	return err
+}
+
+// activateCampaign idempotently transitions a campaign from configuring to active
+func activateCampaign(campaignID string) error {
+	err := transitionStatus(campaignID, models.CampaignStatusConfiguring, models.CampaignStatusActive)
+	if err == nil || !dedup.IsConditionalCheckFailed(err) {
+		return err
+	}
+
+	// The condition failed - check whether an earlier attempt already activated the campaign
+	current, getErr := getCampaign(campaignID)
+	if getErr != nil {
+		return fmt.Errorf("%w (and failed to re-read campaign: %v)", err, getErr)
+	}
+	if resolveActivationError(err, current) == nil {
+		log.Printf("Campaign %s already %s, activation is a no-op", campaignID, current.Status)
+		return nil
+	}
 	return err
 }
 
-func updateCampaignStatus(campaignID string, status string) error {
+// transitionStatus moves a campaign between lifecycle statuses, enforcing the allowed graph.
+// The write is conditional on the stored status still being `from`, so concurrent or stale
+// writers cannot make an illegal jump.
+func transitionStatus(campaignID string, from, to models.CampaignStatus) error {
+	if !models.CanTransition(from, to) {
+		return fmt.Errorf("illegal campaign status transition %s -> %s", from, to)
+	}
+
 	_, err := dynamodbClient.UpdateItem(&dynamodb.UpdateItemInput{
 		TableName: aws.String(campaignsTable),
 		Key: map[string]*dynamodb.AttributeValue{
 			"campaignId": {S: aws.String(campaignID)},
 		},
-		UpdateExpression: aws.String("SET #status = :status, lastUpdatedAt = :lastUpdatedAt"),
+		UpdateExpression:    aws.String("SET #status = :to, lastUpdatedAt = :lastUpdatedAt"),
+		ConditionExpression: aws.String("#status = :from"),
 		ExpressionAttributeNames: map[string]*string{
 			"#status": aws.String("status"),
 		},
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":status":        {S: aws.String(status)},
+			":from":          {S: aws.String(string(from))},
+			":to":            {S: aws.String(string(to))},
 			":lastUpdatedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to update campaign status to %s: %w", status, err)
+		return fmt.Errorf("failed to transition campaign status %s -> %s: %w", from, to, err)
 	}
-	log.Printf("Updated campaign %s status to: %s", campaignID, status)
+	log.Printf("Transitioned campaign %s status: %s -> %s", campaignID, from, to)
 	return nil
 }
 
-func sendIntroductionToMessaging(campaignID, interactionID string, blueprint *models.Blueprint, introduction, introImageS3Key string) error {
-	log.Printf("DEBUG: sendIntroductionToMessaging called - campaignID: %s, interactionID: %s, hasIntroImage: %v",
-		campaignID, interactionID, introImageS3Key != "")
-
-	// Get the campaign to find the channel ID
-	campaign, err := getCampaign(campaignID)
+// sendBudgetSpentMessage tells the campaign channel that the blueprint cannot be woven within its budget
+func sendBudgetSpentMessage(campaign *models.Campaign, interactionID string) error {
+	body, err := json.Marshal(models.MessagingQueueMessage{
+		ChannelID: campaign.Meta.ChannelID,
+		Content:   costs.ThinWeaveMessage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal budget message: %w", err)
+	}
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(messagingQueue),
+		MessageBody:            aws.String(string(body)),
+		MessageGroupId:         aws.String(campaign.CampaignID),
+		MessageDeduplicationId: aws.String(interactionID + "-budget"),
+	})
 	if err != nil {
-		log.Printf("ERROR: Failed to get campaign: %v", err)
-		return fmt.Errorf("failed to get campaign for messaging: %w", err)
+		return fmt.Errorf("failed to send budget message: %w", err)
 	}
+	return nil
+}
 
-	log.Printf("DEBUG: Campaign retrieved - channelID: %s", campaign.Meta.ChannelID)
+func sendIntroductionToMessaging(campaign *models.Campaign, interactionID string, blueprint *models.Blueprint, introduction, introImageS3Key string) error {
+	campaignID := campaign.CampaignID
+	log.Printf("DEBUG: sendIntroductionToMessaging called - campaignID: %s, interactionID: %s, hasIntroImage: %v, channelID: %s",
+		campaignID, interactionID, introImageS3Key != "", campaign.Meta.ChannelID)
 
 	// Message 1: Campaign Title (no image attachment)
 	titleMsg := models.MessagingQueueMessage{
@@ -857,7 +1223,7 @@ func sendIntroductionToMessaging(campaignID, interactionID string, blueprint *mo
 	log.Printf("DEBUG: Sending how-to-act message")
 	howToActMsg := models.MessagingQueueMessage{
 		ChannelID: campaign.Meta.ChannelID,
-		Content:   "How to act:\nUse /syrus declare to state what your character does, intends, or investigates.\n\nExample:\n/syrus declare I step forward and address the council.",
+		Content:   buildHowToActMessage(campaign.Meta.Mode, campaign.DecisionModel),
 		Flags:     64, // Ephemeral flag
 	}
 	howToActMsgJSON, err := json.Marshal(howToActMsg)
@@ -878,8 +1244,101 @@ func sendIntroductionToMessaging(campaignID, interactionID string, blueprint *mo
 	return err
 }
 
-func generateIntroImage(ctx context.Context, campaignID, prompt string) (string, error) {
-	s3Key := fmt.Sprintf("%s/images/intro.png", campaignID)
+// buildHowToActMessage tailors the onboarding text to the campaign's mode and decision model
+func buildHowToActMessage(mode models.CampaignMode, decisionModel models.DecisionModel) string {
+	example := "\n\nExample:\n/syrus declare I step forward and address the council."
+
+	if mode == models.CampaignModeSolo {
+		return "How to act:\nThis tale is yours alone. Use /syrus declare to state what you do, intend, or investigate, and the story answers to you." + example
+	}
+
+	switch decisionModel {
+	case models.DecisionModelHost:
+		return "How to act:\nThe host guides this tale. Only the host's /syrus declare advances the story—everyone else, share your ideas at the table and let the host speak for the party." + example
+	case models.DecisionModelGroup:
+		return "How to act:\nAnyone may use /syrus declare to state what their character does, intends, or investigates.\nWhen the story reaches a choice, the party votes and the majority decides." + example
+	default:
+		return "How to act:\nUse /syrus declare to state what your character does, intends, or investigates." + example
+	}
+}
+
+// minImagePromptLength rejects prompts too short to describe a scene
+const minImagePromptLength = 10
+
+// maxImagePromptLength is the DALL-E 3 prompt limit in characters
+const maxImagePromptLength = 4000
+
+// isValidImagePrompt reports whether a prompt is worth sending to the image provider
+func isValidImagePrompt(prompt string) bool {
+	trimmed := strings.TrimSpace(prompt)
+	n := utf8.RuneCountInString(trimmed)
+	return n >= minImagePromptLength && n <= maxImagePromptLength
+}
+
+// truncatePrompt shortens a prompt for logging without splitting a multi-byte character
+func truncatePrompt(prompt string, max int) string {
+	runes := []rune(prompt)
+	if len(runes) <= max {
+		return prompt
+	}
+	return string(runes[:max])
+}
+
+// buildImageUpload builds the S3 upload for a generated image, recording its provenance as object metadata
+func buildImageUpload(bucket, s3Key string, image models.ImageResult) *s3.PutObjectInput {
+	return &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(s3Key),
+		Body:        bytes.NewReader(image.Data),
+		ContentType: aws.String(image.ContentType),
+		Metadata:    aws.StringMap(image.Metadata()),
+	}
+}
+
+var (
+	// Swapped out in tests
+	introImageGenerator = generateIntroImage
+	introImageRecorder  = updateImagePlanIntroS3Key
+)
+
+// prepareIntroImage generates the intro image and records its S3 key on the blueprint, returning the key to attach.
+// The blueprint record is the source of truth: an image whose key could not be recorded is not attached,
+// so the premise message never references an image the campaign doesn't know about.
+func prepareIntroImage(ctx context.Context, campaignID string, blueprint *models.Blueprint, options models.ImageOptions) string {
+	introPrompt := blueprint.ImagePlan.IntroImage.Prompt
+	if !isValidImagePrompt(introPrompt) {
+		log.Printf("WARNING: IntroImage prompt is empty or invalid (%d chars) - no image will be generated", len(strings.TrimSpace(introPrompt)))
+		return ""
+	}
+
+	log.Printf("INFO: IntroImage prompt detected: %s", truncatePrompt(introPrompt, 100)) // Log first 100 chars
+	log.Printf("INFO: Generating intro image for campaign %s", campaignID)
+	s3Key, err := introImageGenerator(ctx, campaignID, introPrompt, options)
+	if err != nil {
+		// Don't fail the entire blueprint if intro image fails
+		log.Printf("ERROR: Failed to generate intro image: %v", err)
+		return ""
+	}
+	log.Printf("SUCCESS: Intro image generated and stored at S3 key: %s", s3Key)
+
+	if err := introImageRecorder(campaignID, s3Key); err != nil {
+		log.Printf("ERROR: Failed to update intro image S3 key in DynamoDB, sending premise without image: %v", err)
+		return ""
+	}
+	blueprint.ImagePlan.IntroImage.S3Key = s3Key
+	log.Printf("SUCCESS: Updated blueprint with intro image S3 key")
+
+	return blueprint.ImagePlan.IntroImage.S3Key
+}
+
+// introImageS3Key names the intro image after a hash of its prompt, so a regenerated blueprint with a
+// new prompt misses the cache instead of serving the previous image
+func introImageS3Key(campaignID, prompt string) string {
+	return fmt.Sprintf("%s/images/intro-%s.png", campaignID, models.HashPrompt(prompt)[:8])
+}
+
+func generateIntroImage(ctx context.Context, campaignID, prompt string, options models.ImageOptions) (string, error) {
+	s3Key := introImageS3Key(campaignID, prompt)
 
 	// Check S3 cache first
 	_, err := s3Client.HeadObject(&s3.HeadObjectInput{
@@ -890,6 +1349,9 @@ func generateIntroImage(ctx context.Context, campaignID, prompt string) (string,
 		log.Printf("Intro image already cached: %s", s3Key)
 		return s3Key, nil
 	}
+	if !isS3NotFound(err) {
+		return "", fmt.Errorf("failed to check intro image cache: %w", err)
+	}
 
 	// Get API key
 	apiKey, err := getOpenAIAPIKey()
@@ -898,24 +1360,14 @@ func generateIntroImage(ctx context.Context, campaignID, prompt string) (string,
 	}
 
 	// Call OpenAI API
-	imageURL, err := callOpenAIImageAPI(ctx, apiKey, prompt)
+	imageData, err := callOpenAIImageAPI(ctx, apiKey, prompt, imageFormat, options)
 	if err != nil {
 		return "", fmt.Errorf("failed to call OpenAI: %w", err)
 	}
 
-	// Download image
-	imageData, err := downloadImageFromURL(ctx, imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-
 	// Upload to S3
-	_, err = s3Client.PutObject(&s3.PutObjectInput{
-		Bucket:      aws.String(modelCacheBucket),
-		Key:         aws.String(s3Key),
-		Body:        bytes.NewReader(imageData),
-		ContentType: aws.String("image/png"),
-	})
+	image := models.NewImageResult(imageData, models.ImageProviderOpenAI, introImageModel, options.Size, prompt, time.Now())
+	_, err = s3Client.PutObject(buildImageUpload(modelCacheBucket, s3Key, image))
 	if err != nil {
 		return "", fmt.Errorf("failed to upload to S3: %w", err)
 	}
@@ -926,35 +1378,29 @@ func generateIntroImage(ctx context.Context, campaignID, prompt string) (string,
 
 func getOpenAIAPIKey() (string, error) {
 	paramName := fmt.Sprintf("/syrus/%s/openai/api-key", stage)
-	result, err := ssmClient.GetParameter(&ssm.GetParameterInput{
-		Name:           aws.String(paramName),
-		WithDecryption: aws.Bool(true),
-	})
-	if err != nil {
-		return "", err
-	}
-	return *result.Parameter.Value, nil
+	return ssmcache.Get(paramName, true)
 }
 
-func callOpenAIImageAPI(ctx context.Context, apiKey, prompt string) (string, error) {
-	log.Printf("Calling OpenAI DALL-E 3 API")
+func callOpenAIImageAPI(ctx context.Context, apiKey, prompt, format string, options models.ImageOptions) ([]byte, error) {
+	log.Printf("Calling OpenAI DALL-E 3 API (response format: %s, %s, %s)", format, options.Size, options.Quality)
 
 	payload := map[string]interface{}{
-		"model":   "dall-e-3",
-		"prompt":  prompt,
-		"n":       1,
-		"size":    "1024x1024",
-		"quality": "standard",
+		"model":           introImageModel,
+		"prompt":          prompt,
+		"n":               1,
+		"size":            options.Size,
+		"quality":         options.Quality,
+		"response_format": format,
 	}
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/images/generations", bytes.NewReader(payloadJSON))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -963,34 +1409,66 @@ func callOpenAIImageAPI(ctx context.Context, apiKey, prompt string) (string, err
 	client := &http.Client{Timeout: 90 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return nil, fmt.Errorf("API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
+	imageData, imageURL, err := parseOpenAIImageResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	if imageData != nil {
+		return imageData, nil
+	}
+
+	// URL format - fetch the image before the link expires
+	imageData, err = downloadImageFromURL(ctx, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	return imageData, nil
+}
+
+// parseOpenAIImageResponse extracts the image from an OpenAI images response: decoded bytes for
+// b64_json responses, or the image URL for url responses
+func parseOpenAIImageResponse(body []byte) ([]byte, string, error) {
 	var apiResponse struct {
 		Data []struct {
-			URL string `json:"url"`
+			URL     string `json:"url"`
+			B64JSON string `json:"b64_json"`
 		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if len(apiResponse.Data) == 0 {
-		return "", fmt.Errorf("API returned empty data")
+		return nil, "", fmt.Errorf("API returned empty data")
 	}
 
-	return apiResponse.Data[0].URL, nil
+	image := apiResponse.Data[0]
+	if image.B64JSON != "" {
+		imageData, err := base64.StdEncoding.DecodeString(image.B64JSON)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode b64_json image: %w", err)
+		}
+		return imageData, "", nil
+	}
+
+	if image.URL == "" {
+		return nil, "", fmt.Errorf("API returned neither b64_json nor url")
+	}
+	return nil, image.URL, nil
 }
 
 func downloadImageFromURL(ctx context.Context, imageURL string) ([]byte, error) {
@@ -1050,7 +1528,9 @@ func updateImagePlanAdditionalS3Key(campaignID, imageID, s3Key string) error {
 	return err
 }
 
-func queueMilestoneImages(campaignID, interactionID string, blueprint *models.Blueprint) error {
+// queueMilestoneImages queues the blueprint's additional images for imageGen, which generates them
+// with the campaign's image model, size, and quality
+func queueMilestoneImages(campaignID, interactionID string, policy models.ModelPolicy, blueprint *models.Blueprint) error {
 	if imageGenQueue == "" {
 		log.Printf("ImageGen queue URL not configured, skipping milestone images")
 		return nil
@@ -1063,8 +1543,9 @@ func queueMilestoneImages(campaignID, interactionID string, blueprint *models.Bl
 	}
 
 	for imageID, imagePlan := range blueprint.ImagePlan.AdditionalImages {
-		// Skip if no prompt
-		if imagePlan.Prompt == "" {
+		// Skip prompts the image provider would reject
+		if !isValidImagePrompt(imagePlan.Prompt) {
+			log.Printf("Warning: skipping image %s with empty or invalid prompt (%d chars)", imageID, len(strings.TrimSpace(imagePlan.Prompt)))
 			continue
 		}
 
@@ -1074,7 +1555,9 @@ func queueMilestoneImages(campaignID, interactionID string, blueprint *models.Bl
 			InteractionID: interactionID,
 			ImageID:       imageID,
 			Prompt:        imagePlan.Prompt,
-			Model:         "dall-e-3",
+			Model:         string(policy.ImageGen),
+			Size:          policy.ImageSize,
+			Quality:       policy.ImageQuality,
 		}
 
 		msgJSON, err := json.Marshal(imageGenMsg)
@@ -1101,5 +1584,6 @@ func queueMilestoneImages(campaignID, interactionID string, blueprint *models.Bl
 }
 
 func main() {
+	logging.Init("blueprinting")
 	lambda.Start(handler)
 }
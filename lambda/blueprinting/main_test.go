@@ -1,116 +1,324 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	models "loros/syrus-models"
+	validation "loros/syrus-validation"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
 )
 
-func TestValidateBlueprint(t *testing.T) {
-	seeds := models.CampaignSeeds{
-		BeatProfile: models.BeatProfile{
-			Acts: 4,
-			BeatsPerAct: models.MinMaxRange{
-				Min: 8,
-				Max: 12,
-			},
-			AvgMinutesPerBeat: 5,
+func TestValidationMetricEmitted(t *testing.T) {
+	seeds := models.CampaignSeeds{BeatProfile: models.BeatProfile{Acts: 1}}
+	valid := models.Blueprint{
+		Title:           "Test Campaign",
+		Premise:         "A test premise",
+		ThematicPillars: []string{"One", "Two", "Three"},
+		Acts:            []models.Act{{ActNumber: 1, Name: "Act One"}},
+		ImagePlan: models.ImagePlan{
+			IntroImage: models.ImagePlanItem{Prompt: "A misty harbor at dawn", SendWhen: "campaign_start"},
 		},
+		EndStates: models.EndStates{Success: "The harbor is saved", Compromised: "The harbor burns", Failure: "The harbor is lost"},
 	}
+	untitled := valid
+	untitled.Title = ""
 
-	t.Run("valid blueprint", func(t *testing.T) {
-		blueprint := &models.Blueprint{
-			Title:   "Test Campaign",
-			Premise: "A test premise for validation",
-			ThematicPillars: []string{
-				"Pillar One",
-				"Pillar Two",
-				"Pillar Three",
-			},
-			Acts: []models.Act{
-				{ActNumber: 1, Name: "Act One"},
-				{ActNumber: 2, Name: "Act Two"},
-				{ActNumber: 3, Name: "Act Three"},
-				{ActNumber: 4, Name: "Act Four"},
-			},
-			ImagePlan: models.ImagePlan{
-				IntroImage: models.ImagePlanItem{
-					Prompt:   "Test intro image prompt",
-					SendWhen: "campaign_start",
-				},
-			},
+	response := func(blueprint models.Blueprint) string {
+		blueprintJSON, _ := json.Marshal(blueprint)
+		return fmt.Sprintf(`{"blueprint": %s, "intro": "Welcome"}`, blueprintJSON)
+	}
+
+	tests := []struct {
+		name           string
+		response       string
+		expectedCode   string
+		expectedFailed float64
+	}{
+		{"success", response(valid), validation.OutcomeSuccess, 0},
+		{"missing title", response(untitled), validation.OutcomeMissingTitle, 1},
+		{"invalid json", "not json", validation.OutcomeInvalidJSON, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			original := metricsOutput
+			metricsOutput = &buf
+			defer func() { metricsOutput = original }()
+
+			parseAndValidateResponse(tt.response, models.CampaignTypeShort, seeds)
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Expected one EMF JSON record, got %q: %v", buf.String(), err)
+			}
+			if record["Outcome"] != tt.expectedCode {
+				t.Errorf("Expected Outcome %q, got %v", tt.expectedCode, record["Outcome"])
+			}
+			if record["CampaignType"] != "short" {
+				t.Errorf("Expected CampaignType short, got %v", record["CampaignType"])
+			}
+			if record["BlueprintValidationFailed"] != tt.expectedFailed {
+				t.Errorf("Expected BlueprintValidationFailed %v, got %v", tt.expectedFailed, record["BlueprintValidationFailed"])
+			}
+
+			directives := record["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})
+			dimensions := directives[0].(map[string]interface{})["Dimensions"].([]interface{})[0].([]interface{})
+			if fmt.Sprint(dimensions) != "[Stage CampaignType Outcome]" {
+				t.Errorf("Expected outcome metric dimensioned by Stage, CampaignType, Outcome; got %v", dimensions)
+			}
+		})
+	}
+}
+
+func TestSelectPromptVersion(t *testing.T) {
+	original := blueprintPromptVersions
+	defer func() { blueprintPromptVersions = original }()
+	blueprintPromptVersions = []promptVersion{
+		{Name: "v1", Asset: "blueprintPrompt.txt"},
+		{Name: "v2", Asset: "blueprintPrompt-v2.txt"},
+		{Name: "v3", Asset: "blueprintPrompt-v3.txt"},
+	}
+
+	campaign := func(id, pinned string) *models.Campaign {
+		return &models.Campaign{CampaignID: id, Meta: models.CampaignMeta{PromptVersion: pinned}}
+	}
+
+	if got := selectPromptVersion(campaign("campaign-1", ""), ""); got.Name != "v3" {
+		t.Errorf("Expected the latest version by default, got %s", got.Name)
+	}
+	if got := selectPromptVersion(campaign("campaign-1", "v1"), "v2,v3"); got.Name != "v1" {
+		t.Errorf("Expected a pinned version to win over the experiment, got %s", got.Name)
+	}
+	if got := selectPromptVersion(campaign("campaign-1", "v9"), ""); got.Name != "v3" {
+		t.Errorf("Expected an unknown pinned version to fall back to the latest, got %s", got.Name)
+	}
+	if got := selectPromptVersion(campaign("campaign-1", ""), "v9, "); got.Name != "v3" {
+		t.Errorf("Expected an experiment of unknown versions to fall back to the latest, got %s", got.Name)
+	}
+
+	// Experiment arms are stable per campaign and spread across campaigns
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprintf("campaign-%d", i)
+		arm := selectPromptVersion(campaign(id, ""), "v1, v2")
+		if again := selectPromptVersion(campaign(id, ""), "v1, v2"); again.Name != arm.Name {
+			t.Fatalf("Expected campaign %s to keep its arm, got %s then %s", id, arm.Name, again.Name)
 		}
+		if arm.Name == "v3" {
+			t.Fatalf("Expected only experiment arms, got %s", arm.Name)
+		}
+		seen[arm.Name] = true
+	}
+	if !seen["v1"] || !seen["v2"] {
+		t.Errorf("Expected both arms used across campaigns, got %v", seen)
+	}
+}
 
-		err := validateBlueprint(blueprint, seeds)
-		if err != nil {
-			t.Errorf("Expected valid blueprint to pass validation, got error: %v", err)
+func TestBlueprintPromptVersionsEmbedded(t *testing.T) {
+	names := map[string]bool{}
+	for _, version := range blueprintPromptVersions {
+		if strings.TrimSpace(version.Text) == "" || version.Asset == "" {
+			t.Errorf("Expected version %s to embed a prompt and name its asset", version.Name)
+		}
+		if names[version.Name] {
+			t.Errorf("Duplicate prompt version %s", version.Name)
+		}
+		names[version.Name] = true
+	}
+	if key := blueprintCacheKey("campaign-1", "sonnet", "v1", 0); key != "campaign-1/blueprint/sonnet/v1/response.json" {
+		t.Errorf("Expected the prompt version in the cache key, got %s", key)
+	}
+	if key := blueprintCacheKey("campaign-1", "sonnet", "v1", 2); key != "campaign-1/blueprint/reroll-2/sonnet/v1/response.json" {
+		t.Errorf("Expected rerolls to cache apart, got %s", key)
+	}
+}
+
+func TestLoadPromptAssetOverride(t *testing.T) {
+	originalFetch, originalBucket, originalStage := fetchPromptOverride, promptBucket, stage
+	defer func() {
+		fetchPromptOverride, promptBucket, stage = originalFetch, originalBucket, originalStage
+		promptCache = map[string]cachedPromptAsset{}
+	}()
+
+	var fetched []string
+	overrides := map[string]string{"dev/blueprintPrompt.txt": "override prompt", "dev/sample-blueprint-short.json": "  "}
+	var fetchErr error
+	fetchPromptOverride = func(key string) (string, bool, error) {
+		fetched = append(fetched, key)
+		if fetchErr != nil {
+			return "", false, fetchErr
+		}
+		value, ok := overrides[key]
+		return value, ok, nil
+	}
+	reset := func(bucket string) {
+		promptBucket, stage = bucket, "dev"
+		promptCache = map[string]cachedPromptAsset{}
+		fetched = nil
+		fetchErr = nil
+	}
+
+	t.Run("overrides disabled", func(t *testing.T) {
+		reset("")
+		if got := loadPromptAsset(promptAssetSystem, "embedded"); got != "embedded" {
+			t.Errorf("Expected embedded default, got %q", got)
+		}
+		if len(fetched) != 0 {
+			t.Errorf("Expected no fetch without a prompt bucket, got %v", fetched)
 		}
 	})
 
-	t.Run("missing title", func(t *testing.T) {
-		blueprint := &models.Blueprint{
-			Title:   "",
-			Premise: "A test premise",
-			ThematicPillars: []string{
-				"Pillar One",
-				"Pillar Two",
-				"Pillar Three",
-			},
-			Acts: []models.Act{
-				{ActNumber: 1},
-				{ActNumber: 2},
-				{ActNumber: 3},
-				{ActNumber: 4},
-			},
+	t.Run("override present and cached", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		for i := 0; i < 2; i++ {
+			if got := loadPromptAsset(promptAssetSystem, "embedded"); got != "override prompt" {
+				t.Errorf("Expected override, got %q", got)
+			}
 		}
+		if len(fetched) != 1 || fetched[0] != "dev/blueprintPrompt.txt" {
+			t.Errorf("Expected a single fetch of dev/blueprintPrompt.txt, got %v", fetched)
+		}
+	})
 
-		err := validateBlueprint(blueprint, seeds)
-		if err == nil {
-			t.Error("Expected error for missing title")
+	t.Run("cache expires", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		loadPromptAsset(promptAssetSystem, "embedded")
+		promptCache["dev/blueprintPrompt.txt"] = cachedPromptAsset{value: "stale", expiresAt: time.Now().Add(-time.Second)}
+		if got := loadPromptAsset(promptAssetSystem, "embedded"); got != "override prompt" {
+			t.Errorf("Expected refreshed override, got %q", got)
+		}
+		if len(fetched) != 2 {
+			t.Errorf("Expected refetch after expiry, got %v", fetched)
 		}
 	})
 
-	t.Run("wrong number of thematic pillars", func(t *testing.T) {
-		blueprint := &models.Blueprint{
-			Title:           "Test Campaign",
-			Premise:         "A test premise",
-			ThematicPillars: []string{"Only One"},
-			Acts: []models.Act{
-				{ActNumber: 1},
-				{ActNumber: 2},
-				{ActNumber: 3},
-				{ActNumber: 4},
-			},
+	t.Run("absent or blank override falls back", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		if got := loadPromptAsset(promptAssetSampleEpic, "embedded epic"); got != "embedded epic" {
+			t.Errorf("Expected fallback for absent override, got %q", got)
+		}
+		if got := loadPromptAsset(promptAssetSampleShort, "embedded short"); got != "embedded short" {
+			t.Errorf("Expected fallback for blank override, got %q", got)
 		}
+	})
 
-		err := validateBlueprint(blueprint, seeds)
-		if err == nil {
-			t.Error("Expected error for wrong number of thematic pillars")
+	t.Run("fetch error falls back without caching", func(t *testing.T) {
+		reset("syrus-prompts-dev")
+		fetchErr = errors.New("access denied")
+		loadPromptAsset(promptAssetSystem, "embedded")
+		if got := loadPromptAsset(promptAssetSystem, "embedded"); got != "embedded" {
+			t.Errorf("Expected fallback on error, got %q", got)
+		}
+		if len(fetched) != 2 {
+			t.Errorf("Expected failures not to be cached, got %d fetches", len(fetched))
 		}
 	})
+}
 
-	t.Run("wrong number of acts", func(t *testing.T) {
-		blueprint := &models.Blueprint{
-			Title:   "Test Campaign",
-			Premise: "A test premise",
-			ThematicPillars: []string{
-				"Pillar One",
-				"Pillar Two",
-				"Pillar Three",
-			},
-			Acts: []models.Act{
-				{ActNumber: 1},
-				{ActNumber: 2},
-			},
+func TestParseOpenAIImageResponse(t *testing.T) {
+	pngHeader := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+	t.Run("b64_json decodes inline bytes", func(t *testing.T) {
+		body := fmt.Sprintf(`{"created":1700000000,"data":[{"b64_json":"%s","revised_prompt":"a barrow"}]}`, base64.StdEncoding.EncodeToString(pngHeader))
+		imageData, imageURL, err := parseOpenAIImageResponse([]byte(body))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if imageURL != "" {
+			t.Errorf("Expected no URL, got %s", imageURL)
+		}
+		if string(imageData) != string(pngHeader) {
+			t.Errorf("Expected decoded PNG header bytes, got %v", imageData)
+		}
+	})
+
+	t.Run("url format returns url", func(t *testing.T) {
+		imageData, imageURL, err := parseOpenAIImageResponse([]byte(`{"data":[{"url":"https://example.com/image.png"}]}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if imageData != nil || imageURL != "https://example.com/image.png" {
+			t.Errorf("Expected URL only, got data=%v url=%s", imageData, imageURL)
+		}
+	})
+
+	t.Run("invalid base64", func(t *testing.T) {
+		if _, _, err := parseOpenAIImageResponse([]byte(`{"data":[{"b64_json":"not base64!!"}]}`)); err == nil {
+			t.Error("Expected error for invalid base64")
 		}
+	})
 
-		err := validateBlueprint(blueprint, seeds)
-		if err == nil {
-			t.Error("Expected error for wrong number of acts")
+	t.Run("empty data", func(t *testing.T) {
+		if _, _, err := parseOpenAIImageResponse([]byte(`{"data":[]}`)); err == nil {
+			t.Error("Expected error for empty data")
 		}
 	})
 }
 
+func TestIsPastActivation(t *testing.T) {
+	tests := []struct {
+		status   models.CampaignStatus
+		expected bool
+	}{
+		{models.CampaignStatusConfiguring, false},
+		{models.CampaignStatusActive, true},
+		{models.CampaignStatusPlaying, true},
+		{models.CampaignStatusEnded, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := isPastActivation(tt.status); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveActivationError(t *testing.T) {
+	conditionFailed := fmt.Errorf("failed to transition campaign status configuring -> active: %w",
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil))
+	throttled := fmt.Errorf("failed to transition campaign status configuring -> active: %w",
+		awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil))
+
+	tests := []struct {
+		name      string
+		err       error
+		current   *models.Campaign
+		expectErr bool
+	}{
+		{"success", nil, nil, false},
+		{"retry after previous activation", conditionFailed, &models.Campaign{Status: models.CampaignStatusActive}, false},
+		{"retry after play began", conditionFailed, &models.Campaign{Status: models.CampaignStatusPlaying}, false},
+		{"condition failed while still configuring", conditionFailed, &models.Campaign{Status: models.CampaignStatusConfiguring}, true},
+		{"condition failed with campaign missing", conditionFailed, nil, true},
+		{"other errors are not swallowed", throttled, &models.Campaign{Status: models.CampaignStatusActive}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveActivationError(tt.err, tt.current)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error=%v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
 func TestDetermineModel(t *testing.T) {
 	t.Run("haiku model policy", func(t *testing.T) {
 		campaign := &models.Campaign{
@@ -203,6 +411,367 @@ func TestBuildPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildHowToActMessage(t *testing.T) {
+	tests := []struct {
+		mode          models.CampaignMode
+		decisionModel models.DecisionModel
+		expected      string
+	}{
+		{models.CampaignModeGroup, models.DecisionModelHost, "The host guides this tale"},
+		{models.CampaignModeGroup, models.DecisionModelGroup, "the party votes"},
+		{models.CampaignModeGroup, models.DecisionModelFlexible, "Use /syrus declare"},
+		{models.CampaignModeSolo, models.DecisionModelHost, "This tale is yours alone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+"-"+string(tt.decisionModel), func(t *testing.T) {
+			message := buildHowToActMessage(tt.mode, tt.decisionModel)
+			if !contains(message, tt.expected) {
+				t.Errorf("Expected how-to-act message for %s to contain %q, got: %s", tt.decisionModel, tt.expected, message)
+			}
+			if !contains(message, "/syrus declare") {
+				t.Errorf("Expected how-to-act message for %s to mention /syrus declare", tt.decisionModel)
+			}
+		})
+	}
+}
+
+func TestApplyModelOverride(t *testing.T) {
+	originalDebugUsers := debugUsers
+	debugUsers = models.ParseDebugUsers("debug-user, other-debug-user")
+	defer func() { debugUsers = originalDebugUsers }()
+
+	tests := []struct {
+		name        string
+		override    models.Model
+		requestedBy string
+		expected    string
+	}{
+		{"no override keeps policy", "", "debug-user", "sonnet"},
+		{"debug user override applies", models.ModelHaiku, "debug-user", "haiku"},
+		{"second debug user override applies", models.ModelHaiku, "other-debug-user", "haiku"},
+		{"non-debug user override ignored", models.ModelHaiku, "player-1", "sonnet"},
+		{"missing requester override ignored", models.ModelHaiku, "", "sonnet"},
+		{"unknown model override ignored", models.ModelOpenAI, "debug-user", "sonnet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := models.BlueprintMessage{ModelOverride: tt.override, RequestedBy: tt.requestedBy}
+			if got := applyModelOverride("sonnet", msg); got != tt.expected {
+				t.Errorf("Expected model %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClassifyAnthropicError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		overloaded bool
+	}{
+		{"529 is overloaded", 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, true},
+		{"529 with empty body is overloaded", 529, "", true},
+		{"overloaded_error type on other status", 503, `{"type":"error","error":{"type":"overloaded_error"}}`, true},
+		{"500 is generic", 500, `{"type":"error","error":{"type":"api_error"}}`, false},
+		{"400 is generic", 400, `{"type":"error","error":{"type":"invalid_request_error"}}`, false},
+		{"429 is generic", 429, `{"type":"error","error":{"type":"rate_limit_error"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyAnthropicError(tt.statusCode, []byte(tt.body))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			// Classification must survive the wrapping done by callClaude and processBlueprintMessage
+			wrapped := fmt.Errorf("failed to call Claude: %w", err)
+			var overloaded *AnthropicOverloadedError
+			if got := errors.As(wrapped, &overloaded); got != tt.overloaded {
+				t.Errorf("Expected overloaded=%v, got %v (err: %v)", tt.overloaded, got, err)
+			}
+		})
+	}
+}
+
+func TestAnthropicRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		jitter     float64
+		expected   time.Duration
+		ok         bool
+	}{
+		{"first backoff", 1, "", 0, 2 * time.Second, true},
+		{"backoff doubles", 3, "", 0, 8 * time.Second, true},
+		{"jitter adds up to half", 2, "", 0.5, 5 * time.Second, true},
+		{"backoff is capped", 6, "", 0.9, anthropicMaxBackoff, true},
+		{"retry-after wins", 1, "7", 0.9, 7 * time.Second, true},
+		{"retry-after beyond the cap", 1, "120", 0, 120 * time.Second, false},
+		{"unparseable retry-after falls back", 1, "Wed, 21 Oct 2015 07:28:00 GMT", 0, 2 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := anthropicRetryDelay(tt.attempt, tt.retryAfter, tt.jitter)
+			if delay != tt.expected || ok != tt.ok {
+				t.Errorf("Expected (%s, %v), got (%s, %v)", tt.expected, tt.ok, delay, ok)
+			}
+		})
+	}
+}
+
+func TestCallAnthropicAPIRetries(t *testing.T) {
+	success := `{"content":[{"type":"text","text":"the blueprint"}],"stop_reason":"end_turn"}`
+	tests := []struct {
+		name      string
+		statuses  []int
+		calls     int
+		wantErr   bool
+		overload  bool
+		retryHint string
+	}{
+		{"succeeds first time", []int{200}, 1, false, false, ""},
+		{"retries overload then succeeds", []int{529, 429, 200}, 3, false, false, ""},
+		{"client errors are not retried", []int{400, 200}, 1, true, false, ""},
+		{"gives up after max attempts", []int{529, 529, 529, 529, 200}, anthropicMaxAttempts, true, true, ""},
+		{"long retry-after is left to redelivery", []int{429, 200}, 1, true, false, "600"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if tt.retryHint != "" {
+					w.Header().Set("retry-after", tt.retryHint)
+				}
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					fmt.Fprint(w, success)
+				} else {
+					fmt.Fprint(w, `{"type":"error","error":{"type":"api_error"}}`)
+				}
+			}))
+			defer server.Close()
+
+			originalURL, originalWait := anthropicMessagesURL, waitForRetry
+			defer func() { anthropicMessagesURL, waitForRetry = originalURL, originalWait }()
+			anthropicMessagesURL = server.URL
+			var waits []time.Duration
+			waitForRetry = func(ctx context.Context, delay time.Duration) error {
+				waits = append(waits, delay)
+				return nil
+			}
+
+			text, err := callAnthropicAPI(context.Background(), "key", "model", models.CallSettings{Temperature: 0.4, MaxTokens: 100}, "system", "user")
+			if calls != tt.calls {
+				t.Errorf("Expected %d calls, got %d", tt.calls, calls)
+			}
+			if len(waits) != tt.calls-1 {
+				t.Errorf("Expected %d waits, got %d", tt.calls-1, len(waits))
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+			var overloaded *AnthropicOverloadedError
+			if errors.As(err, &overloaded) != tt.overload {
+				t.Errorf("Expected overloaded=%v, got %v", tt.overload, err)
+			}
+			if !tt.wantErr && text != "the blueprint" {
+				t.Errorf("Expected the response text, got %q", text)
+			}
+		})
+	}
+}
+
+func TestOverloadRetryDelay(t *testing.T) {
+	tests := []struct {
+		receiveCount int
+		expected     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, 60 * time.Second},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 15 * time.Minute},
+		{50, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("receive count %d", tt.receiveCount), func(t *testing.T) {
+			if got := overloadRetryDelay(tt.receiveCount); got != tt.expected {
+				t.Errorf("Expected delay %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestQueueURLFromARN(t *testing.T) {
+	url, err := queueURLFromARN("arn:aws:sqs:us-east-1:123456789012:syrus-blueprinting-dev.fifo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "https://sqs.us-east-1.amazonaws.com/123456789012/syrus-blueprinting-dev.fifo"
+	if url != expected {
+		t.Errorf("Expected %s, got %s", expected, url)
+	}
+
+	if _, err := queueURLFromARN("not-an-arn"); err == nil {
+		t.Error("Expected error for invalid ARN")
+	}
+}
+
+func TestIsValidImagePrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   string
+		expected bool
+	}{
+		{"empty", "", false},
+		{"whitespace only", "   \n\t  ", false},
+		{"too short", "a cave", false},
+		{"short with padding", "   a cave   ", false},
+		{"valid", "A torchlit cavern beneath the ruined abbey", true},
+		{"at max length", strings.Repeat("a", maxImagePromptLength), true},
+		{"over max length", strings.Repeat("a", maxImagePromptLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidImagePrompt(tt.prompt); got != tt.expected {
+				t.Errorf("isValidImagePrompt(%q) = %v, expected %v", tt.prompt, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncatePrompt(t *testing.T) {
+	if got := truncatePrompt("short", 100); got != "short" {
+		t.Errorf("Expected short prompt unchanged, got %q", got)
+	}
+	if got := truncatePrompt("éééé", 2); got != "éé" {
+		t.Errorf("Expected rune-safe truncation, got %q", got)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) >= len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr)))
 }
+
+func TestSampleBlueprintsMatchSchema(t *testing.T) {
+	for name, sample := range map[string]string{
+		"short": sampleBlueprintShort,
+		"long":  sampleBlueprintLong,
+		"epic":  sampleBlueprintEpic,
+	} {
+		if err := validation.ValidateSchema([]byte(sample)); err != nil {
+			t.Errorf("Sample blueprint %s does not match schema: %v", name, err)
+		}
+	}
+}
+
+func TestBuildImageUploadSetsMetadata(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	image := models.NewImageResult(png, models.ImageProviderOpenAI, introImageModel, models.ImageSizeSquare, "A misty harbor at dawn", time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	input := buildImageUpload("syrus-model-cache-dev", "campaign123/images/intro.png", image)
+
+	if *input.ContentType != "image/png" {
+		t.Errorf("Expected content type image/png, got %s", *input.ContentType)
+	}
+	for key, value := range map[string]string{
+		models.ImageMetaProvider:   "openai",
+		models.ImageMetaModel:      "dall-e-3",
+		models.ImageMetaSize:       "1024x1024",
+		models.ImageMetaPromptHash: models.HashPrompt("A misty harbor at dawn"),
+		models.ImageMetaCreatedAt:  "2025-06-01T12:00:00Z",
+	} {
+		if got, ok := input.Metadata[key]; !ok || *got != value {
+			t.Errorf("Expected metadata %s=%q on upload, got %v", key, value, got)
+		}
+	}
+}
+
+func TestIntroImageS3Key(t *testing.T) {
+	key := introImageS3Key("campaign123", "A misty harbor at dawn")
+	if key != "campaign123/images/intro-"+models.HashPrompt("A misty harbor at dawn")[:8]+".png" {
+		t.Errorf("Expected the key to carry the prompt hash, got %s", key)
+	}
+	if key != introImageS3Key("campaign123", "A misty harbor at dawn") {
+		t.Error("Expected the same prompt to map to the same key")
+	}
+	if key == introImageS3Key("campaign123", "A torchlit cavern beneath the ruined abbey") {
+		t.Error("Expected a new prompt to map to a new key")
+	}
+}
+
+func TestPrepareIntroImageAttachesOnlyRecordedKey(t *testing.T) {
+	originalGenerator, originalRecorder := introImageGenerator, introImageRecorder
+	defer func() { introImageGenerator, introImageRecorder = originalGenerator, originalRecorder }()
+
+	introImageGenerator = func(ctx context.Context, campaignID, prompt string, options models.ImageOptions) (string, error) {
+		return campaignID + "/images/intro.png", nil
+	}
+
+	tests := []struct {
+		name        string
+		recordErr   error
+		expectedKey string
+	}{
+		{"upload and record succeed", nil, "campaign123/images/intro.png"},
+		{"upload ok but record fails", errors.New("conditional check failed"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded []string
+			introImageRecorder = func(campaignID, s3Key string) error {
+				recorded = append(recorded, s3Key)
+				return tt.recordErr
+			}
+
+			blueprint := &models.Blueprint{}
+			blueprint.ImagePlan.IntroImage.Prompt = "A torchlit cavern beneath the ruined abbey"
+
+			key := prepareIntroImage(context.Background(), "campaign123", blueprint, models.DefaultImageOptions)
+			if key != tt.expectedKey {
+				t.Errorf("Expected attached key %q, got %q", tt.expectedKey, key)
+			}
+			if blueprint.ImagePlan.IntroImage.S3Key != tt.expectedKey {
+				t.Errorf("Expected blueprint S3 key %q, got %q", tt.expectedKey, blueprint.ImagePlan.IntroImage.S3Key)
+			}
+			if len(recorded) != 1 {
+				t.Errorf("Expected the key to be recorded once before attaching, got %v", recorded)
+			}
+		})
+	}
+}
+
+func TestIsS3NotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"get missing key", awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), true},
+		{"head missing key", awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), 404, "req-1"), true},
+		{"wrapped missing key", fmt.Errorf("cache: %w", awserr.New(s3.ErrCodeNoSuchKey, "gone", nil)), true},
+		{"access denied", awserr.New("AccessDenied", "Access Denied", nil), false},
+		{"untyped error naming the code", errors.New("NoSuchKey: but not from the SDK"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isS3NotFound(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	commandopts "loros/syrus-commandopts"
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
 	models "loros/syrus-models"
+	sqsbatch "loros/syrus-sqsbatch"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -21,6 +30,13 @@ import (
 	"github.com/aws/aws-sdk-go/service/sqs"
 )
 
+// dedupPrefix namespaces this lambda's records in the shared dedup table
+const dedupPrefix = "configuring"
+
+// claimLease holds a message while it is processed. It matches the 30 second lambda timeout and lapses
+// before the configuring queue's 60 second visibility timeout redelivers a crashed invocation's message.
+const claimLease = 30 * time.Second
+
 // checkHostExists checks if a host exists in the hosts table
 func checkHostExists(hostID string) (*models.Host, error) {
 	hostsTable := os.Getenv("SYRUS_HOSTS_TABLE")
@@ -64,8 +80,66 @@ func checkHostExists(hostID string) (*models.Host, error) {
 	return &host, nil
 }
 
-// getCampaignByChannelID retrieves a campaign using channelId as campaignId
-func getCampaignByChannelID(channelID string) (*models.Campaign, error) {
+// channelCampaignIndex is the campaigns table GSI keyed by channelId and source
+const channelCampaignIndex = "ByChannel"
+
+// channelCampaignRef is a campaign's entry in the ByChannel index
+type channelCampaignRef struct {
+	CampaignID string    `dynamodbav:"campaignId"`
+	CreatedAt  time.Time `dynamodbav:"createdAt"`
+}
+
+// latestCampaignRef returns the ID of the most recently created campaign, or "" when there are none
+func latestCampaignRef(refs []channelCampaignRef) string {
+	latest := -1
+	for i, ref := range refs {
+		if latest < 0 || ref.CreatedAt.After(refs[latest].CreatedAt) {
+			latest = i
+		}
+	}
+	if latest < 0 {
+		return ""
+	}
+	return refs[latest].CampaignID
+}
+
+// findChannelCampaignID returns the ID of the channel's current campaign on the given platform via the
+// ByChannel index. Campaigns created before IDs were generated are not indexed; they are keyed by their
+// channel, so the channel ID itself is returned when the index has no entry.
+func findChannelCampaignID(svc *dynamodb.DynamoDB, campaignsTable, channelID, platform string) (string, error) {
+	if platform == "" {
+		platform = models.PlatformDiscord
+	}
+
+	result, err := svc.Query(&dynamodb.QueryInput{
+		TableName:              aws.String(campaignsTable),
+		IndexName:              aws.String(channelCampaignIndex),
+		KeyConditionExpression: aws.String("channelId = :channelId AND #source = :source"),
+		ExpressionAttributeNames: map[string]*string{
+			"#source": aws.String("source"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":channelId": {S: aws.String(channelID)},
+			":source":    {S: aws.String(platform)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query channel campaigns: %w", err)
+	}
+
+	var refs []channelCampaignRef
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &refs); err != nil {
+		return "", fmt.Errorf("failed to unmarshal channel campaigns: %w", err)
+	}
+	if campaignID := latestCampaignRef(refs); campaignID != "" {
+		return campaignID, nil
+	}
+	return channelID, nil
+}
+
+// getCampaignByChannelID retrieves the campaign bound to a channel on the message's platform
+// (for thread-scoped campaigns the webhook passes the thread ID as channelId)
+func getCampaignByChannelID(channelID, platform string) (*models.Campaign, error) {
 	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
 	if campaignsTable == "" {
 		return nil, fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
@@ -78,12 +152,16 @@ func getCampaignByChannelID(channelID string) (*models.Campaign, error) {
 
 	svc := dynamodb.New(sess)
 
-	// Use channelId as campaignId (partition key)
+	campaignID, err := findChannelCampaignID(svc, campaignsTable, channelID, platform)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := svc.GetItem(&dynamodb.GetItemInput{
 		TableName: aws.String(campaignsTable),
 		Key: map[string]*dynamodb.AttributeValue{
 			"campaignId": {
-				S: aws.String(channelID),
+				S: aws.String(campaignID),
 			},
 		},
 	})
@@ -102,9 +180,21 @@ func getCampaignByChannelID(channelID string) (*models.Campaign, error) {
 		return nil, fmt.Errorf("failed to unmarshal campaign: %w", err)
 	}
 
+	// Replies always go to the interaction's channel; a stored channel that disagrees means the record
+	// was written under the wrong key, so flag it rather than post into the other channel
+	if campaignChannelMismatch(&campaign, channelID) {
+		log.Printf("WARNING: Campaign %s stores channel %s but was addressed from channel %s; replying in %s",
+			campaign.CampaignID, campaign.Meta.ChannelID, channelID, channelID)
+	}
+
 	return &campaign, nil
 }
 
+// campaignChannelMismatch reports whether the campaign's stored channel differs from the interaction's channel
+func campaignChannelMismatch(campaign *models.Campaign, interactionChannelID string) bool {
+	return campaign.Meta.ChannelID != "" && interactionChannelID != "" && campaign.Meta.ChannelID != interactionChannelID
+}
+
 // isCampaignEnded checks if a campaign is ended
 func isCampaignEnded(campaign *models.Campaign) bool {
 	if campaign == nil {
@@ -132,6 +222,7 @@ func sendToMessagingQueue(channelID, content, interactionToken, interactionID st
 		ChannelID:        channelID,
 		Content:          content,
 		InteractionToken: interactionToken,
+		InteractionID:    interactionID,
 	}
 
 	messageBodyJSON, err := json.Marshal(message)
@@ -154,11 +245,11 @@ func sendToMessagingQueue(channelID, content, interactionToken, interactionID st
 	return nil
 }
 
-// sendToBirthingQueue sends a campaign configuration request to the birthing queue
-func sendToBirthingQueue(campaignID, interactionID string) error {
-	queueURL := os.Getenv("SYRUS_BIRTHING_QUEUE_URL")
+// sendThreadRequestToMessagingQueue asks the messaging lambda to create a campaign thread and replay the start into it
+func sendThreadRequestToMessagingQueue(channelID, content string, thread *models.ThreadRequest, interactionToken, interactionID string) error {
+	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
 	if queueURL == "" {
-		return fmt.Errorf("SYRUS_BIRTHING_QUEUE_URL environment variable not set")
+		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
 	}
 
 	sess, err := session.NewSession()
@@ -168,9 +259,11 @@ func sendToBirthingQueue(campaignID, interactionID string) error {
 
 	svc := sqs.New(sess)
 
-	message := models.BirthingMessage{
-		CampaignID:    campaignID,
-		InteractionID: interactionID,
+	message := models.MessagingQueueMessage{
+		ChannelID:        channelID,
+		Content:          content,
+		InteractionToken: interactionToken,
+		CreateThread:     thread,
 	}
 
 	messageBodyJSON, err := json.Marshal(message)
@@ -181,55 +274,65 @@ func sendToBirthingQueue(campaignID, interactionID string) error {
 	_, err = svc.SendMessage(&sqs.SendMessageInput{
 		QueueUrl:               aws.String(queueURL),
 		MessageBody:            aws.String(string(messageBodyJSON)),
-		MessageGroupId:         aws.String(campaignID),               // Group by campaignID
-		MessageDeduplicationId: aws.String(interactionID + "-birth"), // Dedupe by interactionID
+		MessageGroupId:         aws.String(channelID),
+		MessageDeduplicationId: aws.String(interactionID + "-thread"),
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to send message to birthing queue: %w", err)
+		return fmt.Errorf("failed to send message to queue: %w", err)
 	}
 
-	log.Printf("Sent birthing request for campaign %s", campaignID)
+	log.Printf("Successfully sent thread request to messaging queue for channel %s", channelID)
 	return nil
 }
 
-// checkDedup checks if a message has already been processed
-func checkDedup(interactionID string) (bool, error) {
-	dedupTable := os.Getenv("SYRUS_DEDUP_TABLE")
-	if dedupTable == "" {
-		return false, fmt.Errorf("SYRUS_DEDUP_TABLE environment variable not set")
+// sendFollowupEmbedToMessagingQueue sends embeds as an ephemeral follow-up to an already-resolved interaction
+func sendFollowupEmbedToMessagingQueue(channelID string, embeds []map[string]interface{}, interactionToken, interactionID string) error {
+	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
 	}
 
 	sess, err := session.NewSession()
 	if err != nil {
-		return false, fmt.Errorf("failed to create AWS session: %w", err)
+		return fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
-	svc := dynamodb.New(sess)
+	svc := sqs.New(sess)
 
-	dedupKey := fmt.Sprintf("configuring#%s", interactionID)
+	message := models.MessagingQueueMessage{
+		ChannelID:        channelID,
+		Embeds:           embeds,
+		Flags:            64, // Ephemeral - only the invoking user sees it
+		InteractionToken: interactionToken,
+		Followup:         true,
+	}
 
-	result, err := svc.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(dedupTable),
-		Key: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {
-				S: aws.String(dedupKey),
-			},
-		},
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(channelID),                   // Same group keeps it after the original response
+		MessageDeduplicationId: aws.String(interactionID + "-followup"), // Dedupe by interactionID
 	})
 
 	if err != nil {
-		return false, fmt.Errorf("failed to check dedup table: %w", err)
+		return fmt.Errorf("failed to send message to queue: %w", err)
 	}
 
-	return result.Item != nil, nil
+	log.Printf("Successfully sent follow-up embed to messaging queue for channel %s", channelID)
+	return nil
 }
 
-// writeDedup marks a message as processed
-func writeDedup(interactionID string) error {
-	dedupTable := os.Getenv("SYRUS_DEDUP_TABLE")
-	if dedupTable == "" {
-		return fmt.Errorf("SYRUS_DEDUP_TABLE environment variable not set")
+// sendEmbedToMessagingQueue sends embeds to the messaging queue with the given Discord message flags
+func sendEmbedToMessagingQueue(channelID string, embeds []map[string]interface{}, flags int, interactionToken, interactionID string) error {
+	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
 	}
 
 	sess, err := session.NewSession()
@@ -237,39 +340,301 @@ func writeDedup(interactionID string) error {
 		return fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
-	svc := dynamodb.New(sess)
+	svc := sqs.New(sess)
 
-	dedupKey := fmt.Sprintf("configuring#%s", interactionID)
-	expiresAt := time.Now().Add(24 * time.Hour).Unix()
+	message := models.MessagingQueueMessage{
+		ChannelID:        channelID,
+		Embeds:           embeds,
+		Flags:            flags,
+		InteractionToken: interactionToken,
+	}
 
-	_, err = svc.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(dedupTable),
-		Item: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {
-				S: aws.String(dedupKey),
-			},
-			"expiresAt": {
-				N: aws.String(fmt.Sprintf("%d", expiresAt)),
-			},
-		},
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	// A standalone reply needs no ordering, so it does not wait behind the channel's other messages
+	deduplicationID := interactionID + "-config"
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(models.MessageGroupID(channelID, deduplicationID, models.MessageUnordered)),
+		MessageDeduplicationId: aws.String(deduplicationID),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send message to queue: %w", err)
+	}
+
+	log.Printf("Successfully sent embed to messaging queue for channel %s", channelID)
+	return nil
+}
+
+// sendAttachmentToMessagingQueue sends a file to the invoking user only, captioned with content
+func sendAttachmentToMessagingQueue(channelID, content string, attachment models.Attachment, interactionToken, interactionID string) error {
+	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := sqs.New(sess)
+
+	message := models.MessagingQueueMessage{
+		ChannelID:        channelID,
+		Content:          content,
+		Attachments:      []models.Attachment{attachment},
+		Flags:            64, // Ephemeral - only the invoking user sees it
+		InteractionToken: interactionToken,
+	}
+
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(channelID),                 // Group by campaignID
+		MessageDeduplicationId: aws.String(interactionID + "-export"), // Dedupe by interactionID
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send message to queue: %w", err)
+	}
+
+	log.Printf("Successfully sent attachment to messaging queue for channel %s", channelID)
+	return nil
+}
+
+// sendToBirthingQueue sends a campaign configuration request to the birthing queue
+func sendToBirthingQueue(message models.BirthingMessage) error {
+	queueURL := os.Getenv("SYRUS_BIRTHING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_BIRTHING_QUEUE_URL environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := sqs.New(sess)
+
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(message.CampaignID),               // Group by campaignID
+		MessageDeduplicationId: aws.String(message.InteractionID + "-birth"), // Dedupe by interactionID
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to write to dedup table: %w", err)
+		return fmt.Errorf("failed to send message to birthing queue: %w", err)
+	}
+
+	log.Printf("Sent birthing request for campaign %s", message.CampaignID)
+	return nil
+}
+
+// Bounds for the asynchronous batch window, in minutes
+const (
+	minAsyncWindowMinutes = 5
+	maxAsyncWindowMinutes = 7 * 24 * 60
+)
+
+// resolvePlayStyle validates the start command's style and window options. It returns the play style and
+// window to store, or an in-character message explaining what is wrong.
+func resolvePlayStyle(style string, window int) (models.PlayStyle, int, string) {
+	switch models.PlayStyle(style) {
+	case "", models.PlayStyleSynchronous:
+		if window != 0 {
+			return "", 0, "The hourglass only turns for tales told across distance. Choose the asynchronous style to set a window."
+		}
+		return models.PlayStyleSynchronous, 0, ""
+	case models.PlayStyleAsynchronous:
+		if window == 0 {
+			return models.PlayStyleAsynchronous, models.DefaultAsyncWindowMinutes, ""
+		}
+		if window < minAsyncWindowMinutes || window > maxAsyncWindowMinutes {
+			return "", 0, fmt.Sprintf("The hourglass cannot hold that span. Choose a window between %d minutes and %d days.", minAsyncWindowMinutes, maxAsyncWindowMinutes/(24*60))
+		}
+		return models.PlayStyleAsynchronous, window, ""
+	default:
+		return "", 0, "The rhythm of the tale is unclear. Speak: synchronous or asynchronous."
+	}
+}
+
+// Bounds for the idle nudge interval, in hours
+const (
+	minNudgeIntervalHours = 1
+	maxNudgeIntervalHours = 7 * 24
+)
+
+// validateNudgeInterval checks the start command's nudge option, which only applies to asynchronous play.
+// Zero means the default interval. It returns an in-character message when the option is invalid.
+func validateNudgeInterval(playStyle models.PlayStyle, hours int) string {
+	if hours == 0 {
+		return ""
+	}
+	if playStyle != models.PlayStyleAsynchronous {
+		return "Only tales told across distance need a reminder. Choose the asynchronous style to set a nudge."
 	}
+	if hours < minNudgeIntervalHours || hours > maxNudgeIntervalHours {
+		return fmt.Sprintf("The call cannot carry across that span. Choose a nudge between %d hour and %d days.", minNudgeIntervalHours, maxNudgeIntervalHours/24)
+	}
+	return ""
+}
+
+// Models each policy role may use; text roles feed the LLM providers, ImageGen the image providers
+var (
+	textModels  = map[models.Model]bool{models.ModelHaiku: true, models.ModelSonnet: true}
+	imageModels = map[models.Model]bool{models.ModelNanoBanana: true, models.ModelOpenAI: true}
+)
 
-	log.Printf("Marked interaction %s as processed in dedup table", interactionID)
+// validateModelPolicy checks every role names a known model of the right kind,
+// so a bad policy fails at creation rather than deep inside a provider call
+func validateModelPolicy(policy models.ModelPolicy) error {
+	roles := []struct {
+		name    string
+		model   models.Model
+		allowed map[models.Model]bool
+	}{
+		{"intentParsing", policy.IntentParsing, textModels},
+		{"narration", policy.Narration, textModels},
+		{"cinematics", policy.Cinematics, textModels},
+		{"blueprint", policy.Blueprint, textModels},
+		{"imageGen", policy.ImageGen, imageModels},
+	}
+
+	for _, role := range roles {
+		if role.allowed[role.model] {
+			continue
+		}
+		if textModels[role.model] || imageModels[role.model] {
+			return fmt.Errorf("model policy %s: model %q cannot be used for this role", role.name, role.model)
+		}
+		return fmt.Errorf("model policy %s: unknown model %q", role.name, role.model)
+	}
 	return nil
 }
 
+// guildCampaignIndex is the campaigns table GSI keyed by guildId
+const guildCampaignIndex = "ByGuild"
+
+// guildCampaignCap reads the per-guild cap on concurrent campaigns; zero (or unset) means no cap
+func guildCampaignCap() int {
+	raw := os.Getenv("SYRUS_GUILD_CAMPAIGN_CAP")
+	if raw == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 0 {
+		log.Printf("Warning: invalid SYRUS_GUILD_CAMPAIGN_CAP %q, not capping guild campaigns", raw)
+		return 0
+	}
+	return limit
+}
+
+// countGuildCampaigns is swapped out in tests
+var countGuildCampaigns = countActiveGuildCampaigns
+
+// checkGuildCampaignCap returns an in-character refusal when the guild already runs limit campaigns.
+// Guild admins may start campaigns past the cap.
+func checkGuildCampaignCap(guildID string, guildAdmin bool, limit int) (string, error) {
+	if guildID == "" || limit <= 0 {
+		return "", nil
+	}
+	if guildAdmin {
+		log.Printf("Guild admin override: skipping campaign cap for guild %s", guildID)
+		return "", nil
+	}
+
+	active, err := countGuildCampaigns(guildID)
+	if err != nil {
+		return "", err
+	}
+	if active >= limit {
+		return fmt.Sprintf("This realm already holds %d tales in motion, as many as the loom will bear. Let one end before another begins, or ask a server admin to weave it for you.", active), nil
+	}
+	return "", nil
+}
+
+// countActiveGuildCampaigns counts a guild's campaigns that have not ended
+func countActiveGuildCampaigns(guildID string) (int, error) {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return 0, fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(campaignsTable),
+		IndexName:              aws.String(guildCampaignIndex),
+		KeyConditionExpression: aws.String("guildId = :guildId"),
+		FilterExpression:       aws.String("#status <> :ended"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":guildId": {S: aws.String(guildID)},
+			":ended":   {S: aws.String(string(models.CampaignStatusEnded))},
+		},
+		Select: aws.String(dynamodb.SelectCount),
+	}
+
+	count := 0
+	err = svc.QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		count += int(aws.Int64Value(page.Count))
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query guild campaigns: %w", err)
+	}
+
+	return count, nil
+}
+
+// imageQualityFor gives epic campaigns HD images; shorter campaigns keep the cheaper standard quality
+func imageQualityFor(campaignType models.CampaignType) string {
+	if campaignType == models.CampaignTypeEpic {
+		return models.ImageQualityHD
+	}
+	return models.ImageQualityStandard
+}
+
 // createPlaceholderCampaign creates a placeholder campaign
-func createPlaceholderCampaign(channelID, hostID string, campaignType models.CampaignType, decisionModel models.DecisionModel, stage string) (*models.Campaign, error) {
+func createPlaceholderCampaign(channelID, parentChannelID, hostID string, campaignType models.CampaignType, decisionModel models.DecisionModel, playStyle models.PlayStyle, asyncWindow int, stage string) (*models.Campaign, error) {
+	campaignID, err := models.NewCampaignID()
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now().UTC()
 
 	campaign := &models.Campaign{
-		CampaignID:    channelID, // Use channelId as campaignId
+		CampaignID:    campaignID,
+		ChannelID:     channelID, // Thread ID for thread-scoped campaigns; indexed by ByChannel
 		CampaignType:  campaignType,
 		DecisionModel: decisionModel,
+		PlayStyle:     playStyle,
+		AsyncWindow:   asyncWindow,
 		Status:        models.CampaignStatusConfiguring,
 		Lifecycle: models.Lifecycle{
 			Paused:     false,
@@ -280,13 +645,14 @@ func createPlaceholderCampaign(channelID, hostID string, campaignType models.Cam
 		CreatedAt:     now,
 		LastUpdatedAt: now,
 		HostID:        hostID,
-		Source:        "discord",
+		Source:        models.PlatformDiscord,
 		Meta: models.CampaignMeta{
-			Mode:          "group",
-			GuildID:       nil,
-			ChannelID:     channelID,
-			EngineVersion: "loros-campaign-v1",
-			Narrator:      "syrus",
+			Mode:            models.CampaignModeGroup,
+			GuildID:         nil,
+			ChannelID:       channelID,
+			ParentChannelID: parentChannelID, // Set for thread-scoped campaigns
+			EngineVersion:   "loros-campaign-v1",
+			Narrator:        "syrus",
 		},
 		Party: models.Party{
 			Members: []models.PartyMember{
@@ -297,7 +663,7 @@ func createPlaceholderCampaign(channelID, hostID string, campaignType models.Cam
 				},
 			},
 			Boons: models.Boons{
-				Available: []interface{}{},
+				Available: []models.BoonOption{},
 			},
 			SpectatorsAllowed: true,
 			MaxActivePlayers:  9,
@@ -337,7 +703,7 @@ func createPlaceholderCampaign(channelID, hostID string, campaignType models.Cam
 			CurrentAct:  1,
 			CurrentBeat: 0,
 			TurnState: models.TurnState{
-				Mode:           "group",
+				Mode:           string(models.CampaignModeGroup),
 				ActiveDecision: nil,
 			},
 			ActiveFailurePaths: []string{},
@@ -373,9 +739,14 @@ func createPlaceholderCampaign(channelID, hostID string, campaignType models.Cam
 			Cinematics:    models.ModelHaiku,
 			Blueprint:     models.ModelSonnet,
 			ImageGen:      models.ModelOpenAI,
+			ImageQuality:  imageQualityFor(campaignType),
 		},
 	}
 
+	if err := validateModelPolicy(campaign.ModelPolicy); err != nil {
+		return nil, fmt.Errorf("invalid model policy: %w", err)
+	}
+
 	return campaign, nil
 }
 
@@ -411,242 +782,1243 @@ func saveCampaign(campaign *models.Campaign) error {
 	return nil
 }
 
-// processSQSMessage processes a single SQS message
-func processSQSMessage(message events.SQSMessage, stage string) error {
-	// Parse message body
-	var messageBody models.ConfiguringMessage
-	if err := json.Unmarshal([]byte(message.Body), &messageBody); err != nil {
-		return fmt.Errorf("failed to parse message body: %w", err)
+// transitionStatus moves a campaign to a new lifecycle status, enforcing the allowed graph.
+// The write is conditional on the stored status still matching the campaign's current status,
+// so a stale read cannot resurrect or skip a lifecycle stage. Ending also stamps lifecycle.endedAt.
+func transitionStatus(campaign *models.Campaign, to models.CampaignStatus) error {
+	from := campaign.Status
+	if !models.CanTransition(from, to) {
+		return fmt.Errorf("illegal campaign status transition %s -> %s", from, to)
 	}
 
-	log.Printf("Processing configuring message for channel %s, host %s", messageBody.ChannelID, messageBody.HostID)
-
-	// Validate required fields
-	if messageBody.ChannelID == "" {
-		return fmt.Errorf("missing required field: channelId")
-	}
-	if messageBody.HostID == "" {
-		return fmt.Errorf("missing required field: hostId")
-	}
-	if messageBody.InteractionID == "" {
-		return fmt.Errorf("missing required field: interactionId")
-	}
-	if len(messageBody.Options) == 0 {
-		return fmt.Errorf("missing required field: options")
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
 	}
 
-	// Parse subcommand from options
-	var subcommand string
-	if len(messageBody.Options) > 0 {
-		if name, ok := messageBody.Options[0]["name"].(string); ok {
-			subcommand = name
-		}
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
-	log.Printf("Parsed subcommand: %s", subcommand)
+	svc := dynamodb.New(sess)
 
-	// Check deduplication FIRST (before any business logic)
-	alreadyProcessed, err := checkDedup(messageBody.InteractionID)
+	now := time.Now().UTC()
+	nowAttr, err := dynamodbattribute.Marshal(now)
 	if err != nil {
-		log.Printf("Warning: failed to check dedup table: %v", err)
-		// Continue processing - don't fail on dedup check errors
-	} else if alreadyProcessed {
-		log.Printf("Message already processed (interaction %s), skipping", messageBody.InteractionID)
-		return nil // Successfully handled - already processed
+		return fmt.Errorf("failed to marshal timestamp: %w", err)
 	}
 
-	// Check if host exists
+	updateExpr := "SET #status = :to, lastUpdatedAt = :now"
+	if to == models.CampaignStatusEnded {
+		updateExpr += ", lifecycle.endedAt = :now"
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaign.CampaignID)},
+		},
+		UpdateExpression:    aws.String(updateExpr),
+		ConditionExpression: aws.String("#status = :from"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":from": {S: aws.String(string(from))},
+			":to":   {S: aws.String(string(to))},
+			":now":  nowAttr,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to transition campaign status %s -> %s: %w", from, to, err)
+	}
+
+	campaign.Status = to
+	campaign.LastUpdatedAt = now
+	if to == models.CampaignStatusEnded {
+		campaign.Lifecycle.EndedAt = &now
+	}
+
+	log.Printf("Transitioned campaign %s status: %s -> %s", campaign.CampaignID, from, to)
+	return nil
+}
+
+// patchFieldPattern restricts patchable fields to plain top-level attribute names.
+// Dots, brackets and placeholder sigils would otherwise be read as document paths.
+var patchFieldPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// buildCampaignPatch builds a SET expression for the given top-level fields plus lastUpdatedAt.
+// Every field goes through a #name/:value placeholder, so reserved words like "status" or
+// "settings" are safe. Fields are emitted in sorted order to keep the expression deterministic.
+func buildCampaignPatch(fields map[string]*dynamodb.AttributeValue, now *dynamodb.AttributeValue) (string, map[string]*string, map[string]*dynamodb.AttributeValue, error) {
+	if len(fields) == 0 {
+		return "", nil, nil, fmt.Errorf("campaign patch has no fields")
+	}
+
+	names := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if !patchFieldPattern.MatchString(name) {
+			return "", nil, nil, fmt.Errorf("invalid campaign patch field %q: only top-level attribute names are allowed", name)
+		}
+		if name == "campaignId" || name == "lastUpdatedAt" {
+			return "", nil, nil, fmt.Errorf("campaign patch field %q cannot be set directly", name)
+		}
+		if value == nil {
+			return "", nil, nil, fmt.Errorf("campaign patch field %q has no value", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrNames := map[string]*string{"#lastUpdatedAt": aws.String("lastUpdatedAt")}
+	attrValues := map[string]*dynamodb.AttributeValue{":lastUpdatedAt": now}
+	assignments := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		namePlaceholder := fmt.Sprintf("#f%d", i)
+		valuePlaceholder := fmt.Sprintf(":v%d", i)
+		attrNames[namePlaceholder] = aws.String(name)
+		attrValues[valuePlaceholder] = fields[name]
+		assignments = append(assignments, namePlaceholder+" = "+valuePlaceholder)
+	}
+	assignments = append(assignments, "#lastUpdatedAt = :lastUpdatedAt")
+
+	return "SET " + strings.Join(assignments, ", "), attrNames, attrValues, nil
+}
+
+// patchCampaign updates the given top-level campaign fields and stamps lastUpdatedAt.
+// The write is conditional on the campaign existing, so a patch never creates a stub item.
+func patchCampaign(campaignID string, fields map[string]*dynamodb.AttributeValue) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	nowAttr, err := dynamodbattribute.Marshal(time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	updateExpr, attrNames, attrValues, err := buildCampaignPatch(fields, nowAttr)
+	if err != nil {
+		return err
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:          aws.String(updateExpr),
+		ConditionExpression:       aws.String("attribute_exists(campaignId)"),
+		ExpressionAttributeNames:  attrNames,
+		ExpressionAttributeValues: attrValues,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to patch campaign %s: %w", campaignID, err)
+	}
+
+	log.Printf("Patched campaign %s fields: %d", campaignID, len(fields))
+	return nil
+}
+
+// processSQSMessage processes a single SQS message
+func processSQSMessage(message events.SQSMessage, stage string) error {
+	// Parse message body
+	var messageBody models.ConfiguringMessage
+	if err := json.Unmarshal([]byte(message.Body), &messageBody); err != nil {
+		return fmt.Errorf("failed to parse message body: %w", err)
+	}
+
+	log.Printf("Processing configuring message for channel %s, host %s", messageBody.ChannelID, messageBody.HostID)
+
+	// Validate required fields
+	if messageBody.ChannelID == "" {
+		return fmt.Errorf("missing required field: channelId")
+	}
+	if messageBody.HostID == "" {
+		return fmt.Errorf("missing required field: hostId")
+	}
+	if messageBody.InteractionID == "" {
+		return fmt.Errorf("missing required field: interactionId")
+	}
+	if len(messageBody.Options) == 0 {
+		return fmt.Errorf("missing required field: options")
+	}
+
+	// Parse subcommand from options
+	subcommand, _, err := commandopts.ParseOptions(messageBody.Options)
+	if err != nil {
+		log.Printf("Malformed campaign options for interaction %s: %v", messageBody.InteractionID, err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "Your words reach me tangled, their threads knotted beyond reading. Speak the command again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Retrying cannot untangle a malformed payload
+	}
+
+	log.Printf("Parsed subcommand: %s", subcommand)
+
+	// Claim the interaction FIRST (before any business logic); the conditional write means
+	// concurrent deliveries cannot both get past this point
+	claimed, err := dedup.Claim(dedupPrefix, messageBody.InteractionID, claimLease)
+	if err != nil {
+		log.Printf("Warning: failed to claim dedup record: %v", err)
+		// Continue processing - don't fail on dedup errors
+	} else if !claimed {
+		log.Printf("Message already processed (interaction %s), skipping", messageBody.InteractionID)
+		return nil // Successfully handled - already processed
+	}
+
+	// Check if host exists
 	host, err := checkHostExists(messageBody.HostID)
 	if err != nil {
-		log.Printf("Failed to check host: %v", err)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads flicker with uncertainty. Try again when the loom is stable.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Failed to check host: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads flicker with uncertainty. Try again when the loom is stable.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors after sending message
+	}
+	if host == nil {
+		log.Printf("Host %s not whitelisted", messageBody.HostID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "I sense your presence, but you are not yet bound to the loom. The weaver must grant you passage first.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send unauthorized message: %v", err)
+		}
+		return nil // Successfully handled - sent error message
+	}
+	log.Printf("Host %s is whitelisted", messageBody.HostID)
+
+	if err := handleSubcommand(messageBody, subcommand, stage); err != nil {
+		// Drop the claim so the SQS retry is not mistaken for a duplicate
+		if claimed {
+			if releaseErr := dedup.Release(dedupPrefix, messageBody.InteractionID); releaseErr != nil {
+				log.Printf("Warning: failed to release dedup record: %v", releaseErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// handleSubcommand runs the subcommand-specific logic once the message has passed all validations
+func handleSubcommand(messageBody models.ConfiguringMessage, subcommand, stage string) error {
+	switch subcommand {
+	case "start":
+		return handleStartCampaign(messageBody, stage)
+	case "end":
+		return handleEndCampaign(messageBody, stage)
+	case "archive":
+		return handleArchiveCampaign(messageBody)
+	case "pause":
+		return handleSetPaused(messageBody, true)
+	case "resume":
+		return handleSetPaused(messageBody, false)
+	case "info":
+		return handleCampaignInfo(messageBody, stage)
+	case "export":
+		return handleExportCampaign(messageBody)
+	case "preview":
+		return handleSeedsPreview(messageBody, stage)
+	case "reroll":
+		return handleSeedsReroll(messageBody, stage)
+	default:
+		log.Printf("Unhandled campaign subcommand: %s", subcommand)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads know not this command. Speak more clearly, and I shall listen.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+}
+
+// subcommandOptions returns the option values of the message's subcommand. Malformed options are
+// rejected in processSQSMessage, so a parse failure here yields no options.
+func subcommandOptions(messageBody models.ConfiguringMessage) map[string]string {
+	_, opts, err := commandopts.ParseOptions(messageBody.Options)
+	if err != nil {
+		log.Printf("Failed to parse campaign options: %v", err)
+		return map[string]string{}
+	}
+	return opts
+}
+
+// debugModelOverride returns the blueprint model a subcommand's debug option asked for, and who asked.
+// It is passed along as is; blueprinting honors it only for debug users.
+func debugModelOverride(messageBody models.ConfiguringMessage) (models.Model, string) {
+	override := models.Model(subcommandOptions(messageBody)["model"])
+	if override == "" {
+		return "", ""
+	}
+	return override, messageBody.HostID
+}
+
+// wantsThread reports whether /campaign start asked for a dedicated thread
+func wantsThread(messageBody models.ConfiguringMessage) bool {
+	return subcommandOptions(messageBody)["thread"] == "true"
+}
+
+// buildThreadReplay builds the configuring message the messaging lambda replays once the thread exists.
+// The thread option is stripped so a fallback to the channel doesn't request another thread, and the
+// interaction token is dropped so replies post into the thread rather than editing the original response.
+func buildThreadReplay(messageBody models.ConfiguringMessage) models.ConfiguringMessage {
+	replay := messageBody
+	replay.InteractionID = messageBody.InteractionID + "-thread"
+	replay.InteractionToken = ""
+	replay.Options = make([]map[string]interface{}, 0, len(messageBody.Options))
+
+	for i, option := range messageBody.Options {
+		if i > 0 {
+			replay.Options = append(replay.Options, option)
+			continue
+		}
+		stripped := make(map[string]interface{}, len(option))
+		for k, v := range option {
+			stripped[k] = v
+		}
+		if nestedOpts, ok := option["options"].([]interface{}); ok {
+			kept := make([]interface{}, 0, len(nestedOpts))
+			for _, opt := range nestedOpts {
+				if optMap, ok := opt.(map[string]interface{}); ok && optMap["name"] == "thread" {
+					continue
+				}
+				kept = append(kept, opt)
+			}
+			stripped["options"] = kept
+		}
+		replay.Options = append(replay.Options, stripped)
+	}
+
+	return replay
+}
+
+// requestCampaignThread hands /campaign start off to the messaging lambda to run inside a new thread
+func requestCampaignThread(messageBody models.ConfiguringMessage) error {
+	thread := &models.ThreadRequest{
+		Name:   "Syrus Campaign",
+		Replay: buildThreadReplay(messageBody),
+	}
+
+	content := "The loom draws a fresh thread for this tale. Follow it, and the weaving begins there."
+	if err := sendThreadRequestToMessagingQueue(messageBody.ChannelID, content, thread, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Failed to request campaign thread: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry after sending error message
+	}
+
+	// Mark the original interaction processed; the replay carries its own interaction ID
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
+	}
+
+	log.Printf("Requested campaign thread for channel %s", messageBody.ChannelID)
+	return nil
+}
+
+// handleStartCampaign handles the /campaign start subcommand
+func handleStartCampaign(messageBody models.ConfiguringMessage, stage string) error {
+	// Run the campaign in its own thread when asked (unless we're already in one)
+	if wantsThread(messageBody) && messageBody.ParentChannelID == "" {
+		return requestCampaignThread(messageBody)
+	}
+
+	// Check for an existing campaign in this channel
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
+	if err != nil {
+		log.Printf("Failed to check for existing campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors after sending message
+	}
+
+	// If campaign exists and is not ended, send error message
+	if campaign != nil && !isCampaignEnded(campaign) {
+		log.Printf("Active campaign already exists for channel %s", messageBody.ChannelID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The loom only weaves one story per channel. Your tale still unfolds here—finish what you have begun, let it end before starting anew, or open a thread to weave another.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Successfully handled - sent error message
+	}
+
+	// Extract start subcommand parameters
+	opts := subcommandOptions(messageBody)
+	campaignType := models.CampaignType(opts["type"])
+	decisions := opts["decisions"]
+	mode := models.CampaignMode(opts["mode"])
+	preview := opts["preview"] == "true"
+	style := opts["style"]
+	window, _ := strconv.Atoi(opts["window"])
+	nudgeHours, _ := strconv.Atoi(opts["nudge"])
+
+	log.Printf("Start campaign - type: %s, decisions: %s", campaignType, decisions)
+
+	// Validate campaign type
+	if campaignType == "" {
+		log.Printf("Missing campaign type for /campaign start")
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The pattern lacks shape. You must choose a campaign type.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+	if !campaignType.IsValid() {
+		log.Printf("Unknown campaign type for /campaign start: %s", campaignType)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "No such pattern is woven here. Choose a short, long, or epic tale.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	// Validate mode; a solo campaign's one player makes every decision
+	if mode == "" {
+		mode = models.DefaultCampaignMode(messageBody.Platform)
+	}
+	if !mode.IsValid() {
+		log.Printf("Invalid mode value: %s", mode)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The weave knows but two shapes. Speak: solo or group.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+	if mode == models.CampaignModeSolo {
+		if decisions == "" {
+			decisions = string(models.DecisionModelHost)
+		}
+		if decisions != string(models.DecisionModelHost) {
+			log.Printf("Solo campaign requested with decisions: %s", decisions)
+			if err := sendToMessagingQueue(messageBody.ChannelID, "A tale told for one has but one voice. A solo journey is guided by its traveler alone.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+				log.Printf("Failed to send error message: %v", err)
+			}
+			return nil
+		}
+	}
+
+	// Validate decisions
+	if decisions == "" {
+		log.Printf("Missing decisions option for /campaign start")
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads await direction. Who shall guide the choices?", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	// Validate decisions value
+	validDecisions := map[string]bool{"host": true, "flexible": true, "group": true}
+	if !validDecisions[decisions] {
+		log.Printf("Invalid decisions value: %s", decisions)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The path you choose is unclear. Speak: host, flexible, or group.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	// Validate play style and batch window
+	playStyle, asyncWindow, problem := resolvePlayStyle(style, window)
+	if problem == "" {
+		problem = validateNudgeInterval(playStyle, nudgeHours)
+	}
+	if problem != "" {
+		log.Printf("Invalid play style options: style=%q window=%d", style, window)
+		if err := sendToMessagingQueue(messageBody.ChannelID, problem, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	// Enforce the per-guild cap on concurrent campaigns
+	refusal, err := checkGuildCampaignCap(messageBody.GuildID, messageBody.GuildAdmin, guildCampaignCap())
+	if err != nil {
+		// Don't block starts on a counting failure; the cap is a budget guard, not a hard limit
+		log.Printf("Warning: failed to count active campaigns for guild %s: %v", messageBody.GuildID, err)
+	} else if refusal != "" {
+		log.Printf("Guild %s is at its campaign cap", messageBody.GuildID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, refusal, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	// Create new placeholder campaign
+	log.Printf("Creating new campaign for channel %s with type %s", messageBody.ChannelID, campaignType)
+	newCampaign, err := createPlaceholderCampaign(messageBody.ChannelID, messageBody.ParentChannelID, messageBody.HostID, campaignType, models.DecisionModel(decisions), playStyle, asyncWindow, stage)
+	if err != nil {
+		log.Printf("Failed to create placeholder campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The pattern resists. Something in the weave is wrong. I cannot begin.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry after sending error message
+	}
+
+	newCampaign.NudgeInterval = nudgeHours // Zero uses the default interval
+	applyCampaignMode(newCampaign, mode, messageBody.Platform)
+	if messageBody.GuildID != "" {
+		newCampaign.GuildID = messageBody.GuildID
+		newCampaign.Meta.GuildID = aws.String(messageBody.GuildID)
+	}
+
+	// Save campaign to DynamoDB
+	if err := saveCampaign(newCampaign); err != nil {
+		log.Printf("Failed to save campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry after sending error message
+	}
+
+	// Mark as processed in dedup table
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
+		// Don't fail the entire operation if dedup write fails
+	}
+
+	// Send to birthing queue for blueprint generation (or a seeds preview the host must approve)
+	modelOverride, requestedBy := debugModelOverride(messageBody)
+	birthingMessage := models.BirthingMessage{
+		CampaignID:    newCampaign.CampaignID,
+		ChannelID:     messageBody.ChannelID,
+		InteractionID: messageBody.InteractionID,
+		Preview:       preview,
+		ModelOverride: modelOverride,
+		RequestedBy:   requestedBy,
+	}
+	if err := sendToBirthingQueue(birthingMessage); err != nil {
+		log.Printf("Warning: failed to send to birthing queue: %v", err)
+		// Don't fail campaign creation if birthing queue fails
+	}
+
+	// Send success message to the channel
+	successMessage := `I feel the tension in the threads.
+A campaign takes form — pulled from chance, bound by choice.
+Hold steady. The weaving begins.`
+	if preview {
+		successMessage = `I feel the tension in the threads.
+A campaign takes form — pulled from chance, awaiting your choice.
+The seeds will be laid before you. Nothing is woven until you say so.`
+	}
+	if err := sendToMessagingQueue(messageBody.ChannelID, successMessage, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Warning: failed to send success message: %v", err)
+		// Don't fail if success message fails - campaign was created
+	}
+
+	log.Printf("Successfully created campaign for channel %s", messageBody.ChannelID)
+	return nil
+}
+
+// handleEndCampaign handles the /campaign end subcommand
+func handleEndCampaign(messageBody models.ConfiguringMessage, stage string) error {
+	// Check if campaign exists
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
+	if err != nil {
+		log.Printf("Failed to check for existing campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors after sending message
+	}
+
+	if campaign == nil || isCampaignEnded(campaign) {
+		log.Printf("No active campaign found for channel %s", messageBody.ChannelID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "There are no threads here to sever. The loom is empty, waiting.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Successfully handled - sent error message
+	}
+
+	// Check if this is a confirmation
+	_, hasConfirm := subcommandOptions(messageBody)["confirm"]
+
+	if hasConfirm {
+		return handleEndConfirm(messageBody, campaign, stage)
+	} else {
+		return createEndConfirmation(messageBody, campaign, stage)
+	}
+}
+
+// pauseRefusal returns the in-character reason a campaign cannot be paused (or resumed), or "" if it can
+func pauseRefusal(campaign *models.Campaign, pause bool) string {
+	if campaign == nil || isCampaignEnded(campaign) {
+		return "There are no threads here to still. The loom is empty, waiting."
+	}
+	if pause {
+		if campaign.Status != models.CampaignStatusActive && campaign.Status != models.CampaignStatusPlaying {
+			return "The tale has not yet begun to move. There is nothing to hold still."
+		}
+		if campaign.Lifecycle.Paused {
+			return "The threads already hang motionless. The tale is paused."
+		}
+		return ""
+	}
+	if !campaign.Lifecycle.Paused {
+		return "The threads are already in motion. There is nothing to resume."
+	}
+	return ""
+}
+
+// buildPauseUpdate sets only lifecycle.paused, so an end or archive landing between the read and the write
+// keeps its own lifecycle fields.
+func buildPauseUpdate(campaignsTable, campaignID string, pause bool, now time.Time) (*dynamodb.UpdateItemInput, error) {
+	nowAttr, err := dynamodbattribute.Marshal(now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	return &dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:    aws.String("SET #lifecycle.#paused = :paused, #lastUpdatedAt = :lastUpdatedAt"),
+		ConditionExpression: aws.String("attribute_exists(campaignId)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#lifecycle":     aws.String("lifecycle"),
+			"#paused":        aws.String("paused"),
+			"#lastUpdatedAt": aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":paused":        {BOOL: aws.Bool(pause)},
+			":lastUpdatedAt": nowAttr,
+		},
+	}, nil
+}
+
+// setCampaignPaused pauses or resumes a campaign
+func setCampaignPaused(campaignID string, pause bool, now time.Time) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	input, err := buildPauseUpdate(campaignsTable, campaignID, pause, now)
+	if err != nil {
+		return err
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	if _, err := dynamodb.New(sess).UpdateItem(input); err != nil {
+		return fmt.Errorf("failed to set paused on campaign %s: %w", campaignID, err)
+	}
+	return nil
+}
+
+// handleSetPaused handles /campaign pause and /campaign resume
+func handleSetPaused(messageBody models.ConfiguringMessage, pause bool) error {
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
+	if err != nil {
+		log.Printf("Failed to check for existing campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors after sending message
+	}
+
+	if refusal := pauseRefusal(campaign, pause); refusal != "" {
+		log.Printf("Refusing pause=%t for channel %s", pause, messageBody.ChannelID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, refusal, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	if err := setCampaignPaused(campaign.CampaignID, pause, time.Now()); err != nil {
+		log.Printf("Failed to save paused state: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	// Write dedup
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
+		// Don't fail the entire operation if dedup write fails
+	}
+
+	message := `The loom falls still.
+The threads hang where they are, waiting. No deed will be woven until the tale is resumed.`
+	if !pause {
+		message = `The loom stirs once more.
+The threads pick up where they were left. Declare your deeds.`
+	}
+	if err := sendToMessagingQueue(messageBody.ChannelID, message, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Warning: failed to send success message: %v", err)
+	}
+
+	log.Printf("Campaign %s paused=%t by %s", campaign.CampaignID, pause, messageBody.HostID)
+	return nil
+}
+
+// archiveRefusal returns the in-character reason a campaign cannot be archived, or "" if it can
+func archiveRefusal(campaign *models.Campaign) string {
+	if campaign == nil {
+		return "There is no tale here to lay to rest. The loom is empty, waiting."
+	}
+	if campaign.IsArchived() {
+		return "This tale already rests in the archive, its pages bound and shelved."
+	}
+	if !isCampaignEnded(campaign) {
+		return "Only a finished tale may be laid to rest. End it first with /campaign end."
+	}
+	return ""
+}
+
+// archiveKey is where an archived campaign is kept in the archive bucket
+func archiveKey(campaignID string, archivedAt time.Time) string {
+	return fmt.Sprintf("campaigns/%s/%s.json", campaignID, archivedAt.UTC().Format("20060102T150405Z"))
+}
+
+// archivedBlueprint is what stays in the table once a campaign's blueprint has moved to the archive
+type archivedBlueprint struct {
+	Title   string `dynamodbav:"title"`
+	Premise string `dynamodbav:"premise"`
+}
+
+// buildArchiveUpdate stamps the campaign archived and swaps its blueprint and memory for a pointer to the archived
+// copy, keeping only the title and premise. The write is conditional on the campaign being ended and not yet archived.
+func buildArchiveUpdate(campaignsTable string, campaign *models.Campaign, archivedAt time.Time, key string) (*dynamodb.UpdateItemInput, error) {
+	archivedAtAttr, err := dynamodbattribute.Marshal(archivedAt.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal archive time: %w", err)
+	}
+	blueprintAttr, err := dynamodbattribute.Marshal(archivedBlueprint{Title: campaign.Blueprint.Title, Premise: campaign.Blueprint.Premise})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal archived blueprint: %w", err)
+	}
+
+	return &dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaign.CampaignID)},
+		},
+		UpdateExpression:    aws.String("SET #lifecycle.#archivedAt = :archivedAt, #lifecycle.#archiveKey = :archiveKey, #blueprint = :blueprint, #lastUpdatedAt = :archivedAt REMOVE #memory"),
+		ConditionExpression: aws.String("attribute_not_exists(#lifecycle.#archivedAt) AND (#status = :ended OR attribute_exists(#lifecycle.#endedAt))"),
+		ExpressionAttributeNames: map[string]*string{
+			"#lifecycle":     aws.String("lifecycle"),
+			"#archivedAt":    aws.String("archivedAt"),
+			"#archiveKey":    aws.String("archiveKey"),
+			"#endedAt":       aws.String("endedAt"),
+			"#blueprint":     aws.String("blueprint"),
+			"#memory":        aws.String("memory"),
+			"#status":        aws.String("status"),
+			"#lastUpdatedAt": aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":archivedAt": archivedAtAttr,
+			":archiveKey": {S: aws.String(key)},
+			":blueprint":  blueprintAttr,
+			":ended":      {S: aws.String(string(models.CampaignStatusEnded))},
+		},
+	}, nil
+}
+
+// uploadCampaignArchive writes the full campaign, blueprint and memory included, to the archive bucket
+func uploadCampaignArchive(campaign *models.Campaign, key string) error {
+	bucketName := os.Getenv("SYRUS_ARCHIVE_BUCKET")
+	if bucketName == "" {
+		return fmt.Errorf("SYRUS_ARCHIVE_BUCKET environment variable not set")
+	}
+
+	body, err := json.Marshal(campaign)
+	if err != nil {
+		return fmt.Errorf("failed to marshal campaign archive: %w", err)
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	_, err = s3.New(sess).PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(bucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload campaign archive: %w", err)
+	}
+	return nil
+}
+
+// archiveCampaign moves an ended campaign's blueprint and memory to S3 and marks it archived.
+// It reports false if the campaign was archived (or revived) between the read and the write.
+func archiveCampaign(campaign *models.Campaign, now time.Time) (bool, error) {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return false, fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	key := archiveKey(campaign.CampaignID, now)
+	if err := uploadCampaignArchive(campaign, key); err != nil {
+		return false, err
+	}
+
+	input, err := buildArchiveUpdate(campaignsTable, campaign, now, key)
+	if err != nil {
+		return false, err
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return false, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	if _, err := dynamodb.New(sess).UpdateItem(input); err != nil {
+		if dedup.IsConditionalCheckFailed(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to archive campaign %s: %w", campaign.CampaignID, err)
+	}
+
+	log.Printf("Archived campaign %s to %s", campaign.CampaignID, key)
+	return true, nil
+}
+
+// handleArchiveCampaign handles /campaign archive, retiring an ended campaign to the archive
+func handleArchiveCampaign(messageBody models.ConfiguringMessage) error {
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
+	if err != nil {
+		log.Printf("Failed to check for existing campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil // Don't retry on infrastructure errors after sending message
 	}
-	if host == nil {
-		log.Printf("Host %s not whitelisted", messageBody.HostID)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "I sense your presence, but you are not yet bound to the loom. The weaver must grant you passage first.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
-			log.Printf("Failed to send unauthorized message: %v", err)
+
+	if refusal := archiveRefusal(campaign); refusal != "" {
+		log.Printf("Refusing to archive campaign in channel %s", messageBody.ChannelID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, refusal, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
 		}
-		return nil // Successfully handled - sent error message
+		return nil
 	}
-	log.Printf("Host %s is whitelisted", messageBody.HostID)
 
-	// Now handle subcommand-specific logic after all validations
-	switch subcommand {
-	case "start":
-		return handleStartCampaign(messageBody, stage)
-	case "end":
-		return handleEndCampaign(messageBody, stage)
-	default:
-		log.Printf("Unhandled campaign subcommand: %s", subcommand)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads know not this command. Speak more clearly, and I shall listen.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+	archived, err := archiveCampaign(campaign, time.Now().UTC())
+	if err != nil {
+		log.Printf("Failed to archive campaign %s: %v", campaign.CampaignID, err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+	if !archived {
+		if err := sendToMessagingQueue(messageBody.ChannelID, "This tale already rests in the archive, its pages bound and shelved.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil
 	}
+
+	// Write dedup
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
+		// Don't fail the entire operation if dedup write fails
+	}
+
+	message := `The tale is laid to rest.
+Its pages are bound and shelved in the archive, safe from the fading of the loom.
+This place is free for a new story to begin.`
+	if err := sendToMessagingQueue(messageBody.ChannelID, message, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Warning: failed to send success message: %v", err)
+	}
+
+	log.Printf("Campaign %s archived by %s", campaign.CampaignID, messageBody.HostID)
+	return nil
 }
 
-// handleStartCampaign handles the /campaign start subcommand
-func handleStartCampaign(messageBody models.ConfiguringMessage, stage string) error {
-	// Check for existing campaign using channelId as campaignId
-	campaign, err := getCampaignByChannelID(messageBody.ChannelID)
-	if err != nil {
-		log.Printf("Failed to check for existing campaign: %v", err)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+// parsePreviewAction extracts the action option from /campaign preview
+func parsePreviewAction(messageBody models.ConfiguringMessage) models.PreviewAction {
+	return models.PreviewAction(subcommandOptions(messageBody)["action"])
+}
+
+// handleSeedsPreview handles the /campaign preview subcommand (confirm or reroll pending seeds)
+func handleSeedsPreview(messageBody models.ConfiguringMessage, stage string) error {
+	action := parsePreviewAction(messageBody)
+	if action != models.PreviewActionConfirm && action != models.PreviewActionReroll {
+		log.Printf("Invalid preview action: %s", action)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The seeds await a clearer word. Speak: confirm or reroll.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
-		return nil // Don't retry on infrastructure errors after sending message
+		return nil
 	}
 
-	// If campaign exists and is not ended, send error message
-	if campaign != nil && !isCampaignEnded(campaign) {
-		log.Printf("Active campaign already exists for channel %s", messageBody.ChannelID)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The loom only weaves one story per channel. Your tale still unfolds here—finish what you have begun, or let it end before starting anew.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
-			log.Printf("Failed to send error message: %v", err)
+	return forwardPreviewAction(messageBody, action, nil)
+}
+
+// parseSeedLocks parses the comma-separated lock option from /campaign reroll, returning any unknown categories
+func parseSeedLocks(messageBody models.ConfiguringMessage) ([]models.SeedCategory, []string) {
+	locked := make([]models.SeedCategory, 0)
+	invalid := make([]string, 0)
+
+	raw := subcommandOptions(messageBody)["lock"]
+	seen := make(map[models.SeedCategory]bool)
+	for _, part := range strings.Split(raw, ",") {
+		category := models.SeedCategory(strings.ToLower(strings.TrimSpace(part)))
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		if category.IsValid() {
+			locked = append(locked, category)
+		} else {
+			invalid = append(invalid, string(category))
 		}
-		return nil // Successfully handled - sent error message
 	}
 
-	// Extract start subcommand parameters
-	var campaignType models.CampaignType
-	var decisions string
+	return locked, invalid
+}
 
-	if len(messageBody.Options) > 0 {
-		if nestedOpts, ok := messageBody.Options[0]["options"].([]interface{}); ok {
-			for _, opt := range nestedOpts {
-				if optMap, ok := opt.(map[string]interface{}); ok {
-					name, _ := optMap["name"].(string)
-					switch name {
-					case "type":
-						if typeStr, ok := optMap["value"].(string); ok {
-							campaignType = models.CampaignType(typeStr)
-						}
-					case "decisions":
-						if decisionStr, ok := optMap["value"].(string); ok {
-							decisions = decisionStr
-						}
-					}
-				}
-			}
+// handleSeedsReroll handles the /campaign reroll subcommand (recast pending seeds keeping locked categories,
+// or reweave the blueprint from fresh seeds when none are pending)
+func handleSeedsReroll(messageBody models.ConfiguringMessage, stage string) error {
+	locked, invalid := parseSeedLocks(messageBody)
+	if len(invalid) > 0 {
+		log.Printf("Invalid seed lock categories: %v", invalid)
+		message := fmt.Sprintf("I know not these threads: %s. You may lock: objective, twists, antagonists, setpieces, constraints, location, map, variance.", strings.Join(invalid, ", "))
+		if err := sendToMessagingQueue(messageBody.ChannelID, message, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
 		}
+		return nil
 	}
 
-	log.Printf("Start campaign - type: %s, decisions: %s", campaignType, decisions)
+	return forwardPreviewAction(messageBody, models.PreviewActionReroll, locked)
+}
 
-	// Validate campaign type
-	if campaignType == "" {
-		log.Printf("Missing campaign type for /campaign start")
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The pattern lacks shape. You must choose a campaign type.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+// forwardPreviewAction validates the campaign and host, then hands a preview action to the birthing lambda
+func forwardPreviewAction(messageBody models.ConfiguringMessage, action models.PreviewAction, locked []models.SeedCategory) error {
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
+	if err != nil {
+		log.Printf("Failed to get campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil // Don't retry on infrastructure errors after sending message
+	}
+
+	// Seeds can only be previewed before the blueprint is woven
+	if campaign == nil || campaign.Status != models.CampaignStatusConfiguring {
+		log.Printf("No configuring campaign for channel %s", messageBody.ChannelID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "No seeds lie waiting here. The loom is empty, or the weaving has already begun.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil
 	}
 
-	// Validate decisions
-	if decisions == "" {
-		log.Printf("Missing decisions option for /campaign start")
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads await direction. Who shall guide the choices?", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+	if campaign.HostID != messageBody.HostID {
+		log.Printf("User %s is not the host of campaign %s", messageBody.HostID, campaign.CampaignID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The seeds answer to the one who cast them. Only the host may choose.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil
 	}
 
-	// Validate decisions value
-	validDecisions := map[string]bool{"host": true, "flexible": true, "group": true}
-	if !validDecisions[decisions] {
-		log.Printf("Invalid decisions value: %s", decisions)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The path you choose is unclear. Speak: host, flexible, or group.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+	modelOverride, requestedBy := debugModelOverride(messageBody)
+	birthingMessage := models.BirthingMessage{
+		CampaignID:    campaign.CampaignID,
+		ChannelID:     messageBody.ChannelID,
+		InteractionID: messageBody.InteractionID,
+		PreviewAction: action,
+		Locked:        locked,
+		ModelOverride: modelOverride,
+		RequestedBy:   requestedBy,
+	}
+	if err := sendToBirthingQueue(birthingMessage); err != nil {
+		log.Printf("Failed to send preview action to birthing queue: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil
 	}
 
-	// Create new placeholder campaign
-	log.Printf("Creating new campaign for channel %s with type %s", messageBody.ChannelID, campaignType)
-	newCampaign, err := createPlaceholderCampaign(messageBody.ChannelID, messageBody.HostID, campaignType, models.DecisionModel(decisions), stage)
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
+	}
+
+	message := "So be it. The seeds are sown."
+	if action == models.PreviewActionReroll {
+		message = "The seeds scatter. I cast them once more..."
+	}
+	if err := sendToMessagingQueue(messageBody.ChannelID, message, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Warning: failed to send preview acknowledgement: %v", err)
+	}
+
+	log.Printf("Sent preview %s for campaign %s", action, campaign.CampaignID)
+	return nil
+}
+
+// maxExportBytes is Discord's file size limit; a larger export cannot be attached
+const maxExportBytes = 25 << 20
+
+// campaignExport is the downloadable copy of a campaign's blueprint (and, on request, its memory)
+type campaignExport struct {
+	CampaignID   string              `json:"campaignId"`
+	CampaignType models.CampaignType `json:"campaignType"`
+	ExportedAt   time.Time           `json:"exportedAt"`
+	Blueprint    models.Blueprint    `json:"blueprint"`
+	Memory       *models.Memory      `json:"memory,omitempty"`
+}
+
+// exportRefusal returns the in-character reason hostID may not export the campaign, or "" if they may
+func exportRefusal(campaign *models.Campaign, hostID string) string {
+	if campaign == nil {
+		return "There are no threads here to carry away. The loom is empty, waiting."
+	}
+	if campaign.HostID != hostID {
+		return "The pattern answers to the one who wove it. Only the host may carry it away."
+	}
+	if campaign.IsArchived() {
+		return "This tale already rests in the archive, its pages bound and shelved beyond my reach."
+	}
+	if len(campaign.Blueprint.Acts) == 0 {
+		return "The pattern has not yet taken shape. Wait for the weaving to finish, then ask again."
+	}
+	return ""
+}
+
+// buildCampaignExport renders the campaign's blueprint, and its memory when asked, as indented JSON
+func buildCampaignExport(campaign *models.Campaign, includeMemory bool, now time.Time) ([]byte, error) {
+	export := campaignExport{
+		CampaignID:   campaign.CampaignID,
+		CampaignType: campaign.CampaignType,
+		ExportedAt:   now.UTC(),
+		Blueprint:    campaign.Blueprint,
+	}
+	if includeMemory {
+		export.Memory = &campaign.Memory
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// exportKey is where an export waits in the model cache bucket until messaging attaches it
+func exportKey(campaignID string, exportedAt time.Time) string {
+	return fmt.Sprintf("%s/exports/%s.json", campaignID, exportedAt.UTC().Format("20060102T150405Z"))
+}
+
+// exportFileName names the attachment after the campaign title, falling back to its ID
+func exportFileName(campaign *models.Campaign) string {
+	name := strings.Join(strings.FieldsFunc(strings.ToLower(campaign.Blueprint.Title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+	if name == "" {
+		name = campaign.CampaignID
+	}
+	return name + ".json"
+}
+
+// uploadCampaignExport writes an export to the model cache bucket, whose objects expire after a day
+func uploadCampaignExport(key string, body []byte) error {
+	bucketName := os.Getenv("SYRUS_MODEL_CACHE_BUCKET")
+	if bucketName == "" {
+		return fmt.Errorf("SYRUS_MODEL_CACHE_BUCKET environment variable not set")
+	}
+
+	sess, err := session.NewSession()
 	if err != nil {
-		log.Printf("Failed to create placeholder campaign: %v", err)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "The pattern resists. Something in the weave is wrong. I cannot begin.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	_, err = s3.New(sess).PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(bucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload campaign export: %w", err)
+	}
+	return nil
+}
+
+// handleExportCampaign handles /campaign export, sending the host the blueprint as a JSON attachment.
+// The blueprint holds every twist to come, so only the host sees it.
+func handleExportCampaign(messageBody models.ConfiguringMessage) error {
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
+	if err != nil {
+		log.Printf("Failed to get campaign: %v", err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
-		return nil // Don't retry after sending error message
+		return nil // Don't retry on infrastructure errors after sending message
 	}
 
-	// Save campaign to DynamoDB
-	if err := saveCampaign(newCampaign); err != nil {
-		log.Printf("Failed to save campaign: %v", err)
+	if refusal := exportRefusal(campaign, messageBody.HostID); refusal != "" {
+		log.Printf("Refusing export in channel %s for %s", messageBody.ChannelID, messageBody.HostID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, refusal, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
+	}
+
+	now := time.Now().UTC()
+	body, err := buildCampaignExport(campaign, subcommandOptions(messageBody)["memory"] == "true", now)
+	if err != nil {
+		log.Printf("Failed to marshal export of campaign %s: %v", campaign.CampaignID, err)
 		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
-		return nil // Don't retry after sending error message
+		return nil
+	}
+	if len(body) > maxExportBytes {
+		log.Printf("Export of campaign %s is %d bytes, over the %d byte limit", campaign.CampaignID, len(body), maxExportBytes)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The pattern has grown too vast to carry in one hand. Ask again without its memories.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
 	}
 
-	// Mark as processed in dedup table
-	if err := writeDedup(messageBody.InteractionID); err != nil {
-		log.Printf("Warning: failed to write to dedup table: %v", err)
-		// Don't fail the entire operation if dedup write fails
+	key := exportKey(campaign.CampaignID, now)
+	if err := uploadCampaignExport(key, body); err != nil {
+		log.Printf("Failed to upload export of campaign %s: %v", campaign.CampaignID, err)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+			log.Printf("Failed to send error message: %v", err)
+		}
+		return nil
 	}
 
-	// Send to birthing queue for blueprint generation
-	if err := sendToBirthingQueue(messageBody.ChannelID, messageBody.InteractionID); err != nil {
-		log.Printf("Warning: failed to send to birthing queue: %v", err)
-		// Don't fail campaign creation if birthing queue fails
+	// Write dedup
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
+		log.Printf("Warning: failed to write to dedup table: %v", err)
 	}
 
-	// Send success message to the channel
-	successMessage := `I feel the tension in the threads.
-A campaign takes form — pulled from chance, bound by choice.
-Hold steady. The weaving begins.`
-	if err := sendToMessagingQueue(messageBody.ChannelID, successMessage, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
-		log.Printf("Warning: failed to send success message: %v", err)
-		// Don't fail if success message fails - campaign was created
+	attachment := models.Attachment{Name: exportFileName(campaign), Data: key, ContentType: "application/json"}
+	if err := sendAttachmentToMessagingQueue(messageBody.ChannelID, "The pattern, drawn out whole. Keep it close; it holds every turn yet to come.", attachment, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Failed to send campaign export: %v", err)
 	}
 
-	log.Printf("Successfully created campaign for channel %s", messageBody.ChannelID)
+	log.Printf("Campaign %s exported by %s to %s", campaign.CampaignID, messageBody.HostID, key)
 	return nil
 }
 
-// handleEndCampaign handles the /campaign end subcommand
-func handleEndCampaign(messageBody models.ConfiguringMessage, stage string) error {
-	// Check if campaign exists
-	campaign, err := getCampaignByChannelID(messageBody.ChannelID)
+// handleCampaignInfo handles the /campaign info subcommand
+func handleCampaignInfo(messageBody models.ConfiguringMessage, stage string) error {
+	campaign, err := getCampaignByChannelID(messageBody.ChannelID, messageBody.Platform)
 	if err != nil {
-		log.Printf("Failed to check for existing campaign: %v", err)
+		log.Printf("Failed to get campaign: %v", err)
 		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads blur and tangle. I cannot see clearly. Try again when the pattern settles.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
 		return nil // Don't retry on infrastructure errors after sending message
 	}
 
-	if campaign == nil || isCampaignEnded(campaign) {
-		log.Printf("No active campaign found for channel %s", messageBody.ChannelID)
-		if err := sendToMessagingQueue(messageBody.ChannelID, "There are no threads here to sever. The loom is empty, waiting.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+	if campaign == nil {
+		log.Printf("No campaign found for channel %s", messageBody.ChannelID)
+		if err := sendToMessagingQueue(messageBody.ChannelID, "There are no threads here to read. The loom is empty, waiting.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
 		}
-		return nil // Successfully handled - sent error message
+		return nil
 	}
 
-	// Check if this is a confirmation
-	hasConfirm := false
-	if len(messageBody.Options) > 0 {
-		if nestedOpts, ok := messageBody.Options[0]["options"].([]interface{}); ok {
-			for _, opt := range nestedOpts {
-				if optMap, ok := opt.(map[string]interface{}); ok {
-					if name, _ := optMap["name"].(string); name == "confirm" {
-						hasConfirm = true
-						break
-					}
-				}
-			}
+	embed := buildCampaignInfoEmbed(campaign)
+	if err := sendEmbedToMessagingQueue(messageBody.ChannelID, []map[string]interface{}{embed}, 64, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Failed to send campaign info: %v", err)
+		return nil
+	}
+
+	log.Printf("Sent campaign info for campaign %s", campaign.CampaignID)
+	return nil
+}
+
+// describePlayStyle renders the play style, including the batch window for asynchronous campaigns
+func describePlayStyle(campaign *models.Campaign) string {
+	if campaign.EffectivePlayStyle() == models.PlayStyleAsynchronous {
+		minutes := int(campaign.AsyncWindowDuration().Minutes())
+		if minutes%60 == 0 {
+			return fmt.Sprintf("asynchronous (%dh window)", minutes/60)
 		}
+		return fmt.Sprintf("asynchronous (%dm window)", minutes)
 	}
+	return string(models.PlayStyleSynchronous)
+}
 
-	if hasConfirm {
-		return handleEndConfirm(messageBody, campaign, stage)
-	} else {
-		return createEndConfirmation(messageBody, campaign, stage)
+// applyCampaignMode records who a new campaign is narrated for and where it is delivered.
+// A solo campaign seats only its host and has no audience.
+func applyCampaignMode(campaign *models.Campaign, mode models.CampaignMode, platform string) {
+	campaign.Meta.Mode = mode
+	campaign.Runtime.TurnState.Mode = string(mode)
+	if platform != "" {
+		campaign.Source = platform
+	}
+	if mode == models.CampaignModeSolo {
+		campaign.Party.MaxActivePlayers = 1
+		campaign.Party.SpectatorsAllowed = false
+	}
+}
+
+// describeMode names the campaign's mode, treating unset as group
+func describeMode(campaign *models.Campaign) string {
+	if campaign.IsSolo() {
+		return string(models.CampaignModeSolo)
+	}
+	return string(models.CampaignModeGroup)
+}
+
+// buildCampaignInfoEmbed maps a campaign's configuration into a Discord embed
+func buildCampaignInfoEmbed(campaign *models.Campaign) map[string]interface{} {
+	members := ""
+	for _, member := range campaign.Party.Members {
+		members += fmt.Sprintf("<@%s> (%s)\n", member.UserID, member.Role)
+	}
+	if members == "" {
+		members = "None"
+	}
+
+	policy := campaign.ModelPolicy
+	limits := campaign.CostTracking.SoftLimits
+
+	title := campaign.Blueprint.Title
+	if title == "" {
+		title = "Campaign Settings"
+	}
+
+	return map[string]interface{}{
+		"title":       title,
+		"description": "The shape of the weave, as it was bound.",
+		"fields": []map[string]interface{}{
+			{"name": "Type", "value": string(campaign.CampaignType), "inline": true},
+			{"name": "Mode", "value": describeMode(campaign), "inline": true},
+			{"name": "Decisions", "value": string(campaign.DecisionModel), "inline": true},
+			{"name": "Play Style", "value": describePlayStyle(campaign), "inline": true},
+			{"name": "Status", "value": string(campaign.Status), "inline": true},
+			{"name": "Host", "value": fmt.Sprintf("<@%s>", campaign.HostID), "inline": true},
+			{"name": "Max Active Players", "value": strconv.Itoa(campaign.Party.MaxActivePlayers), "inline": true},
+			{"name": "Spectators Allowed", "value": strconv.FormatBool(campaign.Party.SpectatorsAllowed), "inline": true},
+			{
+				"name": "Model Policy",
+				"value": fmt.Sprintf("Intent: %s\nNarration: %s\nCinematics: %s\nBlueprint: %s\nImages: %s",
+					policy.IntentParsing, policy.Narration, policy.Cinematics, policy.Blueprint, policy.ImageGen),
+			},
+			{
+				"name": "Soft Limits",
+				"value": fmt.Sprintf("Sonnet: %d\nHaiku: %d\nImages: %d",
+					limits.SonnetCalls, limits.HaikuCalls, limits.ImageCalls),
+			},
+			{"name": "Party", "value": members},
+		},
+	}
+}
+
+// buildCostSummaryEmbed summarizes a campaign's model usage and estimated cost for the host
+func buildCostSummaryEmbed(campaign *models.Campaign) map[string]interface{} {
+	usage := campaign.CostTracking.Usage
+	limits := campaign.CostTracking.SoftLimits
+
+	return map[string]interface{}{
+		"title":       "What the Weaving Cost",
+		"description": "The loom keeps its accounts. Only you can see this.",
+		"fields": []map[string]interface{}{
+			{"name": "Sonnet Calls", "value": fmt.Sprintf("%d / %d", usage.SonnetCalls, limits.SonnetCalls), "inline": true},
+			{"name": "Haiku Calls", "value": fmt.Sprintf("%d / %d", usage.HaikuCalls, limits.HaikuCalls), "inline": true},
+			{"name": "Image Calls", "value": fmt.Sprintf("%d / %d", usage.ImageCalls, limits.ImageCalls), "inline": true},
+			{"name": "Estimated Cost", "value": fmt.Sprintf("$%.2f USD", campaign.CostTracking.EstimatedCostUSD)},
+		},
 	}
 }
 
@@ -778,12 +2150,7 @@ If you still wish to end this tale, speak /campaign end once more.`
 	}
 
 	// End the campaign
-	now := time.Now()
-	campaign.Status = models.CampaignStatusEnded
-	campaign.Lifecycle.EndedAt = &now
-	campaign.LastUpdatedAt = now
-
-	if err := saveCampaign(campaign); err != nil {
+	if err := transitionStatus(campaign, models.CampaignStatusEnded); err != nil {
 		log.Printf("Failed to save ended campaign: %v", err)
 		if err := sendToMessagingQueue(messageBody.ChannelID, "The threads slip through my grasp. I cannot hold the pattern. Try again.", messageBody.InteractionToken, messageBody.InteractionID); err != nil {
 			log.Printf("Failed to send error message: %v", err)
@@ -798,7 +2165,7 @@ If you still wish to end this tale, speak /campaign end once more.`
 	}
 
 	// Write dedup
-	if err := writeDedup(messageBody.InteractionID); err != nil {
+	if err := dedup.Mark(dedupPrefix, messageBody.InteractionID, dedup.DefaultTTL); err != nil {
 		log.Printf("Warning: failed to write to dedup table: %v", err)
 		// Don't fail the entire operation if dedup write fails
 	}
@@ -812,6 +2179,11 @@ What was woven here exists now only in memory—yours, and the echo of what once
 		// Don't fail if success message fails - campaign was ended
 	}
 
+	// Show the host what the campaign cost (ephemeral follow-up)
+	if err := sendFollowupEmbedToMessagingQueue(messageBody.ChannelID, []map[string]interface{}{buildCostSummaryEmbed(campaign)}, messageBody.InteractionToken, messageBody.InteractionID); err != nil {
+		log.Printf("Warning: failed to send cost summary: %v", err)
+	}
+
 	log.Printf("Successfully ended campaign %s", campaign.CampaignID)
 	return nil
 }
@@ -881,34 +2253,23 @@ func clearModelCache(campaignID string) error {
 	return nil
 }
 
-// handleSQSRequest handles SQS events
-func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) error {
+// handleSQSRequest handles SQS events, reporting failed messages so SQS retries only those
+func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
 	// Get stage from environment
 	stage := os.Getenv("SYRUS_STAGE")
 	if stage == "" {
 		stage = "dev"
 	}
 
-	// Process each message in the batch
-	var errors []error
-	for _, record := range sqsEvent.Records {
+	failures := sqsbatch.Process(ctx, sqsEvent.Records, sqsbatch.ConcurrencyFromEnv(), func(ctx context.Context, record events.SQSMessage) error {
 		log.Printf("Processing message: %s", record.MessageId)
+		return processSQSMessage(record, stage)
+	})
 
-		if err := processSQSMessage(record, stage); err != nil {
-			log.Printf("Error processing message %s: %v", record.MessageId, err)
-			errors = append(errors, fmt.Errorf("message %s: %w", record.MessageId, err))
-			// Continue processing other messages
-		}
-	}
-
-	// If any messages failed, return error (SQS will retry failed messages)
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to process %d message(s): %v", len(errors), errors)
-	}
-
-	return nil
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
 }
 
 func main() {
+	logging.Init("configuring")
 	lambda.Start(handleSQSRequest)
 }
@@ -2,8 +2,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	models "loros/syrus-models"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 func TestParseStartSubcommandOptions(t *testing.T) {
@@ -325,3 +332,835 @@ func TestClearModelCachePrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildCampaignInfoEmbed(t *testing.T) {
+	campaign := &models.Campaign{
+		CampaignID:    "channel-123",
+		CampaignType:  models.CampaignTypeLong,
+		DecisionModel: models.DecisionModelGroup,
+		Status:        models.CampaignStatusActive,
+		HostID:        "host-1",
+		Party: models.Party{
+			Members: []models.PartyMember{
+				{UserID: "host-1", Role: "host"},
+				{UserID: "player-2", Role: "player"},
+			},
+			SpectatorsAllowed: true,
+			MaxActivePlayers:  9,
+		},
+		Blueprint: models.Blueprint{Title: "The Drowned Crown"},
+		CostTracking: models.CostTracking{
+			SoftLimits: models.SoftLimits{SonnetCalls: 10, HaikuCalls: 1000, ImageCalls: 10},
+		},
+		ModelPolicy: models.ModelPolicy{
+			IntentParsing: models.ModelHaiku,
+			Narration:     models.ModelHaiku,
+			Cinematics:    models.ModelHaiku,
+			Blueprint:     models.ModelSonnet,
+			ImageGen:      models.ModelOpenAI,
+		},
+	}
+
+	embed := buildCampaignInfoEmbed(campaign)
+
+	if embed["title"] != "The Drowned Crown" {
+		t.Errorf("Expected title 'The Drowned Crown', got '%v'", embed["title"])
+	}
+
+	fields, ok := embed["fields"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected fields to be []map[string]interface{}, got %T", embed["fields"])
+	}
+
+	values := make(map[string]string)
+	for _, field := range fields {
+		values[field["name"].(string)] = field["value"].(string)
+	}
+
+	expected := map[string]string{
+		"Type":               "long",
+		"Mode":               "group",
+		"Decisions":          "group",
+		"Play Style":         "synchronous",
+		"Status":             "active",
+		"Host":               "<@host-1>",
+		"Max Active Players": "9",
+		"Spectators Allowed": "true",
+		"Model Policy":       "Intent: haiku\nNarration: haiku\nCinematics: haiku\nBlueprint: sonnet\nImages: openai-dalle",
+		"Soft Limits":        "Sonnet: 10\nHaiku: 1000\nImages: 10",
+		"Party":              "<@host-1> (host)\n<@player-2> (player)\n",
+	}
+
+	for name, want := range expected {
+		got, ok := values[name]
+		if !ok {
+			t.Errorf("Missing embed field %q", name)
+			continue
+		}
+		if got != want {
+			t.Errorf("Field %q: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestBuildCampaignInfoEmbedDefaults(t *testing.T) {
+	embed := buildCampaignInfoEmbed(&models.Campaign{})
+
+	if embed["title"] != "Campaign Settings" {
+		t.Errorf("Expected fallback title 'Campaign Settings', got '%v'", embed["title"])
+	}
+
+	for _, field := range embed["fields"].([]map[string]interface{}) {
+		if field["name"] == "Party" && field["value"] != "None" {
+			t.Errorf("Expected empty party to render as 'None', got '%v'", field["value"])
+		}
+	}
+}
+
+func TestWantsThread(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []map[string]interface{}
+		expected bool
+	}{
+		{
+			name: "thread true",
+			options: []map[string]interface{}{{
+				"name": "start",
+				"options": []interface{}{
+					map[string]interface{}{"name": "type", "value": "short"},
+					map[string]interface{}{"name": "thread", "value": true},
+				},
+			}},
+			expected: true,
+		},
+		{
+			name: "thread false",
+			options: []map[string]interface{}{{
+				"name":    "start",
+				"options": []interface{}{map[string]interface{}{"name": "thread", "value": false}},
+			}},
+			expected: false,
+		},
+		{
+			name: "thread omitted",
+			options: []map[string]interface{}{{
+				"name":    "start",
+				"options": []interface{}{map[string]interface{}{"name": "type", "value": "short"}},
+			}},
+			expected: false,
+		},
+		{
+			name:     "no options",
+			options:  nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wantsThread(models.ConfiguringMessage{Options: tt.options}); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildThreadReplay(t *testing.T) {
+	original := models.ConfiguringMessage{
+		ChannelID:        "channel_1",
+		HostID:           "host_1",
+		InteractionID:    "int_1",
+		InteractionToken: "token_1",
+		Options: []map[string]interface{}{{
+			"name": "start",
+			"options": []interface{}{
+				map[string]interface{}{"name": "type", "value": "short"},
+				map[string]interface{}{"name": "decisions", "value": "host"},
+				map[string]interface{}{"name": "thread", "value": true},
+			},
+		}},
+	}
+
+	replay := buildThreadReplay(original)
+
+	if replay.InteractionID != "int_1-thread" {
+		t.Errorf("Expected replay interaction ID int_1-thread, got %s", replay.InteractionID)
+	}
+	if replay.InteractionToken != "" {
+		t.Errorf("Expected replay interaction token cleared, got %s", replay.InteractionToken)
+	}
+	if replay.HostID != "host_1" || replay.ChannelID != "channel_1" {
+		t.Errorf("Expected host and channel preserved, got %+v", replay)
+	}
+	if wantsThread(replay) {
+		t.Error("Expected thread option stripped from replay")
+	}
+
+	nested := replay.Options[0]["options"].([]interface{})
+	if len(nested) != 2 {
+		t.Errorf("Expected 2 remaining start options, got %d", len(nested))
+	}
+
+	// The original message must be left intact
+	if !wantsThread(original) {
+		t.Error("Original message should still request a thread")
+	}
+
+	// Round-trips through JSON as the messaging lambda sees it
+	if _, err := json.Marshal(models.ThreadRequest{Name: "Syrus Campaign", Replay: replay}); err != nil {
+		t.Errorf("Failed to marshal thread request: %v", err)
+	}
+}
+
+func TestParsePreviewAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []map[string]interface{}
+		expected models.PreviewAction
+	}{
+		{
+			name: "confirm",
+			options: []map[string]interface{}{{
+				"name":    "preview",
+				"options": []interface{}{map[string]interface{}{"name": "action", "value": "confirm"}},
+			}},
+			expected: models.PreviewActionConfirm,
+		},
+		{
+			name: "reroll",
+			options: []map[string]interface{}{{
+				"name":    "preview",
+				"options": []interface{}{map[string]interface{}{"name": "action", "value": "reroll"}},
+			}},
+			expected: models.PreviewActionReroll,
+		},
+		{
+			name:     "missing action",
+			options:  []map[string]interface{}{{"name": "preview"}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePreviewAction(models.ConfiguringMessage{Options: tt.options}); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSubcommandOptions(t *testing.T) {
+	start := models.ConfiguringMessage{Options: []map[string]interface{}{{
+		"name": "start",
+		"type": float64(1),
+		"options": []interface{}{
+			map[string]interface{}{"name": "type", "type": float64(3), "value": "short"},
+			map[string]interface{}{"name": "window", "type": float64(4), "value": float64(30)},
+			map[string]interface{}{"name": "preview", "type": float64(5), "value": true},
+		},
+	}}}
+
+	opts := subcommandOptions(start)
+	if opts["type"] != "short" || opts["window"] != "30" || opts["preview"] != "true" {
+		t.Errorf("Expected typed start options, got %v", opts)
+	}
+
+	duplicate := models.ConfiguringMessage{Options: []map[string]interface{}{{
+		"name": "reroll",
+		"options": []interface{}{
+			map[string]interface{}{"name": "lock", "value": "map"},
+			map[string]interface{}{"name": "lock", "value": "objective"},
+		},
+	}}}
+	if opts := subcommandOptions(duplicate); len(opts) != 0 {
+		t.Errorf("Expected no options from malformed payload, got %v", opts)
+	}
+}
+
+func TestDebugModelOverride(t *testing.T) {
+	withModel := func(subcommand, model string) models.ConfiguringMessage {
+		options := []interface{}{map[string]interface{}{"name": "type", "type": float64(3), "value": "short"}}
+		if model != "" {
+			options = append(options, map[string]interface{}{"name": "model", "type": float64(3), "value": model})
+		}
+		return models.ConfiguringMessage{HostID: "host-1", Options: []map[string]interface{}{{"name": subcommand, "type": float64(1), "options": options}}}
+	}
+
+	if override, requestedBy := debugModelOverride(withModel("start", "haiku")); override != models.ModelHaiku || requestedBy != "host-1" {
+		t.Errorf("Expected haiku requested by host-1, got %q by %q", override, requestedBy)
+	}
+	if override, requestedBy := debugModelOverride(withModel("preview", "sonnet")); override != models.ModelSonnet || requestedBy != "host-1" {
+		t.Errorf("Expected sonnet requested by host-1, got %q by %q", override, requestedBy)
+	}
+	if override, requestedBy := debugModelOverride(withModel("start", "")); override != "" || requestedBy != "" {
+		t.Errorf("Expected no override without the option, got %q by %q", override, requestedBy)
+	}
+}
+
+func TestParseSeedLocks(t *testing.T) {
+	rerollOptions := func(lock string) []map[string]interface{} {
+		return []map[string]interface{}{{
+			"name":    "reroll",
+			"options": []interface{}{map[string]interface{}{"name": "lock", "value": lock}},
+		}}
+	}
+
+	t.Run("valid locks", func(t *testing.T) {
+		locked, invalid := parseSeedLocks(models.ConfiguringMessage{Options: rerollOptions(" Objective, map,antagonists ,map")})
+		expected := []models.SeedCategory{models.SeedCategoryObjective, models.SeedCategoryMap, models.SeedCategoryAntagonists}
+		if len(invalid) != 0 {
+			t.Errorf("Expected no invalid categories, got %v", invalid)
+		}
+		if len(locked) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, locked)
+		}
+		for i := range expected {
+			if locked[i] != expected[i] {
+				t.Errorf("Expected %v, got %v", expected, locked)
+			}
+		}
+	})
+
+	t.Run("unknown categories reported", func(t *testing.T) {
+		locked, invalid := parseSeedLocks(models.ConfiguringMessage{Options: rerollOptions("twists,dragons")})
+		if len(locked) != 1 || locked[0] != models.SeedCategoryTwists {
+			t.Errorf("Expected twists locked, got %v", locked)
+		}
+		if len(invalid) != 1 || invalid[0] != "dragons" {
+			t.Errorf("Expected dragons reported invalid, got %v", invalid)
+		}
+	})
+
+	t.Run("no lock option", func(t *testing.T) {
+		locked, invalid := parseSeedLocks(models.ConfiguringMessage{Options: []map[string]interface{}{{"name": "reroll"}}})
+		if len(locked) != 0 || len(invalid) != 0 {
+			t.Errorf("Expected nothing locked, got %v / %v", locked, invalid)
+		}
+	})
+}
+
+func TestBuildCostSummaryEmbed(t *testing.T) {
+	campaign := &models.Campaign{
+		CostTracking: models.CostTracking{
+			SoftLimits:       models.SoftLimits{SonnetCalls: 10, HaikuCalls: 200, ImageCalls: 5},
+			Usage:            models.Usage{SonnetCalls: 7, HaikuCalls: 143, ImageCalls: 4},
+			EstimatedCostUSD: 1.2345,
+		},
+	}
+
+	embed := buildCostSummaryEmbed(campaign)
+	fields := embed["fields"].([]map[string]interface{})
+
+	expected := map[string]string{
+		"Sonnet Calls":   "7 / 10",
+		"Haiku Calls":    "143 / 200",
+		"Image Calls":    "4 / 5",
+		"Estimated Cost": "$1.23 USD",
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d", len(expected), len(fields))
+	}
+	for _, field := range fields {
+		name := field["name"].(string)
+		if want, ok := expected[name]; !ok || field["value"] != want {
+			t.Errorf("Field %s: expected %q, got %v", name, want, field["value"])
+		}
+	}
+}
+
+func TestResolvePlayStyle(t *testing.T) {
+	tests := []struct {
+		name           string
+		style          string
+		window         int
+		expectedStyle  models.PlayStyle
+		expectedWindow int
+		expectProblem  bool
+	}{
+		{"default is synchronous", "", 0, models.PlayStyleSynchronous, 0, false},
+		{"explicit synchronous", "synchronous", 0, models.PlayStyleSynchronous, 0, false},
+		{"window requires asynchronous", "synchronous", 60, "", 0, true},
+		{"asynchronous default window", "asynchronous", 0, models.PlayStyleAsynchronous, models.DefaultAsyncWindowMinutes, false},
+		{"asynchronous custom window", "asynchronous", 90, models.PlayStyleAsynchronous, 90, false},
+		{"window too short", "asynchronous", 1, "", 0, true},
+		{"window too long", "asynchronous", maxAsyncWindowMinutes + 1, "", 0, true},
+		{"unknown style", "turnbased", 0, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style, window, problem := resolvePlayStyle(tt.style, tt.window)
+			if (problem != "") != tt.expectProblem {
+				t.Fatalf("Expected problem=%v, got %q", tt.expectProblem, problem)
+			}
+			if style != tt.expectedStyle || window != tt.expectedWindow {
+				t.Errorf("Expected %s/%d, got %s/%d", tt.expectedStyle, tt.expectedWindow, style, window)
+			}
+		})
+	}
+}
+
+func TestDescribePlayStyle(t *testing.T) {
+	campaign := &models.Campaign{PlayStyle: models.PlayStyleAsynchronous, AsyncWindow: 90}
+	if got := describePlayStyle(campaign); got != "asynchronous (90m window)" {
+		t.Errorf("Expected 'asynchronous (90m window)', got %q", got)
+	}
+	campaign.AsyncWindow = 0
+	if got := describePlayStyle(campaign); got != "asynchronous (4h window)" {
+		t.Errorf("Expected 'asynchronous (4h window)', got %q", got)
+	}
+}
+
+func TestValidateNudgeInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		style         models.PlayStyle
+		hours         int
+		expectProblem bool
+	}{
+		{"default interval", models.PlayStyleAsynchronous, 0, false},
+		{"default with synchronous", models.PlayStyleSynchronous, 0, false},
+		{"custom interval", models.PlayStyleAsynchronous, 12, false},
+		{"synchronous campaigns are not nudged", models.PlayStyleSynchronous, 12, true},
+		{"interval too long", models.PlayStyleAsynchronous, maxNudgeIntervalHours + 1, true},
+		{"negative interval", models.PlayStyleAsynchronous, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if problem := validateNudgeInterval(tt.style, tt.hours); (problem != "") != tt.expectProblem {
+				t.Errorf("Expected problem=%v, got %q", tt.expectProblem, problem)
+			}
+		})
+	}
+}
+
+func TestValidateModelPolicy(t *testing.T) {
+	valid := models.ModelPolicy{
+		IntentParsing: models.ModelHaiku,
+		Narration:     models.ModelHaiku,
+		Cinematics:    models.ModelSonnet,
+		Blueprint:     models.ModelSonnet,
+		ImageGen:      models.ModelNanoBanana,
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(*models.ModelPolicy)
+		expectErr string
+	}{
+		{"valid policy", func(*models.ModelPolicy) {}, ""},
+		{"image model for blueprint", func(p *models.ModelPolicy) { p.Blueprint = models.ModelOpenAI }, "cannot be used"},
+		{"text model for images", func(p *models.ModelPolicy) { p.ImageGen = models.ModelSonnet }, "cannot be used"},
+		{"unknown narration model", func(p *models.ModelPolicy) { p.Narration = "gpt-2" }, "unknown model"},
+		{"missing intent model", func(p *models.ModelPolicy) { p.IntentParsing = "" }, "unknown model"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := valid
+			tt.mutate(&policy)
+			err := validateModelPolicy(policy)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCreatePlaceholderCampaignPolicyIsValid(t *testing.T) {
+	campaign, err := createPlaceholderCampaign("channel", "", "host", models.CampaignTypeShort, models.DecisionModelHost, models.PlayStyleSynchronous, 0, "dev")
+	if err != nil {
+		t.Fatalf("Expected default campaign to be created, got %v", err)
+	}
+	if err := validateModelPolicy(campaign.ModelPolicy); err != nil {
+		t.Errorf("Expected default model policy to be valid, got %v", err)
+	}
+}
+
+func TestCreatePlaceholderCampaignImageQuality(t *testing.T) {
+	tests := []struct {
+		campaignType models.CampaignType
+		expected     string
+	}{
+		{models.CampaignTypeShort, models.ImageQualityStandard},
+		{models.CampaignTypeLong, models.ImageQualityStandard},
+		{models.CampaignTypeEpic, models.ImageQualityHD},
+	}
+
+	for _, tt := range tests {
+		campaign, err := createPlaceholderCampaign("channel", "", "host", tt.campaignType, models.DecisionModelHost, models.PlayStyleSynchronous, 0, "dev")
+		if err != nil {
+			t.Fatalf("Expected %s campaign to be created, got %v", tt.campaignType, err)
+		}
+		if got := campaign.ModelPolicy.ImageOptions().Quality; got != tt.expected {
+			t.Errorf("Expected %s campaigns to request %s images, got %s", tt.campaignType, tt.expected, got)
+		}
+	}
+}
+
+func TestCreatePlaceholderCampaignGeneratesID(t *testing.T) {
+	campaign, err := createPlaceholderCampaign("channel", "", "host", models.CampaignTypeShort, models.DecisionModelHost, models.PlayStyleSynchronous, 0, "dev")
+	if err != nil {
+		t.Fatalf("Expected campaign to be created, got %v", err)
+	}
+	if campaign.CampaignID == "" || campaign.CampaignID == "channel" {
+		t.Errorf("Expected a generated campaign ID, got %q", campaign.CampaignID)
+	}
+	if campaign.ChannelID != "channel" || campaign.Meta.ChannelID != "channel" {
+		t.Errorf("Expected channel recorded for the ByChannel index, got %q / %q", campaign.ChannelID, campaign.Meta.ChannelID)
+	}
+
+	other, err := createPlaceholderCampaign("channel", "", "host", models.CampaignTypeShort, models.DecisionModelHost, models.PlayStyleSynchronous, 0, "dev")
+	if err != nil {
+		t.Fatalf("Expected campaign to be created, got %v", err)
+	}
+	if other.CampaignID == campaign.CampaignID {
+		t.Errorf("Expected campaigns in the same channel to get distinct IDs, both got %q", campaign.CampaignID)
+	}
+}
+
+func TestApplyCampaignMode(t *testing.T) {
+	campaign, err := createPlaceholderCampaign("channel", "", "host", models.CampaignTypeShort, models.DecisionModelHost, models.PlayStyleSynchronous, 0, "dev")
+	if err != nil {
+		t.Fatalf("Expected campaign to be created, got %v", err)
+	}
+	applyCampaignMode(campaign, models.CampaignModeGroup, "")
+	if campaign.Meta.Mode != models.CampaignModeGroup || campaign.Source != models.PlatformDiscord || campaign.Party.MaxActivePlayers != 9 {
+		t.Errorf("Expected a group Discord campaign for 9 players, got mode %q source %q max %d", campaign.Meta.Mode, campaign.Source, campaign.Party.MaxActivePlayers)
+	}
+
+	applyCampaignMode(campaign, models.CampaignModeSolo, models.PlatformWhatsApp)
+	if campaign.Meta.Mode != models.CampaignModeSolo || campaign.Runtime.TurnState.Mode != "solo" {
+		t.Errorf("Expected solo mode recorded, got %q / %q", campaign.Meta.Mode, campaign.Runtime.TurnState.Mode)
+	}
+	if campaign.Source != models.PlatformWhatsApp {
+		t.Errorf("Expected WhatsApp source, got %q", campaign.Source)
+	}
+	if campaign.Party.MaxActivePlayers != 1 || campaign.Party.SpectatorsAllowed {
+		t.Errorf("Expected a solo party of one without spectators, got max %d spectators %v", campaign.Party.MaxActivePlayers, campaign.Party.SpectatorsAllowed)
+	}
+	if got := describeMode(campaign); got != "solo" {
+		t.Errorf("Expected mode described as solo, got %q", got)
+	}
+}
+
+func TestLatestCampaignRef(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	refs := []channelCampaignRef{
+		{CampaignID: "first", CreatedAt: base},
+		{CampaignID: "latest", CreatedAt: base.Add(48 * time.Hour)},
+		{CampaignID: "middle", CreatedAt: base.Add(time.Hour)},
+	}
+	if got := latestCampaignRef(refs); got != "latest" {
+		t.Errorf("Expected latest campaign, got %q", got)
+	}
+	if got := latestCampaignRef(nil); got != "" {
+		t.Errorf("Expected no campaign for an empty channel, got %q", got)
+	}
+}
+
+func TestBuildCampaignPatch(t *testing.T) {
+	now := &dynamodb.AttributeValue{S: aws.String("2024-01-01T00:00:00Z")}
+	fields := map[string]*dynamodb.AttributeValue{
+		"tone":     {S: aws.String("grim")},
+		"status":   {S: aws.String("active")},
+		"settings": {M: map[string]*dynamodb.AttributeValue{"locale": {S: aws.String("en-US")}}},
+	}
+
+	expr, names, values, err := buildCampaignPatch(fields, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedExpr := "SET #f0 = :v0, #f1 = :v1, #f2 = :v2, #lastUpdatedAt = :lastUpdatedAt"
+	if expr != expectedExpr {
+		t.Errorf("Expected expression %q, got %q", expectedExpr, expr)
+	}
+
+	expectedNames := map[string]string{"#f0": "settings", "#f1": "status", "#f2": "tone", "#lastUpdatedAt": "lastUpdatedAt"}
+	if len(names) != len(expectedNames) {
+		t.Fatalf("Expected %d attribute names, got %d", len(expectedNames), len(names))
+	}
+	for placeholder, name := range expectedNames {
+		if names[placeholder] == nil || *names[placeholder] != name {
+			t.Errorf("Expected %s to name %q", placeholder, name)
+		}
+	}
+
+	if len(values) != 4 {
+		t.Fatalf("Expected 4 attribute values, got %d", len(values))
+	}
+	if values[":v2"] != fields["tone"] || values[":lastUpdatedAt"] != now {
+		t.Error("Expected values to be bound to their placeholders")
+	}
+}
+
+func TestBuildCampaignPatchRejectsUnsafeFields(t *testing.T) {
+	now := &dynamodb.AttributeValue{S: aws.String("2024-01-01T00:00:00Z")}
+	value := &dynamodb.AttributeValue{S: aws.String("x")}
+
+	tests := []struct {
+		name   string
+		fields map[string]*dynamodb.AttributeValue
+	}{
+		{"empty patch", map[string]*dynamodb.AttributeValue{}},
+		{"nested path", map[string]*dynamodb.AttributeValue{"settings.locale": value}},
+		{"list index", map[string]*dynamodb.AttributeValue{"acts[0]": value}},
+		{"placeholder sigil", map[string]*dynamodb.AttributeValue{"#tone": value}},
+		{"expression injection", map[string]*dynamodb.AttributeValue{"tone = :x, status": value}},
+		{"partition key", map[string]*dynamodb.AttributeValue{"campaignId": value}},
+		{"managed timestamp", map[string]*dynamodb.AttributeValue{"lastUpdatedAt": value}},
+		{"nil value", map[string]*dynamodb.AttributeValue{"tone": nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := buildCampaignPatch(tt.fields, now); err == nil {
+				t.Error("Expected error, got none")
+			}
+		})
+	}
+}
+
+func TestPauseRefusal(t *testing.T) {
+	ended := time.Now()
+	tests := []struct {
+		name     string
+		campaign *models.Campaign
+		pause    bool
+		refused  bool
+	}{
+		{"no campaign", nil, true, true},
+		{"pause active", &models.Campaign{Status: models.CampaignStatusActive}, true, false},
+		{"pause playing", &models.Campaign{Status: models.CampaignStatusPlaying}, true, false},
+		{"pause configuring", &models.Campaign{Status: models.CampaignStatusConfiguring}, true, true},
+		{"pause ended", &models.Campaign{Status: models.CampaignStatusPlaying, Lifecycle: models.Lifecycle{EndedAt: &ended}}, true, true},
+		{"pause already paused", &models.Campaign{Status: models.CampaignStatusPlaying, Lifecycle: models.Lifecycle{Paused: true}}, true, true},
+		{"resume paused", &models.Campaign{Status: models.CampaignStatusPlaying, Lifecycle: models.Lifecycle{Paused: true}}, false, false},
+		{"resume running", &models.Campaign{Status: models.CampaignStatusPlaying}, false, true},
+		{"resume ended", &models.Campaign{Status: models.CampaignStatusEnded, Lifecycle: models.Lifecycle{Paused: true}}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refusal := pauseRefusal(tt.campaign, tt.pause)
+			if (refusal != "") != tt.refused {
+				t.Errorf("Expected refused=%t, got %q", tt.refused, refusal)
+			}
+		})
+	}
+}
+
+func TestCampaignChannelMismatch(t *testing.T) {
+	campaign := &models.Campaign{CampaignID: "thread-1", Meta: models.CampaignMeta{ChannelID: "thread-1", ParentChannelID: "chan-1"}}
+
+	if campaignChannelMismatch(campaign, "thread-1") {
+		t.Error("Expected no mismatch when the interaction comes from the campaign's thread")
+	}
+	if !campaignChannelMismatch(campaign, "chan-1") {
+		t.Error("Expected a mismatch when the interaction comes from the parent channel")
+	}
+	if campaignChannelMismatch(&models.Campaign{CampaignID: "legacy"}, "legacy") {
+		t.Error("Expected no mismatch for campaigns without a stored channel")
+	}
+}
+
+func TestCheckGuildCampaignCap(t *testing.T) {
+	original := countGuildCampaigns
+	defer func() { countGuildCampaigns = original }()
+
+	counted := 0
+	countGuildCampaigns = func(guildID string) (int, error) {
+		counted++
+		return 3, nil
+	}
+
+	tests := []struct {
+		name          string
+		guildID       string
+		admin         bool
+		limit         int
+		expectRefusal bool
+		expectCount   bool
+	}{
+		{"under cap", "guild-1", false, 4, false, true},
+		{"at cap", "guild-1", false, 3, true, true},
+		{"admin overrides cap", "guild-1", true, 3, false, false},
+		{"no cap configured", "guild-1", false, 0, false, false},
+		{"direct message has no guild", "", false, 3, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counted = 0
+			refusal, err := checkGuildCampaignCap(tt.guildID, tt.admin, tt.limit)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (refusal != "") != tt.expectRefusal {
+				t.Errorf("Expected refusal=%v, got %q", tt.expectRefusal, refusal)
+			}
+			if (counted > 0) != tt.expectCount {
+				t.Errorf("Expected count query=%v, counted %d times", tt.expectCount, counted)
+			}
+		})
+	}
+
+	t.Run("count failure is reported", func(t *testing.T) {
+		countGuildCampaigns = func(guildID string) (int, error) { return 0, errors.New("index unavailable") }
+		if _, err := checkGuildCampaignCap("guild-1", false, 3); err == nil {
+			t.Error("Expected the count error to be returned")
+		}
+	})
+}
+
+func TestArchiveRefusal(t *testing.T) {
+	ended := time.Now()
+	tests := []struct {
+		name     string
+		campaign *models.Campaign
+		refused  bool
+	}{
+		{"no campaign", nil, true},
+		{"playing", &models.Campaign{Status: models.CampaignStatusPlaying}, true},
+		{"ended", &models.Campaign{Status: models.CampaignStatusEnded}, false},
+		{"ended by lifecycle", &models.Campaign{Status: models.CampaignStatusPlaying, Lifecycle: models.Lifecycle{EndedAt: &ended}}, false},
+		{"already archived", &models.Campaign{Status: models.CampaignStatusEnded, Lifecycle: models.Lifecycle{ArchivedAt: &ended}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refusal := archiveRefusal(tt.campaign)
+			if (refusal != "") != tt.refused {
+				t.Errorf("Expected refused=%t, got %q", tt.refused, refusal)
+			}
+		})
+	}
+}
+
+func TestExportRefusal(t *testing.T) {
+	archived := time.Now()
+	woven := models.Blueprint{Title: "The Drowned Bell", Acts: []models.Act{{ActNumber: 1}}}
+	tests := []struct {
+		name     string
+		campaign *models.Campaign
+		hostID   string
+		refused  bool
+	}{
+		{"no campaign", nil, "host-1", true},
+		{"host", &models.Campaign{HostID: "host-1", Blueprint: woven}, "host-1", false},
+		{"not the host", &models.Campaign{HostID: "host-1", Blueprint: woven}, "player-2", true},
+		{"still weaving", &models.Campaign{HostID: "host-1"}, "host-1", true},
+		{"archived", &models.Campaign{HostID: "host-1", Blueprint: woven, Lifecycle: models.Lifecycle{ArchivedAt: &archived}}, "host-1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refusal := exportRefusal(tt.campaign, tt.hostID)
+			if (refusal != "") != tt.refused {
+				t.Errorf("Expected refused=%t, got %q", tt.refused, refusal)
+			}
+		})
+	}
+}
+
+func TestBuildCampaignExport(t *testing.T) {
+	now := time.Date(2026, 3, 7, 14, 5, 9, 0, time.UTC)
+	campaign := &models.Campaign{
+		CampaignID:   "campaign-1",
+		CampaignType: models.CampaignTypeShort,
+		Blueprint:    models.Blueprint{Title: "The Drowned Bell: Part II", Premise: "A bell tolls beneath the harbor"},
+		Memory:       models.Memory{Global: models.GlobalMemory{CanonicalFacts: map[string]interface{}{"Keeper Orla": "dead"}}},
+	}
+
+	for _, includeMemory := range []bool{false, true} {
+		body, err := buildCampaignExport(campaign, includeMemory, now)
+		if err != nil {
+			t.Fatalf("Expected export to marshal, got %v", err)
+		}
+		var export campaignExport
+		if err := json.Unmarshal(body, &export); err != nil {
+			t.Fatalf("Expected export to be valid JSON, got %v", err)
+		}
+		if export.CampaignID != "campaign-1" || export.Blueprint.Premise != "A bell tolls beneath the harbor" || !export.ExportedAt.Equal(now) {
+			t.Errorf("Unexpected export: %+v", export)
+		}
+		if (export.Memory != nil) != includeMemory {
+			t.Errorf("Expected memory included=%t, got %+v", includeMemory, export.Memory)
+		}
+		if !strings.Contains(string(body), "\n  \"blueprint\"") {
+			t.Errorf("Expected indented JSON, got %s", body)
+		}
+	}
+
+	if got := exportKey("campaign-1", now); got != "campaign-1/exports/20260307T140509Z.json" {
+		t.Errorf("Unexpected export key %q", got)
+	}
+	if got := exportFileName(campaign); got != "the-drowned-bell-part-ii.json" {
+		t.Errorf("Unexpected export file name %q", got)
+	}
+	if got := exportFileName(&models.Campaign{CampaignID: "campaign-1"}); got != "campaign-1.json" {
+		t.Errorf("Expected untitled exports named by campaign ID, got %q", got)
+	}
+}
+
+func TestBuildPauseUpdate(t *testing.T) {
+	input, err := buildPauseUpdate("campaigns", "campaign-1", true, time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildPauseUpdate failed: %v", err)
+	}
+	if got := *input.UpdateExpression; got != "SET #lifecycle.#paused = :paused, #lastUpdatedAt = :lastUpdatedAt" {
+		t.Errorf("Expected only lifecycle.paused written, got %q", got)
+	}
+	if got := input.ExpressionAttributeValues[":paused"].BOOL; got == nil || !*got {
+		t.Errorf("Expected paused set to true, got %v", input.ExpressionAttributeValues[":paused"])
+	}
+	if got := *input.Key["campaignId"].S; got != "campaign-1" {
+		t.Errorf("Expected campaign-1 updated, got %q", got)
+	}
+}
+
+func TestBuildArchiveUpdate(t *testing.T) {
+	archivedAt := time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC)
+	campaign := &models.Campaign{
+		CampaignID: "channel-1",
+		Status:     models.CampaignStatusEnded,
+		Blueprint:  models.Blueprint{Title: "The Drowned Bell", Premise: "A bell tolls beneath the harbor", ThematicPillars: []string{"grief"}},
+	}
+
+	key := archiveKey(campaign.CampaignID, archivedAt)
+	if key != "campaigns/channel-1/20260314T092653Z.json" {
+		t.Errorf("Unexpected archive key %q", key)
+	}
+
+	input, err := buildArchiveUpdate("campaigns", campaign, archivedAt, key)
+	if err != nil {
+		t.Fatalf("buildArchiveUpdate failed: %v", err)
+	}
+	if !strings.Contains(*input.UpdateExpression, "REMOVE #memory") {
+		t.Errorf("Expected memory removed from the table, got %q", *input.UpdateExpression)
+	}
+	if !strings.Contains(*input.ConditionExpression, "attribute_not_exists(#lifecycle.#archivedAt)") {
+		t.Errorf("Expected the write guarded against a second archive, got %q", *input.ConditionExpression)
+	}
+	if got := *input.ExpressionAttributeValues[":archiveKey"].S; got != key {
+		t.Errorf("Expected the archive key recorded, got %q", got)
+	}
+
+	var stub map[string]interface{}
+	if err := dynamodbattribute.Unmarshal(input.ExpressionAttributeValues[":blueprint"], &stub); err != nil {
+		t.Fatalf("Failed to unmarshal blueprint stub: %v", err)
+	}
+	if len(stub) != 2 || stub["title"] != "The Drowned Bell" || stub["premise"] != "A bell tolls beneath the harbor" {
+		t.Errorf("Expected only the title and premise kept in the table, got %v", stub)
+	}
+}
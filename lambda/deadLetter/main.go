@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	logging "loros/syrus-logging"
+	models "loros/syrus-models"
+	sqsbatch "loros/syrus-sqsbatch"
+)
+
+var (
+	awsSession     *session.Session
+	dynamodbClient *dynamodb.DynamoDB
+	sqsClient      *sqs.SQS
+	campaignsTable string
+	messagingQueue string
+	sqsConcurrency int
+)
+
+func init() {
+	awsSession = session.Must(session.NewSession())
+	dynamodbClient = dynamodb.New(awsSession)
+	sqsClient = sqs.New(awsSession)
+
+	campaignsTable = os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	messagingQueue = os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	sqsConcurrency = sqsbatch.ConcurrencyFromEnv()
+}
+
+// frayedMessage is posted when a request exhausted its retries and will never be answered
+const frayedMessage = "*The weave has frayed beyond repair.* What you asked of me was lost in the threads. Speak your command again, and if the silence persists, let your host know."
+
+// interactionTokenLifetime is how long Discord accepts an interaction token. Past it, the deferred
+// response can no longer be edited, so the notice goes to the channel instead.
+const interactionTokenLifetime = 15 * time.Minute
+
+// deadLetter holds the fields the dead-lettered message shapes share. A PlayRequest carries the
+// Discord interaction, a ConfiguringMessage its channel and token, and a BlueprintMessage or
+// BirthingMessage only the campaign (birthing may also name its channel).
+type deadLetter struct {
+	CampaignID        string           `json:"campaignId"`
+	ChannelID         string           `json:"channelId"`
+	InteractionID     string           `json:"interactionId"`
+	InteractionToken  string           `json:"interactionToken"`
+	InteractionObject *deadInteraction `json:"interactionObject"`
+}
+
+// deadInteraction is the part of a PlayRequest's Discord interaction needed to reply to it
+type deadInteraction struct {
+	ChannelID string `json:"channel_id"`
+	Token     string `json:"token"`
+}
+
+// notice is where and how the failure notice is delivered
+type notice struct {
+	ChannelID        string
+	InteractionToken string
+	InteractionID    string
+}
+
+// parseDeadLetter decodes a dead-lettered message body of any of the queued shapes
+func parseDeadLetter(body string) (deadLetter, error) {
+	var letter deadLetter
+	if err := json.Unmarshal([]byte(body), &letter); err != nil {
+		return deadLetter{}, fmt.Errorf("failed to parse dead letter: %w", err)
+	}
+	return letter, nil
+}
+
+// interactionToken returns the token that can edit the original deferred response, if any
+func (l deadLetter) interactionToken() string {
+	if l.InteractionObject != nil && l.InteractionObject.Token != "" {
+		return l.InteractionObject.Token
+	}
+	return l.InteractionToken
+}
+
+// replyChannel returns the channel named by the message itself, preferring the interaction's own
+// channel; "" means only the campaign knows where to reply
+func (l deadLetter) replyChannel() string {
+	if l.InteractionObject != nil && l.InteractionObject.ChannelID != "" {
+		return l.InteractionObject.ChannelID
+	}
+	return l.ChannelID
+}
+
+// tokenUsable reports whether an interaction token from a message first sent at sentAt is still accepted
+func tokenUsable(sentAt, now time.Time) bool {
+	return !sentAt.IsZero() && now.Sub(sentAt) < interactionTokenLifetime
+}
+
+// sentAt reads the time a record was first sent; it survives the move to the dead-letter queue
+func sentAt(record events.SQSMessage) time.Time {
+	millis, err := strconv.ParseInt(record.Attributes["SentTimestamp"], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(millis)
+}
+
+// buildNotice decides where the failure notice goes. lookupChannel resolves a campaign's channel
+// for message shapes that carry only the campaign ID. ok is false when there is nowhere to reply.
+func buildNotice(letter deadLetter, sent, now time.Time, lookupChannel func(campaignID string) (string, error)) (notice, bool, error) {
+	n := notice{ChannelID: letter.replyChannel(), InteractionID: letter.InteractionID}
+	if tokenUsable(sent, now) {
+		n.InteractionToken = letter.interactionToken()
+	}
+
+	if n.ChannelID == "" && letter.CampaignID != "" {
+		channelID, err := lookupChannel(letter.CampaignID)
+		if err != nil {
+			return notice{}, false, err
+		}
+		n.ChannelID = channelID
+	}
+	return n, n.ChannelID != "", nil
+}
+
+// lookupCampaignChannel is swappable in tests
+var lookupCampaignChannel = getCampaignChannel
+
+// getCampaignChannel returns the channel a campaign posts to, or "" if the campaign is gone
+func getCampaignChannel(campaignID string) (string, error) {
+	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		ProjectionExpression: aws.String("campaignId, meta"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get campaign %s: %w", campaignID, err)
+	}
+	if result.Item == nil {
+		return "", nil
+	}
+
+	var campaign models.Campaign
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &campaign); err != nil {
+		return "", fmt.Errorf("failed to unmarshal campaign %s: %w", campaignID, err)
+	}
+	return campaign.ReplyChannelID(), nil
+}
+
+// sendNotice is swappable in tests
+var sendNotice = sendToMessagingQueue
+
+// sendToMessagingQueue queues the failure notice for delivery
+func sendToMessagingQueue(n notice, deduplicationID string) error {
+	message := models.MessagingQueueMessage{
+		ChannelID:        n.ChannelID,
+		Content:          frayedMessage,
+		InteractionToken: n.InteractionToken,
+		InteractionID:    n.InteractionID,
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	// A failure notice stands alone, so it need not wait behind the channel's stuck messages
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(messagingQueue),
+		MessageBody:            aws.String(string(body)),
+		MessageGroupId:         aws.String(models.MessageGroupID(n.ChannelID, deduplicationID, models.MessageUnordered)),
+		MessageDeduplicationId: aws.String(deduplicationID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to queue: %w", err)
+	}
+	return nil
+}
+
+// processDeadLetter tells the channel a request was lost. Messages that cannot be parsed or name no
+// channel are logged and dropped; retrying them would only fail again.
+func processDeadLetter(record events.SQSMessage, now time.Time) error {
+	letter, err := parseDeadLetter(record.Body)
+	if err != nil {
+		log.Printf("Warning: dropping unreadable dead letter %s: %v (body: %s)", record.MessageId, err, record.Body)
+		return nil
+	}
+	logger := logging.For(letter.InteractionID, letter.CampaignID)
+	// The body is logged in full: consuming the dead letter removes it from the queue
+	logger.Printf("Dead letter %s from %s: %s", record.MessageId, record.EventSourceARN, record.Body)
+
+	n, ok, err := buildNotice(letter, sentAt(record), now, lookupCampaignChannel)
+	if err != nil {
+		return fmt.Errorf("failed to resolve reply channel: %w", err)
+	}
+	if !ok {
+		logger.Printf("Warning: dead letter %s names no channel, not notifying", record.MessageId)
+		return nil
+	}
+
+	deduplicationID := letter.InteractionID + "-frayed"
+	if letter.InteractionID == "" {
+		deduplicationID = record.MessageId + "-frayed"
+	}
+	if err := sendNotice(n, deduplicationID); err != nil {
+		return err
+	}
+	logger.Printf("Notified channel %s of dead letter %s", n.ChannelID, record.MessageId)
+	return nil
+}
+
+// handler consumes the dead-letter queues of the user-facing pipelines
+func handler(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	now := time.Now()
+	failures := sqsbatch.Process(ctx, sqsEvent.Records, sqsConcurrency, func(ctx context.Context, record events.SQSMessage) error {
+		return processDeadLetter(record, now)
+	})
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
+}
+
+func main() {
+	logging.Init("deadLetter")
+	lambda.Start(handler)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestParseDeadLetterShapes(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectChannel string
+		expectToken   string
+		expectCamp    string
+	}{
+		{
+			name:          "play request",
+			body:          `{"campaignId":"campaign-1","channelId":"thread-1","interactionId":"int-1","interactionObject":{"id":"int-1","channel_id":"thread-1","token":"tok-1"}}`,
+			expectChannel: "thread-1",
+			expectToken:   "tok-1",
+			expectCamp:    "campaign-1",
+		},
+		{
+			name:          "legacy play request",
+			body:          `{"campaignId":"chan-1","interactionId":"int-1","interactionObject":{"channel_id":"chan-1","token":"tok-1"}}`,
+			expectChannel: "chan-1",
+			expectToken:   "tok-1",
+			expectCamp:    "chan-1",
+		},
+		{
+			name:          "configuring message",
+			body:          `{"channelId":"chan-2","hostId":"host-1","interactionId":"int-2","interactionToken":"tok-2","options":[]}`,
+			expectChannel: "chan-2",
+			expectToken:   "tok-2",
+		},
+		{
+			name:       "blueprint message",
+			body:       `{"campaignId":"campaign-3","interactionId":"int-3","seeds":{}}`,
+			expectCamp: "campaign-3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			letter, err := parseDeadLetter(tt.body)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := letter.replyChannel(); got != tt.expectChannel {
+				t.Errorf("Expected channel %q, got %q", tt.expectChannel, got)
+			}
+			if got := letter.interactionToken(); got != tt.expectToken {
+				t.Errorf("Expected token %q, got %q", tt.expectToken, got)
+			}
+			if letter.CampaignID != tt.expectCamp {
+				t.Errorf("Expected campaign %q, got %q", tt.expectCamp, letter.CampaignID)
+			}
+		})
+	}
+
+	if _, err := parseDeadLetter("not json"); err == nil {
+		t.Error("Expected an error for an unreadable body")
+	}
+}
+
+func TestBuildNotice(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lookup := func(campaignID string) (string, error) {
+		if campaignID == "campaign-3" {
+			return "chan-3", nil
+		}
+		return "", nil
+	}
+
+	play, _ := parseDeadLetter(`{"campaignId":"campaign-1","interactionId":"int-1","interactionObject":{"channel_id":"thread-1","token":"tok-1"}}`)
+
+	n, ok, err := buildNotice(play, now.Add(-5*time.Minute), now, lookup)
+	if err != nil || !ok {
+		t.Fatalf("Expected a notice, got ok=%v err=%v", ok, err)
+	}
+	if n.ChannelID != "thread-1" || n.InteractionToken != "tok-1" || n.InteractionID != "int-1" {
+		t.Errorf("Expected a fresh request to edit its deferred response, got %+v", n)
+	}
+
+	n, _, _ = buildNotice(play, now.Add(-2*time.Hour), now, lookup)
+	if n.InteractionToken != "" {
+		t.Errorf("Expected an expired token to be dropped, got %q", n.InteractionToken)
+	}
+	if n.ChannelID != "thread-1" {
+		t.Errorf("Expected the notice to fall back to the channel, got %q", n.ChannelID)
+	}
+
+	blueprint, _ := parseDeadLetter(`{"campaignId":"campaign-3","interactionId":"int-3"}`)
+	n, ok, err = buildNotice(blueprint, now, now, lookup)
+	if err != nil || !ok || n.ChannelID != "chan-3" {
+		t.Errorf("Expected the campaign's channel chan-3, got %+v ok=%v err=%v", n, ok, err)
+	}
+
+	gone, _ := parseDeadLetter(`{"campaignId":"campaign-gone","interactionId":"int-4"}`)
+	if _, ok, _ := buildNotice(gone, now, now, lookup); ok {
+		t.Error("Expected no notice when the campaign no longer exists")
+	}
+
+	failing := func(string) (string, error) { return "", errors.New("throttled") }
+	if _, _, err := buildNotice(blueprint, now, now, failing); err == nil {
+		t.Error("Expected a lookup failure to be returned so the record is retried")
+	}
+}
+
+func TestProcessDeadLetter(t *testing.T) {
+	originalSend, originalLookup := sendNotice, lookupCampaignChannel
+	t.Cleanup(func() { sendNotice, lookupCampaignChannel = originalSend, originalLookup })
+
+	var sent []notice
+	var dedupIDs []string
+	sendNotice = func(n notice, deduplicationID string) error {
+		sent = append(sent, n)
+		dedupIDs = append(dedupIDs, deduplicationID)
+		return nil
+	}
+	lookupCampaignChannel = func(string) (string, error) { return "", nil }
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	record := func(body string) events.SQSMessage {
+		return events.SQSMessage{
+			MessageId:  "msg-1",
+			Body:       body,
+			Attributes: map[string]string{"SentTimestamp": strconv.FormatInt(now.Add(-time.Minute).UnixMilli(), 10)},
+		}
+	}
+
+	if err := processDeadLetter(record(`{"channelId":"chan-2","interactionId":"int-2","interactionToken":"tok-2"}`), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sent) != 1 || sent[0].ChannelID != "chan-2" || sent[0].InteractionToken != "tok-2" {
+		t.Fatalf("Expected one notice to chan-2 editing the deferred response, got %+v", sent)
+	}
+	if dedupIDs[0] != "int-2-frayed" {
+		t.Errorf("Expected dedup ID int-2-frayed, got %s", dedupIDs[0])
+	}
+
+	// Unreadable and channel-less letters are dropped rather than retried
+	for _, body := range []string{"not json", `{"interactionId":"int-5"}`} {
+		if err := processDeadLetter(record(body), now); err != nil {
+			t.Errorf("Expected %q to be dropped, got %v", body, err)
+		}
+	}
+	if len(sent) != 1 {
+		t.Errorf("Expected no further notices, got %d", len(sent))
+	}
+}
+
+func TestSentAt(t *testing.T) {
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	record := events.SQSMessage{Attributes: map[string]string{"SentTimestamp": strconv.FormatInt(at.UnixMilli(), 10)}}
+	if got := sentAt(record); !got.Equal(at) {
+		t.Errorf("Expected %v, got %v", at, got)
+	}
+	if got := sentAt(events.SQSMessage{}); !got.IsZero() {
+		t.Errorf("Expected zero time without the attribute, got %v", got)
+	}
+	if tokenUsable(time.Time{}, at) {
+		t.Error("Expected an unknown send time to make the token unusable")
+	}
+}
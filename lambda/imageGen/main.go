@@ -3,70 +3,101 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sqs"
 
+	costs "loros/syrus-costs"
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
 	models "loros/syrus-models"
+	sqsbatch "loros/syrus-sqsbatch"
+	ssmcache "loros/syrus-ssmcache"
 )
 
+// dedupPrefix namespaces this lambda's records in the shared dedup table
+const dedupPrefix = "imagegen"
+
+// claimLease holds a message while it is processed. It matches the 2 minute lambda timeout and lapses
+// before the image queue's 3 minute visibility timeout redelivers a crashed invocation's message.
+const claimLease = 2 * time.Minute
+
+// imageSize is the dimensions requested from Nano Banana; DALL-E takes the campaign's ImageOptions
+const imageSize = models.ImageSizeSquare
+
+// Image backends selectable through a campaign's ModelPolicy.ImageGen
+const (
+	defaultOpenAIImageModel = "dall-e-3"
+	nanoBananaModel         = "gemini-2.5-flash-image"
+	nanoBananaAspectRatio   = "1:1" // Square, matching imageSize
+)
+
+// geminiModelsURL is the Gemini API models endpoint; overridden in tests
+var geminiModelsURL = "https://generativelanguage.googleapis.com/v1beta/models"
+
+// deliveryDedupPrefix namespaces delivered-image records, which outlive generation retries
+const deliveryDedupPrefix = "imagegen-delivery"
+
+// deliveryDedupTTL keeps delivered-image records for the lifetime of a long campaign
+const deliveryDedupTTL = 90 * 24 * time.Hour
+
 var (
 	awsSession       *session.Session
 	dynamodbClient   *dynamodb.DynamoDB
 	s3Client         *s3.S3
-	ssmClient        *ssm.SSM
+	sqsClient        *sqs.SQS
 	campaignsTable   string
-	dedupTable       string
 	modelCacheBucket string
+	messagingQueue   string
 	stage            string
+	sqsConcurrency   int
+
+	// Delivery dependencies, overridden in tests
+	claimDelivery      = dedup.Claim
+	releaseDelivery    = dedup.Release
+	sendImageToChannel = sendImageToMessagingQueue
 )
 
 func init() {
 	awsSession = session.Must(session.NewSession())
 	dynamodbClient = dynamodb.New(awsSession)
 	s3Client = s3.New(awsSession)
-	ssmClient = ssm.New(awsSession)
+	sqsClient = sqs.New(awsSession)
 
 	campaignsTable = os.Getenv("SYRUS_CAMPAIGNS_TABLE")
-	dedupTable = os.Getenv("SYRUS_DEDUP_TABLE")
 	modelCacheBucket = os.Getenv("SYRUS_MODEL_CACHE_BUCKET")
+	messagingQueue = os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
 	stage = os.Getenv("SYRUS_STAGE")
+	sqsConcurrency = sqsbatch.ConcurrencyFromEnv()
 }
 
 func handler(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
 	log.Printf("Received %d messages from imageGen queue", len(event.Records))
 
-	var batchItemFailures []events.SQSBatchItemFailure
-
-	for _, record := range event.Records {
-		if err := processImageGenMessage(ctx, record); err != nil {
-			log.Printf("Failed to process message %s: %v", record.MessageId, err)
-			batchItemFailures = append(batchItemFailures, events.SQSBatchItemFailure{
-				ItemIdentifier: record.MessageId,
-			})
-		}
-	}
+	batchItemFailures := sqsbatch.Process(ctx, event.Records, sqsConcurrency, processImageGenMessage)
 
 	return events.SQSEventResponse{
 		BatchItemFailures: batchItemFailures,
 	}, nil
 }
 
-func processImageGenMessage(ctx context.Context, record events.SQSMessage) error {
+func processImageGenMessage(ctx context.Context, record events.SQSMessage) (err error) {
 	log.Printf("Processing imageGen message: %s", record.MessageId)
 
 	// Parse the imageGen message
@@ -77,14 +108,22 @@ func processImageGenMessage(ctx context.Context, record events.SQSMessage) error
 
 	log.Printf("Campaign ID: %s, Image ID: %s", imageGenMsg.CampaignID, imageGenMsg.ImageID)
 
-	// Check dedup table
+	// Claim the message; the conditional write lets only one concurrent delivery through
 	dedupKey := fmt.Sprintf("%s-%s", imageGenMsg.InteractionID, imageGenMsg.ImageID)
-	if isDuplicate, err := checkDedup(dedupKey); err != nil {
-		return fmt.Errorf("failed to check dedup: %w", err)
-	} else if isDuplicate {
+	if claimed, err := dedup.Claim(dedupPrefix, dedupKey, claimLease); err != nil {
+		return fmt.Errorf("failed to claim dedup: %w", err)
+	} else if !claimed {
 		log.Printf("Message already processed (dedupKey: %s), skipping", dedupKey)
 		return nil
 	}
+	// Drop the claim on failure so the SQS retry is not mistaken for a duplicate
+	defer func() {
+		if err != nil {
+			if releaseErr := dedup.Release(dedupPrefix, dedupKey); releaseErr != nil {
+				log.Printf("Warning: failed to release dedup claim: %v", releaseErr)
+			}
+		}
+	}()
 
 	// Check S3 cache (for retries)
 	s3Key := fmt.Sprintf("%s/images/%s.png", imageGenMsg.CampaignID, imageGenMsg.ImageID)
@@ -93,33 +132,40 @@ func processImageGenMessage(ctx context.Context, record events.SQSMessage) error
 	} else if cached {
 		log.Printf("Image already cached in S3: %s", s3Key)
 		// Use cached image - send to messaging queue
+		if err := deliverImage(imageGenMsg, s3Key); err != nil {
+			return fmt.Errorf("failed to deliver image: %w", err)
+		}
 		// Mark as processed
-		if err := markAsProcessed(dedupKey); err != nil {
+		if err := dedup.Mark(dedupPrefix, dedupKey, dedup.DefaultTTL); err != nil {
 			log.Printf("Warning: failed to mark as processed: %v", err)
 		}
 		return nil
 	}
 
-	// Get API key from SSM
-	apiKey, err := getOpenAIAPIKey()
-	if err != nil {
-		return fmt.Errorf("failed to get API key: %w", err)
+	// Images are embellishments, so a spent image budget skips the image rather than interrupting the tale
+	if campaign, err := getCampaign(imageGenMsg.CampaignID); err != nil {
+		log.Printf("Warning: failed to read campaign budget, generating anyway: %v", err)
+	} else if !costs.WithinLimit(campaign.CostTracking, costs.ClassImage) {
+		log.Printf("Campaign %s has spent its image budget, skipping image %s", imageGenMsg.CampaignID, imageGenMsg.ImageID)
+		if err := dedup.Mark(dedupPrefix, dedupKey, dedup.DefaultTTL); err != nil {
+			log.Printf("Warning: failed to mark as processed: %v", err)
+		}
+		return nil
 	}
 
-	// Call OpenAI DALL-E 3 API
-	imageURL, err := callOpenAI(ctx, apiKey, imageGenMsg.Prompt, imageGenMsg.Model)
+	// Generate with the backend, size, and quality named by the campaign's model policy
+	options := models.ImageOptions{Size: imageGenMsg.Size, Quality: imageGenMsg.Quality}.Normalized()
+	model := models.Model(imageGenMsg.Model)
+	image, err := generateImage(ctx, model, imageGenMsg.Prompt, options)
 	if err != nil {
-		return fmt.Errorf("failed to call OpenAI: %w", err)
+		return fmt.Errorf("failed to generate image: %w", err)
 	}
-
-	// Download image from OpenAI URL
-	imageData, err := downloadImage(ctx, imageURL)
-	if err != nil {
-		return fmt.Errorf("failed to download image: %w", err)
+	if err := costs.RecordCost(imageGenMsg.CampaignID, costs.ClassImage, imageCostUSD(model, options)); err != nil {
+		log.Printf("Warning: failed to record image usage: %v", err)
 	}
 
 	// Upload to S3
-	if err := uploadToS3(s3Key, imageData); err != nil {
+	if err := uploadToS3(s3Key, image); err != nil {
 		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
 
@@ -129,9 +175,12 @@ func processImageGenMessage(ctx context.Context, record events.SQSMessage) error
 	}
 
 	// Send to messaging queue
+	if err := deliverImage(imageGenMsg, s3Key); err != nil {
+		return fmt.Errorf("failed to deliver image: %w", err)
+	}
 
 	// Mark as processed in dedup table
-	if err := markAsProcessed(dedupKey); err != nil {
+	if err := dedup.Mark(dedupPrefix, dedupKey, dedup.DefaultTTL); err != nil {
 		log.Printf("Warning: failed to mark as processed: %v", err)
 	}
 
@@ -139,32 +188,81 @@ func processImageGenMessage(ctx context.Context, record events.SQSMessage) error
 	return nil
 }
 
-func checkDedup(dedupKey string) (bool, error) {
-	fullDedupKey := fmt.Sprintf("imagegen#%s", dedupKey)
-	result, err := dynamodbClient.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(dedupTable),
-		Key: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {S: aws.String(fullDedupKey)},
-		},
-	})
+// deliveryKey identifies one delivery of an image to a campaign's channel.
+// Generation retries and re-triggered releases share the key, so each image posts once per trigger.
+func deliveryKey(msg models.ImageGenMessage) string {
+	return fmt.Sprintf("%s-%s-%s", msg.CampaignID, msg.ImageID, msg.DeliveryTrigger)
+}
+
+// deliverImage posts a generated image to the campaign channel unless it has already been delivered.
+// The delivery is claimed before sending and released if the send fails, so it can be retried.
+// Messages without a channel are pre-generation requests and are never delivered.
+func deliverImage(msg models.ImageGenMessage, s3Key string) error {
+	if msg.ChannelID == "" {
+		return nil
+	}
+
+	key := deliveryKey(msg)
+	claimed, err := claimDelivery(deliveryDedupPrefix, key, deliveryDedupTTL)
 	if err != nil {
-		return false, err
+		return fmt.Errorf("failed to claim delivery dedup: %w", err)
+	}
+	if !claimed {
+		log.Printf("Image already delivered (deliveryKey: %s), skipping", key)
+		return nil
 	}
-	return result.Item != nil, nil
+
+	if err := sendImageToChannel(msg, s3Key); err != nil {
+		if releaseErr := releaseDelivery(deliveryDedupPrefix, key); releaseErr != nil {
+			log.Printf("Warning: failed to release delivery claim: %v", releaseErr)
+		}
+		return err
+	}
+
+	log.Printf("Delivered image %s to channel %s", msg.ImageID, msg.ChannelID)
+	return nil
 }
 
-func markAsProcessed(dedupKey string) error {
-	fullDedupKey := fmt.Sprintf("imagegen#%s", dedupKey)
-	ttl := time.Now().Add(24 * time.Hour).Unix()
-	_, err := dynamodbClient.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(dedupTable),
-		Item: map[string]*dynamodb.AttributeValue{
-			"dedupKey":    {S: aws.String(fullDedupKey)},
-			"expiresAt":   {N: aws.String(fmt.Sprintf("%d", ttl))},
-			"processedAt": {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+// sendImageToMessagingQueue queues the cached image as an attachment; messaging resolves the S3 key
+func sendImageToMessagingQueue(msg models.ImageGenMessage, s3Key string) error {
+	message := models.MessagingQueueMessage{
+		ChannelID: msg.ChannelID,
+		Attachments: []models.Attachment{
+			{
+				Name:        fmt.Sprintf("%s.png", msg.ImageID),
+				Data:        s3Key,
+				ContentType: "image/png",
+			},
 		},
+	}
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(messagingQueue),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(msg.ChannelID),
+		MessageDeduplicationId: aws.String(deliveryKey(msg)),
 	})
-	return err
+	if err != nil {
+		return fmt.Errorf("failed to send image to messaging queue: %w", err)
+	}
+	return nil
+}
+
+// s3ErrCodeNotFound is the code HeadObject reports for a missing key; HEAD responses have no body
+// to carry NoSuchKey, and the SDK defines no constant for it
+const s3ErrCodeNotFound = "NotFound"
+
+// isS3NotFound reports whether err is S3's missing-object error: NoSuchKey from GetObject, or NotFound from HeadObject
+func isS3NotFound(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	return awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == s3ErrCodeNotFound
 }
 
 func checkS3Cache(s3Key string) (bool, error) {
@@ -173,7 +271,7 @@ func checkS3Cache(s3Key string) (bool, error) {
 		Key:    aws.String(s3Key),
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "NoSuchKey") {
+		if isS3NotFound(err) {
 			return false, nil
 		}
 		return false, err
@@ -181,27 +279,175 @@ func checkS3Cache(s3Key string) (bool, error) {
 	return true, nil
 }
 
+// imageCostUSD estimates one image from the backend selected by model; only DALL-E prices by size and quality
+func imageCostUSD(model models.Model, options models.ImageOptions) float64 {
+	if model == models.ModelNanoBanana {
+		return costs.ImageCallCostUSD
+	}
+	return costs.ImageCostUSD(options)
+}
+
+// generateImage produces an image for prompt with the backend selected by model, a campaign's
+// ModelPolicy.ImageGen. Anything other than Nano Banana - including the raw OpenAI model names
+// carried by older messages - goes to DALL-E, which honours the size and quality in options.
+func generateImage(ctx context.Context, model models.Model, prompt string, options models.ImageOptions) (models.ImageResult, error) {
+	if model == models.ModelNanoBanana {
+		apiKey, err := getGoogleAPIKey()
+		if err != nil {
+			return models.ImageResult{}, fmt.Errorf("failed to get Google API key: %w", err)
+		}
+		imageData, err := callNanoBanana(ctx, apiKey, prompt)
+		if err != nil {
+			return models.ImageResult{}, fmt.Errorf("failed to call Nano Banana: %w", err)
+		}
+		return models.NewImageResult(imageData, models.ImageProviderGoogle, nanoBananaModel, imageSize, prompt, time.Now()), nil
+	}
+
+	openAIModel := openAIModelFor(model)
+	apiKey, err := getOpenAIAPIKey()
+	if err != nil {
+		return models.ImageResult{}, fmt.Errorf("failed to get OpenAI API key: %w", err)
+	}
+	imageURL, err := callOpenAI(ctx, apiKey, prompt, openAIModel, options)
+	if err != nil {
+		return models.ImageResult{}, fmt.Errorf("failed to call OpenAI: %w", err)
+	}
+	imageData, err := downloadImage(ctx, imageURL)
+	if err != nil {
+		return models.ImageResult{}, fmt.Errorf("failed to download image: %w", err)
+	}
+	return models.NewImageResult(imageData, models.ImageProviderOpenAI, openAIModel, options.Size, prompt, time.Now()), nil
+}
+
+// openAIModelFor maps an image model policy to the OpenAI model to request. Messages queued before
+// the policy was honoured name the OpenAI model directly, so those names pass through.
+func openAIModelFor(model models.Model) string {
+	switch model {
+	case "", models.ModelOpenAI:
+		return defaultOpenAIImageModel
+	default:
+		return string(model)
+	}
+}
+
 func getOpenAIAPIKey() (string, error) {
 	paramName := fmt.Sprintf("/syrus/%s/openai/api-key", stage)
-	result, err := ssmClient.GetParameter(&ssm.GetParameterInput{
-		Name:           aws.String(paramName),
-		WithDecryption: aws.Bool(true),
-	})
+	return ssmcache.Get(paramName, true)
+}
+
+func getGoogleAPIKey() (string, error) {
+	paramName := fmt.Sprintf("/syrus/%s/google/api-key", stage)
+	return ssmcache.Get(paramName, true)
+}
+
+// nanoBananaRequest is the Gemini generateContent request asking for an image-only response
+type nanoBananaRequest struct {
+	Contents         []nanoBananaContent `json:"contents"`
+	GenerationConfig struct {
+		ResponseModalities []string `json:"responseModalities"`
+		ImageConfig        struct {
+			AspectRatio string `json:"aspectRatio"`
+		} `json:"imageConfig"`
+	} `json:"generationConfig"`
+}
+
+// nanoBananaContent is one turn of Gemini content
+type nanoBananaContent struct {
+	Parts []nanoBananaPart `json:"parts"`
+}
+
+// nanoBananaPart is one part of Gemini content: prompt text going in, inline image data coming out
+type nanoBananaPart struct {
+	Text       string `json:"text,omitempty"`
+	InlineData *struct {
+		MimeType string `json:"mimeType"`
+		Data     string `json:"data"` // Base64-encoded image bytes
+	} `json:"inlineData,omitempty"`
+}
+
+// nanoBananaResponse is the subset of the Gemini generateContent response carrying the image
+type nanoBananaResponse struct {
+	Candidates []struct {
+		Content      nanoBananaContent `json:"content"`
+		FinishReason string            `json:"finishReason"`
+	} `json:"candidates"`
+}
+
+func callNanoBanana(ctx context.Context, apiKey, prompt string) ([]byte, error) {
+	log.Printf("Calling Gemini image API with model %s", nanoBananaModel)
+
+	payload := nanoBananaRequest{Contents: []nanoBananaContent{{Parts: []nanoBananaPart{{Text: prompt}}}}}
+	payload.GenerationConfig.ResponseModalities = []string{"IMAGE"}
+	payload.GenerationConfig.ImageConfig.AspectRatio = nanoBananaAspectRatio
+
+	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	return *result.Parameter.Value, nil
+
+	url := fmt.Sprintf("%s/%s:generateContent", geminiModelsURL, nanoBananaModel)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payloadJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", apiKey)
+
+	client := &http.Client{Timeout: 90 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return parseNanoBananaResponse(body)
+}
+
+// parseNanoBananaResponse decodes the first inline image in a Gemini response
+func parseNanoBananaResponse(body []byte) ([]byte, error) {
+	var apiResponse nanoBananaResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(apiResponse.Candidates) == 0 {
+		return nil, fmt.Errorf("API returned no candidates")
+	}
+
+	candidate := apiResponse.Candidates[0]
+	for _, part := range candidate.Content.Parts {
+		if part.InlineData == nil || part.InlineData.Data == "" {
+			continue
+		}
+		imageData, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode inline image: %w", err)
+		}
+		log.Printf("Received %s image from Gemini: %d bytes", part.InlineData.MimeType, len(imageData))
+		return imageData, nil
+	}
+	return nil, fmt.Errorf("API returned no image (finish reason: %s)", candidate.FinishReason)
 }
 
-func callOpenAI(ctx context.Context, apiKey, prompt, model string) (string, error) {
-	log.Printf("Calling OpenAI DALL-E API with model %s", model)
+func callOpenAI(ctx context.Context, apiKey, prompt, model string, options models.ImageOptions) (string, error) {
+	log.Printf("Calling OpenAI DALL-E API with model %s (%s, %s)", model, options.Size, options.Quality)
 
 	payload := map[string]interface{}{
 		"model":   model,
 		"prompt":  prompt,
 		"n":       1,
-		"size":    "1024x1024",
-		"quality": "standard",
+		"size":    options.Size,
+		"quality": options.Quality,
 	}
 
 	payloadJSON, err := json.Marshal(payload)
@@ -279,15 +525,21 @@ func downloadImage(ctx context.Context, imageURL string) ([]byte, error) {
 	return imageData, nil
 }
 
-func uploadToS3(s3Key string, imageData []byte) error {
-	log.Printf("Uploading image to S3: %s", s3Key)
-
-	_, err := s3Client.PutObject(&s3.PutObjectInput{
-		Bucket:      aws.String(modelCacheBucket),
+// buildImageUpload builds the S3 upload for a generated image, recording its provenance as object metadata
+func buildImageUpload(bucket, s3Key string, image models.ImageResult) *s3.PutObjectInput {
+	return &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
 		Key:         aws.String(s3Key),
-		Body:        bytes.NewReader(imageData),
-		ContentType: aws.String("image/png"),
-	})
+		Body:        bytes.NewReader(image.Data),
+		ContentType: aws.String(image.ContentType),
+		Metadata:    aws.StringMap(image.Metadata()),
+	}
+}
+
+func uploadToS3(s3Key string, image models.ImageResult) error {
+	log.Printf("Uploading image to S3: %s (%s %s, %s)", s3Key, image.Provider, image.Model, image.ContentType)
+
+	_, err := s3Client.PutObject(buildImageUpload(modelCacheBucket, s3Key, image))
 	if err != nil {
 		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
@@ -345,5 +597,6 @@ func getCampaign(campaignID string) (*models.Campaign, error) {
 }
 
 func main() {
+	logging.Init("imageGen")
 	lambda.Start(handler)
 }
@@ -1,10 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	costs "loros/syrus-costs"
 	models "loros/syrus-models"
 )
 
@@ -310,3 +321,236 @@ func TestImageGenMessage_LongPrompt(t *testing.T) {
 		t.Errorf("Expected prompt length %d, got %d", len(longPrompt), len(parsed.Prompt))
 	}
 }
+
+func TestDeliverImageSuppressesRedelivery(t *testing.T) {
+	delivered := map[string]bool{}
+	var sent []string
+
+	sendErr := errors.New("queue unavailable")
+	failSend := false
+
+	origClaim, origRelease, origSend := claimDelivery, releaseDelivery, sendImageToChannel
+	defer func() { claimDelivery, releaseDelivery, sendImageToChannel = origClaim, origRelease, origSend }()
+
+	claimDelivery = func(prefix, id string, lease time.Duration) (bool, error) {
+		if delivered[prefix+"#"+id] {
+			return false, nil
+		}
+		delivered[prefix+"#"+id] = true
+		return true, nil
+	}
+	releaseDelivery = func(prefix, id string) error {
+		delete(delivered, prefix+"#"+id)
+		return nil
+	}
+	sendImageToChannel = func(msg models.ImageGenMessage, s3Key string) error {
+		if failSend {
+			return sendErr
+		}
+		sent = append(sent, s3Key)
+		return nil
+	}
+
+	msg := models.ImageGenMessage{
+		CampaignID:      "campaign123",
+		InteractionID:   "interaction456",
+		ImageID:         "gate",
+		ChannelID:       "channel789",
+		DeliveryTrigger: "act1-climax",
+	}
+	s3Key := "campaign123/images/gate.png"
+
+	if err := deliverImage(msg, s3Key); err != nil {
+		t.Fatalf("Unexpected error on first delivery: %v", err)
+	}
+
+	// A retried generation or re-triggered release from a new interaction must not post again
+	msg.InteractionID = "interaction999"
+	if err := deliverImage(msg, s3Key); err != nil {
+		t.Fatalf("Unexpected error on redelivery: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("Expected image to be delivered once, got %d deliveries", len(sent))
+	}
+
+	// A failed send releases its claim so the retry posts the image
+	msg.DeliveryTrigger = "act2-opening"
+	failSend = true
+	if err := deliverImage(msg, s3Key); !errors.Is(err, sendErr) {
+		t.Fatalf("Expected the send error, got %v", err)
+	}
+	failSend = false
+
+	// A different trigger is a distinct delivery
+	if err := deliverImage(msg, s3Key); err != nil {
+		t.Fatalf("Unexpected error on new trigger: %v", err)
+	}
+	if len(sent) != 2 {
+		t.Errorf("Expected a new trigger to deliver again, got %d deliveries", len(sent))
+	}
+
+	// Pre-generation requests have no channel and are never delivered
+	msg.ChannelID = ""
+	msg.DeliveryTrigger = "act3-finale"
+	if err := deliverImage(msg, s3Key); err != nil {
+		t.Fatalf("Unexpected error for pre-generation: %v", err)
+	}
+	if len(sent) != 2 {
+		t.Errorf("Expected pre-generation not to deliver, got %d deliveries", len(sent))
+	}
+}
+
+func TestBuildImageUploadSetsMetadata(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	image := models.NewImageResult(png, models.ImageProviderOpenAI, "dall-e-3", imageSize, "A sunken bell tower", createdAt)
+
+	input := buildImageUpload("syrus-model-cache-dev", "campaign123/images/bell.png", image)
+
+	if *input.Bucket != "syrus-model-cache-dev" || *input.Key != "campaign123/images/bell.png" {
+		t.Errorf("Unexpected upload target s3://%s/%s", *input.Bucket, *input.Key)
+	}
+	if *input.ContentType != "image/png" {
+		t.Errorf("Expected content type image/png, got %s", *input.ContentType)
+	}
+
+	expected := map[string]string{
+		models.ImageMetaProvider:   "openai",
+		models.ImageMetaModel:      "dall-e-3",
+		models.ImageMetaSize:       "1024x1024",
+		models.ImageMetaPromptHash: models.HashPrompt("A sunken bell tower"),
+		models.ImageMetaCreatedAt:  "2025-06-01T12:00:00Z",
+	}
+	for key, value := range expected {
+		if got, ok := input.Metadata[key]; !ok || *got != value {
+			t.Errorf("Expected metadata %s=%q on upload, got %v", key, value, got)
+		}
+	}
+}
+
+func TestOpenAIModelFor(t *testing.T) {
+	tests := []struct {
+		model    models.Model
+		expected string
+	}{
+		{models.ModelOpenAI, "dall-e-3"},
+		{"", "dall-e-3"},
+		{"dall-e-2", "dall-e-2"}, // Queued before the policy was honoured
+	}
+
+	for _, tt := range tests {
+		if got := openAIModelFor(tt.model); got != tt.expected {
+			t.Errorf("openAIModelFor(%q) = %q, expected %q", tt.model, got, tt.expected)
+		}
+	}
+}
+
+func TestImageCostUSD(t *testing.T) {
+	hd := models.ImageOptions{Size: models.ImageSizeSquare, Quality: models.ImageQualityHD}
+	if got := imageCostUSD(models.ModelOpenAI, hd); got != costs.ImageHDCallCostUSD {
+		t.Errorf("Expected HD DALL-E images to cost %v, got %v", costs.ImageHDCallCostUSD, got)
+	}
+	if got := imageCostUSD(models.ModelNanoBanana, hd); got != costs.ImageCallCostUSD {
+		t.Errorf("Expected Nano Banana to ignore quality, got %v", got)
+	}
+}
+
+func TestParseNanoBananaResponse(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "image after text part",
+			body: `{"candidates":[{"content":{"parts":[{"text":"Here is your harbor"},{"inlineData":{"mimeType":"image/png","data":"` + encoded + `"}}]}}]}`,
+			want: png,
+		},
+		{name: "no candidates", body: `{"candidates":[]}`, wantErr: true},
+		{name: "refused without image", body: `{"candidates":[{"content":{"parts":[{"text":"I cannot draw that"}]},"finishReason":"IMAGE_SAFETY"}]}`, wantErr: true},
+		{name: "corrupt image data", body: `{"candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"image/png","data":"!!"}}]}}]}`, wantErr: true},
+		{name: "malformed json", body: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNanoBananaResponse([]byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %d bytes", len(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCallNanoBanana(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	var gotPath, gotKey string
+	var gotRequest nanoBananaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotKey = r.URL.Path, r.Header.Get("x-goog-api-key")
+		if err := json.NewDecoder(r.Body).Decode(&gotRequest); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		fmt.Fprintf(w, `{"candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"image/png","data":%q}}]}}]}`, base64.StdEncoding.EncodeToString(png))
+	}))
+	defer server.Close()
+
+	original := geminiModelsURL
+	geminiModelsURL = server.URL + "/v1beta/models"
+	defer func() { geminiModelsURL = original }()
+
+	got, err := callNanoBanana(context.Background(), "test-key", "A misty harbor at dawn")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, png) {
+		t.Errorf("Expected the decoded image, got %v", got)
+	}
+	if gotPath != "/v1beta/models/gemini-2.5-flash-image:generateContent" {
+		t.Errorf("Unexpected request path %s", gotPath)
+	}
+	if gotKey != "test-key" {
+		t.Errorf("Expected the API key header, got %q", gotKey)
+	}
+	if len(gotRequest.Contents) != 1 || gotRequest.Contents[0].Parts[0].Text != "A misty harbor at dawn" {
+		t.Errorf("Expected the prompt as the only content, got %+v", gotRequest.Contents)
+	}
+	if modalities := gotRequest.GenerationConfig.ResponseModalities; len(modalities) != 1 || modalities[0] != "IMAGE" {
+		t.Errorf("Expected an image-only response requested, got %v", modalities)
+	}
+}
+
+func TestIsS3NotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"get missing key", awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), true},
+		{"head missing key", awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), 404, "req-1"), true},
+		{"wrapped missing key", fmt.Errorf("cache: %w", awserr.New(s3.ErrCodeNoSuchKey, "gone", nil)), true},
+		{"access denied", awserr.New("AccessDenied", "Access Denied", nil), false},
+		{"untyped error naming the code", errors.New("NoSuchKey: but not from the SDK"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isS3NotFound(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -4,9 +4,21 @@ go 1.21
 
 replace github.com/loros/syrus-models => ../../lib/go/models
 
+replace loros/syrus-sqsbatch => ../../lib/go/sqsbatch
+
+replace loros/syrus-ssmcache => ../../lib/go/ssmcache
+
+replace loros/syrus-logging => ../../lib/go/logging
+
+replace loros/syrus-dedup => ../../lib/go/dedup
+
 require (
 	github.com/aws/aws-lambda-go v1.47.0
-	github.com/aws/aws-sdk-go v1.50.0
+	github.com/aws/aws-sdk-go v1.55.5
+	loros/syrus-dedup v0.0.0
+	loros/syrus-logging v0.0.0
+	loros/syrus-sqsbatch v0.0.0
+	loros/syrus-ssmcache v0.0.0
 )
 
 require github.com/jmespath/go-jmespath v0.4.0 // indirect
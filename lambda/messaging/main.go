@@ -5,21 +5,29 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
+	sqsbatch "loros/syrus-sqsbatch"
+	ssmcache "loros/syrus-ssmcache"
 )
 
 // DiscordMessage represents the message structure sent to Discord API
@@ -39,6 +47,22 @@ type SQSMessageBody struct {
 	InteractionToken string                   `json:"interactionToken,omitempty"`
 	Flags            int                      `json:"flags,omitempty"` // Discord message flags
 	Attachments      []Attachment             `json:"attachments,omitempty"`
+	CreateThread     *ThreadRequest           `json:"createThread,omitempty"`
+	Followup         bool                     `json:"followup,omitempty"`      // Post a new interaction follow-up rather than editing @original
+	Platform         string                   `json:"platform,omitempty"`      // platformDiscord (default) or platformWhatsApp
+	InteractionID    string                   `json:"interactionId,omitempty"` // Correlates delivery logs with the originating interaction
+}
+
+// Platforms a message can be sent to; messages without one go to Discord
+const (
+	platformDiscord  = "discord"
+	platformWhatsApp = "whatsapp"
+)
+
+// ThreadRequest asks messaging to create a campaign thread and replay a configuring message into it
+type ThreadRequest struct {
+	Name   string                 `json:"name"`
+	Replay map[string]interface{} `json:"replay"`
 }
 
 // Attachment represents a file attachment
@@ -49,15 +73,54 @@ type Attachment struct {
 }
 
 var (
-	awsSession       *session.Session
-	s3Client         *s3.S3
-	modelCacheBucket string
+	awsSession          *session.Session
+	s3Client            *s3.S3
+	sqsClient           *sqs.SQS
+	modelCacheBucket    string
+	configuringQueueURL string
+	sqsConcurrency      int
+	sendTimeout         time.Duration
+)
+
+// Discord send timeouts. The send deadline is also capped by the Lambda's remaining time less
+// sendDeadlineMargin, so a slow send is cancelled cleanly instead of being killed mid-flight.
+const (
+	sendTimeoutEnvVar    = "SYRUS_DISCORD_SEND_TIMEOUT_SECONDS"
+	defaultSendTimeout   = 30 * time.Second // Generous enough for file uploads
+	defaultThreadTimeout = 10 * time.Second
+	sendDeadlineMargin   = 2 * time.Second
 )
 
 func init() {
 	awsSession = session.Must(session.NewSession())
 	s3Client = s3.New(awsSession)
+	sqsClient = sqs.New(awsSession)
 	modelCacheBucket = os.Getenv("SYRUS_MODEL_CACHE_BUCKET")
+	configuringQueueURL = os.Getenv("SYRUS_CONFIGURING_QUEUE_URL")
+	sqsConcurrency = sqsbatch.ConcurrencyFromEnv()
+	sendTimeout = parseSendTimeout(os.Getenv(sendTimeoutEnvVar))
+}
+
+// parseSendTimeout reads the configured send timeout in seconds, falling back to the default when unset or invalid
+func parseSendTimeout(raw string) time.Duration {
+	if raw == "" {
+		return defaultSendTimeout
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 {
+		log.Printf("Warning: invalid %s %q, using default %s", sendTimeoutEnvVar, raw, defaultSendTimeout)
+		return defaultSendTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// sendContext bounds a single Discord call by timeout and by the handler context's deadline less sendDeadlineMargin
+func sendContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Add(-sendDeadlineMargin).Before(deadline) {
+		deadline = ctxDeadline.Add(-sendDeadlineMargin)
+	}
+	return context.WithDeadline(ctx, deadline)
 }
 
 // getImageFromS3 retrieves an image from S3 and returns it as base64-encoded string
@@ -81,73 +144,80 @@ func getImageFromS3(s3Key string) (string, error) {
 
 // getDiscordBotToken retrieves the Discord bot token from SSM Parameter Store
 func getDiscordBotToken(stage string) (string, error) {
-	sess, err := session.NewSession()
-	if err != nil {
-		return "", fmt.Errorf("failed to create AWS session: %w", err)
-	}
-
-	svc := ssm.New(sess)
-	paramName := fmt.Sprintf("/syrus/%s/discord/bot-token", stage)
-	result, err := svc.GetParameter(&ssm.GetParameterInput{
-		Name:           aws.String(paramName),
-		WithDecryption: aws.Bool(true), // Bot token is SecureString, needs decryption
-	})
-
-	if err != nil {
-		return "", fmt.Errorf("failed to get parameter %s: %w", paramName, err)
-	}
-
-	if result.Parameter == nil || result.Parameter.Value == nil {
-		return "", fmt.Errorf("parameter %s not found or has no value", paramName)
-	}
-
-	return *result.Parameter.Value, nil
+	// Bot token is SecureString, needs decryption
+	return ssmcache.Get(fmt.Sprintf("/syrus/%s/discord/bot-token", stage), true)
 }
 
 // getDiscordAppID retrieves the Discord application ID from SSM Parameter Store
 func getDiscordAppID(stage string) (string, error) {
-	sess, err := session.NewSession()
+	appID, err := ssmcache.Get(fmt.Sprintf("/syrus/%s/discord/app-id", stage), false)
 	if err != nil {
-		return "", fmt.Errorf("failed to create AWS session: %w", err)
+		return "", err
 	}
+	return strings.TrimSpace(appID), nil
+}
 
-	svc := ssm.New(sess)
-	paramName := fmt.Sprintf("/syrus/%s/discord/app-id", stage)
-	result, err := svc.GetParameter(&ssm.GetParameterInput{
-		Name:           aws.String(paramName),
-		WithDecryption: aws.Bool(false),
-	})
+// Discord JSON error codes for resources that no longer exist
+const (
+	discordErrUnknownChannel = 10003
+	discordErrUnknownMessage = 10008
+)
 
-	if err != nil {
-		return "", fmt.Errorf("failed to get parameter %s: %w", paramName, err)
-	}
+// terminalDiscordError is a Discord failure that retrying cannot fix, such as a deleted channel or message
+type terminalDiscordError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
 
-	if result.Parameter == nil || result.Parameter.Value == nil {
-		return "", fmt.Errorf("parameter %s not found or has no value", paramName)
-	}
+func (e *terminalDiscordError) Error() string {
+	return fmt.Sprintf("discord API returned status %d (code %d): %s", e.StatusCode, e.Code, e.Message)
+}
 
-	return strings.TrimSpace(*result.Parameter.Value), nil
+// discordAPIError converts a non-2xx Discord response into an error, marking
+// Unknown Channel and Unknown Message responses as terminal
+func discordAPIError(statusCode int, body []byte) error {
+	var discordErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if statusCode == http.StatusNotFound && json.Unmarshal(body, &discordErr) == nil {
+		switch discordErr.Code {
+		case discordErrUnknownChannel, discordErrUnknownMessage:
+			return &terminalDiscordError{StatusCode: statusCode, Code: discordErr.Code, Message: discordErr.Message}
+		}
+	}
+	return fmt.Errorf("discord API returned status %d: %s", statusCode, string(body))
 }
 
-// sendDiscordMessage sends a message to Discord
-// If interactionToken is provided, uses webhook endpoint to resolve the interaction
-// Otherwise, uses channel messages endpoint
-func sendDiscordMessage(channelID string, message DiscordMessage, botToken string, interactionToken string, applicationID string, attachments []Attachment) error {
-	var url string
-	var method string
+// isTerminalDiscordError reports whether err (or anything it wraps) should not be retried
+func isTerminalDiscordError(err error) bool {
+	var terminal *terminalDiscordError
+	return errors.As(err, &terminal)
+}
 
+// resolveDiscordEndpoint picks the Discord endpoint and method for a message
+func resolveDiscordEndpoint(channelID, interactionToken, applicationID string, followup bool) (string, string) {
 	if interactionToken != "" && applicationID != "" {
+		if followup {
+			// Use webhook endpoint to post a new follow-up (can be ephemeral even after a public response)
+			return fmt.Sprintf("https://discord.com/api/v10/webhooks/%s/%s", applicationID, interactionToken), "POST"
+		}
 		// Use webhook endpoint to edit the original deferred interaction response
-		url = fmt.Sprintf("https://discord.com/api/v10/webhooks/%s/%s/messages/@original", applicationID, interactionToken)
-		method = "PATCH"
-	} else {
-		// Use channel messages endpoint
-		url = fmt.Sprintf("https://discord.com/api/v10/channels/%s/messages", channelID)
-		method = "POST"
+		return fmt.Sprintf("https://discord.com/api/v10/webhooks/%s/%s/messages/@original", applicationID, interactionToken), "PATCH"
 	}
+	// Use channel messages endpoint
+	return fmt.Sprintf("https://discord.com/api/v10/channels/%s/messages", channelID), "POST"
+}
 
-	var req *http.Request
-	var err error
+// sendDiscordMessage sends a message to Discord
+// If interactionToken is provided, uses webhook endpoint to resolve the interaction (or follow up on it)
+// Otherwise, uses channel messages endpoint
+func sendDiscordMessage(ctx context.Context, channelID string, message DiscordMessage, botToken string, interactionToken string, applicationID string, followup bool, attachments []Attachment) error {
+	url, method := resolveDiscordEndpoint(channelID, interactionToken, applicationID, followup)
+
+	var payload []byte
+	var contentType string
 
 	// If we have attachments, use multipart form data
 	if len(attachments) > 0 {
@@ -165,35 +235,9 @@ func sendDiscordMessage(channelID string, message DiscordMessage, botToken strin
 
 		// Add attachments
 		for i, attachment := range attachments {
-			var fileData []byte
-			var err error
-
-			// Check if Data is an S3 key or base64-encoded data
-			// S3 keys will have forward slashes and not be valid base64 (or will be a path pattern)
-			if strings.Contains(attachment.Data, "/") && !strings.Contains(attachment.Data, " ") {
-				// Likely an S3 key - fetch from S3
-				log.Printf("Fetching attachment from S3: %s", attachment.Data)
-				base64Data, err := getImageFromS3(attachment.Data)
-				if err != nil {
-					log.Printf("Warning: failed to fetch from S3, trying as base64: %v", err)
-					// Fall back to treating as base64
-					fileData, err = base64.StdEncoding.DecodeString(attachment.Data)
-					if err != nil {
-						return fmt.Errorf("failed to decode attachment data: %w", err)
-					}
-				} else {
-					// Successfully fetched from S3, now decode the base64
-					fileData, err = base64.StdEncoding.DecodeString(base64Data)
-					if err != nil {
-						return fmt.Errorf("failed to decode S3 image data: %w", err)
-					}
-				}
-			} else {
-				// Decode base64 data directly
-				fileData, err = base64.StdEncoding.DecodeString(attachment.Data)
-				if err != nil {
-					return fmt.Errorf("failed to decode attachment data: %w", err)
-				}
+			fileData, err := resolveAttachmentData(attachment)
+			if err != nil {
+				return err
 			}
 
 			// Create form file
@@ -211,92 +255,631 @@ func sendDiscordMessage(channelID string, message DiscordMessage, botToken strin
 			return fmt.Errorf("failed to close multipart writer: %w", err)
 		}
 
-		req, err = http.NewRequest(method, url, body)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Content-Type", writer.FormDataContentType())
+		payload, contentType = body.Bytes(), writer.FormDataContentType()
 	} else {
 		// No attachments, use JSON
 		jsonData, err := json.Marshal(message)
 		if err != nil {
 			return fmt.Errorf("failed to marshal message: %w", err)
 		}
+		payload, contentType = jsonData, "application/json"
+	}
 
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(jsonData))
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
 		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
-	}
+		req.Header.Set("Content-Type", contentType)
 
-	// Set authorization header
-	// Webhook endpoint doesn't need Authorization header (token in URL is sufficient)
-	// Channel messages endpoint requires Bot token
-	if interactionToken == "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bot %s", botToken))
+		// Set authorization header
+		// Webhook endpoint doesn't need Authorization header (token in URL is sufficient)
+		// Channel messages endpoint requires Bot token
+		if interactionToken == "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bot %s", botToken))
+		}
+		return req, nil
 	}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second, // Increased timeout for file uploads
+	_, err := sendWithRateLimitRetry(ctx, newRequest, discordRetryAfter, discordAPIError)
+	return err
+}
+
+// resolveAttachmentData returns an attachment's file bytes. Data is either an S3 key in the model
+// cache bucket or base64-encoded file data.
+func resolveAttachmentData(attachment Attachment) ([]byte, error) {
+	// Check if Data is an S3 key or base64-encoded data
+	// S3 keys will have forward slashes and not be valid base64 (or will be a path pattern)
+	if strings.Contains(attachment.Data, "/") && !strings.Contains(attachment.Data, " ") {
+		// Likely an S3 key - fetch from S3
+		log.Printf("Fetching attachment from S3: %s", attachment.Data)
+		base64Data, err := getImageFromS3(attachment.Data)
+		if err != nil {
+			log.Printf("Warning: failed to fetch from S3, trying as base64: %v", err)
+			// Fall back to treating as base64
+			fileData, err := base64.StdEncoding.DecodeString(attachment.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode attachment data: %w", err)
+			}
+			return fileData, nil
+		}
+		// Successfully fetched from S3, now decode the base64
+		fileData, err := base64.StdEncoding.DecodeString(base64Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode S3 image data: %w", err)
+		}
+		return fileData, nil
 	}
 
-	// Send request
-	resp, err := client.Do(req)
+	// Decode base64 data directly
+	fileData, err := base64.StdEncoding.DecodeString(attachment.Data)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to decode attachment data: %w", err)
 	}
-	defer resp.Body.Close()
+	return fileData, nil
+}
 
-	// Check response status
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+// discordRetryAfter reads how long Discord asks us to wait from a 429 response body
+func discordRetryAfter(resp *http.Response, body []byte) (time.Duration, bool) {
+	var rateLimitResp struct {
+		Message    string  `json:"message"`
+		RetryAfter float64 `json:"retry_after"`
+		Global     bool    `json:"global"`
+	}
+	if err := json.Unmarshal(body, &rateLimitResp); err != nil || rateLimitResp.RetryAfter <= 0 {
+		return 0, false
+	}
+	// Wait for the retry_after duration plus a small buffer
+	return time.Duration(rateLimitResp.RetryAfter*1000)*time.Millisecond + 100*time.Millisecond, true
+}
+
+// sendWithRateLimitRetry sends the request built by newRequest and returns the response body. A rate-limited
+// (429) response is retried once after the wait retryAfter reads from it; apiError converts any other failure
+// into an error. The request is rebuilt for the retry because the first attempt consumes its body.
+func sendWithRateLimitRetry(ctx context.Context, newRequest func() (*http.Request, error), retryAfter func(*http.Response, []byte) (time.Duration, bool), apiError func(int, []byte) error) ([]byte, error) {
+	// The request context carries the send deadline
+	client := &http.Client{}
+
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		// Send request
+		resp, err := client.Do(req)
+		if err != nil {
+			if attempt > 0 {
+				return nil, fmt.Errorf("failed to send request on retry: %w", err)
+			}
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
 		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
 
-		// Handle rate limiting (429) with retry
-		if resp.StatusCode == 429 {
-			// Parse rate limit response
-			var rateLimitResp struct {
-				Message    string  `json:"message"`
-				RetryAfter float64 `json:"retry_after"`
-				Global     bool    `json:"global"`
+		// Check response status
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if attempt > 0 {
+				logging.FromContext(ctx).Printf("Successfully sent message after rate limit retry")
 			}
-			if err := json.Unmarshal(body, &rateLimitResp); err == nil && rateLimitResp.RetryAfter > 0 {
-				// Wait for the retry_after duration plus a small buffer
-				sleepDuration := time.Duration(rateLimitResp.RetryAfter*1000)*time.Millisecond + 100*time.Millisecond
-				log.Printf("Rate limited, sleeping for %.2f seconds", sleepDuration.Seconds())
-				time.Sleep(sleepDuration)
-
-				// Retry the request once
-				resp2, err := client.Do(req)
-				if err != nil {
-					return fmt.Errorf("failed to send request on retry: %w", err)
+			return body, nil
+		}
+		if attempt > 0 {
+			return nil, fmt.Errorf("rate limit retry failed: %w", apiError(resp.StatusCode, body))
+		}
+
+		// Handle rate limiting (429) with one retry
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if wait, ok := retryAfter(resp, body); ok {
+				logging.FromContext(ctx).Printf("Rate limited, sleeping for %.2f seconds", wait.Seconds())
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return nil, fmt.Errorf("send deadline reached while rate limited: %w", ctx.Err())
 				}
-				defer resp2.Body.Close()
+				continue
+			}
+		}
+
+		return nil, apiError(resp.StatusCode, body)
+	}
+}
+
+// whatsAppGraphURL is the WhatsApp Cloud (Graph) API base URL; overridden in tests
+var whatsAppGraphURL = "https://graph.facebook.com/v21.0"
+
+// whatsAppRateLimitBackoff is waited before retrying a rate-limited WhatsApp send that carries no Retry-After
+const whatsAppRateLimitBackoff = 2 * time.Second
 
-				if resp2.StatusCode < 200 || resp2.StatusCode >= 300 {
-					body2, _ := io.ReadAll(resp2.Body)
-					return fmt.Errorf("discord API returned status %d on retry: %s", resp2.StatusCode, string(body2))
+// getWhatsAppAccessToken retrieves the WhatsApp Business API access token from SSM Parameter Store
+func getWhatsAppAccessToken(stage string) (string, error) {
+	return ssmcache.Get(fmt.Sprintf("/syrus/%s/whatsapp/access-token", stage), true)
+}
+
+// getWhatsAppPhoneID retrieves the WhatsApp Business phone number ID messages are sent from
+func getWhatsAppPhoneID(stage string) (string, error) {
+	phoneID, err := ssmcache.Get(fmt.Sprintf("/syrus/%s/whatsapp/phone-number-id", stage), false)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(phoneID), nil
+}
+
+// whatsAppRecipient converts a WhatsApp ID such as "1234567890@c.us" into the number the Cloud API expects
+func whatsAppRecipient(channelID string) string {
+	return strings.TrimSuffix(channelID, "@c.us")
+}
+
+// whatsAppRetryAfter honours a Retry-After header on a rate-limited WhatsApp response, else waits a fixed backoff
+func whatsAppRetryAfter(resp *http.Response, body []byte) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	return whatsAppRateLimitBackoff, true
+}
+
+// whatsAppAPIError converts a non-2xx Graph API response into an error
+func whatsAppAPIError(statusCode int, body []byte) error {
+	return fmt.Errorf("whatsapp API returned status %d: %s", statusCode, string(body))
+}
+
+// buildWhatsAppTextPayload builds a Cloud API text message
+func buildWhatsAppTextPayload(to, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]interface{}{"preview_url": false, "body": text},
+	}
+}
+
+// buildWhatsAppImagePayload builds a Cloud API image message for previously uploaded media
+func buildWhatsAppImagePayload(to, mediaID, caption string) map[string]interface{} {
+	image := map[string]interface{}{"id": mediaID}
+	if caption != "" {
+		image["caption"] = caption
+	}
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "image",
+		"image":             image,
+	}
+}
+
+// postWhatsApp sends one Graph API request from the business phone number, returning the response body
+func postWhatsApp(ctx context.Context, phoneID, path, accessToken string, payload []byte, contentType string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s/%s", whatsAppGraphURL, phoneID, path)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		return req, nil
+	}
+	return sendWithRateLimitRetry(ctx, newRequest, whatsAppRetryAfter, whatsAppAPIError)
+}
+
+// uploadWhatsAppMedia uploads an attachment to WhatsApp and returns its media ID
+func uploadWhatsAppMedia(ctx context.Context, phoneID, accessToken string, attachment Attachment) (string, error) {
+	fileData, err := resolveAttachmentData(attachment)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("messaging_product", "whatsapp"); err != nil {
+		return "", fmt.Errorf("failed to write messaging_product: %w", err)
+	}
+	if err := writer.WriteField("type", attachment.ContentType); err != nil {
+		return "", fmt.Errorf("failed to write media type: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", attachment.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(fileData); err != nil {
+		return "", fmt.Errorf("failed to write file data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	respBody, err := postWhatsApp(ctx, phoneID, "media", accessToken, body.Bytes(), writer.FormDataContentType())
+	if err != nil {
+		return "", fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	var media struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &media); err != nil || media.ID == "" {
+		return "", fmt.Errorf("media upload returned no id: %s", string(respBody))
+	}
+	return media.ID, nil
+}
+
+// sendWhatsAppMessage sends a queued message to a WhatsApp user. Each attachment is uploaded and sent as an
+// image, the first carrying the content as its caption; without attachments the content is sent as text.
+// Discord-only parts (embeds, components, flags, threads) have no WhatsApp equivalent and are dropped.
+func sendWhatsAppMessage(ctx context.Context, messageBody SQSMessageBody, phoneID, accessToken string) error {
+	to := whatsAppRecipient(messageBody.ChannelID)
+
+	var payloads []map[string]interface{}
+	caption := messageBody.Content
+	for _, attachment := range messageBody.Attachments {
+		mediaID, err := uploadWhatsAppMedia(ctx, phoneID, accessToken, attachment)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, buildWhatsAppImagePayload(to, mediaID, caption))
+		caption = ""
+	}
+	if len(payloads) == 0 {
+		if messageBody.Content == "" {
+			return fmt.Errorf("whatsapp messages need content or attachments")
+		}
+		payloads = append(payloads, buildWhatsAppTextPayload(to, messageBody.Content))
+	}
+
+	for _, payload := range payloads {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %w", err)
+		}
+		if _, err := postWhatsApp(ctx, phoneID, "messages", accessToken, payloadJSON, "application/json"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processWhatsAppMessage sends a validated queue message through the WhatsApp Cloud API
+func processWhatsAppMessage(ctx context.Context, messageBody SQSMessageBody, stage string) error {
+	accessToken, err := getWhatsAppAccessToken(stage)
+	if err != nil {
+		return fmt.Errorf("failed to get WhatsApp access token: %w", err)
+	}
+	phoneID, err := getWhatsAppPhoneID(stage)
+	if err != nil {
+		return fmt.Errorf("failed to get WhatsApp phone number ID: %w", err)
+	}
+
+	sendCtx, cancel := sendContext(ctx, sendTimeout)
+	defer cancel()
+	if err := sendWhatsAppMessage(sendCtx, messageBody, phoneID, accessToken); err != nil {
+		return fmt.Errorf("failed to send message to WhatsApp: %w", err)
+	}
+
+	logging.FromContext(ctx).Printf("Successfully sent WhatsApp message to %s", whatsAppRecipient(messageBody.ChannelID))
+	return nil
+}
+
+// Discord public thread channel type
+const channelTypePublicThread = 11
+
+// buildThreadPayload builds the Discord "start thread without message" request body
+func buildThreadPayload(name string) map[string]interface{} {
+	if len(name) > 100 {
+		name = name[:100] // Discord limits thread names to 100 characters
+	}
+	return map[string]interface{}{
+		"name":                  name,
+		"type":                  channelTypePublicThread,
+		"auto_archive_duration": 10080, // 7 days
+	}
+}
+
+// createDiscordThread creates a public thread in a channel and returns the thread ID
+func createDiscordThread(ctx context.Context, channelID, name, botToken string) (string, error) {
+	payload, err := json.Marshal(buildThreadPayload(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal thread payload: %w", err)
+	}
+
+	url := fmt.Sprintf("https://discord.com/api/v10/channels/%s/threads", channelID)
+	threadCtx, cancel := sendContext(ctx, defaultThreadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(threadCtx, "POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bot %s", botToken))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", discordAPIError(resp.StatusCode, body)
+	}
+
+	var thread struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &thread); err != nil || thread.ID == "" {
+		return "", fmt.Errorf("failed to parse thread response: %s", string(body))
+	}
+
+	return thread.ID, nil
+}
+
+// resolveThreadReplay points the replayed configuring message at the new thread,
+// falling back to the original channel when the thread could not be created
+func resolveThreadReplay(replay map[string]interface{}, channelID, threadID string) map[string]interface{} {
+	target := make(map[string]interface{}, len(replay)+2)
+	for k, v := range replay {
+		target[k] = v
+	}
+
+	if threadID == "" {
+		target["channelId"] = channelID
+		delete(target, "parentChannelId")
+		return target
+	}
+
+	target["channelId"] = threadID
+	target["parentChannelId"] = channelID
+	return target
+}
+
+// forwardToConfiguringQueue replays a configuring message so the campaign starts in its thread
+func forwardToConfiguringQueue(replay map[string]interface{}) error {
+	if configuringQueueURL == "" {
+		return fmt.Errorf("SYRUS_CONFIGURING_QUEUE_URL environment variable not set")
+	}
+
+	channelID, _ := replay["channelId"].(string)
+	interactionID, _ := replay["interactionId"].(string)
+
+	replayJSON, err := json.Marshal(replay)
+	if err != nil {
+		return fmt.Errorf("failed to marshal replay message: %w", err)
+	}
+
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(configuringQueueURL),
+		MessageBody:            aws.String(string(replayJSON)),
+		MessageGroupId:         aws.String(channelID),
+		MessageDeduplicationId: aws.String(interactionID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send replay to configuring queue: %w", err)
+	}
+
+	return nil
+}
+
+// threadDedupPrefix namespaces the threads created per interaction in the shared dedup table
+const threadDedupPrefix = "messaging-thread"
+
+// Thread creation dependencies, overridden in tests
+var (
+	lookupThread  = func(id string) (string, bool, error) { return dedup.Lookup(threadDedupPrefix, id) }
+	storeThread   = func(id, threadID string) error { return dedup.Store(threadDedupPrefix, id, threadID, dedup.DefaultTTL) }
+	createThread  = createDiscordThread
+	forwardReplay = forwardToConfiguringQueue
+)
+
+// handleThreadRequest creates the campaign thread and replays the configuring message into it.
+// Returns the thread ID, or "" if the campaign fell back to the original channel.
+// The thread is recorded against the interaction, so when a later step fails and SQS redelivers
+// the message, the retry reuses the thread instead of opening a second one.
+func handleThreadRequest(ctx context.Context, channelID, interactionID string, thread *ThreadRequest, botToken string) (string, error) {
+	threadID := ""
+	if interactionID != "" {
+		existing, found, err := lookupThread(interactionID)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up thread for interaction %s: %w", interactionID, err)
+		}
+		if found {
+			logging.FromContext(ctx).Printf("Reusing thread %s already created for interaction %s", existing, interactionID)
+			threadID = existing
+		}
+	}
+
+	if threadID == "" {
+		created, err := createThread(ctx, channelID, thread.Name, botToken)
+		if err != nil {
+			logging.FromContext(ctx).Printf("Warning: failed to create thread in channel %s, falling back to channel: %v", channelID, err)
+		} else {
+			threadID = created
+			if interactionID != "" {
+				if err := storeThread(interactionID, threadID); err != nil {
+					logging.FromContext(ctx).Printf("Warning: failed to record thread %s for interaction %s: %v", threadID, interactionID, err)
 				}
+			}
+		}
+	}
+
+	if err := forwardReplay(resolveThreadReplay(thread.Replay, channelID, threadID)); err != nil {
+		return "", err
+	}
 
-				log.Printf("Successfully sent message after rate limit retry")
-				return nil
+	return threadID, nil
+}
+
+// discordContentLimit is the most characters Discord accepts in a message's content
+const discordContentLimit = 2000
+
+// fenceMarker opens and closes a markdown code block
+const fenceMarker = "```"
+
+// dedupPrefix namespaces this lambda's records in the shared dedup table
+const dedupPrefix = "messaging"
+
+// Part delivery dependencies, overridden in tests
+var (
+	partDelivered     = func(id string) (bool, error) { return dedup.Check(dedupPrefix, id) }
+	markPartDelivered = func(id string) error { return dedup.Mark(dedupPrefix, id, dedup.DefaultTTL) }
+	deliverPart       = sendDiscordMessage
+)
+
+// openFence finds a code block left open at the end of text, returning the byte offset of the line
+// that opened it and that line (e.g. "```go"); offset is -1 when every block is closed
+func openFence(text string) (int, string) {
+	offset, header := -1, ""
+	lineStart := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, fenceMarker) {
+			if offset < 0 {
+				offset, header = lineStart, trimmed
+			} else {
+				offset, header = -1, ""
 			}
 		}
+		lineStart += len(line)
+	}
+	return offset, header
+}
 
-		return fmt.Errorf("discord API returned status %d: %s", resp.StatusCode, string(body))
+// runeOffset returns the byte offset of the n-th rune in text, or len(text) if it is shorter
+func runeOffset(text string, n int) int {
+	count := 0
+	for i := range text {
+		if count == n {
+			return i
+		}
+		count++
 	}
+	return len(text)
+}
 
+// breakPoint picks where to end a chunk of window, preferring a paragraph break, then a line break,
+// then the end of a sentence, then a space, in the latter half of the window. It returns the end of
+// the chunk and the start of the remainder, which skips the whitespace broken at.
+func breakPoint(window string) (int, int) {
+	floor := len(window) / 2
+	if i := strings.LastIndex(window, "\n\n"); i > floor {
+		return i, i + 2
+	}
+	if i := strings.LastIndex(window, "\n"); i > floor {
+		return i, i + 1
+	}
+	sentence := -1
+	for _, end := range []string{". ", "! ", "? "} {
+		if i := strings.LastIndex(window, end); i > sentence {
+			sentence = i
+		}
+	}
+	if sentence > floor {
+		return sentence + 1, sentence + 2
+	}
+	if i := strings.LastIndex(window, " "); i > floor {
+		return i, i + 1
+	}
+	return len(window), len(window)
+}
+
+// splitContent breaks content into chunks of at most limit characters at paragraph or sentence
+// boundaries. A chunk ends before a code block rather than inside it; a block too long for one chunk
+// is closed at the break and reopened, with its language, at the start of the next.
+func splitContent(content string, limit int) []string {
+	var chunks []string
+	remaining := content
+	for utf8.RuneCountInString(remaining) > limit {
+		// Leave room to close a code block split across chunks
+		window := remaining[:runeOffset(remaining, limit-len("\n"+fenceMarker))]
+		end, next := breakPoint(window)
+		if fence, _ := openFence(window[:end]); fence > 0 && strings.TrimSpace(window[:fence]) != "" {
+			end, next = fence, fence
+		}
+
+		chunk := strings.TrimRight(window[:end], " \n")
+		rest := strings.TrimLeft(remaining[next:], "\n")
+		if fence, header := openFence(chunk); fence >= 0 {
+			chunk += "\n" + fenceMarker
+			rest = header + "\n" + rest
+		}
+		chunks = append(chunks, chunk)
+		remaining = rest
+	}
+	return append(chunks, remaining)
+}
+
+// discordPart is one message of a reply split across several
+type discordPart struct {
+	Message     DiscordMessage
+	Attachments []Attachment
+	Followup    bool
+}
+
+// buildDiscordParts splits a message whose content is over limit into parts sent in order. The first
+// part resolves the interaction the way the whole message would have; the rest follow up on it. Embeds,
+// components and attachments ride on the last part so they follow the text they belong to.
+func buildDiscordParts(message DiscordMessage, attachments []Attachment, followup bool, limit int) []discordPart {
+	chunks := splitContent(message.Content, limit)
+	parts := make([]discordPart, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = discordPart{
+			Message:  DiscordMessage{Content: chunk, Flags: message.Flags},
+			Followup: followup || i > 0,
+		}
+	}
+	last := &parts[len(parts)-1]
+	last.Message.Embeds = message.Embeds
+	last.Message.Components = message.Components
+	last.Attachments = attachments
+	return parts
+}
+
+// partDedupID names part i of a queued message so a retry skips the parts already posted
+func partDedupID(message events.SQSMessage, i int) string {
+	id := message.Attributes["MessageDeduplicationId"]
+	if id == "" {
+		id = message.MessageId
+	}
+	return fmt.Sprintf("%s-part%d", id, i)
+}
+
+// sendDiscordParts posts each part in order, recording those delivered so a retry after a failed
+// part resumes where it stopped. Parts are sent inline rather than requeued: the channel's message
+// group may already hold later replies, and requeued parts would land behind them.
+func sendDiscordParts(ctx context.Context, message events.SQSMessage, channelID string, parts []discordPart, botToken, interactionToken, applicationID string) error {
+	logger := logging.FromContext(ctx)
+	for i, part := range parts {
+		id := partDedupID(message, i)
+		delivered, err := partDelivered(id)
+		if err != nil {
+			return fmt.Errorf("failed to check delivery of %s: %w", id, err)
+		}
+		if delivered {
+			logger.Printf("Skipping %s, already delivered", id)
+			continue
+		}
+
+		sendCtx, cancel := sendContext(ctx, sendTimeout)
+		err = deliverPart(sendCtx, channelID, part.Message, botToken, interactionToken, applicationID, part.Followup, part.Attachments)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to send part %d of %d: %w", i+1, len(parts), err)
+		}
+		if err := markPartDelivered(id); err != nil {
+			logger.Printf("Warning: failed to write to dedup table: %v", err)
+		}
+	}
 	return nil
 }
 
 // processSQSMessage processes a single SQS message
-func processSQSMessage(message events.SQSMessage, botToken string, stage string) error {
+func processSQSMessage(ctx context.Context, message events.SQSMessage, botToken string, stage string) error {
 	// Parse message body
 	var messageBody SQSMessageBody
 	if err := json.Unmarshal([]byte(message.Body), &messageBody); err != nil {
 		return fmt.Errorf("failed to parse message body: %w", err)
 	}
+	ctx = logging.WithContext(ctx, logging.For(messageBody.InteractionID, ""))
 
 	// Validate required fields
 	if messageBody.ChannelID == "" {
@@ -306,6 +889,14 @@ func processSQSMessage(message events.SQSMessage, botToken string, stage string)
 		return fmt.Errorf("missing required field: content, embeds, or attachments")
 	}
 
+	switch messageBody.Platform {
+	case "", platformDiscord:
+	case platformWhatsApp:
+		return processWhatsAppMessage(ctx, messageBody, stage)
+	default:
+		return fmt.Errorf("unknown platform: %s", messageBody.Platform)
+	}
+
 	// Build Discord message
 	discordMsg := DiscordMessage{
 		Content: messageBody.Content,
@@ -320,6 +911,17 @@ func processSQSMessage(message events.SQSMessage, botToken string, stage string)
 		discordMsg.Flags = messageBody.Flags
 	}
 
+	// Create the campaign thread first so the response can point players at it
+	if messageBody.CreateThread != nil {
+		threadID, err := handleThreadRequest(ctx, messageBody.ChannelID, messageBody.InteractionID, messageBody.CreateThread, botToken)
+		if err != nil {
+			return fmt.Errorf("failed to handle thread request: %w", err)
+		}
+		if threadID != "" {
+			discordMsg.Content = fmt.Sprintf("%s\n<#%s>", discordMsg.Content, threadID)
+		}
+	}
+
 	// Get application ID from SSM if we have an interaction token
 	var applicationID string
 	if messageBody.InteractionToken != "" {
@@ -330,17 +932,29 @@ func processSQSMessage(message events.SQSMessage, botToken string, stage string)
 		applicationID = appID
 	}
 
+	// Long content goes out as several messages
+	if utf8.RuneCountInString(discordMsg.Content) > discordContentLimit {
+		parts := buildDiscordParts(discordMsg, messageBody.Attachments, messageBody.Followup, discordContentLimit)
+		if err := sendDiscordParts(ctx, message, messageBody.ChannelID, parts, botToken, messageBody.InteractionToken, applicationID); err != nil {
+			return fmt.Errorf("failed to send message to Discord: %w", err)
+		}
+		logging.FromContext(ctx).Printf("Successfully sent %d-part message to channel %s", len(parts), messageBody.ChannelID)
+		return nil
+	}
+
 	// Send to Discord
-	if err := sendDiscordMessage(messageBody.ChannelID, discordMsg, botToken, messageBody.InteractionToken, applicationID, messageBody.Attachments); err != nil {
+	sendCtx, cancel := sendContext(ctx, sendTimeout)
+	defer cancel()
+	if err := sendDiscordMessage(sendCtx, messageBody.ChannelID, discordMsg, botToken, messageBody.InteractionToken, applicationID, messageBody.Followup, messageBody.Attachments); err != nil {
 		return fmt.Errorf("failed to send message to Discord: %w", err)
 	}
 
-	log.Printf("Successfully sent message to channel %s", messageBody.ChannelID)
+	logging.FromContext(ctx).Printf("Successfully sent message to channel %s", messageBody.ChannelID)
 	return nil
 }
 
-// handleSQSRequest handles SQS events
-func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) error {
+// handleSQSRequest handles SQS events, reporting failed messages so SQS retries only those
+func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
 	// Get stage from environment
 	stage := os.Getenv("SYRUS_STAGE")
 	if stage == "" {
@@ -350,29 +964,26 @@ func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) error {
 	// Get Discord bot token from SSM (cache it for the batch)
 	botToken, err := getDiscordBotToken(stage)
 	if err != nil {
-		return fmt.Errorf("failed to get Discord bot token: %w", err)
+		return events.SQSEventResponse{}, fmt.Errorf("failed to get Discord bot token: %w", err)
 	}
 
-	// Process each message in the batch
-	var errors []error
-	for _, record := range sqsEvent.Records {
+	// Channels are message groups, so each channel's messages are still delivered in order
+	failures := sqsbatch.Process(ctx, sqsEvent.Records, sqsConcurrency, func(ctx context.Context, record events.SQSMessage) error {
 		log.Printf("Processing message: %s", record.MessageId)
 
-		if err := processSQSMessage(record, botToken, stage); err != nil {
-			log.Printf("Error processing message %s: %v", record.MessageId, err)
-			errors = append(errors, fmt.Errorf("message %s: %w", record.MessageId, err))
-			// Continue processing other messages
+		err := processSQSMessage(ctx, record, botToken, stage)
+		// The channel or message is gone; retrying would only walk the message to the DLQ
+		if isTerminalDiscordError(err) {
+			log.Printf("Dropping message %s for a deleted Discord channel or message: %v", record.MessageId, err)
+			return nil
 		}
-	}
-
-	// If any messages failed, return error (SQS will retry failed messages)
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to process %d message(s): %v", len(errors), errors)
-	}
+		return err
+	})
 
-	return nil
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
 }
 
 func main() {
+	logging.Init("messaging")
 	lambda.Start(handleSQSRequest)
 }
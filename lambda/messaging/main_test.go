@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -329,3 +337,506 @@ func TestSQSMessageBody_WithFlags(t *testing.T) {
 	}
 }
 
+func TestBuildThreadPayload(t *testing.T) {
+	payload := buildThreadPayload("Syrus Campaign")
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Failed to marshal thread payload: %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(payloadJSON, &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal thread payload: %v", err)
+	}
+
+	if parsed["name"] != "Syrus Campaign" {
+		t.Errorf("Expected name 'Syrus Campaign', got %v", parsed["name"])
+	}
+	if parsed["type"] != float64(11) {
+		t.Errorf("Expected public thread type 11, got %v", parsed["type"])
+	}
+	if parsed["auto_archive_duration"] != float64(10080) {
+		t.Errorf("Expected auto_archive_duration 10080, got %v", parsed["auto_archive_duration"])
+	}
+
+	// Names are truncated to Discord's 100 character limit
+	longName := ""
+	for i := 0; i < 150; i++ {
+		longName += "x"
+	}
+	if name := buildThreadPayload(longName)["name"].(string); len(name) != 100 {
+		t.Errorf("Expected thread name truncated to 100 characters, got %d", len(name))
+	}
+}
+
+func TestResolveThreadReplay(t *testing.T) {
+	replay := map[string]interface{}{
+		"channelId":     "channel_1",
+		"hostId":        "host_1",
+		"interactionId": "int_1-thread",
+		"options":       []interface{}{map[string]interface{}{"name": "start"}},
+	}
+
+	t.Run("thread created", func(t *testing.T) {
+		target := resolveThreadReplay(replay, "channel_1", "thread_1")
+		if target["channelId"] != "thread_1" {
+			t.Errorf("Expected channelId thread_1, got %v", target["channelId"])
+		}
+		if target["parentChannelId"] != "channel_1" {
+			t.Errorf("Expected parentChannelId channel_1, got %v", target["parentChannelId"])
+		}
+		if target["hostId"] != "host_1" || target["interactionId"] != "int_1-thread" {
+			t.Errorf("Expected other replay fields preserved, got %v", target)
+		}
+	})
+
+	t.Run("thread creation failed falls back to channel", func(t *testing.T) {
+		target := resolveThreadReplay(replay, "channel_1", "")
+		if target["channelId"] != "channel_1" {
+			t.Errorf("Expected fallback channelId channel_1, got %v", target["channelId"])
+		}
+		if _, ok := target["parentChannelId"]; ok {
+			t.Errorf("Expected no parentChannelId on fallback, got %v", target["parentChannelId"])
+		}
+	})
+
+	if replay["channelId"] != "channel_1" {
+		t.Error("Original replay message should not be mutated")
+	}
+}
+
+func TestSQSMessageBody_WithCreateThread(t *testing.T) {
+	body := `{"channelId":"channel_1","content":"Opening a thread","createThread":{"name":"Syrus Campaign","replay":{"channelId":"channel_1","interactionId":"int_1-thread"}}}`
+
+	var parsed SQSMessageBody
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		t.Fatalf("Failed to parse message body: %v", err)
+	}
+
+	if parsed.CreateThread == nil {
+		t.Fatal("Expected createThread to be parsed")
+	}
+	if parsed.CreateThread.Name != "Syrus Campaign" {
+		t.Errorf("Expected thread name 'Syrus Campaign', got %s", parsed.CreateThread.Name)
+	}
+	if parsed.CreateThread.Replay["interactionId"] != "int_1-thread" {
+		t.Errorf("Expected replay interactionId int_1-thread, got %v", parsed.CreateThread.Replay["interactionId"])
+	}
+}
+
+func TestHandleThreadRequestRetry(t *testing.T) {
+	originalLookup, originalStore, originalCreate, originalForward := lookupThread, storeThread, createThread, forwardReplay
+	t.Cleanup(func() {
+		lookupThread, storeThread, createThread, forwardReplay = originalLookup, originalStore, originalCreate, originalForward
+	})
+
+	stored := map[string]string{}
+	lookupThread = func(id string) (string, bool, error) {
+		threadID, ok := stored[id]
+		return threadID, ok, nil
+	}
+	storeThread = func(id, threadID string) error {
+		stored[id] = threadID
+		return nil
+	}
+	created := 0
+	createThread = func(_ context.Context, channelID, name, _ string) (string, error) {
+		created++
+		return fmt.Sprintf("thread_%d", created), nil
+	}
+	var forwarded []map[string]interface{}
+	forwardErr := fmt.Errorf("configuring queue unavailable")
+	forwardReplay = func(replay map[string]interface{}) error {
+		forwarded = append(forwarded, replay)
+		return forwardErr
+	}
+
+	thread := &ThreadRequest{Name: "Syrus Campaign", Replay: map[string]interface{}{"channelId": "channel_1", "interactionId": "int_1-thread"}}
+	if _, err := handleThreadRequest(context.Background(), "channel_1", "int_1", thread, "bot"); err == nil {
+		t.Fatal("Expected the failed replay to fail the message for retry")
+	}
+
+	// SQS redelivers the message; the retry must not open a second thread
+	forwardErr = nil
+	threadID, err := handleThreadRequest(context.Background(), "channel_1", "int_1", thread, "bot")
+	if err != nil {
+		t.Fatalf("Unexpected error on retry: %v", err)
+	}
+	if created != 1 || threadID != "thread_1" {
+		t.Errorf("Expected the retry to reuse thread_1, got %s after %d creations", threadID, created)
+	}
+	if len(forwarded) != 2 || forwarded[1]["channelId"] != "thread_1" {
+		t.Errorf("Expected the retried replay pointed at thread_1, got %v", forwarded)
+	}
+
+	// Without an interaction to key on, every delivery creates its own thread
+	if threadID, _ := handleThreadRequest(context.Background(), "channel_1", "", thread, "bot"); threadID != "thread_2" || len(stored) != 1 {
+		t.Errorf("Expected an unkeyed request to create thread_2 without recording it, got %s and %v", threadID, stored)
+	}
+}
+
+func TestResolveDiscordEndpoint(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		appID          string
+		followup       bool
+		expectedURL    string
+		expectedMethod string
+	}{
+		{"channel message", "", "", false, "https://discord.com/api/v10/channels/chan/messages", "POST"},
+		{"edit original response", "tok", "app", false, "https://discord.com/api/v10/webhooks/app/tok/messages/@original", "PATCH"},
+		{"follow-up message", "tok", "app", true, "https://discord.com/api/v10/webhooks/app/tok", "POST"},
+		{"follow-up without token falls back to channel", "", "", true, "https://discord.com/api/v10/channels/chan/messages", "POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, method := resolveDiscordEndpoint("chan", tt.token, tt.appID, tt.followup)
+			if url != tt.expectedURL || method != tt.expectedMethod {
+				t.Errorf("Expected %s %s, got %s %s", tt.expectedMethod, tt.expectedURL, method, url)
+			}
+		})
+	}
+}
+
+func TestDiscordAPIErrorTerminalCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		terminal bool
+	}{
+		{"unknown channel", 404, `{"message": "Unknown Channel", "code": 10003}`, true},
+		{"unknown message", 404, `{"message": "Unknown Message", "code": 10008}`, true},
+		{"unknown webhook", 404, `{"message": "Unknown Webhook", "code": 10015}`, false},
+		{"missing access", 403, `{"message": "Missing Access", "code": 50001}`, false},
+		{"unknown channel code on non-404", 400, `{"message": "Unknown Channel", "code": 10003}`, false},
+		{"server error", 502, `<html>Bad Gateway</html>`, false},
+		{"rate limited", 429, `{"message": "You are being rate limited.", "retry_after": 1.5}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := discordAPIError(tt.status, []byte(tt.body))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			// processSQSMessage wraps send errors, so terminal detection must see through %w
+			wrapped := fmt.Errorf("failed to send message to Discord: %w", err)
+			if isTerminalDiscordError(wrapped) != tt.terminal {
+				t.Errorf("Expected terminal=%v for %d %s, got %v", tt.terminal, tt.status, tt.body, !tt.terminal)
+			}
+		})
+	}
+}
+
+func TestSendContextBoundedByHandlerDeadline(t *testing.T) {
+	t.Run("no handler deadline uses timeout", func(t *testing.T) {
+		ctx, cancel := sendContext(context.Background(), 5*time.Second)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("Expected a send deadline")
+		}
+		if remaining := time.Until(deadline); remaining > 5*time.Second || remaining < 4*time.Second {
+			t.Errorf("Expected deadline about 5s away, got %s", remaining)
+		}
+	})
+
+	t.Run("handler deadline less margin wins", func(t *testing.T) {
+		handlerDeadline := time.Now().Add(10 * time.Second)
+		parent, parentCancel := context.WithDeadline(context.Background(), handlerDeadline)
+		defer parentCancel()
+
+		ctx, cancel := sendContext(parent, defaultSendTimeout)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		if expected := handlerDeadline.Add(-sendDeadlineMargin); !deadline.Equal(expected) {
+			t.Errorf("Expected deadline %s, got %s", expected, deadline)
+		}
+	})
+
+	t.Run("handler nearly out of time", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), sendDeadlineMargin/2)
+		defer parentCancel()
+
+		ctx, cancel := sendContext(parent, defaultSendTimeout)
+		defer cancel()
+		if ctx.Err() == nil {
+			t.Error("Expected the send context to be expired when less than the margin remains")
+		}
+	})
+}
+
+func TestParseSendTimeout(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected time.Duration
+	}{
+		{"", defaultSendTimeout},
+		{"12", 12 * time.Second},
+		{"0", defaultSendTimeout},
+		{"-3", defaultSendTimeout},
+		{"soon", defaultSendTimeout},
+	}
+
+	for _, tt := range tests {
+		if got := parseSendTimeout(tt.raw); got != tt.expected {
+			t.Errorf("parseSendTimeout(%q) = %s, expected %s", tt.raw, got, tt.expected)
+		}
+	}
+}
+
+func TestSendWithRateLimitRetry(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message": "You are being rate limited.", "retry_after": 0.01}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": "42"}`)
+	}))
+	defer server.Close()
+
+	newRequest := func() (*http.Request, error) {
+		return http.NewRequest("POST", server.URL, strings.NewReader(`{"content":"hello"}`))
+	}
+	body, err := sendWithRateLimitRetry(context.Background(), newRequest, discordRetryAfter, discordAPIError)
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if string(body) != `{"id": "42"}` {
+		t.Errorf("Expected the successful response body, got %s", body)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"content":"hello"}` {
+		t.Errorf("Expected the retry to resend the full payload, got %q", bodies)
+	}
+}
+
+func TestSendWhatsAppMessage(t *testing.T) {
+	type request struct {
+		path        string
+		auth        string
+		contentType string
+		body        string
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Type"), string(body)})
+		if strings.HasSuffix(r.URL.Path, "/media") {
+			fmt.Fprint(w, `{"id": "media-1"}`)
+			return
+		}
+		fmt.Fprint(w, `{"messages": [{"id": "wamid.1"}]}`)
+	}))
+	defer server.Close()
+
+	original := whatsAppGraphURL
+	whatsAppGraphURL = server.URL
+	defer func() { whatsAppGraphURL = original }()
+
+	t.Run("text", func(t *testing.T) {
+		requests = nil
+		message := SQSMessageBody{ChannelID: "15551234567@c.us", Content: "The loom stirs.", Platform: platformWhatsApp}
+		if err := sendWhatsAppMessage(context.Background(), message, "phone-1", "wa-token"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(requests) != 1 || requests[0].path != "/phone-1/messages" || requests[0].auth != "Bearer wa-token" {
+			t.Fatalf("Expected one authorised send from the business number, got %+v", requests)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(requests[0].body), &payload); err != nil {
+			t.Fatalf("Invalid payload: %v", err)
+		}
+		if payload["to"] != "15551234567" || payload["type"] != "text" || payload["text"].(map[string]interface{})["body"] != "The loom stirs." {
+			t.Errorf("Expected a text message to the bare number, got %v", payload)
+		}
+	})
+
+	t.Run("image with caption", func(t *testing.T) {
+		requests = nil
+		message := SQSMessageBody{
+			ChannelID:   "15551234567",
+			Content:     "The bell rises.",
+			Platform:    platformWhatsApp,
+			Attachments: []Attachment{{Name: "bell.png", Data: base64.StdEncoding.EncodeToString([]byte("png")), ContentType: "image/png"}},
+		}
+		if err := sendWhatsAppMessage(context.Background(), message, "phone-1", "wa-token"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(requests) != 2 || requests[0].path != "/phone-1/media" || !strings.HasPrefix(requests[0].contentType, "multipart/form-data") {
+			t.Fatalf("Expected the image uploaded before sending, got %+v", requests)
+		}
+		if !strings.Contains(requests[0].body, "png") {
+			t.Errorf("Expected the attachment bytes uploaded, got %q", requests[0].body)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(requests[1].body), &payload); err != nil {
+			t.Fatalf("Invalid payload: %v", err)
+		}
+		image, _ := payload["image"].(map[string]interface{})
+		if payload["type"] != "image" || image["id"] != "media-1" || image["caption"] != "The bell rises." {
+			t.Errorf("Expected the uploaded image sent with the content as caption, got %v", payload)
+		}
+	})
+}
+
+func TestProcessSQSMessage_UnknownPlatform(t *testing.T) {
+	body, _ := json.Marshal(SQSMessageBody{ChannelID: "c", Content: "hello", Platform: "carrier-pigeon"})
+	err := processSQSMessage(context.Background(), events.SQSMessage{Body: string(body)}, "token", "test")
+	if err == nil || !strings.Contains(err.Error(), "unknown platform") {
+		t.Errorf("Expected an unknown platform error, got %v", err)
+	}
+}
+
+func TestSplitContent(t *testing.T) {
+	short := "The lantern gutters."
+	if got := splitContent(short, 2000); len(got) != 1 || got[0] != short {
+		t.Errorf("Expected short content unchanged, got %q", got)
+	}
+
+	paragraph := strings.Repeat("a", 60)
+	content := paragraph + "\n\n" + paragraph + "\n\n" + paragraph
+	got := splitContent(content, 100)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d: %q", len(got), got)
+	}
+	for i, chunk := range got {
+		if chunk != paragraph {
+			t.Errorf("Expected chunk %d to be a whole paragraph, got %q", i, chunk)
+		}
+	}
+
+	sentences := strings.Repeat("The road bends. ", 10)
+	for _, chunk := range splitContent(strings.TrimSpace(sentences), 50) {
+		if !strings.HasSuffix(chunk, ".") {
+			t.Errorf("Expected chunks to end at a sentence, got %q", chunk)
+		}
+	}
+
+	// Multi-byte runes count once towards the limit
+	runes := strings.Repeat("é", 150)
+	got = splitContent(runes, 100)
+	if len(got) != 2 || strings.Join(got, "") != runes {
+		t.Errorf("Expected rune-aware hard split, got %q", got)
+	}
+}
+
+func TestSplitContentCodeFences(t *testing.T) {
+	// A code block that fits in a chunk moves whole to the next one
+	intro := strings.Repeat("word ", 15)
+	block := "```go\nfmt.Println(\"hi\")\n```"
+	got := splitContent(intro+"\n"+block, 90)
+	if len(got) != 2 || got[1] != block {
+		t.Fatalf("Expected the block kept whole in its own chunk, got %q", got)
+	}
+
+	// A block too long for one chunk is closed and reopened with its language
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %02d", i))
+	}
+	long := "```go\n" + strings.Join(lines, "\n") + "\n```"
+	got = splitContent(long, 60)
+	if len(got) < 2 {
+		t.Fatalf("Expected the block to be split, got %q", got)
+	}
+	for i, chunk := range got {
+		if n := len([]rune(chunk)); n > 60 {
+			t.Errorf("Chunk %d is %d characters, over the limit", i, n)
+		}
+		if !strings.HasPrefix(chunk, "```go\n") || !strings.HasSuffix(chunk, "\n```") {
+			t.Errorf("Expected chunk %d to be a complete go block, got %q", i, chunk)
+		}
+		if fence, _ := openFence(chunk); fence >= 0 {
+			t.Errorf("Expected chunk %d to close its block, got %q", i, chunk)
+		}
+	}
+}
+
+func TestBuildDiscordParts(t *testing.T) {
+	message := DiscordMessage{
+		Content:    strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 60),
+		Embeds:     []map[string]interface{}{{"title": "Act II"}},
+		Components: []map[string]interface{}{{"type": 1}},
+		Flags:      64,
+	}
+	attachments := []Attachment{{Name: "scene.png"}}
+
+	parts := buildDiscordParts(message, attachments, false, 100)
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 parts, got %d", len(parts))
+	}
+	if parts[0].Followup || !parts[1].Followup {
+		t.Errorf("Expected only later parts to follow up, got %v and %v", parts[0].Followup, parts[1].Followup)
+	}
+	if parts[0].Message.Embeds != nil || parts[0].Message.Components != nil || parts[0].Attachments != nil {
+		t.Errorf("Expected the first part to carry text only, got %+v", parts[0])
+	}
+	if len(parts[1].Message.Embeds) != 1 || len(parts[1].Message.Components) != 1 || len(parts[1].Attachments) != 1 {
+		t.Errorf("Expected the last part to carry embeds, components and attachments, got %+v", parts[1])
+	}
+	for i, part := range parts {
+		if part.Message.Flags != 64 {
+			t.Errorf("Expected part %d to keep flags, got %d", i, part.Message.Flags)
+		}
+	}
+}
+
+func TestSendDiscordParts(t *testing.T) {
+	originalDelivered, originalMark, originalDeliver := partDelivered, markPartDelivered, deliverPart
+	t.Cleanup(func() {
+		partDelivered, markPartDelivered, deliverPart = originalDelivered, originalMark, originalDeliver
+	})
+
+	recorded := map[string]bool{"dedup-1-part0": true}
+	partDelivered = func(id string) (bool, error) { return recorded[id], nil }
+	markPartDelivered = func(id string) error {
+		recorded[id] = true
+		return nil
+	}
+
+	var sent []string
+	failOn := "part 2"
+	deliverPart = func(_ context.Context, _ string, message DiscordMessage, _, _, _ string, _ bool, _ []Attachment) error {
+		if message.Content == failOn {
+			return fmt.Errorf("discord unavailable")
+		}
+		sent = append(sent, message.Content)
+		return nil
+	}
+
+	record := events.SQSMessage{MessageId: "msg-1", Attributes: map[string]string{"MessageDeduplicationId": "dedup-1"}}
+	parts := []discordPart{
+		{Message: DiscordMessage{Content: "part 0"}},
+		{Message: DiscordMessage{Content: "part 1"}},
+		{Message: DiscordMessage{Content: "part 2"}},
+	}
+
+	if err := sendDiscordParts(context.Background(), record, "chan", parts, "bot", "", ""); err == nil {
+		t.Fatal("Expected the failed part to be returned for retry")
+	}
+	if len(sent) != 1 || sent[0] != "part 1" {
+		t.Errorf("Expected only part 1 sent before the failure, got %q", sent)
+	}
+
+	// The retry resumes at the failed part
+	failOn = ""
+	sent = nil
+	if err := sendDiscordParts(context.Background(), record, "chan", parts, "bot", "", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sent) != 1 || sent[0] != "part 2" {
+		t.Errorf("Expected the retry to send only part 2, got %q", sent)
+	}
+
+	if got := partDedupID(events.SQSMessage{MessageId: "msg-9"}, 3); got != "msg-9-part3" {
+		t.Errorf("Expected fallback to the message ID, got %s", got)
+	}
+}
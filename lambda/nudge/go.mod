@@ -0,0 +1,21 @@
+module syrus-nudge
+
+go 1.23
+
+toolchain go1.23.4
+
+replace loros/syrus-models => ../../lib/go/models
+
+replace loros/syrus-logging => ../../lib/go/logging
+
+replace loros/syrus-dedup => ../../lib/go/dedup
+
+require (
+	github.com/aws/aws-lambda-go v1.47.0
+	github.com/aws/aws-sdk-go v1.55.5
+	loros/syrus-dedup v0.0.0-00010101000000-000000000000
+	loros/syrus-logging v0.0.0-00010101000000-000000000000
+	loros/syrus-models v0.0.0-00010101000000-000000000000
+)
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
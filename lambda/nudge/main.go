@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
+	models "loros/syrus-models"
+)
+
+var (
+	awsSession     *session.Session
+	dynamodbClient *dynamodb.DynamoDB
+	sqsClient      *sqs.SQS
+	campaignsTable string
+	messagingQueue string
+)
+
+func init() {
+	awsSession = session.Must(session.NewSession())
+	dynamodbClient = dynamodb.New(awsSession)
+	sqsClient = sqs.New(awsSession)
+
+	campaignsTable = os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	messagingQueue = os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+}
+
+// handler runs on a schedule and nudges idle asynchronous campaigns. Unlike pausing, a nudge never changes
+// campaign state - it only posts a reminder, at most once per nudge interval.
+func handler(ctx context.Context, event events.CloudWatchEvent) error {
+	now := time.Now().UTC()
+
+	campaigns, err := listAsyncCampaigns()
+	if err != nil {
+		return fmt.Errorf("failed to list asynchronous campaigns: %w", err)
+	}
+
+	nudged := 0
+	for i := range campaigns {
+		campaign := &campaigns[i]
+		if !nudgeEligible(campaign, now) {
+			continue
+		}
+		if err := nudgeCampaign(campaign, now); err != nil {
+			log.Printf("Warning: failed to nudge campaign %s: %v", campaign.CampaignID, err)
+			continue
+		}
+		nudged++
+	}
+
+	log.Printf("Nudge sweep complete: %d asynchronous campaigns checked, %d nudged", len(campaigns), nudged)
+	return nil
+}
+
+// lastActivity is the most recent sign of play: the last campaign update, declaration heartbeat, or pending declaration
+func lastActivity(campaign *models.Campaign) time.Time {
+	latest := campaign.LastUpdatedAt
+	if last := campaign.Runtime.LastDeclarationAt; last != nil && last.After(latest) {
+		latest = *last
+	}
+	for _, pending := range campaign.Runtime.TurnState.PendingDeclarations {
+		if pending.DeclaredAt.After(latest) {
+			latest = pending.DeclaredAt
+		}
+	}
+	return latest
+}
+
+// nudgeEligible reports whether an asynchronous campaign has been idle for its nudge interval and hasn't
+// been nudged within that interval
+func nudgeEligible(campaign *models.Campaign, now time.Time) bool {
+	if campaign.EffectivePlayStyle() != models.PlayStyleAsynchronous {
+		return false
+	}
+	if campaign.Status != models.CampaignStatusActive && campaign.Status != models.CampaignStatusPlaying {
+		return false
+	}
+	if campaign.Lifecycle.Paused {
+		return false
+	}
+
+	interval := campaign.NudgeIntervalDuration()
+	if now.Sub(lastActivity(campaign)) < interval {
+		return false
+	}
+	if lastNudged := campaign.Runtime.LastNudgedAt; lastNudged != nil && now.Sub(*lastNudged) < interval {
+		return false
+	}
+	return true
+}
+
+// awaitingPlayers returns the party members who haven't declared in the open batch
+func awaitingPlayers(campaign *models.Campaign) []string {
+	declared := make(map[string]bool)
+	for _, pending := range campaign.Runtime.TurnState.PendingDeclarations {
+		declared[pending.UserID] = true
+	}
+
+	var awaiting []string
+	for _, member := range campaign.Party.Members {
+		if !declared[member.UserID] {
+			awaiting = append(awaiting, member.UserID)
+		}
+	}
+	return awaiting
+}
+
+// buildNudgeMessage renders the in-character reminder, calling on the players the story is waiting for
+func buildNudgeMessage(campaign *models.Campaign) string {
+	var mentions []string
+	for _, userID := range awaitingPlayers(campaign) {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+	}
+
+	message := "*The weave awaits your move.* The threads have lain still too long, and the tale cannot unfold without you. Use `/syrus declare` to step back into the story."
+	if len(mentions) > 0 {
+		message = strings.Join(mentions, " ") + "\n\n" + message
+	}
+	return message
+}
+
+// nudgeCampaign records the nudge and posts it. The conditional write makes the one-nudge-per-interval
+// guarantee hold even if sweeps overlap; the nudge is skipped (not retried) if another sweep won.
+func nudgeCampaign(campaign *models.Campaign, now time.Time) error {
+	cutoff := now.Add(-campaign.NudgeIntervalDuration())
+	if err := markNudged(campaign.CampaignID, now, cutoff); err != nil {
+		if dedup.IsConditionalCheckFailed(err) {
+			log.Printf("Campaign %s was already nudged this interval, skipping", campaign.CampaignID)
+			return nil
+		}
+		return err
+	}
+
+	if err := sendToMessagingQueue(campaign.ReplyChannelID(), buildNudgeMessage(campaign), now); err != nil {
+		return err
+	}
+
+	log.Printf("Nudged idle asynchronous campaign %s", campaign.CampaignID)
+	return nil
+}
+
+// listAsyncCampaigns scans for asynchronous campaigns that are in play
+func listAsyncCampaigns() ([]models.Campaign, error) {
+	var campaigns []models.Campaign
+	var scanErr error
+
+	err := dynamodbClient.ScanPages(&dynamodb.ScanInput{
+		TableName:        aws.String(campaignsTable),
+		FilterExpression: aws.String("#playStyle = :async AND #status IN (:active, :playing)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#playStyle": aws.String("playStyle"),
+			"#status":    aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":async":   {S: aws.String(string(models.PlayStyleAsynchronous))},
+			":active":  {S: aws.String(string(models.CampaignStatusActive))},
+			":playing": {S: aws.String(string(models.CampaignStatusPlaying))},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pageCampaigns []models.Campaign
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageCampaigns); err != nil {
+			scanErr = fmt.Errorf("failed to unmarshal campaigns: %w", err)
+			return false
+		}
+		campaigns = append(campaigns, pageCampaigns...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return campaigns, nil
+}
+
+// markNudged records the nudge time, failing the condition if the campaign was nudged after cutoff
+func markNudged(campaignID string, now, cutoff time.Time) error {
+	_, err := dynamodbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:    aws.String("SET #runtime.#lastNudgedAt = :now"),
+		ConditionExpression: aws.String("attribute_not_exists(#runtime.#lastNudgedAt) OR #runtime.#lastNudgedAt <= :cutoff"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":      aws.String("runtime"),
+			"#lastNudgedAt": aws.String("lastNudgedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now":    {S: aws.String(now.Format(time.RFC3339))},
+			":cutoff": {S: aws.String(cutoff.Format(time.RFC3339))},
+		},
+	})
+	return err
+}
+
+func sendToMessagingQueue(channelID, content string, now time.Time) error {
+	message := models.MessagingQueueMessage{
+		ChannelID: channelID,
+		Content:   content,
+	}
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(messagingQueue),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(channelID),
+		MessageDeduplicationId: aws.String(fmt.Sprintf("%s-nudge-%d", channelID, now.Unix())),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to queue: %w", err)
+	}
+	return nil
+}
+
+func main() {
+	logging.Init("nudge")
+	lambda.Start(handler)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	models "loros/syrus-models"
+)
+
+func TestNudgeEligible(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	hoursAgo := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	base := func() *models.Campaign {
+		return &models.Campaign{
+			CampaignID:    "campaign-1",
+			Status:        models.CampaignStatusPlaying,
+			PlayStyle:     models.PlayStyleAsynchronous,
+			LastUpdatedAt: hoursAgo(30),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(c *models.Campaign)
+		expected bool
+	}{
+		{"idle past default interval", func(c *models.Campaign) {}, true},
+		{"active status is eligible", func(c *models.Campaign) { c.Status = models.CampaignStatusActive }, true},
+		{"synchronous campaigns are never nudged", func(c *models.Campaign) { c.PlayStyle = models.PlayStyleSynchronous }, false},
+		{"unset play style is synchronous", func(c *models.Campaign) { c.PlayStyle = "" }, false},
+		{"paused campaigns are left alone", func(c *models.Campaign) { c.Lifecycle.Paused = true }, false},
+		{"ended campaigns are left alone", func(c *models.Campaign) { c.Status = models.CampaignStatusEnded }, false},
+		{"recent update", func(c *models.Campaign) { c.LastUpdatedAt = hoursAgo(2) }, false},
+		{"recent pending declaration counts as activity", func(c *models.Campaign) {
+			c.Runtime.TurnState.PendingDeclarations = []models.PendingDeclaration{{UserID: "p1", DeclaredAt: hoursAgo(1)}}
+		}, false},
+		{"recent declaration heartbeat counts as activity", func(c *models.Campaign) { c.Runtime.LastDeclarationAt = ptr(hoursAgo(4)) }, false},
+		{"custom shorter interval", func(c *models.Campaign) {
+			c.NudgeInterval = 6
+			c.LastUpdatedAt = hoursAgo(7)
+		}, true},
+		{"custom longer interval", func(c *models.Campaign) { c.NudgeInterval = 48 }, false},
+		{"nudged within interval", func(c *models.Campaign) { c.Runtime.LastNudgedAt = ptr(hoursAgo(3)) }, false},
+		{"last nudge older than interval", func(c *models.Campaign) { c.Runtime.LastNudgedAt = ptr(hoursAgo(25)) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := base()
+			tt.mutate(campaign)
+			if got := nudgeEligible(campaign, now); got != tt.expected {
+				t.Errorf("Expected eligible=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildNudgeMessage(t *testing.T) {
+	campaign := &models.Campaign{
+		Party: models.Party{Members: []models.PartyMember{{UserID: "host"}, {UserID: "p1"}, {UserID: "p2"}}},
+		Runtime: models.RuntimeState{TurnState: models.TurnState{
+			PendingDeclarations: []models.PendingDeclaration{{UserID: "p1", Declaration: "I wait by the well"}},
+		}},
+	}
+
+	message := buildNudgeMessage(campaign)
+	if !strings.HasPrefix(message, "<@host> <@p2>\n\n") {
+		t.Errorf("Expected only players yet to declare to be mentioned, got %q", message)
+	}
+	if strings.Contains(message, "<@p1>") {
+		t.Errorf("Expected player who already declared not to be mentioned, got %q", message)
+	}
+	if !strings.Contains(message, "The weave awaits your move.") {
+		t.Errorf("Expected in-character nudge, got %q", message)
+	}
+}
@@ -2,12 +2,33 @@ module syrus-play
 
 go 1.21
 
+replace loros/syrus-capture => ../../lib/go/capture
+
+replace loros/syrus-commandopts => ../../lib/go/commandopts
+
+replace loros/syrus-costs => ../../lib/go/costs
+
 replace loros/syrus-models => ../../lib/go/models
 
+replace loros/syrus-dedup => ../../lib/go/dedup
+
+replace loros/syrus-sqsbatch => ../../lib/go/sqsbatch
+
+replace loros/syrus-ssmcache => ../../lib/go/ssmcache
+
+replace loros/syrus-logging => ../../lib/go/logging
+
 require (
 	github.com/aws/aws-lambda-go v1.47.0
-	github.com/aws/aws-sdk-go v1.50.0
+	github.com/aws/aws-sdk-go v1.55.5
+	loros/syrus-capture v0.0.0
+	loros/syrus-commandopts v0.0.0
+	loros/syrus-costs v0.0.0
+	loros/syrus-dedup v0.0.0
+	loros/syrus-logging v0.0.0-00010101000000-000000000000
 	loros/syrus-models v0.0.0
+	loros/syrus-sqsbatch v0.0.0
+	loros/syrus-ssmcache v0.0.0
 )
 
 require github.com/jmespath/go-jmespath v0.4.0 // indirect
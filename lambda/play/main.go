@@ -1,14 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"math/rand"
+	"net/http"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
-
+	"unicode"
+	"unicode/utf8"
+
+	capture "loros/syrus-capture"
+	commandopts "loros/syrus-commandopts"
+	costs "loros/syrus-costs"
+	dedup "loros/syrus-dedup"
+	logging "loros/syrus-logging"
 	models "loros/syrus-models"
+	sqsbatch "loros/syrus-sqsbatch"
+	ssmcache "loros/syrus-ssmcache"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -19,6 +38,217 @@ import (
 	"github.com/aws/aws-sdk-go/service/sqs"
 )
 
+// dedupPrefix namespaces this lambda's records in the shared dedup table
+const dedupPrefix = "play"
+
+// claimLease holds a message while it is processed. It matches the 5 minute lambda timeout and lapses
+// before the play queue's 6 minute visibility timeout redelivers a crashed invocation's message.
+const claimLease = 5 * time.Minute
+
+// imageReleaseDedupPrefix namespaces milestone images already posted to a campaign's channel
+const imageReleaseDedupPrefix = "play-image"
+
+// imageReleaseTTL keeps released-image records for the lifetime of a long campaign, so an image posts once
+const imageReleaseTTL = 90 * 24 * time.Hour
+
+var (
+	// features holds the feature flags for this deployment, parsed once at init
+	features models.Features
+	// debugUsers holds the user IDs allowed to use debug features, parsed once at init
+	debugUsers models.DebugUsers
+	// temperatureRamp bounds narration temperature for this deployment
+	temperatureRamp TemperatureRamp
+	// maxDeclarationLength caps how many characters a declaration may carry into narration
+	maxDeclarationLength int
+	// sqsConcurrency is how many message groups (channels) a batch processes in parallel
+	sqsConcurrency int
+)
+
+// The play handler's storage, queue, and model dependencies; overridden in tests (see the campaign simulation)
+var (
+	claimProcessed        = dedup.Claim
+	releaseProcessed      = dedup.Release
+	markProcessed         = dedup.Mark
+	loadCampaign          = getCampaignByID
+	queueMessage          = sendMessageToQueue
+	queueHostFollowup     = sendHostFollowupToQueue
+	queuePrepared         = sendQueueMessage
+	storeTurnState        = saveTurnState
+	storeLastNarration    = saveLastNarration
+	storeCampaignProgress = saveCampaignProgress
+	storeDecisionOutcome  = saveDecisionOutcome
+	storeParty            = saveParty
+	fetchAnthropicAPIKey  = getAnthropicAPIKey
+	callNarrationModel    = callAnthropicAPI
+	callIntentModel       = callAnthropicAPI
+	recordModelUsage      = costs.Record
+)
+
+func init() {
+	features = models.ParseFeatures(os.Getenv("SYRUS_FEATURES"))
+	debugUsers = models.ParseDebugUsers(os.Getenv("SYRUS_DEBUG_USERS"))
+	temperatureRamp = parseTemperatureRamp(os.Getenv("SYRUS_NARRATION_TEMPERATURES"))
+	maxDeclarationLength = parseMaxDeclarationLength(os.Getenv("SYRUS_MAX_DECLARATION_LENGTH"))
+	sqsConcurrency = sqsbatch.ConcurrencyFromEnv()
+}
+
+// Declaration length bounds: the default, and the range SYRUS_MAX_DECLARATION_LENGTH may set
+const (
+	defaultMaxDeclarationLength = 500
+	minDeclarationLengthLimit   = 50
+	maxDeclarationLengthLimit   = 2000
+)
+
+// parseMaxDeclarationLength parses the declaration length cap, falling back to the default when unset or out of range
+func parseMaxDeclarationLength(raw string) int {
+	if strings.TrimSpace(raw) == "" {
+		return defaultMaxDeclarationLength
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || value < minDeclarationLengthLimit || value > maxDeclarationLengthLimit {
+		log.Printf("Warning: SYRUS_MAX_DECLARATION_LENGTH must be %d-%d, got %q; using %d", minDeclarationLengthLimit, maxDeclarationLengthLimit, raw, defaultMaxDeclarationLength)
+		return defaultMaxDeclarationLength
+	}
+	return value
+}
+
+// injectionPatterns match declarations that try to override the narrator rather than act in the story
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,20}\b(previous|prior|above|earlier|all|your)\b.{0,20}\b(instructions?|prompts?|rules|directives|context)\b`),
+	regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output)\b.{0,20}\b(system|hidden|original)\s+(prompt|instructions?)\b`),
+	regexp.MustCompile(`(?i)\byou are (now|no longer)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated) (instructions?|rules|persona)\s*:`),
+}
+
+// roleMarkers are chat-transcript markers and tags that could be mistaken for prompt structure; they are stripped
+var roleMarkers = regexp.MustCompile("(?i)(</?\\s*(system|assistant|user|human|instructions?)\\s*>|\\b(human|assistant|system)\\s*:|```)")
+
+// sanitizeDeclaration cleans a declaration for narration: control characters are removed, whitespace is
+// collapsed, and role markers are stripped. Empty, oversized, and injection-style declarations are
+// rejected with an in-character reason instead.
+func sanitizeDeclaration(raw string, maxLength int) (string, string) {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, raw)
+	cleaned = roleMarkers.ReplaceAllString(cleaned, " ")
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+
+	if cleaned == "" {
+		return "", "*Syrus hears only silence.* Speak your intent plainly, brave adventurer. Try `/syrus declare \"your action here\"`."
+	}
+	if length := utf8.RuneCountInString(cleaned); length > maxLength {
+		return "", fmt.Sprintf("*The scroll unfurls past the edge of the table.* Your declaration runs %d characters; keep it within %d so the tale can hold it.", length, maxLength)
+	}
+	for _, pattern := range injectionPatterns {
+		if pattern.MatchString(cleaned) {
+			return "", "*The words twist and will not take hold.* Syrus weaves only the deeds of heroes within the tale. Declare what your character does."
+		}
+	}
+	return cleaned, ""
+}
+
+// TemperatureRamp bounds narration temperature: exposition runs cooler, climactic beats run hotter
+type TemperatureRamp struct {
+	Exposition float64
+	Base       float64
+	Climax     float64
+}
+
+// defaultTemperatureRamp is used when SYRUS_NARRATION_TEMPERATURES is unset or invalid
+var defaultTemperatureRamp = TemperatureRamp{Exposition: 0.6, Base: 0.8, Climax: 1.0}
+
+// highPressureLevel is the Pressure.Level at which narration runs at climax temperature
+const highPressureLevel = 3
+
+// expositionBeats is how many opening beats of an act are narrated at exposition temperature
+const expositionBeats = 2
+
+// parseTemperatureRamp parses "exposition,base,climax" (e.g. "0.6,0.8,1.0"). Values must lie in [0, 1]
+// (the Anthropic range) and be non-decreasing; anything else falls back to the default ramp.
+func parseTemperatureRamp(raw string) TemperatureRamp {
+	if strings.TrimSpace(raw) == "" {
+		return defaultTemperatureRamp
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) != 3 {
+		log.Printf("Warning: SYRUS_NARRATION_TEMPERATURES needs exposition,base,climax; using defaults")
+		return defaultTemperatureRamp
+	}
+
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || value < 0 || value > 1 {
+			log.Printf("Warning: invalid narration temperature %q; using defaults", part)
+			return defaultTemperatureRamp
+		}
+		values[i] = value
+	}
+	if values[0] > values[1] || values[1] > values[2] {
+		log.Printf("Warning: narration temperatures must not decrease toward climax; using defaults")
+		return defaultTemperatureRamp
+	}
+
+	return TemperatureRamp{Exposition: values[0], Base: values[1], Climax: values[2]}
+}
+
+// narrationTemperature picks the generation temperature for the current beat. Pressure and lateness in
+// the act each ramp from base toward climax (the stronger wins): full climax at highPressureLevel or at
+// the act's hard-pressure beat, ramping in from its soft-pressure beat. Calm opening beats use exposition.
+func narrationTemperature(ramp TemperatureRamp, act models.Act, runtime models.RuntimeState) float64 {
+	beat := runtime.CurrentBeat
+	level := runtime.Pressure.Level
+	signals := act.LateActSignals
+
+	intensity := 0.0
+	if level > 0 {
+		intensity = math.Min(1, float64(level)/highPressureLevel)
+	}
+	if signals.HardPressureAtBeat > 0 && beat >= signals.HardPressureAtBeat {
+		intensity = 1
+	} else if signals.SoftPressureAtBeat > 0 && beat >= signals.SoftPressureAtBeat && signals.HardPressureAtBeat > signals.SoftPressureAtBeat {
+		lateness := float64(beat-signals.SoftPressureAtBeat) / float64(signals.HardPressureAtBeat-signals.SoftPressureAtBeat)
+		intensity = math.Max(intensity, lateness)
+	}
+
+	if intensity == 0 && beat < expositionBeats {
+		return ramp.Exposition
+	}
+	return ramp.Base + (ramp.Climax-ramp.Base)*intensity
+}
+
+// resolveModel returns the model to use for a single call, honoring a debug override
+// only for debug users. Unknown override values are ignored.
+func resolveModel(policy models.Model, override models.Model, userID string) models.Model {
+	if override == "" {
+		return policy
+	}
+	if !debugUsers.Allows(userID) {
+		log.Printf("Ignoring model override %q from non-debug user %s", override, userID)
+		return policy
+	}
+	switch override {
+	case models.ModelHaiku, models.ModelSonnet:
+		log.Printf("DEBUG: model override active for user %s: %s -> %s", userID, policy, override)
+		return override
+	default:
+		log.Printf("Ignoring unknown model override %q", override)
+		return policy
+	}
+}
+
+// isEnabled reports whether a feature flag is enabled for this deployment
+func isEnabled(flag string) bool {
+	return features.IsEnabled(flag)
+}
+
 // Discord interaction structures (copied from webhook for play lambda)
 type DiscordInteraction struct {
 	ID        string                 `json:"id"`
@@ -43,92 +273,112 @@ type DiscordUser struct {
 // PlayRequest represents the message sent to the play queue
 type PlayRequest struct {
 	CampaignId        string             `json:"campaignId"`
+	ChannelId         string             `json:"channelId,omitempty"` // Channel the campaign is bound to
 	InteractionId     string             `json:"interactionId"`
 	InteractionObject DiscordInteraction `json:"interactionObject"`
+	ModelOverride     models.Model       `json:"modelOverride,omitempty"` // Debug only - honored for debug users
+
+	// replyChannelID is the reconciled reply target, set by handlePlayRequest
+	replyChannelID string
+	// campaigns caches the campaign for the rest of the request, set by handlePlayRequest
+	campaigns *campaignLoader
 }
 
-// HaikuResponse represents the response from the Haiku model
-type HaikuResponse struct {
-	Message              string `json:"message"`
-	BeatAdvanced         bool   `json:"beatAdvanced"`
-	RollRequired         bool   `json:"rollRequired"`
-	RollType             string `json:"rollType"`
-	CombatOccurred       bool   `json:"combatOccurred"`
-	FailurePathActivated string `json:"failurePathActivated"`
-	SuccessPathActivated string `json:"successPathActivated"`
-	MemoryUpdates        struct {
-		Flags []string `json:"flags"`
-		Facts []string `json:"facts"`
-	} `json:"memoryUpdates"`
-	ImageTrigger string `json:"imageTrigger"`
+// campaignLoader reads a request's campaign once and reuses it, so a debug snapshot and the command
+// it accompanies share one DynamoDB read. Failed reads are not cached.
+type campaignLoader struct {
+	campaign *models.Campaign
+	loaded   bool
 }
 
-// checkDedup checks if a message has already been processed
-func checkDedup(interactionID string) (bool, error) {
-	dedupTable := os.Getenv("SYRUS_DEDUP_TABLE")
-	if dedupTable == "" {
-		return false, fmt.Errorf("SYRUS_DEDUP_TABLE environment variable not set")
+// load returns the cached campaign, reading it through loadCampaign on first use or after invalidate
+func (l *campaignLoader) load(campaignID string) (*models.Campaign, error) {
+	if l.loaded {
+		return l.campaign, nil
 	}
-
-	sess, err := session.NewSession()
+	campaign, err := loadCampaign(campaignID)
 	if err != nil {
-		return false, fmt.Errorf("failed to create AWS session: %w", err)
+		return nil, err
 	}
+	l.campaign, l.loaded = campaign, true
+	return campaign, nil
+}
 
-	svc := dynamodb.New(sess)
-
-	dedupKey := fmt.Sprintf("play#%s", interactionID)
-
-	result, err := svc.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(dedupTable),
-		Key: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {
-				S: aws.String(dedupKey),
-			},
-		},
-	})
+// invalidate drops the cached campaign so the next load reads the table again
+func (l *campaignLoader) invalidate() {
+	l.campaign, l.loaded = nil, false
+}
 
-	if err != nil {
-		return false, fmt.Errorf("failed to check dedup table: %w", err)
+// campaign returns the request's campaign, reading it at most once per request
+func (r PlayRequest) campaign() (*models.Campaign, error) {
+	if r.campaigns == nil {
+		return loadCampaign(r.CampaignId)
 	}
-
-	return result.Item != nil, nil
+	return r.campaigns.load(r.CampaignId)
 }
 
-// writeDedup marks a message as processed
-func writeDedup(interactionID string) error {
-	dedupTable := os.Getenv("SYRUS_DEDUP_TABLE")
-	if dedupTable == "" {
-		return fmt.Errorf("SYRUS_DEDUP_TABLE environment variable not set")
+// forgetCampaign drops the cached campaign ahead of a write, so no later read in the request sees stale state
+func (r PlayRequest) forgetCampaign() {
+	if r.campaigns != nil {
+		r.campaigns.invalidate()
 	}
+}
 
-	sess, err := session.NewSession()
-	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
+// ReplyChannelID is the channel replies to this request are posted and grouped under
+func (r PlayRequest) ReplyChannelID() string {
+	if r.replyChannelID != "" {
+		return r.replyChannelID
 	}
+	return r.campaignChannelID()
+}
 
-	svc := dynamodb.New(sess)
-
-	dedupKey := fmt.Sprintf("play#%s", interactionID)
-	expiresAt := time.Now().Add(24 * time.Hour).Unix()
+// logger returns a logger carrying the request's interaction and campaign IDs
+func (r PlayRequest) logger() logging.Logger {
+	return logging.For(r.InteractionId, r.CampaignId)
+}
 
-	_, err = svc.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(dedupTable),
-		Item: map[string]*dynamodb.AttributeValue{
-			"dedupKey": {
-				S: aws.String(dedupKey),
-			},
-			"expiresAt": {
-				N: aws.String(fmt.Sprintf("%d", expiresAt)),
-			},
-		},
-	})
+// campaignChannelID is the channel the campaign is bound to. Requests queued before campaign IDs
+// were generated carry only the campaign ID, which was the channel.
+func (r PlayRequest) campaignChannelID() string {
+	if r.ChannelId != "" {
+		return r.ChannelId
+	}
+	return r.CampaignId
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to write to dedup table: %w", err)
+// reconcileReplyChannel checks the campaign's channel against the channel the interaction came from.
+// Interaction-token replies always land in the interaction's channel, so when the two differ (e.g. a
+// thread campaign addressed from its parent channel) the interaction's channel wins; otherwise channel
+// fallbacks and follow-ups would cross-post into the other channel.
+func reconcileReplyChannel(campaignChannelID, interactionChannelID string) (string, bool) {
+	if interactionChannelID == "" || interactionChannelID == campaignChannelID {
+		return campaignChannelID, false
 	}
+	return interactionChannelID, true
+}
 
-	return nil
+// HaikuResponse represents the response from the Haiku model
+type HaikuResponse struct {
+	Message              string `json:"message"`
+	BeatAdvanced         bool   `json:"beatAdvanced"`
+	ActCompleted         bool   `json:"actCompleted"`
+	RollRequired         bool   `json:"rollRequired"`
+	RollType             string `json:"rollType"`
+	CombatOccurred       bool   `json:"combatOccurred"`
+	FailurePathActivated string `json:"failurePathActivated"`
+	SuccessPathActivated string `json:"successPathActivated"`
+	MemoryUpdates        struct {
+		Flags []string `json:"flags"`
+		Facts []string `json:"facts"`
+	} `json:"memoryUpdates"`
+	RelationshipUpdates []RelationshipUpdate `json:"relationshipUpdates"`
+	ImageTrigger        string               `json:"imageTrigger"`
+}
+
+// RelationshipUpdate is a narration's report that an entity's standing with the party has shifted
+type RelationshipUpdate struct {
+	Entity string `json:"entity"`
+	State  string `json:"state"`
 }
 
 // getCampaignByID retrieves a campaign by campaignId
@@ -186,8 +436,9 @@ func sendMessageToQueue(channelID string, content string, interactionToken strin
 	svc := sqs.New(sess)
 
 	messageBody := map[string]interface{}{
-		"channelId": channelID,
-		"content":   content,
+		"channelId":     channelID,
+		"content":       content,
+		"interactionId": interactionID,
 	}
 	if interactionToken != "" {
 		messageBody["interactionToken"] = interactionToken
@@ -212,90 +463,190 @@ func sendMessageToQueue(channelID string, content string, interactionToken strin
 	return nil
 }
 
-// handlePlayRequest processes a single play request
-func handlePlayRequest(ctx context.Context, playRequest PlayRequest) error {
-	log.Printf("Processing play request for campaign %s, interaction %s", playRequest.CampaignId, playRequest.InteractionId)
+// sendHostFollowupToQueue sends an ephemeral follow-up that only the invoking user (the host) sees
+func sendHostFollowupToQueue(channelID, content, interactionToken, deduplicationID string) error {
+	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
+	}
 
-	// Check dedup table for safety
-	alreadyProcessed, err := checkDedup(playRequest.InteractionId)
+	sess, err := session.NewSession()
 	if err != nil {
-		log.Printf("Failed to check dedup table: %v", err)
-		return err
+		return fmt.Errorf("failed to create AWS session: %w", err)
 	}
-	if alreadyProcessed {
-		log.Printf("Interaction %s already processed, skipping", playRequest.InteractionId)
-		return nil
+
+	svc := sqs.New(sess)
+
+	message := models.MessagingQueueMessage{
+		ChannelID:        channelID,
+		Content:          content,
+		Flags:            64, // Ephemeral - only the invoking user sees it
+		InteractionToken: interactionToken,
+		Followup:         true,
+	}
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(channelID), // Same group keeps it after the narration
+		MessageDeduplicationId: aws.String(deduplicationID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to queue: %w", err)
+	}
+
+	log.Printf("Successfully sent host follow-up to queue for channel %s", channelID)
+	return nil
+}
+
+// getUserID extracts the invoking user's ID (can be in user or member.user)
+func getUserID(interaction DiscordInteraction) string {
+	if interaction.User != nil {
+		return interaction.User.ID
+	}
+	if interaction.Member != nil {
+		return interaction.Member.User.ID
+	}
+	return ""
+}
+
+// DeclarationRoute describes how a declaration is handled under the campaign's decision model
+type DeclarationRoute string
+
+const (
+	// RouteNarrate advances the story with the declaration
+	RouteNarrate DeclarationRoute = "narrate"
+	// RouteConsensus folds the declaration into the party's pending group decision
+	RouteConsensus DeclarationRoute = "consensus"
+	// RouteDeniedNotHost rejects a non-host declaration in a host-decision campaign
+	RouteDeniedNotHost DeclarationRoute = "denied_not_host"
+	// RouteInvalidModel rejects declarations for a campaign with an unknown decision model
+	RouteInvalidModel DeclarationRoute = "invalid_model"
+)
+
+// isValidDecisionModel reports whether the decision model is one the play lambda knows how to enforce
+func isValidDecisionModel(decisionModel models.DecisionModel) bool {
+	switch decisionModel {
+	case models.DecisionModelHost, models.DecisionModelGroup, models.DecisionModelFlexible:
+		return true
+	default:
+		return false
+	}
+}
+
+// routeDeclaration decides how a user's declaration is handled under the campaign's decision model.
+// Host mode only accepts the host, group mode sends declarations into the consensus flow while a
+// decision is pending, and flexible mode accepts anyone. A solo campaign is always host mode: its
+// one player decides everything.
+func routeDeclaration(campaign *models.Campaign, userID string) DeclarationRoute {
+	decisionModel := campaign.DecisionModel
+	if campaign.IsSolo() {
+		decisionModel = models.DecisionModelHost
+	}
+	if !isValidDecisionModel(decisionModel) {
+		return RouteInvalidModel
+	}
+
+	switch decisionModel {
+	case models.DecisionModelHost:
+		if userID == "" || userID != campaign.HostID {
+			return RouteDeniedNotHost
+		}
+		return RouteNarrate
+	case models.DecisionModelGroup:
+		if campaign.Runtime.TurnState.ActiveDecision != nil {
+			return RouteConsensus
+		}
+		return RouteNarrate
+	default:
+		return RouteNarrate
+	}
+}
+
+// handlePlayRequest processes a single play request
+func handlePlayRequest(ctx context.Context, playRequest PlayRequest) error {
+	playRequest.logger().Printf("Processing play request for campaign %s, interaction %s", playRequest.CampaignId, playRequest.InteractionId)
+
+	replyChannelID, mismatch := reconcileReplyChannel(playRequest.campaignChannelID(), playRequest.InteractionObject.ChannelID)
+	if mismatch {
+		playRequest.logger().Printf("WARNING: Interaction %s came from channel %s but targets campaign %s; replying in %s",
+			playRequest.InteractionId, playRequest.InteractionObject.ChannelID, playRequest.CampaignId, replyChannelID)
 	}
+	playRequest.replyChannelID = replyChannelID
+	playRequest.campaigns = &campaignLoader{}
 
 	// Parse interaction to determine what to do
 	interaction := playRequest.InteractionObject
 
+	// Group decision buttons carry the chosen option in their custom ID
+	if choice, ok := parseVoteCustomID(interaction.Data); ok {
+		return handleVoteCommand(playRequest, choice)
+	}
+
 	// Check if this is a syrus command
 	if interaction.Data != nil {
 		if commandName, ok := interaction.Data["name"].(string); ok && commandName == "syrus" {
-			// Parse options to determine subcommand and debug mode
-			options, hasOptions := interaction.Data["options"].([]interface{})
-
-			// Check for debug flag first (only for authorized user)
-			debugMode := false
-			if hasOptions {
-				for _, opt := range options {
-					if optMap, ok := opt.(map[string]interface{}); ok {
-						if name, ok := optMap["name"].(string); ok && name == "debug" {
-							if debugValue, ok := optMap["value"].(bool); ok && debugValue {
-								// Only enable debug mode for authorized user
-								userID := ""
-								if interaction.User != nil {
-									userID = interaction.User.ID
-								} else if interaction.Member != nil && interaction.Member.User.ID != "" {
-									userID = interaction.Member.User.ID
-								}
-
-								if userID == "1400583338720235591" {
-									debugMode = true
-									break
-								}
-							}
-						}
-					}
-				}
+			subcommand, opts, err := commandopts.Parse(interaction.Data)
+			if err != nil {
+				playRequest.logger().Printf("Malformed /syrus options for interaction %s: %v", playRequest.InteractionId, err)
+				return queueMessage(playRequest.ReplyChannelID(), "*The words arrive tangled, their threads knotted beyond reading.* Speak your command again, brave adventurer.", playRequest.InteractionObject.Token, playRequest.InteractionId)
 			}
 
-			// Send debug snapshot if debug mode is enabled
+			// Debug is a subcommand, or a legacy boolean flag beside another option (authorized users only)
+			debugMode := (subcommand == "debug" || opts["debug"] == "true") && debugUsers.Allows(getUserID(interaction))
+			if subcommand == "debug" {
+				if !debugMode {
+					return queueMessage(playRequest.ReplyChannelID(), "*The veil does not part for you.* Some secrets are kept by Syrus alone.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+				}
+				return handleDebugMode(playRequest)
+			}
 			if debugMode {
 				if err := handleDebugMode(playRequest); err != nil {
-					log.Printf("Failed to send debug mode response: %v", err)
+					playRequest.logger().Printf("Failed to send debug mode response: %v", err)
 					// Continue with normal processing even if debug fails
 				}
 			}
 
-			// Parse for declare subcommand
-			if hasOptions && len(options) > 0 {
-				firstOption, ok := options[0].(map[string]interface{})
-				if ok {
-					if name, ok := firstOption["name"].(string); ok && name == "declare" {
-						if declaration, ok := firstOption["value"].(string); ok {
-							// Handle declare command
-							return handleDeclareCommand(playRequest, declaration)
-						}
-					}
+			switch subcommand {
+			case "declare":
+				if intent := opts["intent"]; intent != "" {
+					return handleDeclareCommand(ctx, playRequest, intent)
+				}
+			case "reroll":
+				return handleRerollCommand(ctx, playRequest)
+			case "intro":
+				return handleIntroCommand(playRequest)
+			case "join":
+				return handleJoinCommand(playRequest)
+			case "leave":
+				return handleLeaveCommand(playRequest)
+			case "status":
+				return handleStatusCommand(playRequest)
+			case "":
+				// Legacy payloads carry the declaration as a top-level string option
+				if declaration := opts["declare"]; declaration != "" {
+					return handleDeclareCommand(ctx, playRequest, declaration)
 				}
 			}
 		}
 	}
 
 	// Unknown command or no valid subcommand found
-	log.Printf("Unknown or invalid syrus command for interaction %s", playRequest.InteractionId)
-	return sendMessageToQueue(playRequest.CampaignId, "*The mists of fate swirl uncertainly.* I do not understand this command, brave adventurer. Try `/syrus declare \"your action here\"` to weave your tale.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	playRequest.logger().Printf("Unknown or invalid syrus command for interaction %s", playRequest.InteractionId)
+	return queueMessage(playRequest.ReplyChannelID(), "*The mists of fate swirl uncertainly.* I do not understand this command, brave adventurer. Try `/syrus declare \"your action here\"` to weave your tale.", playRequest.InteractionObject.Token, playRequest.InteractionId)
 }
 
 // handleDebugMode sends a truncated debug snapshot
 func handleDebugMode(playRequest PlayRequest) error {
 	// Get campaign state
-	campaign, err := getCampaignByID(playRequest.CampaignId)
+	campaign, err := playRequest.campaign()
 	if err != nil {
-		log.Printf("Failed to get campaign: %v", err)
-		return sendMessageToQueue(playRequest.CampaignId, "*The ancient tomes refuse to open.* Debug failed: cannot access campaign data.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient tomes refuse to open.* Debug failed: cannot access campaign data.", playRequest.InteractionObject.Token, playRequest.InteractionId)
 	}
 
 	// Create truncated debug response (Discord 2000 char limit)
@@ -324,107 +675,3002 @@ func handleDebugMode(playRequest PlayRequest) error {
 	// Add a note about full data availability
 	debugInfo += "\n\n*📜 Extended diagnostics recorded for debugging*"
 
-	return sendMessageToQueue(playRequest.CampaignId, debugInfo, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	return queueMessage(playRequest.ReplyChannelID(), debugInfo, playRequest.InteractionObject.Token, playRequest.InteractionId)
 }
 
-// handleDeclareCommand processes a /syrus declare command
-func handleDeclareCommand(playRequest PlayRequest, declaration string) error {
-	log.Printf("Processing declare command: %s", declaration)
+// buildIntroMessages re-creates the campaign opening from the stored blueprint for one player.
+// The title and premise (with the cached intro image) resolve the deferred ephemeral response;
+// the introduction follows as an ephemeral follow-up.
+func buildIntroMessages(channelID, interactionToken string, blueprint models.Blueprint) []models.MessagingQueueMessage {
+	opening := models.MessagingQueueMessage{
+		ChannelID:        channelID,
+		Content:          fmt.Sprintf("This is the thread drawn from the weave:\n## %s\n\n%s", blueprint.Title, blueprint.Premise),
+		Flags:            64, // Ephemeral - only the late joiner sees it
+		InteractionToken: interactionToken,
+	}
+	if s3Key := blueprint.ImagePlan.IntroImage.S3Key; s3Key != "" {
+		opening.Attachments = []models.Attachment{
+			{
+				Name:        "intro.png",
+				Data:        s3Key, // S3 key - messaging fetches the cached image
+				ContentType: "image/png",
+			},
+		}
+	}
 
-	// Get campaign
-	campaign, err := getCampaignByID(playRequest.CampaignId)
-	if err != nil {
-		log.Printf("Failed to get campaign: %v", err)
-		return sendMessageToQueue(playRequest.CampaignId, "*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	messages := []models.MessagingQueueMessage{opening}
+	if blueprint.Introduction != "" {
+		messages = append(messages, models.MessagingQueueMessage{
+			ChannelID:        channelID,
+			Content:          blueprint.Introduction,
+			Flags:            64,
+			InteractionToken: interactionToken,
+			Followup:         true,
+		})
 	}
-	if campaign == nil {
-		return sendMessageToQueue(playRequest.CampaignId, "*The pages of destiny remain blank.* This tale has not yet begun. The story awaits your first step.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	return messages
+}
+
+// discordMessageLimit is the most characters Discord accepts in a message's content
+const discordMessageLimit = 2000
+
+// buildStatusMessage summarizes where the party stands in the tale: act, beat progress,
+// looming failure paths, pressure, and roster. It never includes prompts or raw state.
+func buildStatusMessage(campaign *models.Campaign) string {
+	var b strings.Builder
+	title := campaign.Blueprint.Title
+	if title == "" {
+		title = "An Unnamed Tale"
+	}
+	fmt.Fprintf(&b, "**📜 %s**\n", title)
+	if campaign.Lifecycle.Paused {
+		b.WriteString("*The loom is still. This tale is paused.*\n")
 	}
 
-	// Validate campaign status
-	switch campaign.Status {
-	case models.CampaignStatusEnded:
-		return sendMessageToQueue(playRequest.CampaignId, "*The final page has been written.* This adventure has passed into legend. The tale is complete, the heroes immortalized in song. Try `/syrus start` to begin a new tale.", playRequest.InteractionObject.Token, playRequest.InteractionId)
-	case models.CampaignStatusConfiguring:
-		return sendMessageToQueue(playRequest.CampaignId, "*The ink is still wet on the contract.* Your campaign is still being prepared. The world awaits your final choices.", playRequest.InteractionObject.Token, playRequest.InteractionId)
-	case models.CampaignStatusActive:
-		// Check lifecycle for paused state
-		if campaign.Lifecycle.Paused {
-			return sendMessageToQueue(playRequest.CampaignId, "*Time itself holds its breath.* The tale rests in stasis, waiting for the moment to continue. Try `/syrus resume` to continue the story.", playRequest.InteractionObject.Token, playRequest.InteractionId)
-		}
-		// Transition to playing if currently active (not playing)
-		if campaign.Status != models.CampaignStatusPlaying {
-			// TODO: Update campaign status to "playing" in DynamoDB
-			log.Printf("Transitioning campaign %s to playing status", playRequest.CampaignId)
+	currentAct := campaign.Runtime.CurrentAct
+	if currentAct >= 0 && currentAct < len(campaign.Blueprint.Acts) {
+		act := campaign.Blueprint.Acts[currentAct]
+		fmt.Fprintf(&b, "\n**Act %d of %d:** %s\n", currentAct+1, len(campaign.Blueprint.Acts), act.Name)
+		if act.ExpectedBeats > 0 {
+			fmt.Fprintf(&b, "**Progress:** beat %d of about %d\n", campaign.Runtime.CurrentBeat, act.ExpectedBeats)
+		} else {
+			fmt.Fprintf(&b, "**Progress:** beat %d\n", campaign.Runtime.CurrentBeat)
 		}
+	} else {
+		b.WriteString("\n*The tale has not yet found its first act.*\n")
 	}
 
-	// Load current act and memory
-	currentAct := campaign.Runtime.CurrentAct
-	if currentAct < 0 || currentAct >= len(campaign.Blueprint.Acts) {
-		return sendMessageToQueue(playRequest.CampaignId, "*The ancient runes have been defiled.* The structure of this tale is corrupted. Seek the wisdom of the elders to restore the chronicle.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	fmt.Fprintf(&b, "**Pressure:** %s\n", describePressure(campaign.Runtime.Pressure.Level))
+	if len(campaign.Runtime.ActiveFailurePaths) > 0 {
+		fmt.Fprintf(&b, "**Shadows gathering:** %s\n", strings.Join(campaign.Runtime.ActiveFailurePaths, ", "))
 	}
 
-	act := campaign.Blueprint.Acts[currentAct]
-	memory := campaign.Memory.PerAct[fmt.Sprintf("%d", currentAct)]
+	if campaign.IsSolo() {
+		fmt.Fprintf(&b, "\n**The traveler:** <@%s>", campaign.HostID)
+	} else if len(campaign.Party.Members) > 0 {
+		roster := make([]string, 0, len(campaign.Party.Members))
+		for _, member := range campaign.Party.Members {
+			entry := fmt.Sprintf("<@%s>", member.UserID)
+			if member.Role == "host" {
+				entry += " (host)"
+			}
+			roster = append(roster, entry)
+		}
+		fmt.Fprintf(&b, "\n**The party (%d):** %s", len(roster), strings.Join(roster, ", "))
+	}
 
-	// Ensure memory structure exists
-	if memory.Beats == nil {
-		memory.Beats = new(int)
+	return truncateMessage(b.String(), discordMessageLimit)
+}
+
+// describePressure names a pressure level for players
+func describePressure(level int) string {
+	switch {
+	case level <= 0:
+		return "calm"
+	case level == 1:
+		return "rising"
+	case level == 2:
+		return "mounting"
+	default:
+		return "crushing"
 	}
-	if memory.CombatSceneCount == nil {
-		memory.CombatSceneCount = new(int)
+}
+
+// truncateMessage shortens content to at most max characters, marking the cut with an ellipsis
+func truncateMessage(content string, max int) string {
+	runes := []rune(content)
+	if len(runes) <= max {
+		return content
 	}
-	if memory.Flags == nil {
-		memory.Flags = []string{}
+	return string(runes[:max-1]) + "…"
+}
+
+// handleStatusCommand tells the requester where the party stands in the tale
+func handleStatusCommand(playRequest PlayRequest) error {
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.", playRequest.InteractionObject.Token, playRequest.InteractionId)
 	}
-	if memory.Failures == nil {
-		memory.Failures = []string{}
+	if campaign == nil || campaign.Blueprint.Title == "" {
+		return queueMessage(playRequest.ReplyChannelID(), "*The pages of destiny remain blank.* No tale has been woven here yet, so there is nothing to recount.", playRequest.InteractionObject.Token, playRequest.InteractionId)
 	}
-	if memory.Successes == nil {
-		memory.Successes = []string{}
+
+	return queueMessage(playRequest.ReplyChannelID(), buildStatusMessage(campaign), playRequest.InteractionObject.Token, playRequest.InteractionId)
+}
+
+// handleIntroCommand re-sends the campaign's title, premise, and introduction to the requester
+func handleIntroCommand(playRequest PlayRequest) error {
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	if campaign == nil || campaign.Blueprint.Title == "" {
+		return queueMessage(playRequest.ReplyChannelID(), "*The pages of destiny remain blank.* No tale has been woven here yet, so there is no beginning to recount.", playRequest.InteractionObject.Token, playRequest.InteractionId)
 	}
 
-	// TODO: Call Haiku model with proper input
-	// For now, provide a simple response
-	message := fmt.Sprintf("*Your words echo through the ages...* \"%s\"\n\n*In the shadowed depths of %s, fate begins to unfold...*", declaration, act.PrimaryArea)
+	messages := buildIntroMessages(playRequest.ReplyChannelID(), playRequest.InteractionObject.Token, campaign.Blueprint)
+	for i, message := range messages {
+		if err := sendQueueMessage(message, fmt.Sprintf("%s-intro-%d", playRequest.InteractionId, i)); err != nil {
+			return fmt.Errorf("failed to re-send intro: %w", err)
+		}
+	}
 
-	return sendMessageToQueue(playRequest.CampaignId, message, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	playRequest.logger().Printf("Re-sent intro for campaign %s to user %s", playRequest.CampaignId, getUserID(playRequest.InteractionObject))
+	return nil
 }
 
-// handleSQSRequest processes SQS events
-func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) error {
-	var errors []error
+// defaultMaxActivePlayers caps the party when a campaign predates Party.MaxActivePlayers
+const defaultMaxActivePlayers = 9
+
+// joinOutcome describes how a /syrus join request changed the party
+type joinOutcome int
+
+const (
+	joinAdded joinOutcome = iota
+	joinRejoined
+	joinAlreadyHost
+	joinPartyFull
+)
 
-	for _, message := range sqsEvent.Records {
-		var playRequest PlayRequest
-		if err := json.Unmarshal([]byte(message.Body), &playRequest); err != nil {
-			log.Printf("Failed to unmarshal play request: %v", err)
-			errors = append(errors, err)
+// addPartyMember adds userID to the party as a player. The host already counts as a member,
+// and a returning player has their JoinedAt refreshed rather than being listed twice.
+func addPartyMember(party *models.Party, userID string, now time.Time) joinOutcome {
+	for i, member := range party.Members {
+		if member.UserID != userID {
 			continue
 		}
+		if member.Role == "host" {
+			return joinAlreadyHost
+		}
+		party.Members[i].JoinedAt = now
+		return joinRejoined
+	}
 
-		if err := handlePlayRequest(ctx, playRequest); err != nil {
-			log.Printf("Failed to process play request: %v", err)
-			errors = append(errors, err)
-			continue
+	maxPlayers := party.MaxActivePlayers
+	if maxPlayers <= 0 {
+		maxPlayers = defaultMaxActivePlayers
+	}
+	if len(party.Members) >= maxPlayers {
+		return joinPartyFull
+	}
+
+	party.Members = append(party.Members, models.PartyMember{
+		UserID:   userID,
+		Role:     "player",
+		JoinedAt: now,
+	})
+	return joinAdded
+}
+
+// handleJoinCommand adds the requester to the campaign's party
+func handleJoinCommand(playRequest PlayRequest) error {
+	reply := func(content string) error {
+		return queueMessage(playRequest.ReplyChannelID(), content, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return reply("*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.")
+	}
+	if campaign == nil {
+		return reply("*The pages of destiny remain blank.* No tale has been woven here yet, so there is no party to join.")
+	}
+	if campaign.Status == models.CampaignStatusEnded {
+		return reply("*This tale has already reached its end.* The party has disbanded; wait for a new story to begin.")
+	}
+	if campaign.IsSolo() {
+		return reply("*This tale is told for one.* Its traveler walks alone; begin a tale of your own to walk beside them in spirit.")
+	}
+
+	userID := getUserID(playRequest.InteractionObject)
+	if userID == "" {
+		return reply("*The mists cannot discern your face.* I cannot add a stranger without a name to the party.")
+	}
+
+	// The conditional write expects the member count we loaded; a concurrent join fails it
+	loadedCount := len(campaign.Party.Members)
+	outcome := addPartyMember(&campaign.Party, userID, time.Now().UTC())
+	switch outcome {
+	case joinAlreadyHost:
+		return reply("*You already stand at the head of this tale.* The host needs no invitation to their own story.")
+	case joinPartyFull:
+		return reply("*The circle is full.* No more adventurers may join this tale; perhaps you may watch from the shadows.")
+	}
+
+	playRequest.forgetCampaign()
+	if err := storeParty(playRequest.CampaignId, campaign.HostID, campaign.Party.Members, loadedCount); err != nil {
+		if dedup.IsConditionalCheckFailed(err) {
+			playRequest.logger().Printf("Party for campaign %s changed during join by %s", playRequest.CampaignId, userID)
+			return reply("*The circle shifts as you approach.* Others are joining at this very moment; try again.")
 		}
+		return fmt.Errorf("failed to save party members: %w", err)
+	}
+
+	playRequest.logger().Printf("User %s joined campaign %s (outcome %d, party size %d)", userID, playRequest.CampaignId, outcome, len(campaign.Party.Members))
+	if outcome == joinRejoined {
+		return reply(fmt.Sprintf("*The fire welcomes you back, <@%s>.* Your place in the party awaits.", userID))
+	}
+	return reply(fmt.Sprintf("*A new figure steps into the firelight.* <@%s> joins the party.", userID))
+}
+
+// leaveOutcome describes how a /syrus leave request changed the party
+type leaveOutcome int
 
-		// Mark as processed in dedup table
-		if err := writeDedup(playRequest.InteractionId); err != nil {
-			log.Printf("Failed to write dedup: %v", err)
-			// Don't add to errors - message was processed successfully, dedup is just safety
+const (
+	leaveRemoved leaveOutcome = iota
+	leaveHostPromoted
+	leaveNotMember
+	leaveLastMember
+)
+
+// removePartyMember removes userID from the party. A departing host hands the weave to the
+// earliest-joined remaining member; the last member cannot leave. Returns the resulting host ID.
+func removePartyMember(party *models.Party, hostID, userID string) (string, leaveOutcome) {
+	index := -1
+	for i, member := range party.Members {
+		if member.UserID == userID {
+			index = i
+			break
 		}
 	}
+	if index < 0 {
+		return hostID, leaveNotMember
+	}
+	if len(party.Members) == 1 {
+		return hostID, leaveLastMember
+	}
 
-	// If any messages failed, return error (SQS will retry failed messages)
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to process %d message(s): %v", len(errors), errors)
+	leaving := party.Members[index]
+	party.Members = append(party.Members[:index:index], party.Members[index+1:]...)
+	if leaving.Role != "host" && leaving.UserID != hostID {
+		return hostID, leaveRemoved
 	}
 
+	heir := 0
+	for i, member := range party.Members {
+		if member.JoinedAt.Before(party.Members[heir].JoinedAt) {
+			heir = i
+		}
+	}
+	party.Members[heir].Role = "host"
+	return party.Members[heir].UserID, leaveHostPromoted
+}
+
+// handleLeaveCommand removes the requester from the campaign's party, promoting a new host if needed
+func handleLeaveCommand(playRequest PlayRequest) error {
+	reply := func(content string) error {
+		return queueMessage(playRequest.ReplyChannelID(), content, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return reply("*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.")
+	}
+	if campaign == nil {
+		return reply("*The pages of destiny remain blank.* No tale has been woven here yet, so there is no party to leave.")
+	}
+
+	userID := getUserID(playRequest.InteractionObject)
+	loadedCount := len(campaign.Party.Members)
+	hostID, outcome := removePartyMember(&campaign.Party, campaign.HostID, userID)
+	switch outcome {
+	case leaveNotMember:
+		return reply("*You are not among this party.* There is no place at the fire for you to leave.")
+	case leaveLastMember:
+		return reply("*You are the last soul holding the weave.* If the tale is done, close it with `/campaign end`.")
+	}
+	campaign.HostID = hostID
+
+	playRequest.forgetCampaign()
+	if err := storeParty(playRequest.CampaignId, campaign.HostID, campaign.Party.Members, loadedCount); err != nil {
+		if dedup.IsConditionalCheckFailed(err) {
+			playRequest.logger().Printf("Party for campaign %s changed during leave by %s", playRequest.CampaignId, userID)
+			return reply("*The circle shifts as you turn to go.* Others are coming and going at this very moment; try again.")
+		}
+		return fmt.Errorf("failed to save party members: %w", err)
+	}
+
+	playRequest.logger().Printf("User %s left campaign %s (outcome %d, host %s, party size %d)", userID, playRequest.CampaignId, outcome, hostID, len(campaign.Party.Members))
+	if outcome == leaveHostPromoted {
+		return reply(fmt.Sprintf("*<@%s> steps away from the fire.* The weave passes to <@%s>, who now holds the threads of this tale.", userID, hostID))
+	}
+	return reply(fmt.Sprintf("*<@%s> steps away from the fire.* <@%s> still holds the weave.", userID, hostID))
+}
+
+// sendQueueMessage sends a prepared message to the messaging SQS queue
+func sendQueueMessage(message models.MessagingQueueMessage, deduplicationID string) error {
+	queueURL := os.Getenv("SYRUS_MESSAGING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_MESSAGING_QUEUE_URL environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := sqs.New(sess)
+
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(message.ChannelID),
+		MessageDeduplicationId: aws.String(deduplicationID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to queue: %w", err)
+	}
+
+	return nil
+}
+
+// handleDeclareCommand processes a /syrus declare command
+func handleDeclareCommand(ctx context.Context, playRequest PlayRequest, declaration string) error {
+	declaration, rejection := sanitizeDeclaration(declaration, maxDeclarationLength)
+	if rejection != "" {
+		playRequest.logger().Printf("Rejected declaration for interaction %s: %s", playRequest.InteractionId, rejection)
+		return queueMessage(playRequest.ReplyChannelID(), rejection, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	playRequest.logger().Printf("Processing declare command: %s", declaration)
+
+	// Get campaign
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	if campaign == nil {
+		return queueMessage(playRequest.ReplyChannelID(), "*The pages of destiny remain blank.* This tale has not yet begun. The story awaits your first step.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	// A redelivered declaration whose narration was already recorded resumes by re-sending it,
+	// rather than narrating (and charging for) the same turn twice
+	if last := campaign.Runtime.TurnState.LastNarration; last != nil && last.InteractionID != "" && last.InteractionID == playRequest.InteractionId {
+		playRequest.logger().Printf("Interaction %s was already narrated for campaign %s, re-sending the narration", playRequest.InteractionId, playRequest.CampaignId)
+		return queueMessage(playRequest.ReplyChannelID(), last.Narration, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	// Validate campaign status; an archived tale is finished whatever status it was archived with
+	switch {
+	case campaign.Status == models.CampaignStatusEnded || campaign.IsArchived():
+		return queueMessage(playRequest.ReplyChannelID(), "*The final page has been written.* This adventure has passed into legend. The tale is complete, the heroes immortalized in song. Try `/syrus start` to begin a new tale.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	case campaign.Status == models.CampaignStatusConfiguring:
+		return queueMessage(playRequest.ReplyChannelID(), "*The ink is still wet on the contract.* Your campaign is still being prepared. The world awaits your final choices.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	case campaign.Status == models.CampaignStatusActive || campaign.Status == models.CampaignStatusPlaying:
+		// Check lifecycle for paused state
+		if campaign.Lifecycle.Paused {
+			return queueMessage(playRequest.ReplyChannelID(), "*Time itself holds its breath.* The tale rests in stasis, waiting for the moment to continue. Try `/syrus resume` to continue the story.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+		}
+	}
+
+	// A campaign can be active with a partial blueprint if blueprinting failed midway
+	if missing := missingBlueprintFields(campaign.Blueprint); len(missing) > 0 {
+		playRequest.logger().Printf("Campaign %s has an incomplete blueprint (missing %s)", playRequest.CampaignId, strings.Join(missing, ", "))
+		if shouldReweaveBlueprint(campaign, time.Now().UTC()) {
+			reweaveBlueprint(campaign.CampaignID, campaign.ReplyChannelID(), playRequest.InteractionId)
+		}
+		return queueMessage(playRequest.ReplyChannelID(), "*The tale is still being woven.* Syrus has not finished shaping this adventure. Give the loom a few moments, then declare again.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	// A group decision whose vote has closed is settled before the declaration is routed
+	if decision := campaign.Runtime.TurnState.ActiveDecision; decision != nil && !time.Now().UTC().Before(decision.ExpiresAt) {
+		if err := resolveGroupDecision(playRequest, campaign, pluralityChoice(*decision)); err != nil {
+			return err
+		}
+	}
+
+	// Enforce the campaign's decision model
+	userID := getUserID(playRequest.InteractionObject)
+	route := routeDeclaration(campaign, userID)
+	if route == RouteConsensus && !isEnabled(models.FeatureGroupVoting) {
+		route = RouteNarrate
+	}
+	switch route {
+	case RouteInvalidModel:
+		playRequest.logger().Printf("Campaign %s has unknown decision model %q", playRequest.CampaignId, campaign.DecisionModel)
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient runes have been defiled.* This tale does not know who guides its choices. Seek the wisdom of the elders to restore the chronicle.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	case RouteDeniedNotHost:
+		playRequest.logger().Printf("User %s may not declare in host-decision campaign %s", userID, playRequest.CampaignId)
+		return queueMessage(playRequest.ReplyChannelID(), "*The threads answer to one hand alone.* The host guides this tale. Share your counsel with them, and let their voice carry the party forward.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	// A cheap read of the declaration turns away out-of-scope or abusive input before narration is paid for
+	var intent *models.DeclarationIntent
+	if route != RouteConsensus && isEnabled(models.FeatureIntentParsing) {
+		parsed, err := parseDeclarationIntent(ctx, campaign, declaration)
+		switch {
+		case err != nil:
+			playRequest.logger().Printf("Warning: intent parsing failed for campaign %s, narrating without it: %v", playRequest.CampaignId, err)
+		case parsed.redirect() != "":
+			playRequest.logger().Printf("Redirected %s declaration for interaction %s (out of scope %t, abusive %t)", parsed.Category, playRequest.InteractionId, parsed.OutOfScope, parsed.Abusive)
+			return queueMessage(playRequest.ReplyChannelID(), parsed.redirect(), playRequest.InteractionObject.Token, playRequest.InteractionId)
+		default:
+			intent = &models.DeclarationIntent{Category: parsed.Category, Targets: parsed.Targets}
+		}
+	}
+
+	// The declaration is accepted; record the heartbeat before routing it
+	recordDeclarationHeartbeat(campaign, time.Now().UTC())
+	startPlaying(campaign)
+
+	if route == RouteConsensus {
+		return handleConsensusDeclaration(playRequest, campaign, declaration)
+	}
+
+	// Load current act and memory
+	currentAct := campaign.Runtime.CurrentAct
+	if currentAct < 0 || currentAct >= len(campaign.Blueprint.Acts) {
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient runes have been defiled.* The structure of this tale is corrupted. Seek the wisdom of the elders to restore the chronicle.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	declared := models.PendingDeclaration{UserID: userID, Declaration: declaration, DeclaredAt: time.Now().UTC(), Intent: intent}
+	if campaign.EffectivePlayStyle() == models.PlayStyleAsynchronous {
+		return handleAsyncDeclaration(ctx, playRequest, campaign, userID, declared)
+	}
+
+	return narrateDeclarations(ctx, playRequest, campaign, userID, []models.PendingDeclaration{declared})
+}
+
+// queueNarration posts narration to the campaign's channel. A WhatsApp campaign is narrated by direct
+// message to its player, which carries no interaction to answer.
+func queueNarration(playRequest PlayRequest, campaign *models.Campaign, content string) error {
+	if platform := campaign.MessagingPlatform(); platform != models.PlatformDiscord {
+		message := models.MessagingQueueMessage{ChannelID: playRequest.ReplyChannelID(), Content: content, Platform: platform, InteractionID: playRequest.InteractionId}
+		return queuePrepared(message, playRequest.InteractionId+"-play")
+	}
+	return queueMessage(playRequest.ReplyChannelID(), content, playRequest.InteractionObject.Token, playRequest.InteractionId)
+}
+
+// narrateDeclarations advances the story for one or more declarations (several when an asynchronous batch closes)
+func narrateDeclarations(ctx context.Context, playRequest PlayRequest, campaign *models.Campaign, userID string, declarations []models.PendingDeclaration) error {
+	currentAct := campaign.Runtime.CurrentAct
+	act := campaign.Blueprint.Acts[currentAct]
+
+	narrationModel, affordable := costs.Choose(campaign.CostTracking, resolveModel(campaign.ModelPolicy.Narration, playRequest.ModelOverride, userID))
+	if !affordable {
+		playRequest.logger().Printf("Campaign %s has spent its narration budget", playRequest.CampaignId)
+		return queueMessage(playRequest.ReplyChannelID(), costs.ThinWeaveMessage, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	temperature := narrationTemperature(temperatureRamp, act, campaign.Runtime)
+	playRequest.logger().Printf("Using narration model: %s (temperature %.2f at beat %d, pressure %d)", narrationModel, temperature, campaign.Runtime.CurrentBeat, campaign.Runtime.Pressure.Level)
+
+	response, err := composeNarration(ctx, campaign, narrationModel, declarations, temperature, "")
+	if err != nil {
+		playRequest.logger().Printf("Narration failed for campaign %s: %v", playRequest.CampaignId, err)
+		return queueMessage(playRequest.ReplyChannelID(), narrationFailureMessage, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	instruction, lead := "", ""
+	if response.RollRequired {
+		rollType := response.RollType
+		var rolls []diceRoll
+		response, rolls = resolveRoll(ctx, campaign, narrationModel, declarations, temperature, response)
+		instruction, lead = rollInstruction(rolls, rollType), formatRolls(rolls)+"\n\n"
+	}
+	message := response.Message
+
+	// Keep the story consistent with established facts
+	regenerate := continuityRegenerator(ctx, campaign, narrationModel, declarations, temperature, instruction, lead)
+	message, issues := enforceContinuity(message, campaign.Memory.Global.CanonicalFacts, regenerate)
+	if len(issues) > 0 {
+		flagContinuityForHost(playRequest, campaign, userID, issues)
+	}
+
+	// Persist the outcome together with the narration before posting it. If the post fails, the SQS retry
+	// finds this interaction's narration already recorded and re-sends it rather than narrating again.
+	record := models.NarrationRecord{
+		Declarations:     declarations,
+		Narration:        message,
+		InteractionToken: playRequest.InteractionObject.Token,
+		InteractionID:    playRequest.InteractionId,
+		Model:            narrationModel,
+		Temperature:      temperature,
+		NarratedAt:       time.Now().UTC(),
+	}
+	campaign.Runtime.TurnState.LastNarration = &record
+	heldBoons := len(campaign.Party.Boons.Available)
+	playRequest.forgetCampaign()
+	if err := applyHaikuResponse(campaign, *response); err != nil {
+		if errors.Is(err, errNarrationAlreadyApplied) {
+			playRequest.logger().Printf("Interaction %s was already narrated for campaign %s, not posting again", playRequest.InteractionId, playRequest.CampaignId)
+			return nil
+		}
+		// Still post: the players are owed the narration even if the story state could not be saved
+		playRequest.logger().Printf("Warning: failed to apply narration outcome to campaign %s: %v", playRequest.CampaignId, err)
+		// Boons that were not saved are not announced
+		campaign.Party.Boons.Available = campaign.Party.Boons.Available[:heldBoons]
+	}
+
+	if err := queueNarration(playRequest, campaign, message); err != nil {
+		return err
+	}
+
+	for i, boon := range campaign.Party.Boons.Available[heldBoons:] {
+		announcement := models.MessagingQueueMessage{ChannelID: playRequest.ReplyChannelID(), Content: boonAwardMessage(boon), InteractionID: playRequest.InteractionId, Platform: campaign.MessagingPlatform()}
+		if err := queuePrepared(announcement, fmt.Sprintf("%s-boon%d", playRequest.InteractionId, i)); err != nil {
+			playRequest.logger().Printf("Warning: failed to announce boon %s for campaign %s: %v", boon.Name, playRequest.CampaignId, err)
+		}
+	}
+
+	if campaign.Runtime.CurrentAct != currentAct {
+		transition := models.MessagingQueueMessage{ChannelID: playRequest.ReplyChannelID(), Content: actTransitionMessage(campaign), InteractionID: playRequest.InteractionId, Platform: campaign.MessagingPlatform()}
+		if err := queuePrepared(transition, playRequest.InteractionId+"-act"); err != nil {
+			playRequest.logger().Printf("Warning: failed to announce act %d for campaign %s: %v", campaign.Runtime.CurrentAct, playRequest.CampaignId, err)
+		}
+	}
+
+	// Milestone images are embellishments: one that is missing or fails to post never fails the turn
+	if response.ImageTrigger != "" {
+		releaseTriggeredImage(campaign, playRequest.ReplyChannelID(), playRequest.InteractionId, response.ImageTrigger)
+	}
+
+	if decisionReached(campaign, act) {
+		openGroupDecision(playRequest, campaign, act, time.Now().UTC())
+	}
+	return nil
+}
+
+// findTriggeredImage finds the milestone image a narration's imageTrigger names: by image ID,
+// or failing that by the image's sendWhen
+func findTriggeredImage(plan models.ImagePlan, trigger string) (string, models.ImagePlanItem, bool) {
+	trigger = strings.TrimSpace(trigger)
+	if item, ok := plan.AdditionalImages[trigger]; ok {
+		return trigger, item, true
+	}
+
+	imageIDs := make([]string, 0, len(plan.AdditionalImages))
+	for imageID := range plan.AdditionalImages {
+		imageIDs = append(imageIDs, imageID)
+	}
+	sort.Strings(imageIDs)
+
+	for _, imageID := range imageIDs {
+		if strings.EqualFold(imageID, trigger) {
+			return imageID, plan.AdditionalImages[imageID], true
+		}
+	}
+	for _, imageID := range imageIDs {
+		if item := plan.AdditionalImages[imageID]; strings.EqualFold(strings.TrimSpace(item.SendWhen), trigger) {
+			return imageID, item, true
+		}
+	}
+	return "", models.ImagePlanItem{}, false
+}
+
+// releaseTriggeredImage posts the cached milestone image named by a narration's imageTrigger to the channel,
+// once per campaign. Images imageGen has not generated yet are skipped; failures are logged.
+func releaseTriggeredImage(campaign *models.Campaign, channelID, interactionID, trigger string) {
+	logger := logging.For(interactionID, campaign.CampaignID)
+	imageID, item, ok := findTriggeredImage(campaign.Blueprint.ImagePlan, trigger)
+	if !ok {
+		logger.Printf("Image trigger %q matches no image in campaign %s's plan, skipping", trigger, campaign.CampaignID)
+		return
+	}
+	if item.S3Key == "" {
+		logger.Printf("Image %s for campaign %s has not been generated yet, skipping", imageID, campaign.CampaignID)
+		return
+	}
+
+	releaseKey := fmt.Sprintf("%s-%s", campaign.CampaignID, imageID)
+	claimed, err := claimProcessed(imageReleaseDedupPrefix, releaseKey, imageReleaseTTL)
+	if err != nil {
+		logger.Printf("Warning: failed to claim image release %s: %v", releaseKey, err)
+		return
+	}
+	if !claimed {
+		logger.Printf("Image %s was already released in campaign %s, skipping", imageID, campaign.CampaignID)
+		return
+	}
+
+	message := models.MessagingQueueMessage{
+		ChannelID:     channelID,
+		InteractionID: interactionID,
+		Attachments: []models.Attachment{
+			{
+				Name:        imageID + ".png",
+				Data:        item.S3Key, // S3 key - messaging fetches the cached image
+				ContentType: "image/png",
+			},
+		},
+	}
+	if err := queuePrepared(message, fmt.Sprintf("%s-image-%s", interactionID, imageID)); err != nil {
+		logger.Printf("Warning: failed to release image %s for campaign %s: %v", imageID, campaign.CampaignID, err)
+		if err := releaseProcessed(imageReleaseDedupPrefix, releaseKey); err != nil {
+			logger.Printf("Warning: failed to release image claim %s: %v", releaseKey, err)
+		}
+		return
+	}
+	logger.Printf("Released image %s to channel %s", imageID, channelID)
+}
+
+// boonAwardMessage announces a boon the party has earned from the campaign's BoonPlan
+func boonAwardMessage(boon models.BoonOption) string {
+	message := fmt.Sprintf("*A thread of fortune knots itself around the party.* You have earned **%s**.", boon.Name)
+	if description := strings.TrimSpace(boon.Description); description != "" {
+		message += "\n> " + description
+	}
+	return message
+}
+
+// boonTriggered reports whether a narration outcome meets a BoonPlan trigger: the success path it activated
+// or one of the flags it raised
+func boonTriggered(trigger string, resp HaikuResponse) bool {
+	trigger = strings.TrimSpace(trigger)
+	if trigger == "" {
+		return false
+	}
+	if strings.EqualFold(strings.TrimSpace(resp.SuccessPathActivated), trigger) {
+		return true
+	}
+	for _, flag := range resp.MemoryUpdates.Flags {
+		if strings.EqualFold(strings.TrimSpace(flag), trigger) {
+			return true
+		}
+	}
+	return false
+}
+
+// boonEntryAwarded reports whether a BoonPlan entry has already yielded one of its boons; each entry awards once
+func boonEntryAwarded(entry models.BoonPlanEntry, boons models.Boons) bool {
+	for _, option := range entry.Boons {
+		if boons.Has(option.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingBoonTriggers lists the triggers of BoonPlan entries that have not yet yielded a boon
+func pendingBoonTriggers(campaign *models.Campaign) []string {
+	var triggers []string
+	for _, entry := range campaign.Blueprint.BoonPlan {
+		if strings.TrimSpace(entry.Trigger) != "" && !boonEntryAwarded(entry, campaign.Party.Boons) {
+			triggers = append(triggers, entry.Trigger)
+		}
+	}
+	return triggers
+}
+
+// selectWeightedBoon picks one of options the party does not hold yet, weighted as birthing weights its
+// constraint seeds: an option without a weight counts as 1
+func selectWeightedBoon(options []models.BoonOption, held models.Boons) (models.BoonOption, bool) {
+	remaining := make([]models.BoonOption, 0, len(options))
+	totalWeight := 0
+	for _, option := range options {
+		if strings.TrimSpace(option.Name) == "" || held.Has(option.Name) {
+			continue
+		}
+		remaining = append(remaining, option)
+		totalWeight += boonWeight(option)
+	}
+	if len(remaining) == 0 {
+		return models.BoonOption{}, false
+	}
+
+	r := rollDie(totalWeight) - 1
+	sum := 0
+	for _, option := range remaining {
+		sum += boonWeight(option)
+		if r < sum {
+			return option, true
+		}
+	}
+	return remaining[len(remaining)-1], true
+}
+
+// boonWeight is an option's selection weight, defaulting to 1
+func boonWeight(option models.BoonOption) int {
+	if option.Weight <= 0 {
+		return 1
+	}
+	return option.Weight
+}
+
+// awardBoons adds a boon to the party for each BoonPlan entry the narration triggered and returns those awarded
+func awardBoons(campaign *models.Campaign, resp HaikuResponse) []models.BoonOption {
+	var awarded []models.BoonOption
+	for _, entry := range campaign.Blueprint.BoonPlan {
+		if !boonTriggered(entry.Trigger, resp) || boonEntryAwarded(entry, campaign.Party.Boons) {
+			continue
+		}
+		boon, ok := selectWeightedBoon(entry.Boons, campaign.Party.Boons)
+		if !ok {
+			log.Printf("Warning: boon trigger %q in campaign %s has no boon left to award", entry.Trigger, campaign.CampaignID)
+			continue
+		}
+		campaign.Party.Boons.Available = append(campaign.Party.Boons.Available, boon)
+		awarded = append(awarded, boon)
+	}
+	return awarded
+}
+
+// flagKey reduces a flag or trigger name to letters and digits in lower case, so the narrator's snake_case
+// flags match the blueprint's CamelCase names
+func flagKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// recordDecisionFlags raises, in the campaign's global memory, each of the blueprint's decision flags that the
+// narration reported, and returns those newly raised
+func recordDecisionFlags(campaign *models.Campaign, flags []string) []string {
+	reported := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		if key := flagKey(flag); key != "" {
+			reported[key] = true
+		}
+	}
+
+	var raised []string
+	for _, declared := range campaign.Blueprint.MemoryDirectives.DecisionFlags {
+		if !reported[flagKey(declared)] {
+			continue
+		}
+		if campaign.Memory.Global.DecisionFlags == nil {
+			campaign.Memory.Global.DecisionFlags = map[string]interface{}{}
+		}
+		if raisedBefore, _ := campaign.Memory.Global.DecisionFlags[declared].(bool); raisedBefore {
+			continue
+		}
+		campaign.Memory.Global.DecisionFlags[declared] = true
+		raised = append(raised, declared)
+	}
+	return raised
+}
+
+// maxCanonicalFacts caps the facts narration may promote into global memory, keeping the narrator's prompt bounded
+const maxCanonicalFacts = 50
+
+// canonicalFactKey normalizes a fact for deduplication, ignoring case, surrounding space, and a closing period
+func canonicalFactKey(fact string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(fact), ".!"))
+}
+
+// promoteCanonicalFacts records the facts a narration established as canonical facts in the campaign's global
+// memory, where continuity checks and later narration see them, and returns those newly promoted. Roll notes,
+// repeats of a known fact, and anything past maxCanonicalFacts are left to act memory alone.
+func promoteCanonicalFacts(campaign *models.Campaign, facts []string) []string {
+	known := make(map[string]bool, len(campaign.Memory.Global.CanonicalFacts))
+	for key := range campaign.Memory.Global.CanonicalFacts {
+		known[canonicalFactKey(key)] = true
+	}
+
+	var promoted []string
+	for _, fact := range facts {
+		fact = strings.TrimSpace(fact)
+		key := canonicalFactKey(fact)
+		if key == "" || known[key] || strings.HasPrefix(fact, rollNotePrefix) {
+			continue
+		}
+		if len(known) >= maxCanonicalFacts {
+			log.Printf("Warning: campaign %s holds %d canonical facts, not promoting %q", campaign.CampaignID, maxCanonicalFacts, fact)
+			continue
+		}
+		if campaign.Memory.Global.CanonicalFacts == nil {
+			campaign.Memory.Global.CanonicalFacts = map[string]interface{}{}
+		}
+		campaign.Memory.Global.CanonicalFacts[fact] = true
+		known[key] = true
+		promoted = append(promoted, fact)
+	}
+	return promoted
+}
+
+// actFailureTrigger matches failure path triggers tied to an act going badly, e.g. Act3Failure
+var actFailureTrigger = regexp.MustCompile(`^act(\d+)failure$`)
+
+// failurePathTriggered reports whether a failure path's trigger is met. A trigger naming an act's failure is met
+// once that act, reached by now, has recorded a failure; any other trigger is met when a decision flag or a flag
+// raised in any act so far carries its name.
+func failurePathTriggered(campaign *models.Campaign, trigger string) bool {
+	key := flagKey(trigger)
+	if key == "" {
+		return false
+	}
+
+	if match := actFailureTrigger.FindStringSubmatch(key); match != nil {
+		act, _ := strconv.Atoi(match[1])
+		if act < 1 || act-1 > campaign.Runtime.CurrentAct {
+			return false
+		}
+		return len(campaign.Memory.PerAct[fmt.Sprintf("%d", act-1)].Failures) > 0
+	}
+
+	for flag, value := range campaign.Memory.Global.DecisionFlags {
+		if raised, _ := value.(bool); raised && flagKey(flag) == key {
+			return true
+		}
+	}
+	for act := 0; act <= campaign.Runtime.CurrentAct; act++ {
+		for _, flag := range campaign.Memory.PerAct[fmt.Sprintf("%d", act)].Flags {
+			if flagKey(flag) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// failurePathActive reports whether a failure path is already in motion
+func failurePathActive(campaign *models.Campaign, id string) bool {
+	for _, active := range campaign.Runtime.ActiveFailurePaths {
+		if active == id {
+			return true
+		}
+	}
+	return false
+}
+
+// activateFailurePaths sets in motion each failure path whose trigger is now met, recording it against the
+// current act, and returns those newly activated
+func activateFailurePaths(campaign *models.Campaign) []models.FailurePath {
+	var activated []models.FailurePath
+	for _, path := range campaign.Blueprint.FailurePaths {
+		if path.ID == "" || failurePathActive(campaign, path.ID) || !failurePathTriggered(campaign, path.Trigger) {
+			continue
+		}
+		campaign.Runtime.ActiveFailurePaths = appendUnique(campaign.Runtime.ActiveFailurePaths, path.ID)
+
+		actKey := fmt.Sprintf("%d", campaign.Runtime.CurrentAct)
+		memory := campaign.Memory.PerAct[actKey]
+		memory.Failures = appendUnique(memory.Failures, path.ID)
+		campaign.Memory.PerAct[actKey] = memory
+		activated = append(activated, path)
+	}
+	return activated
+}
+
+// applyHaikuResponse folds a narration's outcome into the campaign: memory updates and activated paths go into
+// the current act's memory, an advanced beat moves the runtime forward, and the result is persisted
+func applyHaikuResponse(campaign *models.Campaign, resp HaikuResponse) error {
+	currentAct := campaign.Runtime.CurrentAct
+	actKey := fmt.Sprintf("%d", currentAct)
+	if campaign.Memory.PerAct == nil {
+		campaign.Memory.PerAct = map[string]models.ActMemory{}
+	}
+	memory := campaign.Memory.PerAct[actKey]
+
+	// Ensure memory structure exists
+	if memory.Beats == nil {
+		memory.Beats = new(int)
+	}
+	if memory.CombatSceneCount == nil {
+		memory.CombatSceneCount = new(int)
+	}
+	if memory.Flags == nil {
+		memory.Flags = []string{}
+	}
+	if memory.Failures == nil {
+		memory.Failures = []string{}
+	}
+	if memory.Successes == nil {
+		memory.Successes = []string{}
+	}
+
+	memory.Flags = appendUnique(memory.Flags, resp.MemoryUpdates.Flags...)
+	for _, fact := range resp.MemoryUpdates.Facts {
+		if strings.TrimSpace(fact) != "" {
+			memory.Notes = append(memory.Notes, fact)
+		}
+	}
+
+	if resp.BeatAdvanced {
+		campaign.Runtime.CurrentBeat++
+		*memory.Beats++
+	}
+	if resp.CombatOccurred {
+		*memory.CombatSceneCount++
+	}
+	if resp.FailurePathActivated != "" {
+		memory.Failures = appendUnique(memory.Failures, resp.FailurePathActivated)
+		campaign.Runtime.ActiveFailurePaths = appendUnique(campaign.Runtime.ActiveFailurePaths, resp.FailurePathActivated)
+	}
+	if resp.SuccessPathActivated != "" {
+		memory.Successes = appendUnique(memory.Successes, resp.SuccessPathActivated)
+	}
+	accepted, rejected := validateRelationshipUpdates(campaign.Blueprint.MemoryDirectives.RelationshipAxes, resp.RelationshipUpdates)
+	for _, update := range rejected {
+		log.Printf("Warning: campaign %s ignored relationship update %q -> %q outside its declared axes", campaign.CampaignID, update.Entity, update.State)
+	}
+	if len(accepted) > 0 {
+		if campaign.Memory.Global.Relationships == nil {
+			campaign.Memory.Global.Relationships = map[string]interface{}{}
+		}
+		if memory.RelationshipChanges == nil {
+			memory.RelationshipChanges = map[string]interface{}{}
+		}
+		for _, update := range accepted {
+			campaign.Memory.Global.Relationships[update.Entity] = update.State
+			memory.RelationshipChanges[update.Entity] = update.State
+		}
+	}
+	for _, boon := range awardBoons(campaign, resp) {
+		log.Printf("Campaign %s earned the boon %s", campaign.CampaignID, boon.Name)
+	}
+
+	for _, flag := range recordDecisionFlags(campaign, resp.MemoryUpdates.Flags) {
+		log.Printf("Campaign %s raised the decision flag %s", campaign.CampaignID, flag)
+	}
+	for _, fact := range promoteCanonicalFacts(campaign, resp.MemoryUpdates.Facts) {
+		log.Printf("Campaign %s established the canonical fact %q", campaign.CampaignID, fact)
+	}
+
+	campaign.Memory.PerAct[actKey] = memory
+	for _, path := range activateFailurePaths(campaign) {
+		log.Printf("Campaign %s set the failure path %s in motion", campaign.CampaignID, path.ID)
+	}
+	if limit := campaign.Blueprint.CombatConstraints.MaxCombatScenes; resp.CombatOccurred && limit > 0 && combatScenesPlayed(campaign.Memory.PerAct) > limit {
+		log.Printf("Warning: campaign %s narrated combat past its budget of %d scenes", campaign.CampaignID, limit)
+	}
+	if evaluateActProgression(campaign, resp) {
+		log.Printf("Campaign %s moved into act %d", campaign.CampaignID, campaign.Runtime.CurrentAct)
+	}
+	return storeCampaignProgress(campaign)
+}
+
+// validateRelationshipUpdates splits a narration's relationship updates into those naming a declared axis and
+// one of its states, and those that do not. Matching ignores case and surrounding space; accepted updates carry
+// the blueprint's own spelling, and a later update for the same entity replaces an earlier one.
+func validateRelationshipUpdates(axes []models.RelationshipAxis, updates []RelationshipUpdate) (accepted, rejected []RelationshipUpdate) {
+	normalize := func(s string) string { return strings.ToLower(strings.TrimSpace(s)) }
+
+	for _, update := range updates {
+		var matched *RelationshipUpdate
+		for _, axis := range axes {
+			if normalize(axis.Entity) != normalize(update.Entity) {
+				continue
+			}
+			for _, state := range axis.States {
+				if normalize(state) == normalize(update.State) {
+					matched = &RelationshipUpdate{Entity: axis.Entity, State: state}
+					break
+				}
+			}
+			break
+		}
+		if matched == nil {
+			rejected = append(rejected, update)
+			continue
+		}
+
+		replaced := false
+		for i := range accepted {
+			if accepted[i].Entity == matched.Entity {
+				accepted[i] = *matched
+				replaced = true
+			}
+		}
+		if !replaced {
+			accepted = append(accepted, *matched)
+		}
+	}
+	return accepted, rejected
+}
+
+// maxPressureLevel caps how far lateness in an act can raise Pressure.Level
+const maxPressureLevel = highPressureLevel
+
+// evaluateActProgression checks the current act after a narration. Each beat past the act's soft-pressure beat
+// raises Pressure.Level and records why; the act ends when the narration reports its completion condition met,
+// or when the hard-pressure beat forces it. Acts that end on a group vote are left for the vote to settle.
+// It reports whether the story moved into the next act.
+func evaluateActProgression(campaign *models.Campaign, resp HaikuResponse) bool {
+	if campaign.Runtime.CurrentAct < 0 || campaign.Runtime.CurrentAct >= len(campaign.Blueprint.Acts) {
+		return false
+	}
+	act := campaign.Blueprint.Acts[campaign.Runtime.CurrentAct]
+	signals := act.LateActSignals
+	beat := campaign.Runtime.CurrentBeat
+
+	if resp.BeatAdvanced && signals.SoftPressureAtBeat > 0 && beat >= signals.SoftPressureAtBeat {
+		pressure := &campaign.Runtime.Pressure
+		if pressure.Level < maxPressureLevel {
+			pressure.Level++
+		}
+		pressure.Causes = appendUnique(pressure.Causes, fmt.Sprintf("act %d is running long", campaign.Runtime.CurrentAct+1))
+	}
+
+	forced := signals.HardPressureAtBeat > 0 && beat >= signals.HardPressureAtBeat
+	if !resp.ActCompleted && !forced {
+		return false
+	}
+	if groupDecides(campaign, act) {
+		return false
+	}
+	return advanceAct(campaign)
+}
+
+// groupDecides reports whether the party settles the act's ending by vote
+func groupDecides(campaign *models.Campaign, act models.Act) bool {
+	return campaign.DecisionModel == models.DecisionModelGroup && isEnabled(models.FeatureGroupVoting) && act.Completion.IsChoice()
+}
+
+// advanceAct moves the story into the next act: the beat count and the act's lateness pressure start over,
+// and the new act gets a fresh memory entry. The final act has nowhere to advance to, so it reports false.
+func advanceAct(campaign *models.Campaign) bool {
+	next := campaign.Runtime.CurrentAct + 1
+	if next >= len(campaign.Blueprint.Acts) {
+		return false
+	}
+
+	campaign.Runtime.CurrentAct = next
+	campaign.Runtime.CurrentBeat = 0
+	campaign.Runtime.Pressure = models.Pressure{Level: 0, Causes: []string{}}
+	if campaign.Memory.PerAct == nil {
+		campaign.Memory.PerAct = map[string]models.ActMemory{}
+	}
+	actKey := fmt.Sprintf("%d", next)
+	if _, exists := campaign.Memory.PerAct[actKey]; !exists {
+		campaign.Memory.PerAct[actKey] = newActMemory()
+	}
+	return true
+}
+
+// newActMemory is the empty memory an act starts with
+func newActMemory() models.ActMemory {
+	return models.ActMemory{
+		KeyDecisions:        []interface{}{},
+		RelationshipChanges: map[string]interface{}{},
+		Notes:               []interface{}{},
+		Beats:               new(int),
+		CombatSceneCount:    new(int),
+		Flags:               []string{},
+		Failures:            []string{},
+		Successes:           []string{},
+	}
+}
+
+// actTransitionMessage announces the act the story has moved into
+func actTransitionMessage(campaign *models.Campaign) string {
+	act := campaign.Blueprint.Acts[campaign.Runtime.CurrentAct]
+	return fmt.Sprintf("*The tale turns.* Act %d: **%s**", campaign.Runtime.CurrentAct+1, act.Name)
+}
+
+// appendUnique appends the non-empty values not already present
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// Dice settings for rolls the narration asks for
+const (
+	defaultDieSides = 20
+	rollNotePrefix  = "Roll"
+)
+
+// rollMode is how many dice are rolled and which one counts
+type rollMode int
+
+const (
+	rollFlat         rollMode = iota // One die
+	rollAdvantage                    // Two dice, keep the higher
+	rollDisadvantage                 // Two dice, keep the lower
+)
+
+// dieSizePattern finds a die size such as "d20" in a narration's rollType
+var dieSizePattern = regexp.MustCompile(`(?i)\bd(\d+)\b`)
+
+// knownDieSizes are the dice a rollType may name; anything else is rolled as a d20
+var knownDieSizes = map[int]bool{4: true, 6: true, 8: true, 10: true, 12: true, 20: true, 100: true}
+
+// rollDie rolls one die with the given number of sides; overridden in tests
+var rollDie = func(sides int) int { return rand.Intn(sides) + 1 }
+
+// diceRoll is one resolved roll: the dice thrown and the result that counts
+type diceRoll struct {
+	UserID string // Empty when the party rolls together
+	Sides  int
+	Mode   rollMode
+	Dice   []int
+	Result int
+}
+
+// parseRollType reads the die size and advantage from a narration's free-form rollType,
+// e.g. "d20 stealth check with disadvantage". Unknown types are a flat d20.
+func parseRollType(rollType string) (int, rollMode) {
+	sides := defaultDieSides
+	if match := dieSizePattern.FindStringSubmatch(rollType); match != nil {
+		if n, err := strconv.Atoi(match[1]); err == nil && knownDieSizes[n] {
+			sides = n
+		}
+	}
+
+	lower := strings.ToLower(rollType)
+	switch {
+	case strings.Contains(lower, "disadvantage"):
+		return sides, rollDisadvantage
+	case strings.Contains(lower, "advantage"):
+		return sides, rollAdvantage
+	default:
+		return sides, rollFlat
+	}
+}
+
+// isContestedRoll reports whether the narration asked for a roll the players make against each other
+func isContestedRoll(rollType string) bool {
+	lower := strings.ToLower(rollType)
+	return strings.Contains(lower, "contested") || strings.Contains(lower, "opposed")
+}
+
+// throwDice rolls for one player under the given die and mode
+func throwDice(userID string, sides int, mode rollMode) diceRoll {
+	roll := diceRoll{UserID: userID, Sides: sides, Mode: mode, Dice: []int{rollDie(sides)}}
+	roll.Result = roll.Dice[0]
+	if mode == rollFlat {
+		return roll
+	}
+
+	second := rollDie(sides)
+	roll.Dice = append(roll.Dice, second)
+	if (mode == rollAdvantage && second > roll.Result) || (mode == rollDisadvantage && second < roll.Result) {
+		roll.Result = second
+	}
+	return roll
+}
+
+// resolveRolls throws the dice a narration asked for. A contested roll in a group-decision campaign
+// is made by every party member; otherwise a single declarant rolls, or the party rolls once together.
+func resolveRolls(campaign *models.Campaign, declarations []models.PendingDeclaration, rollType string) []diceRoll {
+	sides, mode := parseRollType(rollType)
+
+	if campaign.DecisionModel == models.DecisionModelGroup && isContestedRoll(rollType) && len(campaign.Party.Members) > 1 {
+		rolls := make([]diceRoll, 0, len(campaign.Party.Members))
+		for _, member := range campaign.Party.Members {
+			rolls = append(rolls, throwDice(member.UserID, sides, mode))
+		}
+		return rolls
+	}
+
+	roller := ""
+	for _, declared := range declarations {
+		if roller != "" && roller != declared.UserID {
+			roller = ""
+			break
+		}
+		roller = declared.UserID
+	}
+	return []diceRoll{throwDice(roller, sides, mode)}
+}
+
+// describe names the dice thrown, e.g. "d20 with advantage"
+func (r diceRoll) describe() string {
+	switch r.Mode {
+	case rollAdvantage:
+		return fmt.Sprintf("d%d with advantage", r.Sides)
+	case rollDisadvantage:
+		return fmt.Sprintf("d%d with disadvantage", r.Sides)
+	default:
+		return fmt.Sprintf("d%d", r.Sides)
+	}
+}
+
+// roller names who threw the dice in a message
+func (r diceRoll) roller() string {
+	if r.UserID == "" {
+		return "The party"
+	}
+	return fmt.Sprintf("<@%s>", r.UserID)
+}
+
+// formatRolls shows the dice to the players above the narration
+func formatRolls(rolls []diceRoll) string {
+	lines := make([]string, 0, len(rolls))
+	for _, roll := range rolls {
+		line := fmt.Sprintf("🎲 %s rolls a %s: **%d**", roll.roller(), roll.describe(), roll.Result)
+		if len(roll.Dice) > 1 {
+			line += fmt.Sprintf(" (%d and %d)", roll.Dice[0], roll.Dice[1])
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rollInstruction asks the second narration pass to narrate the outcome the dice decided
+func rollInstruction(rolls []diceRoll, rollType string) string {
+	var b strings.Builder
+	if rollType = strings.TrimSpace(rollType); rollType != "" {
+		fmt.Fprintf(&b, "The %s has been rolled:\n", rollType)
+	} else {
+		b.WriteString("The dice have been rolled:\n")
+	}
+	for _, roll := range rolls {
+		fmt.Fprintf(&b, "- %s: %d on a %s\n", roll.roller(), roll.Result, roll.describe())
+	}
+	b.WriteString("Narrate the outcome so it follows from these results: high rolls succeed, low rolls falter or cost something. ")
+	b.WriteString("Do not ask for another roll; set rollRequired to false.")
+	return b.String()
+}
+
+// rollNotes records the rolls as act memory notes
+func rollNotes(rolls []diceRoll, rollType string) []string {
+	label := rollNotePrefix
+	if rollType = strings.TrimSpace(rollType); rollType != "" {
+		label = fmt.Sprintf("%s (%s)", rollNotePrefix, rollType)
+	}
+	notes := make([]string, 0, len(rolls))
+	for _, roll := range rolls {
+		notes = append(notes, fmt.Sprintf("%s: %s rolled %d on a %s", label, roll.roller(), roll.Result, roll.describe()))
+	}
+	return notes
+}
+
+// resolveRoll throws the dice a narration asked for and narrates again so the outcome reflects them.
+// The rolls are shown above the narration and kept in act memory. If the second pass fails, the
+// first narration stands with the rolls shown, rather than losing the turn.
+func resolveRoll(ctx context.Context, campaign *models.Campaign, model models.Model, declarations []models.PendingDeclaration, temperature float64, first *HaikuResponse) (*HaikuResponse, []diceRoll) {
+	rolls := resolveRolls(campaign, declarations, first.RollType)
+	logging.FromContext(ctx).Printf("Resolved %d roll(s) of %q for campaign %s", len(rolls), first.RollType, campaign.CampaignID)
+
+	resolved := first
+	if second, err := composeNarration(ctx, campaign, model, declarations, temperature, rollInstruction(rolls, first.RollType)); err != nil {
+		logging.FromContext(ctx).Printf("Warning: roll narration failed for campaign %s, keeping the first narration: %v", campaign.CampaignID, err)
+	} else {
+		resolved = second
+	}
+
+	resolved.RollRequired = false
+	resolved.Message = formatRolls(rolls) + "\n\n" + resolved.Message
+	resolved.MemoryUpdates.Facts = append(resolved.MemoryUpdates.Facts, rollNotes(rolls, first.RollType)...)
+	return resolved, rolls
+}
+
+// errNarrationAlreadyApplied reports that the campaign already records the outcome of this interaction's narration
+var errNarrationAlreadyApplied = errors.New("narration already applied")
+
+// saveCampaignProgress persists the story state narration changes: the act and beat, pressure, active failure paths,
+// per-act memory, and the relationships, decision flags, and canonical facts in global memory.
+// When the campaign carries a new narration record it is written in the same update, conditional on the stored
+// record not already belonging to that interaction, so one interaction's outcome is applied at most once.
+func saveCampaignProgress(campaign *models.Campaign) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	perActAV, err := dynamodbattribute.Marshal(campaign.Memory.PerAct)
+	if err != nil {
+		return fmt.Errorf("failed to marshal act memory: %w", err)
+	}
+	failurePathsAV, err := dynamodbattribute.Marshal(campaign.Runtime.ActiveFailurePaths)
+	if err != nil {
+		return fmt.Errorf("failed to marshal active failure paths: %w", err)
+	}
+	pressureAV, err := dynamodbattribute.Marshal(campaign.Runtime.Pressure)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pressure: %w", err)
+	}
+	boons := campaign.Party.Boons.Available
+	if boons == nil {
+		boons = []models.BoonOption{}
+	}
+	boonsAV, err := dynamodbattribute.Marshal(boons)
+	if err != nil {
+		return fmt.Errorf("failed to marshal boons: %w", err)
+	}
+	relationships := campaign.Memory.Global.Relationships
+	if relationships == nil {
+		relationships = map[string]interface{}{}
+	}
+	relationshipsAV, err := dynamodbattribute.Marshal(relationships)
+	if err != nil {
+		return fmt.Errorf("failed to marshal relationships: %w", err)
+	}
+	decisionFlags := campaign.Memory.Global.DecisionFlags
+	if decisionFlags == nil {
+		decisionFlags = map[string]interface{}{}
+	}
+	decisionFlagsAV, err := dynamodbattribute.Marshal(decisionFlags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision flags: %w", err)
+	}
+	canonicalFacts := campaign.Memory.Global.CanonicalFacts
+	if canonicalFacts == nil {
+		canonicalFacts = map[string]interface{}{}
+	}
+	canonicalFactsAV, err := dynamodbattribute.Marshal(canonicalFacts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal canonical facts: %w", err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaign.CampaignID)},
+		},
+		UpdateExpression: aws.String("SET #runtime.#currentAct = :act, #runtime.#currentBeat = :beat, #runtime.#pressure = :pressure, #runtime.#activeFailurePaths = :failurePaths, #memory.#perAct = :perAct, #memory.#global.#relationships = :relationships, #memory.#global.#decisionFlags = :decisionFlags, #memory.#global.#canonicalFacts = :canonicalFacts, #party.#boons.#available = :boons, #lastUpdatedAt = :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":            aws.String("runtime"),
+			"#currentAct":         aws.String("currentAct"),
+			"#currentBeat":        aws.String("currentBeat"),
+			"#pressure":           aws.String("pressure"),
+			"#activeFailurePaths": aws.String("activeFailurePaths"),
+			"#memory":             aws.String("memory"),
+			"#perAct":             aws.String("perAct"),
+			"#global":             aws.String("global"),
+			"#relationships":      aws.String("relationships"),
+			"#decisionFlags":      aws.String("decisionFlags"),
+			"#canonicalFacts":     aws.String("canonicalFacts"),
+			"#party":              aws.String("party"),
+			"#boons":              aws.String("boons"),
+			"#available":          aws.String("available"),
+			"#lastUpdatedAt":      aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":act":            {N: aws.String(strconv.Itoa(campaign.Runtime.CurrentAct))},
+			":beat":           {N: aws.String(strconv.Itoa(campaign.Runtime.CurrentBeat))},
+			":pressure":       pressureAV,
+			":failurePaths":   failurePathsAV,
+			":perAct":         perActAV,
+			":relationships":  relationshipsAV,
+			":decisionFlags":  decisionFlagsAV,
+			":canonicalFacts": canonicalFactsAV,
+			":boons":          boonsAV,
+			":now":            {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+	}
+	if record := campaign.Runtime.TurnState.LastNarration; record != nil && record.InteractionID != "" {
+		if err := guardNarrationRecord(input, *record); err != nil {
+			return err
+		}
+	}
+
+	if _, err := svc.UpdateItem(input); err != nil {
+		if dedup.IsConditionalCheckFailed(err) {
+			return errNarrationAlreadyApplied
+		}
+		return fmt.Errorf("failed to update campaign progress: %w", err)
+	}
+	return nil
+}
+
+// guardNarrationRecord adds a narration record to a progress update, conditional on the stored record
+// not already belonging to the same interaction
+func guardNarrationRecord(input *dynamodb.UpdateItemInput, record models.NarrationRecord) error {
+	recordAV, err := dynamodbattribute.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal narration record: %w", err)
+	}
+
+	input.UpdateExpression = aws.String(*input.UpdateExpression + ", #runtime.#turnState.#lastNarration = :record")
+	input.ConditionExpression = aws.String("attribute_not_exists(#runtime.#turnState.#lastNarration.#interactionId) OR #runtime.#turnState.#lastNarration.#interactionId <> :interactionId")
+	input.ExpressionAttributeNames["#turnState"] = aws.String("turnState")
+	input.ExpressionAttributeNames["#lastNarration"] = aws.String("lastNarration")
+	input.ExpressionAttributeNames["#interactionId"] = aws.String("interactionId")
+	input.ExpressionAttributeValues[":record"] = recordAV
+	input.ExpressionAttributeValues[":interactionId"] = &dynamodb.AttributeValue{S: aws.String(record.InteractionID)}
+	return nil
+}
+
+// Anthropic API settings
+const (
+	anthropicTimeout = 4 * time.Minute // Claude can take a while; stays inside the 5 minute handler timeout
+	anthropicVersion = "2023-06-01"
+)
+
+// narrationModelIDs maps a campaign's narration (or intent parsing) model policy to a concrete Claude model
+var narrationModelIDs = map[models.Model]string{
+	models.ModelHaiku:  "claude-3-5-haiku-20241022",
+	models.ModelSonnet: "claude-sonnet-4-20250514",
+}
+
+// narrationFailureMessage is sent in place of narration when the model call or its response fails
+const narrationFailureMessage = "*The loom shudders and the threads slip from the weaver's hands.* Syrus could not find the words for this moment. Declare again, and the tale will try once more."
+
+// narrationResponseFormat tells the model to answer with the fields of HaikuResponse
+const narrationResponseFormat = `Respond with a single JSON object and nothing else:
+{
+  "message": "the narration shown to the party (under 1500 characters)",
+  "beatAdvanced": true or false,
+  "actCompleted": true if the act's completion condition is now met, otherwise false,
+  "rollRequired": true or false,
+  "rollType": "the kind of roll, or null",
+  "combatOccurred": true or false,
+  "failurePathActivated": "the failure path id, or null",
+  "successPathActivated": "the success path id, or null",
+  "memoryUpdates": {"flags": ["short_snake_case_flags"], "facts": ["facts the story must now respect"]},
+  "relationshipUpdates": [{"entity": "a tracked entity", "state": "one of its listed states"}],
+  "imageTrigger": "an image moment id, or null"
+}`
+
+// Intent categories a declaration is classified into
+const (
+	IntentAction        = "action"
+	IntentInvestigation = "investigation"
+	IntentDialogue      = "dialogue"
+	IntentMeta          = "meta"
+)
+
+// ParsedIntent is the intent parsing model's reading of a declaration
+type ParsedIntent struct {
+	Category   string   `json:"category"`
+	Targets    []string `json:"targets"`
+	OutOfScope bool     `json:"outOfScope"`
+	Abusive    bool     `json:"abusive"`
+}
+
+// Redirects sent in place of narration for declarations intent parsing turned away
+const (
+	outOfScopeRedirect = "*The weaver tilts their head, the thread slack in their hands.* That lies beyond the edges of this tale. Tell Syrus what your character does within the story, and the loom will answer."
+	abusiveRedirect    = "*The loom falls silent, and the threads will not take that shape.* Syrus will not weave those words into the tale. Declare what your character does, and the story will continue."
+)
+
+// redirect returns the in-character reply for a declaration that should not be narrated, or "" to narrate it
+func (p ParsedIntent) redirect() string {
+	switch {
+	case p.Abusive:
+		return abusiveRedirect
+	case p.OutOfScope:
+		return outOfScopeRedirect
+	}
+	return ""
+}
+
+// intentResponseFormat tells the intent parsing model to answer with the fields of ParsedIntent
+const intentResponseFormat = `Respond with a single JSON object and nothing else:
+{
+  "category": "action", "investigation", "dialogue", or "meta",
+  "targets": ["characters, creatures, places, or objects the declaration acts on"],
+  "outOfScope": true if the declaration has nothing to do with playing this story, otherwise false,
+  "abusive": true if the declaration is hateful, harassing, or sexual content, otherwise false
+}`
+
+// buildIntentSystemPrompt gives the intent parsing model just enough of the campaign to judge what is in scope
+func buildIntentSystemPrompt(campaign *models.Campaign) string {
+	var b strings.Builder
+	b.WriteString("You read a player's declaration in a fantasy campaign before it is narrated. ")
+	b.WriteString("Classify it: an action the character takes, an investigation of the world, dialogue with someone, or meta talk about the game itself. ")
+	b.WriteString("Bold, strange, or foolish choices are in scope; only input unrelated to playing the story is out of scope.\n\n")
+	fmt.Fprintf(&b, "Campaign: %s\nPremise: %s\n", campaign.Blueprint.Title, campaign.Blueprint.Premise)
+	if act := campaign.Runtime.CurrentAct; act >= 0 && act < len(campaign.Blueprint.Acts) {
+		fmt.Fprintf(&b, "Current act: %s, in %s\n", campaign.Blueprint.Acts[act].Name, campaign.Blueprint.Acts[act].PrimaryArea)
+	}
+	b.WriteString("\n")
+	b.WriteString(intentResponseFormat)
+	return b.String()
+}
+
+// parseIntentResponse parses the intent parsing model's reply. An unknown category is read as an action.
+func parseIntentResponse(text string) (*ParsedIntent, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+
+	var intent ParsedIntent
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &intent); err != nil {
+		return nil, fmt.Errorf("failed to parse intent response: %w", err)
+	}
+	intent.Category = strings.ToLower(strings.TrimSpace(intent.Category))
+	switch intent.Category {
+	case IntentAction, IntentInvestigation, IntentDialogue, IntentMeta:
+	default:
+		intent.Category = IntentAction
+	}
+
+	targets := intent.Targets[:0]
+	for _, target := range intent.Targets {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	intent.Targets = targets
+	return &intent, nil
+}
+
+// parseDeclarationIntent asks the campaign's intent parsing model to read a declaration. It errors when the
+// campaign cannot afford the call, so the declaration is narrated unread rather than refused.
+func parseDeclarationIntent(ctx context.Context, campaign *models.Campaign, declaration string) (*ParsedIntent, error) {
+	policy := campaign.ModelPolicy.IntentParsing
+	if policy == "" {
+		policy = models.ModelHaiku
+	}
+	model, affordable := costs.Choose(campaign.CostTracking, policy)
+	if !affordable {
+		return nil, fmt.Errorf("campaign has spent its intent parsing budget")
+	}
+	modelID, ok := narrationModelIDs[model]
+	if !ok {
+		return nil, fmt.Errorf("no intent parsing model for policy %q", model)
+	}
+
+	apiKey, err := fetchAnthropicAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Anthropic API key: %w", err)
+	}
+
+	settings := models.CallSettingsFor(models.CallIntentParsing, model)
+	text, err := callIntentModel(ctx, apiKey, modelID, settings, buildIntentSystemPrompt(campaign), declaration)
+	if err != nil {
+		return nil, err
+	}
+
+	if class, billed := costs.ClassOf(model); billed {
+		if err := recordModelUsage(campaign.CampaignID, class); err != nil {
+			logging.FromContext(ctx).Printf("Warning: failed to record intent parsing usage for campaign %s: %v", campaign.CampaignID, err)
+		}
+	}
+
+	return parseIntentResponse(text)
+}
+
+// composeNarration asks the narration model to narrate a set of declarations.
+// A non-empty instruction steers the model away from a previous take.
+func composeNarration(ctx context.Context, campaign *models.Campaign, model models.Model, declarations []models.PendingDeclaration, temperature float64, instruction string) (*HaikuResponse, error) {
+	modelID, ok := narrationModelIDs[model]
+	if !ok {
+		return nil, fmt.Errorf("no narration model for policy %q", model)
+	}
+
+	apiKey, err := fetchAnthropicAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Anthropic API key: %w", err)
+	}
+
+	systemPrompt := buildNarrationSystemPrompt(campaign)
+	userPrompt := buildNarrationUserPrompt(declarations, instruction)
+	settings := models.CallSettingsFor(models.CallNarration, model)
+	settings.Temperature = temperature
+	text, err := callNarrationModel(ctx, apiKey, modelID, settings, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The call is spent even if its reply cannot be parsed
+	if class, billed := costs.ClassOf(model); billed {
+		if err := recordModelUsage(campaign.CampaignID, class); err != nil {
+			logging.FromContext(ctx).Printf("Warning: failed to record narration usage for campaign %s: %v", campaign.CampaignID, err)
+		}
+	}
+
+	narration, err := parseHaikuResponse(text)
+	captureNarrationExchange(ctx, campaign, modelID, systemPrompt, userPrompt, text, err)
+	return narration, err
+}
+
+// captureNarrationExchange writes the narration prompt, raw response, and parse outcome to the
+// capture bucket. It is a no-op unless SYRUS_CAPTURE_BUCKET is set, and never fails the caller.
+func captureNarrationExchange(ctx context.Context, campaign *models.Campaign, modelID, systemPrompt, userPrompt, response string, parseErr error) {
+	record := capture.Record{
+		Kind:              "play",
+		CampaignType:      string(campaign.CampaignType),
+		Model:             modelID,
+		ValidationOutcome: capture.Outcome(parseErr),
+		SystemPrompt:      systemPrompt,
+		Prompt:            userPrompt,
+		Response:          response,
+	}
+	if parseErr != nil {
+		record.ValidationError = parseErr.Error()
+	}
+
+	key, err := capture.Write(record)
+	if err != nil {
+		logging.FromContext(ctx).Printf("Warning: failed to capture narration exchange: %v", err)
+		return
+	}
+	if key != "" {
+		logging.FromContext(ctx).Printf("Captured narration exchange to %s", key)
+	}
+}
+
+// buildNarrationSystemPrompt grounds the narrator in the campaign: its premise, pillars, current act, and memory
+func buildNarrationSystemPrompt(campaign *models.Campaign) string {
+	blueprint := campaign.Blueprint
+	currentAct := campaign.Runtime.CurrentAct
+	act := blueprint.Acts[currentAct]
+
+	var b strings.Builder
+	if campaign.IsSolo() {
+		b.WriteString("You are Syrus, the narrator of a solo fantasy campaign told to a single player. ")
+		b.WriteString("Address the lone traveler directly in second person, narrating the outcome of their declarations vividly but briefly, and never decide for them.\n\n")
+	} else {
+		b.WriteString("You are Syrus, the narrator of a collaborative fantasy campaign played in Discord. ")
+		b.WriteString("Narrate the outcome of the party's declarations in second person, vividly but briefly, and never decide for the players.\n\n")
+	}
+
+	fmt.Fprintf(&b, "Campaign: %s\nPremise: %s\n", blueprint.Title, blueprint.Premise)
+	if len(blueprint.ThematicPillars) > 0 {
+		fmt.Fprintf(&b, "Thematic pillars: %s\n", strings.Join(blueprint.ThematicPillars, "; "))
+	}
+
+	fmt.Fprintf(&b, "\nCurrent act (%d of %d): %s\n", currentAct+1, len(blueprint.Acts), act.Name)
+	fmt.Fprintf(&b, "Primary area: %s\n", act.PrimaryArea)
+	if act.NarrativePurpose != "" {
+		fmt.Fprintf(&b, "Purpose: %s\n", act.NarrativePurpose)
+	}
+	if act.PrimaryDanger != "" {
+		fmt.Fprintf(&b, "Primary danger: %s\n", act.PrimaryDanger)
+	}
+	fmt.Fprintf(&b, "Beat %d of about %d expected\n", campaign.Runtime.CurrentBeat, act.ExpectedBeats)
+	if act.Completion.Condition != "" {
+		fmt.Fprintf(&b, "The act ends when: %s\n", act.Completion.Condition)
+	}
+	if soft := act.LateActSignals.SoftPressureAtBeat; soft > 0 && campaign.Runtime.CurrentBeat >= soft {
+		b.WriteString("The act is running long: steer the scene toward its conclusion.\n")
+	}
+
+	memory := campaign.Memory.PerAct[fmt.Sprintf("%d", currentAct)]
+	if memory.Summary != nil && *memory.Summary != "" {
+		fmt.Fprintf(&b, "\nThe story of this act so far: %s\n", *memory.Summary)
+	}
+	if len(memory.Flags) > 0 {
+		fmt.Fprintf(&b, "Flags: %s\n", strings.Join(memory.Flags, ", "))
+	}
+	if len(memory.Successes) > 0 {
+		fmt.Fprintf(&b, "Successes: %s\n", strings.Join(memory.Successes, "; "))
+	}
+	if len(memory.Failures) > 0 {
+		fmt.Fprintf(&b, "Failures: %s\n", strings.Join(memory.Failures, "; "))
+	}
+	if choices := formatKeyDecisions(campaign.Memory.PerAct, currentAct); choices != "" {
+		fmt.Fprintf(&b, "Choices the party has made: %s\n", choices)
+	}
+	if triggers := pendingBoonTriggers(campaign); len(triggers) > 0 {
+		fmt.Fprintf(&b, "Moments that earn the party a boon (raise the matching flag when one happens): %s\n", strings.Join(triggers, ", "))
+	}
+
+	if flags := pendingDecisionFlags(campaign); len(flags) > 0 {
+		fmt.Fprintf(&b, "Decisions to watch for (raise the matching flag when the party's choices bring one about): %s\n", strings.Join(flags, ", "))
+	}
+	if consequences := formatActiveFailurePaths(campaign); consequences != "" {
+		fmt.Fprintf(&b, "\nFailure paths in motion (let their consequences shape the scene):\n%s\n", consequences)
+	}
+
+	if guidance := buildCombatGuidance(campaign); guidance != "" {
+		fmt.Fprintf(&b, "\n%s\n", guidance)
+	}
+
+	if facts := formatCanonicalFacts(campaign.Memory.Global.CanonicalFacts); facts != "" {
+		fmt.Fprintf(&b, "\nEstablished facts the narration must not contradict:\n%s\n", facts)
+	}
+	if relationships := formatRelationships(blueprint.MemoryDirectives.RelationshipAxes, campaign.Memory.Global.Relationships); relationships != "" {
+		fmt.Fprintf(&b, "\nRelationships with the party (report a shift in relationshipUpdates, using only the listed states):\n%s\n", relationships)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(narrationResponseFormat)
+	return b.String()
+}
+
+// combatScenesPlayed counts the combat scenes recorded across every act's memory
+func combatScenesPlayed(perAct map[string]models.ActMemory) int {
+	played := 0
+	for _, memory := range perAct {
+		if memory.CombatSceneCount != nil {
+			played += *memory.CombatSceneCount
+		}
+	}
+	return played
+}
+
+// buildCombatGuidance tells the narrator how much of the campaign's combat budget is left, and what combat
+// in this act is for. Once the budget is spent, conflict must be resolved without another fight.
+func buildCombatGuidance(campaign *models.Campaign) string {
+	constraints := campaign.Blueprint.CombatConstraints
+	if constraints.MaxCombatScenes <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	played := combatScenesPlayed(campaign.Memory.PerAct)
+	fmt.Fprintf(&b, "Combat scenes: %d of %d played.", played, constraints.MaxCombatScenes)
+	if played >= constraints.MaxCombatScenes {
+		b.WriteString(" The combat budget is spent: do not begin another fight. Resolve threats through escape, stealth, negotiation, sacrifice, or consequence, and set combatOccurred to false.")
+		return b.String()
+	}
+
+	b.WriteString(" Further combat is budgeted, but only when the story truly calls for it.")
+	if intent := constraints.CombatIntent[fmt.Sprintf("act%d", campaign.Runtime.CurrentAct+1)]; intent != "" {
+		fmt.Fprintf(&b, "\nCombat in this act should: %s", intent)
+	}
+	if len(constraints.CombatOutcomesMust) > 0 {
+		fmt.Fprintf(&b, "\nAny combat must: %s", strings.Join(constraints.CombatOutcomesMust, "; "))
+	}
+	return b.String()
+}
+
+// formatKeyDecisions lists the choices the party made in the acts up to and including currentAct
+func formatKeyDecisions(perAct map[string]models.ActMemory, currentAct int) string {
+	var choices []string
+	for act := 0; act <= currentAct; act++ {
+		for _, entry := range perAct[fmt.Sprintf("%d", act)].KeyDecisions {
+			if decision, ok := entry.(map[string]interface{}); ok {
+				choices = append(choices, fmt.Sprintf("%v — %v", decision["prompt"], decision["choice"]))
+			}
+		}
+	}
+	return strings.Join(choices, "; ")
+}
+
+// formatCanonicalFacts lists canonical facts one per line, in a stable order
+func formatCanonicalFacts(facts map[string]interface{}) string {
+	keys := make([]string, 0, len(facts))
+	for key := range facts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		switch value := facts[key].(type) {
+		case bool:
+			if value {
+				lines = append(lines, "- "+key)
+			}
+		default:
+			lines = append(lines, fmt.Sprintf("- %s: %v", key, value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pendingDecisionFlags lists the blueprint's decision flags the party has not raised yet
+func pendingDecisionFlags(campaign *models.Campaign) []string {
+	var pending []string
+	for _, flag := range campaign.Blueprint.MemoryDirectives.DecisionFlags {
+		if raised, _ := campaign.Memory.Global.DecisionFlags[flag].(bool); strings.TrimSpace(flag) != "" && !raised {
+			pending = append(pending, flag)
+		}
+	}
+	return pending
+}
+
+// formatActiveFailurePaths lists each failure path in motion with its consequence, one per line
+func formatActiveFailurePaths(campaign *models.Campaign) string {
+	consequences := make(map[string]string, len(campaign.Blueprint.FailurePaths))
+	for _, path := range campaign.Blueprint.FailurePaths {
+		consequences[path.ID] = path.Consequence
+	}
+
+	lines := make([]string, 0, len(campaign.Runtime.ActiveFailurePaths))
+	for _, id := range campaign.Runtime.ActiveFailurePaths {
+		if consequence := consequences[id]; consequence != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", id, consequence))
+		} else {
+			lines = append(lines, "- "+id)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatRelationships lists each tracked entity with its allowed states and, once one is recorded, its current state
+func formatRelationships(axes []models.RelationshipAxis, current map[string]interface{}) string {
+	lines := make([]string, 0, len(axes))
+	for _, axis := range axes {
+		if axis.Entity == "" || len(axis.States) == 0 {
+			continue
+		}
+		line := fmt.Sprintf("- %s (%s)", axis.Entity, strings.Join(axis.States, " / "))
+		if state, ok := current[axis.Entity]; ok {
+			line += fmt.Sprintf(": currently %v", state)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// buildNarrationUserPrompt carries the declarations, and any instruction, as the user message
+func buildNarrationUserPrompt(declarations []models.PendingDeclaration, instruction string) string {
+	prompt := "The party declares:\n"
+	for _, declared := range declarations {
+		prompt += fmt.Sprintf("<@%s>: %s\n", declared.UserID, declared.Declaration)
+		if intent := declared.Intent; intent != nil {
+			prompt += fmt.Sprintf("  (read as %s", intent.Category)
+			if len(intent.Targets) > 0 {
+				prompt += ", aimed at " + strings.Join(intent.Targets, ", ")
+			}
+			prompt += ")\n"
+		}
+	}
+	if instruction != "" {
+		prompt += "\n" + instruction
+	}
+	return prompt
+}
+
+// parseHaikuResponse decodes the narration model's JSON answer, tolerating a surrounding code fence
+func parseHaikuResponse(text string) (*HaikuResponse, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+
+	var response HaikuResponse
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse narration response: %w", err)
+	}
+	if strings.TrimSpace(response.Message) == "" {
+		return nil, fmt.Errorf("narration response has no message")
+	}
+	return &response, nil
+}
+
+// getAnthropicAPIKey reads the Anthropic API key for this stage from SSM
+func getAnthropicAPIKey() (string, error) {
+	stage := os.Getenv("SYRUS_STAGE")
+	if stage == "" {
+		stage = "dev"
+	}
+
+	return ssmcache.Get(fmt.Sprintf("/syrus/%s/anthropic/api-key", stage), true)
+}
+
+// callAnthropicAPI sends one system and user prompt to the Anthropic Messages API and returns the text reply
+func callAnthropicAPI(ctx context.Context, apiKey, modelID string, settings models.CallSettings, systemPrompt, userPrompt string) (string, error) {
+	logging.FromContext(ctx).Printf("Calling Anthropic API with model %s (temperature %.2f, max tokens %d)", modelID, settings.Temperature, settings.MaxTokens)
+
+	payload := map[string]interface{}{
+		"model":       modelID,
+		"max_tokens":  settings.MaxTokens,
+		"temperature": settings.Temperature,
+		"system":      systemPrompt,
+		"messages": []map[string]interface{}{
+			{
+				"role":    "user",
+				"content": userPrompt,
+			},
+		},
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(payloadJSON))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	client := &http.Client{Timeout: anthropicTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResponse struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+	}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(apiResponse.Content) == 0 {
+		return "", fmt.Errorf("API returned empty content")
+	}
+
+	logging.FromContext(ctx).Printf("Received narration from Claude (length: %d characters, stop reason: %s)", len(apiResponse.Content[0].Text), apiResponse.StopReason)
+	return apiResponse.Content[0].Text, nil
+}
+
+// formatDeclarations renders the declarations being narrated; batches attribute each one to its player
+func formatDeclarations(declarations []models.PendingDeclaration) string {
+	if len(declarations) == 1 {
+		return fmt.Sprintf("\"%s\"", declarations[0].Declaration)
+	}
+	lines := make([]string, 0, len(declarations))
+	for _, declared := range declarations {
+		lines = append(lines, fmt.Sprintf("\n<@%s>: \"%s\"", declared.UserID, declared.Declaration))
+	}
+	return strings.Join(lines, "")
+}
+
+// addToBatch adds a declaration to the pending asynchronous batch, opening the batch if needed.
+// A player who declares again before the batch closes replaces their earlier declaration.
+func addToBatch(turn *models.TurnState, declared models.PendingDeclaration) {
+	if turn.BatchOpenedAt == nil || len(turn.PendingDeclarations) == 0 {
+		openedAt := declared.DeclaredAt
+		turn.BatchOpenedAt = &openedAt
+		turn.PendingDeclarations = nil
+	}
+	for i, pending := range turn.PendingDeclarations {
+		if pending.UserID == declared.UserID {
+			turn.PendingDeclarations[i] = declared
+			return
+		}
+	}
+	turn.PendingDeclarations = append(turn.PendingDeclarations, declared)
+}
+
+// batchReady reports whether the asynchronous batch should be narrated: its window has elapsed,
+// or every party member has declared
+func batchReady(turn models.TurnState, window time.Duration, partySize int, now time.Time) bool {
+	if turn.BatchOpenedAt == nil || len(turn.PendingDeclarations) == 0 {
+		return false
+	}
+	if partySize > 0 && len(turn.PendingDeclarations) >= partySize {
+		return true
+	}
+	return !now.Before(turn.BatchOpenedAt.Add(window))
+}
+
+// handleAsyncDeclaration holds a declaration in the campaign's batch and narrates the batch once it closes
+func handleAsyncDeclaration(ctx context.Context, playRequest PlayRequest, campaign *models.Campaign, userID string, declared models.PendingDeclaration) error {
+	turn := campaign.Runtime.TurnState
+	addToBatch(&turn, declared)
+
+	if batchReady(turn, campaign.AsyncWindowDuration(), len(campaign.Party.Members), declared.DeclaredAt) {
+		declarations := turn.PendingDeclarations
+		turn.PendingDeclarations = nil
+		turn.BatchOpenedAt = nil
+		playRequest.forgetCampaign()
+		if err := storeTurnState(playRequest.CampaignId, turn); err != nil {
+			return fmt.Errorf("failed to close declaration batch: %w", err)
+		}
+		playRequest.logger().Printf("Narrating batch of %d declarations for campaign %s", len(declarations), playRequest.CampaignId)
+		return narrateDeclarations(ctx, playRequest, campaign, userID, declarations)
+	}
+
+	playRequest.forgetCampaign()
+	if err := storeTurnState(playRequest.CampaignId, turn); err != nil {
+		return fmt.Errorf("failed to save declaration batch: %w", err)
+	}
+
+	closesAt := turn.BatchOpenedAt.Add(campaign.AsyncWindowDuration())
+	message := fmt.Sprintf("*Your words are inscribed in the chronicle.* \"%s\"\n\nThe tale gathers the party's voices (%d of %d) and will move when all have spoken or the hourglass empties <t:%d:R>.",
+		declared.Declaration, len(turn.PendingDeclarations), len(campaign.Party.Members), closesAt.Unix())
+	return queueMessage(playRequest.ReplyChannelID(), message, playRequest.InteractionObject.Token, playRequest.InteractionId)
+}
+
+// saveTurnState persists the campaign's turn state (including any pending asynchronous batch)
+func saveTurnState(campaignID string, turn models.TurnState) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	turnAV, err := dynamodbattribute.Marshal(turn)
+	if err != nil {
+		return fmt.Errorf("failed to marshal turn state: %w", err)
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression: aws.String("SET #runtime.#turnState = :turnState, #lastUpdatedAt = :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":       aws.String("runtime"),
+			"#turnState":     aws.String("turnState"),
+			"#lastUpdatedAt": aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":turnState": turnAV,
+			":now":       {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update turn state: %w", err)
+	}
+	return nil
+}
+
+// reweaveDedupPrefix rate-limits blueprint re-triggers per campaign in the shared dedup table
+const reweaveDedupPrefix = "play-reweave"
+
+// blueprintReweaveGrace is how long an incomplete blueprint is left alone before play re-triggers
+// blueprinting; it also spaces out repeated re-triggers for the same campaign
+const blueprintReweaveGrace = 15 * time.Minute
+
+// missingBlueprintFields lists the blueprint fields play cannot run without
+func missingBlueprintFields(blueprint models.Blueprint) []string {
+	var missing []string
+	if strings.TrimSpace(blueprint.Title) == "" {
+		missing = append(missing, "title")
+	}
+	if strings.TrimSpace(blueprint.Premise) == "" {
+		missing = append(missing, "premise")
+	}
+	if len(blueprint.Acts) == 0 {
+		missing = append(missing, "acts")
+	}
+	return missing
+}
+
+// shouldReweaveBlueprint reports whether an incomplete blueprint has been stalled long enough that blueprinting
+// most likely failed rather than still running
+func shouldReweaveBlueprint(campaign *models.Campaign, now time.Time) bool {
+	return now.Sub(campaign.LastUpdatedAt) >= blueprintReweaveGrace
+}
+
+// reweaveBlueprint sends the campaign back through birthing (fresh seeds, then blueprinting), at most once
+// per grace period. Failures are logged; the player already gets the "still being woven" message.
+func reweaveBlueprint(campaignID, channelID, interactionID string) {
+	logger := logging.For(interactionID, campaignID)
+	claimed, err := claimProcessed(reweaveDedupPrefix, campaignID, blueprintReweaveGrace)
+	if err != nil {
+		logger.Printf("Warning: failed to claim blueprint re-trigger for campaign %s: %v", campaignID, err)
+		return
+	}
+	if !claimed {
+		logger.Printf("Blueprint for campaign %s was re-triggered recently, waiting", campaignID)
+		return
+	}
+
+	if err := sendToBirthingQueue(models.BirthingMessage{CampaignID: campaignID, ChannelID: channelID, InteractionID: interactionID}); err != nil {
+		logger.Printf("Warning: failed to re-trigger blueprint for campaign %s: %v", campaignID, err)
+		if err := releaseProcessed(reweaveDedupPrefix, campaignID); err != nil {
+			logger.Printf("Warning: failed to release blueprint re-trigger for campaign %s: %v", campaignID, err)
+		}
+		return
+	}
+	logger.Printf("Re-triggered blueprinting for campaign %s", campaignID)
+}
+
+// sendToBirthingQueue sends a campaign back to birthing for seed and blueprint generation
+func sendToBirthingQueue(message models.BirthingMessage) error {
+	queueURL := os.Getenv("SYRUS_BIRTHING_QUEUE_URL")
+	if queueURL == "" {
+		return fmt.Errorf("SYRUS_BIRTHING_QUEUE_URL environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := sqs.New(sess)
+
+	messageBodyJSON, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message body: %w", err)
+	}
+
+	_, err = svc.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:               aws.String(queueURL),
+		MessageBody:            aws.String(string(messageBodyJSON)),
+		MessageGroupId:         aws.String(message.CampaignID),
+		MessageDeduplicationId: aws.String(message.InteractionID + "-reweave"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to birthing queue: %w", err)
+	}
+	return nil
+}
+
+// saveDeclarationHeartbeat persists the campaign's last declaration time; overridden in tests
+var saveDeclarationHeartbeat = saveLastDeclarationAt
+
+// recordDeclarationHeartbeat stamps the campaign as being played now. The heartbeat is observability only,
+// so a failed write is logged rather than failing the declaration.
+func recordDeclarationHeartbeat(campaign *models.Campaign, now time.Time) {
+	campaign.Runtime.LastDeclarationAt = &now
+	if err := saveDeclarationHeartbeat(campaign.CampaignID, now); err != nil {
+		log.Printf("Warning: failed to record declaration heartbeat for campaign %s: %v", campaign.CampaignID, err)
+	}
+}
+
+// markCampaignPlaying persists the transition to playing; overridden in tests
+var markCampaignPlaying = transitionToPlaying
+
+// startPlaying moves an active campaign to playing on its first accepted declaration. Like the heartbeat,
+// a failed write is logged rather than failing the declaration; the next declaration tries again.
+func startPlaying(campaign *models.Campaign) {
+	if !models.CanTransition(campaign.Status, models.CampaignStatusPlaying) {
+		return
+	}
+	err := markCampaignPlaying(campaign.CampaignID, campaign.Status)
+	switch {
+	case err == nil:
+		log.Printf("Campaign %s is now playing", campaign.CampaignID)
+	case dedup.IsConditionalCheckFailed(err):
+		// A concurrent declaration already started play (or the campaign has moved on)
+		log.Printf("Campaign %s was no longer active, leaving its status as is", campaign.CampaignID)
+	default:
+		log.Printf("Warning: failed to mark campaign %s as playing: %v", campaign.CampaignID, err)
+		return
+	}
+	campaign.Status = models.CampaignStatusPlaying
+}
+
+// transitionToPlaying sets the campaign's status to playing, enforcing the allowed lifecycle graph. The write
+// is conditional on the stored status still being `from` so concurrent declarations write the transition once.
+func transitionToPlaying(campaignID string, from models.CampaignStatus) error {
+	if !models.CanTransition(from, models.CampaignStatusPlaying) {
+		return fmt.Errorf("illegal campaign status transition %s -> %s", from, models.CampaignStatusPlaying)
+	}
+
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:    aws.String("SET #status = :playing, #lastUpdatedAt = :now"),
+		ConditionExpression: aws.String("#status = :from"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status":        aws.String("status"),
+			"#lastUpdatedAt": aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":from":    {S: aws.String(string(from))},
+			":playing": {S: aws.String(string(models.CampaignStatusPlaying))},
+			":now":     {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to transition campaign status %s -> %s: %w", from, models.CampaignStatusPlaying, err)
+	}
+	return nil
+}
+
+// saveParty replaces party.members and hostId, provided the party still has expectedCount members
+func saveParty(campaignID, hostID string, members []models.PartyMember, expectedCount int) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	membersAV, err := dynamodbattribute.Marshal(members)
+	if err != nil {
+		return fmt.Errorf("failed to marshal party members: %w", err)
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:    aws.String("SET #party.#members = :members, #hostId = :hostId, #lastUpdatedAt = :now"),
+		ConditionExpression: aws.String("size(#party.#members) = :expected"),
+		ExpressionAttributeNames: map[string]*string{
+			"#party":         aws.String("party"),
+			"#members":       aws.String("members"),
+			"#hostId":        aws.String("hostId"),
+			"#lastUpdatedAt": aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":members":  membersAV,
+			":hostId":   {S: aws.String(hostID)},
+			":expected": {N: aws.String(strconv.Itoa(expectedCount))},
+			":now":      {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update party members: %w", err)
+	}
+	return nil
+}
+
+// saveLastDeclarationAt sets runtime.lastDeclarationAt without touching the rest of the runtime state
+func saveLastDeclarationAt(campaignID string, at time.Time) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	atAV, err := dynamodbattribute.Marshal(at)
+	if err != nil {
+		return fmt.Errorf("failed to marshal declaration time: %w", err)
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression: aws.String("SET #runtime.#lastDeclarationAt = :at"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":           aws.String("runtime"),
+			"#lastDeclarationAt": aws.String("lastDeclarationAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":at": atAV,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update last declaration time: %w", err)
+	}
+	return nil
+}
+
+// saveLastNarration records the most recent narration without touching the rest of the turn state
+func saveLastNarration(campaignID string, record models.NarrationRecord) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	recordAV, err := dynamodbattribute.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal narration record: %w", err)
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression: aws.String("SET #runtime.#turnState.#lastNarration = :record, #lastUpdatedAt = :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":       aws.String("runtime"),
+			"#turnState":     aws.String("turnState"),
+			"#lastNarration": aws.String("lastNarration"),
+			"#lastUpdatedAt": aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":record": recordAV,
+			":now":    {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update last narration: %w", err)
+	}
+	return nil
+}
+
+// maxRerollsPerNarration bounds how many different takes the host may ask for on one narration
+const maxRerollsPerNarration = 2
+
+// rerollWindow is how long a narration can be replaced; Discord interaction tokens expire after 15 minutes
+const rerollWindow = 14 * time.Minute
+
+// rerollTemperatureBoost is added to the original narration temperature for each reroll
+const rerollTemperatureBoost = 0.15
+
+// rerollInstruction asks the narration model for a fresh take on the same declarations
+const rerollInstruction = "Try a different take on this moment: keep every established fact and outcome, but change the imagery, pacing, and framing."
+
+// withinCallBudget reports whether the campaign's soft limit allows another call to the model (zero limits are unbounded)
+func withinCallBudget(tracking models.CostTracking, model models.Model) bool {
+	class, billed := costs.ClassOf(model)
+	return !billed || costs.WithinLimit(tracking, class)
+}
+
+// planReroll decides whether the last narration may be rerolled and returns the updated record.
+// Only the narration record changes; beats, pressure, and memory stay as the original narration left them.
+// When the reroll is refused, the in-character reason is returned instead.
+func planReroll(campaign *models.Campaign, now time.Time) (*models.NarrationRecord, string) {
+	last := campaign.Runtime.TurnState.LastNarration
+	if last == nil || last.InteractionToken == "" {
+		return nil, "*The loom is bare.* There is no telling to weave anew. Let the party declare their next move first."
+	}
+	if last.Rerolls >= maxRerollsPerNarration {
+		return nil, fmt.Sprintf("*The threads have been rewoven %d times already.* This telling must now stand. Let the party's next move shape the tale.", last.Rerolls)
+	}
+	if now.Sub(last.NarratedAt) > rerollWindow {
+		return nil, "*The ink has dried upon the page.* This telling is too old to be woven anew. Let the party's next move shape the tale."
+	}
+	if !withinCallBudget(campaign.CostTracking, last.Model) {
+		return nil, "*The loom's strength is spent.* This tale has reached the limit of its weavings. The telling must stand as written."
+	}
+
+	rerolled := *last
+	rerolled.Rerolls++
+	rerolled.Temperature = math.Min(1.0, last.Temperature+rerollTemperatureBoost)
+	return &rerolled, ""
+}
+
+// handleRerollCommand replaces the last narration with a different take (host only)
+func handleRerollCommand(ctx context.Context, playRequest PlayRequest) error {
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	if campaign == nil {
+		return queueMessage(playRequest.ReplyChannelID(), "*The pages of destiny remain blank.* This tale has not yet begun. The story awaits your first step.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	userID := getUserID(playRequest.InteractionObject)
+	if userID == "" || userID != campaign.HostID {
+		playRequest.logger().Printf("User %s may not reroll narration in campaign %s", userID, playRequest.CampaignId)
+		return queueMessage(playRequest.ReplyChannelID(), "*Only the host may ask the threads to be rewoven.* Share your counsel with them if this telling troubles you.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	currentAct := campaign.Runtime.CurrentAct
+	if currentAct < 0 || currentAct >= len(campaign.Blueprint.Acts) {
+		return queueMessage(playRequest.ReplyChannelID(), "*The ancient runes have been defiled.* The structure of this tale is corrupted. Seek the wisdom of the elders to restore the chronicle.", playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	record, refusal := planReroll(campaign, time.Now().UTC())
+	if record == nil {
+		return queueMessage(playRequest.ReplyChannelID(), refusal, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	playRequest.logger().Printf("Rerolling narration for campaign %s (reroll %d, temperature %.2f)", playRequest.CampaignId, record.Rerolls, record.Temperature)
+	response, err := composeNarration(ctx, campaign, record.Model, record.Declarations, record.Temperature, rerollInstruction)
+	if err != nil {
+		playRequest.logger().Printf("Reroll narration failed for campaign %s: %v", playRequest.CampaignId, err)
+		return queueMessage(playRequest.ReplyChannelID(), narrationFailureMessage, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+	regenerate := continuityRegenerator(ctx, campaign, record.Model, record.Declarations, record.Temperature, rerollInstruction, "")
+	message, issues := enforceContinuity(response.Message, campaign.Memory.Global.CanonicalFacts, regenerate)
+	if len(issues) > 0 {
+		flagContinuityForHost(playRequest, campaign, userID, issues)
+	}
+
+	// Replace the original narration by editing the response of the interaction that produced it
+	if err := queueMessage(playRequest.ReplyChannelID(), message, record.InteractionToken, playRequest.InteractionId+"-reroll"); err != nil {
+		return err
+	}
+
+	record.Narration = message
+	playRequest.forgetCampaign()
+	if err := storeLastNarration(playRequest.CampaignId, *record); err != nil {
+		return fmt.Errorf("failed to record rerolled narration: %w", err)
+	}
+
+	return queueMessage(playRequest.ReplyChannelID(), fmt.Sprintf("*The threads unravel and are woven anew.* The last telling has been replaced (%d of %d rewoven).", record.Rerolls, maxRerollsPerNarration), playRequest.InteractionObject.Token, playRequest.InteractionId)
+}
+
+// ContinuityIssue describes narration that contradicts an established canonical fact
+type ContinuityIssue struct {
+	Fact     string // The canonical fact as recorded in memory
+	Subject  string // The person, place, or thing the fact is about
+	Sentence string // The narration sentence that contradicts it
+}
+
+// maxContinuityRetries bounds how many corrected narrations are requested before flagging the host
+const maxContinuityRetries = 1
+
+// continuityRule pairs terminal fact states with narration cues that contradict them
+type continuityRule struct {
+	states []string
+	cues   *regexp.Regexp
+}
+
+var continuityRules = []continuityRule{
+	{
+		// The dead do not speak or act
+		states: []string{"dead", "deceased", "slain", "killed"},
+		cues:   regexp.MustCompile(`(?i)\b(says|said|speaks|spoke|whispers|whispered|replies|replied|asks|asked|shouts|shouted|laughs|laughed|smiles|smiled|grins|grinned|nods|nodded|greets|greeted|walks|walked|arrives|arrived)\b`),
+	},
+	{
+		// Destroyed places and things do not stand intact
+		states: []string{"destroyed", "collapsed", "razed", "ruined"},
+		cues:   regexp.MustCompile(`(?i)\b(stands|stood|intact|unharmed|untouched|unscathed|gleams|gleamed|whole)\b`),
+	},
+}
+
+// continuityExemptions mark sentences that acknowledge the fact (a ghost speaking, the ruins standing)
+var continuityExemptions = regexp.MustCompile(`(?i)\b(ghost|ghostly|spirit|specter|spectre|corpse|body|grave|tomb|memory|memories|remember|remembers|remembered|late|echo|echoes|vision|dream|dreams|ruins|rubble|remains|once|dead|death|slain|destroyed)\b`)
+
+var (
+	narrationSentencePattern = regexp.MustCompile(`[^.!?\n]+[.!?]*`)
+	factStatementPattern     = regexp.MustCompile(`(?i)^(.+?)\s+(?:is|was|has been|lies|lay)\s+(\w+)[.!]?$`)
+)
+
+// parseCanonicalFact extracts the subject and state from a canonical fact. Facts may be recorded as
+// {"Mira": "dead"}, {"mira.status": "dead"}, {"fact1": "Mira is dead"}, or {"Mira is dead": true}.
+func parseCanonicalFact(key string, value interface{}) (subject, state string, ok bool) {
+	statement := ""
+	switch v := value.(type) {
+	case string:
+		trimmed := strings.ToLower(strings.TrimSpace(v))
+		if findContinuityRule(trimmed) != nil {
+			subject = strings.TrimSpace(key)
+			for _, suffix := range []string{".status", "_status", " status"} {
+				subject = strings.TrimSuffix(subject, suffix)
+			}
+			return subject, trimmed, subject != ""
+		}
+		statement = v
+	case bool:
+		if !v {
+			return "", "", false
+		}
+		statement = key
+	default:
+		return "", "", false
+	}
+
+	match := factStatementPattern.FindStringSubmatch(strings.TrimSpace(statement))
+	if match == nil {
+		return "", "", false
+	}
+	state = strings.ToLower(match[2])
+	if findContinuityRule(state) == nil {
+		return "", "", false
+	}
+	return strings.TrimSpace(match[1]), state, true
+}
+
+func findContinuityRule(state string) *continuityRule {
+	for i, rule := range continuityRules {
+		for _, s := range rule.states {
+			if s == state {
+				return &continuityRules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// checkContinuity scans narration for sentences that contradict canonical facts, e.g. a dead NPC speaking.
+// It is a keyword heuristic: a sentence contradicts a fact when it names the subject alongside a cue the
+// fact's state rules out, and doesn't acknowledge the state (ghosts, memories, ruins).
+func checkContinuity(narration string, facts map[string]interface{}) []ContinuityIssue {
+	keys := make([]string, 0, len(facts))
+	for key := range facts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sentences := narrationSentencePattern.FindAllString(narration, -1)
+
+	var issues []ContinuityIssue
+	for _, key := range keys {
+		subject, state, ok := parseCanonicalFact(key, facts[key])
+		if !ok {
+			continue
+		}
+		rule := findContinuityRule(state)
+		subjectPattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(subject) + `\b`)
+
+		for _, sentence := range sentences {
+			if !subjectPattern.MatchString(sentence) || !rule.cues.MatchString(sentence) {
+				continue
+			}
+			if continuityExemptions.MatchString(sentence) {
+				continue
+			}
+			issues = append(issues, ContinuityIssue{
+				Fact:     fmt.Sprintf("%s is %s", subject, state),
+				Subject:  subject,
+				Sentence: strings.TrimSpace(sentence),
+			})
+			break
+		}
+	}
+	return issues
+}
+
+// enforceContinuity checks narration against canonical facts, asking regenerate for a corrected narration
+// (up to maxContinuityRetries times) when it contradicts them. It returns the narration to send and any
+// issues that remain. A nil regenerate only detects.
+func enforceContinuity(narration string, facts map[string]interface{}, regenerate func([]ContinuityIssue) (string, error)) (string, []ContinuityIssue) {
+	issues := checkContinuity(narration, facts)
+	for attempt := 0; len(issues) > 0 && regenerate != nil && attempt < maxContinuityRetries; attempt++ {
+		log.Printf("Narration contradicts %d canonical fact(s), requesting a correction (attempt %d)", len(issues), attempt+1)
+		corrected, err := regenerate(issues)
+		if err != nil {
+			log.Printf("Warning: failed to regenerate narration: %v", err)
+			break
+		}
+		narration = corrected
+		issues = checkContinuity(narration, facts)
+	}
+	return narration, issues
+}
+
+// continuityRegenerator re-prompts the narration model for the same declarations, adding the continuity issues
+// to correct after instruction. Only the corrected message is used; the story state stays as the first narration
+// left it. lead is kept ahead of each correction, such as the dice a roll already threw.
+func continuityRegenerator(ctx context.Context, campaign *models.Campaign, model models.Model, declarations []models.PendingDeclaration, temperature float64, instruction, lead string) func([]ContinuityIssue) (string, error) {
+	return func(issues []ContinuityIssue) (string, error) {
+		correction := continuityInstruction(issues)
+		if instruction != "" {
+			correction = instruction + "\n\n" + correction
+		}
+		response, err := composeNarration(ctx, campaign, model, declarations, temperature, correction)
+		if err != nil {
+			return "", err
+		}
+		return lead + response.Message, nil
+	}
+}
+
+// continuityInstruction asks the narration model to retell a moment without contradicting the listed facts
+func continuityInstruction(issues []ContinuityIssue) string {
+	var b strings.Builder
+	b.WriteString("Your last telling contradicted established facts. Tell the moment again so it agrees with every one of them:\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "- %s (you wrote: \"%s\")\n", issue.Fact, issue.Sentence)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// flagContinuityForHost records continuity issues and, when the host made the declaration, tells them privately
+func flagContinuityForHost(playRequest PlayRequest, campaign *models.Campaign, userID string, issues []ContinuityIssue) {
+	var lines []string
+	for _, issue := range issues {
+		playRequest.logger().Printf("WARNING: Continuity issue in campaign %s: %q contradicts %q", playRequest.CampaignId, issue.Sentence, issue.Fact)
+		lines = append(lines, fmt.Sprintf("• %s — but the tale says: \"%s\"", issue.Fact, issue.Sentence))
+	}
+
+	if userID == "" || userID != campaign.HostID {
+		return
+	}
+	content := "*The chronicle trembles.* This telling may stray from what is written:\n" + strings.Join(lines, "\n")
+	if err := queueHostFollowup(playRequest.ReplyChannelID(), content, playRequest.InteractionObject.Token, playRequest.InteractionId+"-continuity"); err != nil {
+		playRequest.logger().Printf("Warning: failed to flag continuity issues for host: %v", err)
+	}
+}
+
+// handleConsensusDeclaration handles a declaration made while the party has a group decision pending.
+// The story does not advance until the party settles the decision together, so the vote is offered again.
+func handleConsensusDeclaration(playRequest PlayRequest, campaign *models.Campaign, declaration string) error {
+	decision := campaign.Runtime.TurnState.ActiveDecision
+	playRequest.logger().Printf("Routing declaration into consensus flow for campaign %s: %s", playRequest.CampaignId, declaration)
+
+	message := models.MessagingQueueMessage{
+		ChannelID:        playRequest.ReplyChannelID(),
+		Content:          fmt.Sprintf("*Your voice joins the council.* \"%s\"\n\n%s", declaration, buildDecisionMessage(*decision)),
+		Components:       decisionComponents(decision.Options),
+		InteractionToken: playRequest.InteractionObject.Token,
+		InteractionID:    playRequest.InteractionId,
+	}
+	return queuePrepared(message, playRequest.InteractionId)
+}
+
+// voteCustomIDPrefix prefixes the custom ID of a group decision's vote buttons; the suffix is the option index.
+// The webhook forwards clicks on these buttons to the play queue.
+const voteCustomIDPrefix = "syrus_vote:"
+
+// synchronousDecisionWindow is how long a group decision stays open in synchronous play; asynchronous
+// campaigns keep it open for their declaration window
+const synchronousDecisionWindow = 30 * time.Minute
+
+// Discord allows five buttons to an action row and five rows to a message
+const (
+	buttonsPerRow      = 5
+	maxComponentRows   = 5
+	maxButtonLabelSize = 80
+)
+
+// decisionWindow returns how long the party has to vote on a group decision
+func decisionWindow(campaign *models.Campaign) time.Duration {
+	if campaign.EffectivePlayStyle() == models.PlayStyleAsynchronous {
+		return campaign.AsyncWindowDuration()
+	}
+	return synchronousDecisionWindow
+}
+
+// decisionReached reports whether a group-decision campaign has played the current act's expected beats and
+// the act ends on a choice the party has not yet made
+func decisionReached(campaign *models.Campaign, act models.Act) bool {
+	if campaign.DecisionModel != models.DecisionModelGroup || !isEnabled(models.FeatureGroupVoting) {
+		return false
+	}
+	if campaign.Runtime.TurnState.ActiveDecision != nil || !act.Completion.IsChoice() {
+		return false
+	}
+	if act.ExpectedBeats <= 0 || campaign.Runtime.CurrentBeat < act.ExpectedBeats {
+		return false
+	}
+	memory := campaign.Memory.PerAct[fmt.Sprintf("%d", campaign.Runtime.CurrentAct)]
+	return !decisionMade(memory, act.Completion.Prompt)
+}
+
+// decisionMade reports whether the act's memory already records the party's answer to prompt
+func decisionMade(memory models.ActMemory, prompt string) bool {
+	for _, entry := range memory.KeyDecisions {
+		if decision, ok := entry.(map[string]interface{}); ok && decision["prompt"] == prompt {
+			return true
+		}
+	}
+	return false
+}
+
+// openGroupDecision puts the act's choice to the party and posts the vote. A failure is logged rather than
+// failing the turn: the narration is already posted, and declarations re-offer the vote once it is open.
+func openGroupDecision(playRequest PlayRequest, campaign *models.Campaign, act models.Act, now time.Time) {
+	decision := &models.ActiveDecision{
+		Prompt:    act.Completion.Prompt,
+		Type:      models.CompletionTypeChoice,
+		Options:   act.Completion.Options,
+		ExpiresAt: now.Add(decisionWindow(campaign)),
+		Votes:     map[string]int{},
+	}
+	campaign.Runtime.TurnState.ActiveDecision = decision
+	playRequest.forgetCampaign()
+	if err := storeTurnState(playRequest.CampaignId, campaign.Runtime.TurnState); err != nil {
+		playRequest.logger().Printf("Warning: failed to open group decision for campaign %s: %v", playRequest.CampaignId, err)
+		campaign.Runtime.TurnState.ActiveDecision = nil
+		return
+	}
+
+	message := models.MessagingQueueMessage{
+		ChannelID:     playRequest.ReplyChannelID(),
+		Content:       buildDecisionMessage(*decision),
+		Components:    decisionComponents(decision.Options),
+		InteractionID: playRequest.InteractionId,
+	}
+	if err := queuePrepared(message, playRequest.InteractionId+"-decision"); err != nil {
+		playRequest.logger().Printf("Warning: failed to post group decision for campaign %s: %v", playRequest.CampaignId, err)
+		return
+	}
+	playRequest.logger().Printf("Opened group decision for campaign %s with %d options", playRequest.CampaignId, len(decision.Options))
+}
+
+// buildDecisionMessage presents a group decision's prompt and numbered options
+func buildDecisionMessage(decision models.ActiveDecision) string {
+	var b strings.Builder
+	prompt := decision.Prompt
+	if prompt == "" {
+		prompt = "Which way will the party go?"
+	}
+	fmt.Fprintf(&b, "*The path forks before you.* %s\n", prompt)
+	for i, option := range decision.Options {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, option)
+	}
+	fmt.Fprintf(&b, "\n\nThe party must decide together: the majority carries the tale, or the vote closes <t:%d:R>.", decision.ExpiresAt.Unix())
+	return b.String()
+}
+
+// decisionComponents renders a decision's options as vote buttons
+func decisionComponents(options []string) []map[string]interface{} {
+	var rows []map[string]interface{}
+	for start := 0; start < len(options) && len(rows) < maxComponentRows; start += buttonsPerRow {
+		end := start + buttonsPerRow
+		if end > len(options) {
+			end = len(options)
+		}
+		buttons := make([]map[string]interface{}, 0, end-start)
+		for i := start; i < end; i++ {
+			buttons = append(buttons, map[string]interface{}{
+				"type":      2, // Button
+				"style":     1, // Primary
+				"label":     truncateMessage(options[i], maxButtonLabelSize),
+				"custom_id": fmt.Sprintf("%s%d", voteCustomIDPrefix, i),
+			})
+		}
+		rows = append(rows, map[string]interface{}{
+			"type":       1, // Action row
+			"components": buttons,
+		})
+	}
+	return rows
+}
+
+// parseVoteCustomID extracts the chosen option from a vote button's interaction data
+func parseVoteCustomID(data map[string]interface{}) (int, bool) {
+	customID, _ := data["custom_id"].(string)
+	if !strings.HasPrefix(customID, voteCustomIDPrefix) {
+		return 0, false
+	}
+	choice, err := strconv.Atoi(strings.TrimPrefix(customID, voteCustomIDPrefix))
+	if err != nil || choice < 0 {
+		return 0, false
+	}
+	return choice, true
+}
+
+// tallyVotes counts the votes cast for each option
+func tallyVotes(decision models.ActiveDecision) []int {
+	counts := make([]int, len(decision.Options))
+	for _, choice := range decision.Votes {
+		if choice >= 0 && choice < len(counts) {
+			counts[choice]++
+		}
+	}
+	return counts
+}
+
+// majorityChoice returns the option more than half the party has voted for, if any
+func majorityChoice(decision models.ActiveDecision, partySize int) (int, bool) {
+	for choice, count := range tallyVotes(decision) {
+		if count*2 > partySize {
+			return choice, true
+		}
+	}
+	return 0, false
+}
+
+// pluralityChoice returns the option with the most votes when the vote closes. Ties, including a vote
+// nobody cast, go to the earliest option.
+func pluralityChoice(decision models.ActiveDecision) int {
+	counts := tallyVotes(decision)
+	best := 0
+	for choice, count := range counts {
+		if count > counts[best] {
+			best = choice
+		}
+	}
+	return best
+}
+
+// isPartyMember reports whether userID is in the campaign's party
+func isPartyMember(party models.Party, userID string) bool {
+	for _, member := range party.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// handleVoteCommand records a party member's vote on the pending group decision, settling it once an option
+// has a majority of the party or the vote has closed
+func handleVoteCommand(playRequest PlayRequest, choice int) error {
+	reply := func(content string) error {
+		return queueMessage(playRequest.ReplyChannelID(), content, playRequest.InteractionObject.Token, playRequest.InteractionId)
+	}
+
+	campaign, err := playRequest.campaign()
+	if err != nil {
+		playRequest.logger().Printf("Failed to get campaign: %v", err)
+		return reply("*The ancient tomes refuse to open.* I cannot find your tale in the chronicles. The threads of fate may be frayed.")
+	}
+	if campaign == nil {
+		return reply("*The pages of destiny remain blank.* This tale has not yet begun. The story awaits your first step.")
+	}
+
+	decision := campaign.Runtime.TurnState.ActiveDecision
+	if decision == nil {
+		return reply("*That crossroads lies behind you.* The party has already chosen its path.")
+	}
+	userID := getUserID(playRequest.InteractionObject)
+	if !isPartyMember(campaign.Party, userID) {
+		return reply("*Only those who walk this road may choose its turning.* Use `/syrus join` to join the party first.")
+	}
+	if choice >= len(decision.Options) {
+		return reply("*That path has faded from the map.* Choose again from the options before you.")
+	}
+
+	if !time.Now().UTC().Before(decision.ExpiresAt) {
+		// The vote closed before this one arrived; settle it with the votes already cast
+		chosen := pluralityChoice(*decision)
+		if err := resolveGroupDecision(playRequest, campaign, chosen); err != nil {
+			return err
+		}
+		return reply(fmt.Sprintf("*The hourglass emptied before your voice was heard.* The party has chosen **%s**.", decision.Options[chosen]))
+	}
+
+	if decision.Votes == nil {
+		decision.Votes = map[string]int{}
+	}
+	decision.Votes[userID] = choice
+	if chosen, ok := majorityChoice(*decision, len(campaign.Party.Members)); ok {
+		if err := resolveGroupDecision(playRequest, campaign, chosen); err != nil {
+			return err
+		}
+		return reply(fmt.Sprintf("*Your voice tips the balance.* The party has chosen **%s**.", decision.Options[chosen]))
+	}
+
+	playRequest.forgetCampaign()
+	if err := storeTurnState(playRequest.CampaignId, campaign.Runtime.TurnState); err != nil {
+		return fmt.Errorf("failed to record vote: %w", err)
+	}
+	playRequest.logger().Printf("User %s voted for option %d in campaign %s (%d of %d voted)", userID, choice, playRequest.CampaignId, len(decision.Votes), len(campaign.Party.Members))
+	return reply(fmt.Sprintf("*Your voice is counted.* You chose **%s** (%d of %d have voted). The vote closes <t:%d:R>.",
+		decision.Options[choice], len(decision.Votes), len(campaign.Party.Members), decision.ExpiresAt.Unix()))
+}
+
+// resolveGroupDecision settles the pending group decision on the chosen option: the choice is kept in the act's
+// memory, the decision is cleared, the story moves into the next act, and the outcome is announced to the channel
+func resolveGroupDecision(playRequest PlayRequest, campaign *models.Campaign, choice int) error {
+	decision := campaign.Runtime.TurnState.ActiveDecision
+	chosen := decision.Options[choice]
+
+	actKey := fmt.Sprintf("%d", campaign.Runtime.CurrentAct)
+	if campaign.Memory.PerAct == nil {
+		campaign.Memory.PerAct = map[string]models.ActMemory{}
+	}
+	memory := campaign.Memory.PerAct[actKey]
+	memory.KeyDecisions = append(memory.KeyDecisions, map[string]interface{}{"prompt": decision.Prompt, "choice": chosen})
+	campaign.Memory.PerAct[actKey] = memory
+
+	message := fmt.Sprintf("*The council has spoken.* The party chooses **%s**.", chosen)
+	campaign.Runtime.TurnState.ActiveDecision = nil
+	if advanceAct(campaign) {
+		message += "\n\n" + actTransitionMessage(campaign)
+	}
+
+	playRequest.forgetCampaign()
+	if err := storeDecisionOutcome(campaign); err != nil {
+		return fmt.Errorf("failed to record group decision: %w", err)
+	}
+	playRequest.logger().Printf("Group decision for campaign %s resolved on option %d (%s)", playRequest.CampaignId, choice, chosen)
+
+	// The announcement is best effort: the outcome is recorded, and the voter's reply names the choice
+	announcement := models.MessagingQueueMessage{ChannelID: playRequest.ReplyChannelID(), Content: message, InteractionID: playRequest.InteractionId}
+	if err := queuePrepared(announcement, playRequest.InteractionId+"-decided"); err != nil {
+		playRequest.logger().Printf("Warning: failed to announce group decision for campaign %s: %v", playRequest.CampaignId, err)
+	}
+	return nil
+}
+
+// saveDecisionOutcome persists a settled group decision: the act, beat, and pressure it moved the story to,
+// the cleared decision, and the per-act memory recording the choice
+func saveDecisionOutcome(campaign *models.Campaign) error {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	svc := dynamodb.New(sess)
+
+	perActAV, err := dynamodbattribute.Marshal(campaign.Memory.PerAct)
+	if err != nil {
+		return fmt.Errorf("failed to marshal act memory: %w", err)
+	}
+	pressureAV, err := dynamodbattribute.Marshal(campaign.Runtime.Pressure)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pressure: %w", err)
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaign.CampaignID)},
+		},
+		UpdateExpression: aws.String("SET #runtime.#currentAct = :act, #runtime.#currentBeat = :beat, #runtime.#pressure = :pressure, #runtime.#turnState.#activeDecision = :decision, #memory.#perAct = :perAct, #lastUpdatedAt = :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#runtime":        aws.String("runtime"),
+			"#currentAct":     aws.String("currentAct"),
+			"#currentBeat":    aws.String("currentBeat"),
+			"#pressure":       aws.String("pressure"),
+			"#turnState":      aws.String("turnState"),
+			"#activeDecision": aws.String("activeDecision"),
+			"#memory":         aws.String("memory"),
+			"#perAct":         aws.String("perAct"),
+			"#lastUpdatedAt":  aws.String("lastUpdatedAt"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":act":      {N: aws.String(strconv.Itoa(campaign.Runtime.CurrentAct))},
+			":beat":     {N: aws.String(strconv.Itoa(campaign.Runtime.CurrentBeat))},
+			":pressure": pressureAV,
+			":decision": {NULL: aws.Bool(true)},
+			":perAct":   perActAV,
+			":now":      {S: aws.String(time.Now().UTC().Format(time.RFC3339))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update group decision: %w", err)
+	}
+	return nil
+}
+
+// handleSQSRequest processes SQS events, reporting failed messages so SQS retries only those
+func handleSQSRequest(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	failures := sqsbatch.Process(ctx, sqsEvent.Records, sqsConcurrency, processPlayMessage)
+	return events.SQSEventResponse{BatchItemFailures: failures}, nil
+}
+
+// processPlayMessage claims one play request, handles it, and marks it processed. The claim is a
+// conditional write, so concurrent deliveries of the same interaction cannot both get through.
+func processPlayMessage(ctx context.Context, message events.SQSMessage) error {
+	var playRequest PlayRequest
+	if err := json.Unmarshal([]byte(message.Body), &playRequest); err != nil {
+		return fmt.Errorf("failed to unmarshal play request: %w", err)
+	}
+
+	claimed, err := claimProcessed(dedupPrefix, playRequest.InteractionId, claimLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim play request: %w", err)
+	}
+	if !claimed {
+		playRequest.logger().Printf("Interaction %s already processed, skipping", playRequest.InteractionId)
+		return nil
+	}
+
+	ctx = logging.WithContext(ctx, playRequest.logger())
+	if err := handlePlayRequest(ctx, playRequest); err != nil {
+		// Drop the claim so the SQS retry is not mistaken for a duplicate
+		if releaseErr := releaseProcessed(dedupPrefix, playRequest.InteractionId); releaseErr != nil {
+			playRequest.logger().Printf("Failed to release dedup claim: %v", releaseErr)
+		}
+		return fmt.Errorf("failed to process play request: %w", err)
+	}
+
+	// Mark as processed in dedup table
+	if err := markProcessed(dedupPrefix, playRequest.InteractionId, dedup.DefaultTTL); err != nil {
+		playRequest.logger().Printf("Failed to write dedup: %v", err)
+		// Don't fail the message - it was processed successfully, dedup is just safety
+	}
 	return nil
 }
 
 func main() {
+	logging.Init("play")
 	lambda.Start(handleSQSRequest)
 }
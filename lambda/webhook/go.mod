@@ -2,11 +2,21 @@ module syrus-webhook
 
 go 1.21
 
-replace github.com/loros/syrus-models => ../../lib/go/models
+replace loros/syrus-models => ../../lib/go/models
+
+replace loros/syrus-commandopts => ../../lib/go/commandopts
+
+replace loros/syrus-ssmcache => ../../lib/go/ssmcache
+
+replace loros/syrus-logging => ../../lib/go/logging
 
 require (
 	github.com/aws/aws-lambda-go v1.47.0
-	github.com/aws/aws-sdk-go v1.50.0
+	github.com/aws/aws-sdk-go v1.55.5
+	loros/syrus-commandopts v0.0.0
+	loros/syrus-logging v0.0.0
+	loros/syrus-models v0.0.0
+	loros/syrus-ssmcache v0.0.0
 )
 
 require github.com/jmespath/go-jmespath v0.4.0 // indirect
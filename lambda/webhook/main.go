@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -17,7 +19,10 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/sqs"
-	"github.com/aws/aws-sdk-go/service/ssm"
+	"loros/syrus-commandopts"
+	"loros/syrus-logging"
+	models "loros/syrus-models"
+	"loros/syrus-ssmcache"
 )
 
 // Discord interaction structures
@@ -27,13 +32,140 @@ type DiscordInteraction struct {
 	Data      map[string]interface{} `json:"data,omitempty"`
 	GuildID   string                 `json:"guild_id,omitempty"`
 	ChannelID string                 `json:"channel_id,omitempty"`
+	Channel   *DiscordChannel        `json:"channel,omitempty"`
 	Member    *DiscordMember         `json:"member,omitempty"`
 	User      *DiscordUser           `json:"user,omitempty"`
 	Token     string                 `json:"token"`
 }
 
+// DiscordChannel is the partial channel object sent with an interaction
+type DiscordChannel struct {
+	ID       string `json:"id"`
+	Type     int    `json:"type"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// Discord thread channel types
+const (
+	channelTypeAnnouncementThread = 10
+	channelTypePublicThread       = 11
+	channelTypePrivateThread      = 12
+)
+
+// isThreadChannel reports whether a Discord channel type is a thread
+func isThreadChannel(channelType int) bool {
+	switch channelType {
+	case channelTypeAnnouncementThread, channelTypePublicThread, channelTypePrivateThread:
+		return true
+	}
+	return false
+}
+
+// deriveCampaignChannelID returns the channel an interaction's campaign is bound to: the thread ID when
+// the interaction originates in a thread (so one channel can host parallel campaigns), otherwise the channel ID
+func deriveCampaignChannelID(interaction DiscordInteraction) string {
+	if interaction.Channel != nil && interaction.Channel.ID != "" && isThreadChannel(interaction.Channel.Type) {
+		return interaction.Channel.ID
+	}
+	return interaction.ChannelID
+}
+
+// ephemeralSyrusSubcommands are /syrus subcommands whose reply only the invoking user should see
+var ephemeralSyrusSubcommands = map[string]bool{
+	"intro": true, // Late joiners catch up without re-posting the opening to the whole channel
+}
+
+// syrusDeferredResponse returns the deferred response body for a /syrus interaction.
+// Ephemerality is fixed by the deferral, so private subcommands must be deferred ephemerally.
+// Everything else defers publicly: the play lambda edits the deferred original with its narration,
+// and an ephemeral deferral would hide the story from the rest of the party.
+func syrusDeferredResponse(data map[string]interface{}) string {
+	subcommand, _, err := commandopts.Parse(data)
+	if err != nil {
+		// Defer publicly; the play lambda reports the malformed options in channel
+		log.Printf("Failed to parse /syrus options: %v", err)
+		return `{"type": 5}`
+	}
+	if ephemeralSyrusSubcommands[subcommand] {
+		return `{"type": 5, "data": {"flags": 64}}`
+	}
+	return `{"type": 5}`
+}
+
+// syrusModelOverride returns the narration model a /syrus debug option asked for, or "" when none was given.
+// The play lambda honors it only for debug users.
+func syrusModelOverride(data map[string]interface{}) models.Model {
+	_, opts, err := commandopts.Parse(data)
+	if err != nil {
+		return ""
+	}
+	return models.Model(opts["model"])
+}
+
+// interactionTypeMessageComponent is the interaction type Discord sends for button clicks
+const interactionTypeMessageComponent = 3
+
+// voteCustomIDPrefix prefixes the custom ID of a group decision's vote buttons (must match the play lambda)
+const voteCustomIDPrefix = "syrus_vote:"
+
+// isVoteInteraction reports whether an interaction is a click on a group decision's vote button
+func isVoteInteraction(interaction DiscordInteraction) bool {
+	if interaction.Type != interactionTypeMessageComponent {
+		return false
+	}
+	customID, _ := interaction.Data["custom_id"].(string)
+	return strings.HasPrefix(customID, voteCustomIDPrefix)
+}
+
+// ephemeralMessageResponse returns an immediate (type 4) response body visible only to the
+// invoking user. Used when nothing was queued, since a deferral would never be followed up.
+func ephemeralMessageResponse(content string) string {
+	body, err := json.Marshal(map[string]interface{}{
+		"type": 4,
+		"data": map[string]interface{}{
+			"content": content,
+			"flags":   64,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal ephemeral response: %v", err)
+		return `{"type": 4, "data": {"flags": 64}}`
+	}
+	return string(body)
+}
+
+// unreachableResponse answers commands that could not be handed to a worker lambda
+var unreachableResponse = ephemeralMessageResponse("*Your words drift into the void. Try again in a moment.*")
+
+// deriveParentChannelID returns the parent channel ID for thread interactions, or "" otherwise
+func deriveParentChannelID(interaction DiscordInteraction) string {
+	if interaction.Channel != nil && isThreadChannel(interaction.Channel.Type) {
+		return interaction.Channel.ParentID
+	}
+	return ""
+}
+
 type DiscordMember struct {
-	User DiscordUser `json:"user"`
+	User        DiscordUser `json:"user"`
+	Permissions string      `json:"permissions,omitempty"` // Bitwise permission set, serialized as a string
+}
+
+// Discord permission bits that mark a guild admin
+const (
+	permissionAdministrator = 1 << 3
+	permissionManageGuild   = 1 << 5
+)
+
+// isGuildAdmin reports whether the invoking member can administer or manage the guild
+func isGuildAdmin(interaction DiscordInteraction) bool {
+	if interaction.GuildID == "" || interaction.Member == nil {
+		return false
+	}
+	permissions, err := strconv.ParseUint(interaction.Member.Permissions, 10, 64)
+	if err != nil {
+		return false
+	}
+	return permissions&(permissionAdministrator|permissionManageGuild) != 0
 }
 
 type DiscordUser struct {
@@ -111,30 +243,124 @@ func checkHostExists(userID string) (string, bool) {
 	return name, true
 }
 
-// getDiscordPublicKey retrieves the Discord public key from SSM Parameter Store
-func getDiscordPublicKey(stage string) (ed25519.PublicKey, error) {
+// channelCampaignIndex is the campaigns table GSI keyed by channelId and source
+const channelCampaignIndex = "ByChannel"
+
+// latestChannelCampaign returns the campaignId of the most recently created item, or "" when there are none
+func latestChannelCampaign(items []map[string]*dynamodb.AttributeValue) string {
+	var latestID string
+	var latestAt time.Time
+	for _, item := range items {
+		idAttr, ok := item["campaignId"]
+		if !ok || idAttr.S == nil {
+			continue
+		}
+		var createdAt time.Time
+		if attr, ok := item["createdAt"]; ok && attr.S != nil {
+			createdAt, _ = time.Parse(time.RFC3339Nano, *attr.S)
+		}
+		if latestID == "" || createdAt.After(latestAt) {
+			latestID, latestAt = *idAttr.S, createdAt
+		}
+	}
+	return latestID
+}
+
+// findChannelCampaign returns the ID of the campaign bound to a channel, or "" when there is none.
+// Campaigns created before IDs were generated are keyed by their channel and absent from the index.
+func findChannelCampaign(channelID string) (string, error) {
+	campaignsTable := os.Getenv("SYRUS_CAMPAIGNS_TABLE")
+	if campaignsTable == "" {
+		return "", fmt.Errorf("SYRUS_CAMPAIGNS_TABLE environment variable not set")
+	}
+
 	sess, err := session.NewSession()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+		return "", fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
-	svc := ssm.New(sess)
-	paramName := fmt.Sprintf("/syrus/%s/discord/public-key", stage)
-	result, err := svc.GetParameter(&ssm.GetParameterInput{
-		Name:           aws.String(paramName),
-		WithDecryption: aws.Bool(false), // Public key doesn't need decryption
+	svc := dynamodb.New(sess)
+	indexed, err := svc.Query(&dynamodb.QueryInput{
+		TableName:              aws.String(campaignsTable),
+		IndexName:              aws.String(channelCampaignIndex),
+		KeyConditionExpression: aws.String("channelId = :channelId AND #source = :source"),
+		ExpressionAttributeNames: map[string]*string{
+			"#source": aws.String("source"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":channelId": {S: aws.String(channelID)},
+			":source":    {S: aws.String("discord")},
+		},
 	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query channel campaigns: %w", err)
+	}
+	if campaignID := latestChannelCampaign(indexed.Items); campaignID != "" {
+		return campaignID, nil
+	}
 
+	legacy, err := svc.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(campaignsTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(channelID)},
+		},
+		ProjectionExpression: aws.String("campaignId"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get campaign: %w", err)
+	}
+	if legacy.Item == nil {
+		return "", nil
+	}
+	return channelID, nil
+}
+
+// lookupCampaign is swappable in tests
+var lookupCampaign = findChannelCampaign
+
+// resolveCampaignID returns the campaign to queue an interaction under. Lookup failures fall back to the
+// channel ID, which was the campaign ID before IDs were generated.
+func resolveCampaignID(channelID string) string {
+	campaignID, err := lookupCampaign(channelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get parameter %s: %w", paramName, err)
+		log.Printf("Failed to look up campaign for channel %s: %v", channelID, err)
+		return channelID
+	}
+	if campaignID == "" {
+		return channelID
 	}
+	return campaignID
+}
+
+// noCampaignResponse is the reply to /syrus in a channel with no campaign
+const noCampaignResponse = `{"type": 4, "data": {"content": "*The pages of destiny remain blank. No tale has been woven here yet — ask a host to begin one with /campaign.*", "flags": 64}}`
 
-	if result.Parameter == nil || result.Parameter.Value == nil {
-		return nil, fmt.Errorf("parameter %s not found or has no value", paramName)
+// syrusInteractionResponse returns the response body for a /syrus interaction, the campaign it
+// belongs to, and whether it should be forwarded to the play queue. Channels without a campaign are
+// answered immediately; lookup failures fall through to the play lambda under the channel ID, and
+// the play lambda reports a missing campaign itself.
+func syrusInteractionResponse(channelID string, data map[string]interface{}) (string, string, bool) {
+	campaignID, err := lookupCampaign(channelID)
+	if err != nil {
+		log.Printf("Failed to look up campaign for channel %s: %v", channelID, err)
+		return syrusDeferredResponse(data), channelID, true
+	}
+	if campaignID == "" {
+		return noCampaignResponse, "", false
+	}
+	return syrusDeferredResponse(data), campaignID, true
+}
+
+// getDiscordPublicKey retrieves the Discord public key from SSM Parameter Store
+func getDiscordPublicKey(stage string) (ed25519.PublicKey, error) {
+	// Public key doesn't need decryption
+	publicKeyValue, err := ssmcache.Get(fmt.Sprintf("/syrus/%s/discord/public-key", stage), false)
+	if err != nil {
+		return nil, err
 	}
 
 	// Decode hex-encoded public key
-	publicKeyHex := strings.TrimSpace(*result.Parameter.Value)
+	publicKeyHex := strings.TrimSpace(publicKeyValue)
 	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode public key hex: %w", err)
@@ -165,8 +391,9 @@ func sendMessageToQueue(channelID string, content string, interactionToken strin
 
 	// Create message body
 	messageBody := map[string]interface{}{
-		"channelId": channelID,
-		"content":   content,
+		"channelId":     channelID,
+		"content":       content,
+		"interactionId": interactionID,
 	}
 	if interactionToken != "" {
 		messageBody["interactionToken"] = interactionToken
@@ -176,12 +403,13 @@ func sendMessageToQueue(channelID string, content string, interactionToken strin
 		return fmt.Errorf("failed to marshal message body: %w", err)
 	}
 
-	// Send message to queue
+	// Send message to queue; a standalone reply needs no ordering, so it does not wait behind the channel's other messages
+	deduplicationID := interactionID + "-webhook"
 	_, err = svc.SendMessage(&sqs.SendMessageInput{
 		QueueUrl:               aws.String(queueURL),
 		MessageBody:            aws.String(string(messageBodyJSON)),
-		MessageGroupId:         aws.String(channelID),                  // Group by campaignID (channelID = campaignID)
-		MessageDeduplicationId: aws.String(interactionID + "-webhook"), // Dedupe by interactionID
+		MessageGroupId:         aws.String(models.MessageGroupID(channelID, deduplicationID, models.MessageUnordered)),
+		MessageDeduplicationId: aws.String(deduplicationID),
 	})
 
 	if err != nil {
@@ -192,8 +420,8 @@ func sendMessageToQueue(channelID string, content string, interactionToken strin
 	return nil
 }
 
-// sendToPlayQueue sends a play request to the play queue
-func sendToPlayQueue(campaignID, interactionID string, interaction DiscordInteraction) error {
+// sendToPlayQueue sends a play request to the play queue, with any debug model override for its narration
+func sendToPlayQueue(campaignID, channelID, interactionID string, interaction DiscordInteraction, modelOverride models.Model) error {
 	queueURL := os.Getenv("SYRUS_PLAY_QUEUE_URL")
 	if queueURL == "" {
 		return fmt.Errorf("SYRUS_PLAY_QUEUE_URL environment variable not set")
@@ -208,9 +436,13 @@ func sendToPlayQueue(campaignID, interactionID string, interaction DiscordIntera
 
 	playRequest := map[string]interface{}{
 		"campaignId":        campaignID,
+		"channelId":         channelID,
 		"interactionId":     interactionID,
 		"interactionObject": interaction,
 	}
+	if modelOverride != "" {
+		playRequest["modelOverride"] = modelOverride
+	}
 
 	messageBodyJSON, err := json.Marshal(playRequest)
 	if err != nil {
@@ -233,7 +465,7 @@ func sendToPlayQueue(campaignID, interactionID string, interaction DiscordIntera
 }
 
 // sendToConfiguringQueue sends a campaign configuration request
-func sendToConfiguringQueue(channelID, hostID, interactionID, interactionToken string, options []map[string]interface{}) error {
+func sendToConfiguringQueue(channelID, parentChannelID, hostID, guildID string, guildAdmin bool, interactionID, interactionToken string, options []map[string]interface{}) error {
 	queueURL := os.Getenv("SYRUS_CONFIGURING_QUEUE_URL")
 	if queueURL == "" {
 		return fmt.Errorf("SYRUS_CONFIGURING_QUEUE_URL environment variable not set")
@@ -253,6 +485,13 @@ func sendToConfiguringQueue(channelID, hostID, interactionID, interactionToken s
 		"interactionToken": interactionToken,
 		"options":          options,
 	}
+	if parentChannelID != "" {
+		message["parentChannelId"] = parentChannelID
+	}
+	if guildID != "" {
+		message["guildId"] = guildID
+		message["guildAdmin"] = guildAdmin
+	}
 
 	messageBodyJSON, err := json.Marshal(message)
 	if err != nil {
@@ -274,6 +513,22 @@ func sendToConfiguringQueue(channelID, hostID, interactionID, interactionToken s
 	return nil
 }
 
+// signatureMaxSkew is how far a signature timestamp may be from now; older signed payloads are treated as replays
+const signatureMaxSkew = 5 * time.Minute
+
+// checkSignatureTimestamp verifies the X-Signature-Timestamp (unix seconds) is within signatureMaxSkew of now
+func checkSignatureTimestamp(timestamp string, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp %q: %w", timestamp, err)
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > signatureMaxSkew || skew < -signatureMaxSkew {
+		return fmt.Errorf("signature timestamp %s is %s from now, beyond the allowed %s", timestamp, skew.Round(time.Second), signatureMaxSkew)
+	}
+	return nil
+}
+
 // verifyDiscordSignature verifies the Discord interaction signature using Ed25519
 // Uses raw bytes to avoid any string encoding issues
 func verifyDiscordSignature(signature string, timestamp string, bodyBytes []byte, publicKey ed25519.PublicKey) bool {
@@ -372,6 +627,20 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 		return response, nil
 	}
 
+	// Reject stale timestamps before the signature check, so a captured payload cannot be replayed
+	if err := checkSignatureTimestamp(timestamp, time.Now()); err != nil {
+		log.Printf("Rejecting Discord request: %v", err)
+		response := events.APIGatewayV2HTTPResponse{
+			StatusCode: 401,
+			Body:       `{"error": "Unauthorized"}`,
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+		}
+
+		return response, nil
+	}
+
 	// Get Discord public key from SSM
 	stage := os.Getenv("SYRUS_STAGE")
 	if stage == "" {
@@ -420,7 +689,9 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 		return response, nil
 	}
 
-	log.Printf("Interaction type: %d", interaction.Type)
+	// Every line from here on carries the interaction ID, which play and messaging log under too
+	logger := logging.For(interaction.ID, "")
+	logger.Printf("Interaction type: %d", interaction.Type)
 
 	if interaction.Type == 1 {
 		response := events.APIGatewayV2HTTPResponse{
@@ -443,7 +714,7 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 	}
 
 	if userID == "" {
-		log.Printf("No user ID found in interaction")
+		logger.Printf("No user ID found in interaction")
 		return events.APIGatewayV2HTTPResponse{
 			StatusCode: 400,
 			Body:       `{"error": "Missing user information"}`,
@@ -456,7 +727,7 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 	// Check if user is whitelisted in hosts table
 	_, exists := checkHostExists(userID)
 	if !exists {
-		log.Printf("User %s is not whitelisted, ignoring interaction", userID)
+		logger.Printf("User %s is not whitelisted, ignoring interaction", userID)
 		// Return 200 OK but don't process (silently ignore)
 		response := events.APIGatewayV2HTTPResponse{
 			StatusCode: 200,
@@ -473,24 +744,57 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 	if interaction.Data != nil {
 		// Log the interaction data to see what we're receiving
 		dataJSON, _ := json.Marshal(interaction.Data)
-		log.Printf("Interaction data: %s", string(dataJSON))
+		logger.Printf("Interaction data: %s", string(dataJSON))
+
+		// Votes are counted by the play lambda, which tells the voter privately how their vote landed
+		if isVoteInteraction(interaction) {
+			channelID := deriveCampaignChannelID(interaction)
+			if err := sendToPlayQueue(resolveCampaignID(channelID), channelID, interaction.ID, interaction, ""); err != nil {
+				logger.Printf("Failed to send vote to play queue: %v", err)
+				return events.APIGatewayV2HTTPResponse{
+					StatusCode: 200,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: unreachableResponse,
+				}, nil
+			}
+			return events.APIGatewayV2HTTPResponse{
+				StatusCode: 200,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: `{"type": 5, "data": {"flags": 64}}`,
+			}, nil
+		}
 
 		if commandName, ok := interaction.Data["name"].(string); ok {
-			log.Printf("Command name detected: %s", commandName)
+			logger.Printf("Command name detected: %s", commandName)
 			switch commandName {
 			case "syrus":
+				channelID := deriveCampaignChannelID(interaction)
+				body, campaignID, forward := syrusInteractionResponse(channelID, interaction.Data)
+				if !forward {
+					logger.Printf("No campaign found for channel %s", channelID)
+					return events.APIGatewayV2HTTPResponse{
+						StatusCode: 200,
+						Headers: map[string]string{
+							"Content-Type": "application/json",
+						},
+						Body: body,
+					}, nil
+				}
+
 				// Send the entire interaction to the play queue for processing
-				if err := sendToPlayQueue(interaction.ChannelID, interaction.ID, interaction); err != nil {
-					log.Printf("Failed to send to play queue: %v", err)
-					// Return error response
-					response := events.APIGatewayV2HTTPResponse{
-						StatusCode: 500,
+				if err := sendToPlayQueue(campaignID, channelID, interaction.ID, interaction, syrusModelOverride(interaction.Data)); err != nil {
+					logger.Printf("Failed to send to play queue: %v", err)
+					return events.APIGatewayV2HTTPResponse{
+						StatusCode: 200,
 						Headers: map[string]string{
 							"Content-Type": "application/json",
 						},
-						Body: `{"error": "Internal server error"}`,
-					}
-					return response, nil
+						Body: unreachableResponse,
+					}, nil
 				}
 
 				// Return type 5 (DEFERRED_CHANNEL_MESSAGE_WITH_SOURCE) - play lambda will follow up via messaging queue
@@ -499,14 +803,21 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 					Headers: map[string]string{
 						"Content-Type": "application/json",
 					},
-					Body: `{"type": 5}`,
+					Body: body,
 				}
 
 				return response, nil
 			case "ping":
 				// Send "Pong! 🏓" message via queue with interaction token
 				if err := sendMessageToQueue(interaction.ChannelID, "Pong! 🏓", interaction.Token, interaction.ID); err != nil {
-					log.Printf("Failed to send ping response to queue: %v", err)
+					logger.Printf("Failed to send ping response to queue: %v", err)
+					return events.APIGatewayV2HTTPResponse{
+						StatusCode: 200,
+						Headers: map[string]string{
+							"Content-Type": "application/json",
+						},
+						Body: unreachableResponse,
+					}, nil
 				}
 				// Return type 5 (DEFERRED_CHANNEL_MESSAGE_WITH_SOURCE) - will follow up via webhook
 				response := events.APIGatewayV2HTTPResponse{
@@ -531,13 +842,23 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 
 				// Send to configuring queue with raw options
 				if err := sendToConfiguringQueue(
-					interaction.ChannelID,
+					deriveCampaignChannelID(interaction),
+					deriveParentChannelID(interaction),
 					interaction.Member.User.ID,
+					interaction.GuildID,
+					isGuildAdmin(interaction),
 					interaction.ID,
 					interaction.Token,
 					options,
 				); err != nil {
-					log.Printf("Failed to send to configuring queue: %v", err)
+					logger.Printf("Failed to send to configuring queue: %v", err)
+					return events.APIGatewayV2HTTPResponse{
+						StatusCode: 200,
+						Headers: map[string]string{
+							"Content-Type": "application/json",
+						},
+						Body: unreachableResponse,
+					}, nil
 				}
 
 				// Return type 5 (DEFERRED_CHANNEL_MESSAGE_WITH_SOURCE)
@@ -552,23 +873,24 @@ func handleRequest(ctx context.Context, request events.APIGatewayV2HTTPRequest)
 				return response, nil
 			}
 
-			log.Printf("unhandled command: %s", string(commandName))
+			logger.Printf("unhandled command: %s", string(commandName))
 		}
 		// Log unhandled interaction with full payload
 	}
 
-	// Return type 5 (DEFERRED_CHANNEL_MESSAGE_WITH_SOURCE) - will follow up via webhook
+	// Nothing was queued, so answer immediately - a deferral would never be followed up
 	response := events.APIGatewayV2HTTPResponse{
 		StatusCode: 200,
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
-		Body: `{"type": 4, "data": {}}`,
+		Body: ephemeralMessageResponse("*Syrus does not recognise that command.*"),
 	}
 
 	return response, nil
 }
 
 func main() {
+	logging.Init("webhook")
 	lambda.Start(handleRequest)
 }
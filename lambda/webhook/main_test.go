@@ -4,8 +4,15 @@ import (
 	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	models "loros/syrus-models"
 )
 
 func TestFormatDebugPayload(t *testing.T) {
@@ -226,6 +233,34 @@ func TestVerifyDiscordSignature(t *testing.T) {
 	}
 }
 
+func TestCheckSignatureTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		valid     bool
+	}{
+		{"current", "1700000000", true},
+		{"within skew in the past", "1699999760", true},
+		{"within skew in the future", "1700000240", true},
+		{"at the skew limit", "1699999700", true},
+		{"stale", "1699999699", false},
+		{"too far in the future", "1700000301", false},
+		{"not a number", "yesterday", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSignatureTimestamp(tt.timestamp, now)
+			if (err == nil) != tt.valid {
+				t.Errorf("checkSignatureTimestamp(%q) = %v, expected valid=%v", tt.timestamp, err, tt.valid)
+			}
+		})
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
@@ -347,3 +382,294 @@ func TestCampaignOptionsExtraction(t *testing.T) {
 		})
 	}
 }
+
+func TestDeriveCampaignChannelID(t *testing.T) {
+	tests := []struct {
+		name             string
+		interaction      DiscordInteraction
+		expectedCampaign string
+		expectedParent   string
+	}{
+		{
+			name:             "guild text channel uses channel ID",
+			interaction:      DiscordInteraction{ChannelID: "channel_1", Channel: &DiscordChannel{ID: "channel_1", Type: 0}},
+			expectedCampaign: "channel_1",
+			expectedParent:   "",
+		},
+		{
+			name:             "missing channel object falls back to channel ID",
+			interaction:      DiscordInteraction{ChannelID: "channel_1"},
+			expectedCampaign: "channel_1",
+			expectedParent:   "",
+		},
+		{
+			name:             "public thread uses thread ID",
+			interaction:      DiscordInteraction{ChannelID: "thread_1", Channel: &DiscordChannel{ID: "thread_1", Type: 11, ParentID: "channel_1"}},
+			expectedCampaign: "thread_1",
+			expectedParent:   "channel_1",
+		},
+		{
+			name:             "private thread uses thread ID",
+			interaction:      DiscordInteraction{ChannelID: "thread_2", Channel: &DiscordChannel{ID: "thread_2", Type: 12, ParentID: "channel_1"}},
+			expectedCampaign: "thread_2",
+			expectedParent:   "channel_1",
+		},
+		{
+			name:             "announcement thread uses thread ID",
+			interaction:      DiscordInteraction{ChannelID: "thread_3", Channel: &DiscordChannel{ID: "thread_3", Type: 10, ParentID: "channel_2"}},
+			expectedCampaign: "thread_3",
+			expectedParent:   "channel_2",
+		},
+		{
+			name:             "forum parent channel is not a thread",
+			interaction:      DiscordInteraction{ChannelID: "forum_1", Channel: &DiscordChannel{ID: "forum_1", Type: 15}},
+			expectedCampaign: "forum_1",
+			expectedParent:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveCampaignChannelID(tt.interaction); got != tt.expectedCampaign {
+				t.Errorf("Expected campaign channel ID %s, got %s", tt.expectedCampaign, got)
+			}
+			if got := deriveParentChannelID(tt.interaction); got != tt.expectedParent {
+				t.Errorf("Expected parent channel ID %q, got %q", tt.expectedParent, got)
+			}
+		})
+	}
+}
+
+func TestDiscordInteractionChannelParsing(t *testing.T) {
+	payload := `{"id":"int_1","type":2,"channel_id":"thread_1","channel":{"id":"thread_1","type":11,"parent_id":"channel_1"},"token":"tok"}`
+
+	var interaction DiscordInteraction
+	if err := json.Unmarshal([]byte(payload), &interaction); err != nil {
+		t.Fatalf("Failed to unmarshal interaction: %v", err)
+	}
+
+	if interaction.Channel == nil {
+		t.Fatal("Expected channel object to be parsed")
+	}
+	if deriveCampaignChannelID(interaction) != "thread_1" {
+		t.Errorf("Expected thread campaign channel ID, got %s", deriveCampaignChannelID(interaction))
+	}
+	if deriveParentChannelID(interaction) != "channel_1" {
+		t.Errorf("Expected parent channel_1, got %s", deriveParentChannelID(interaction))
+	}
+}
+
+func TestSyrusDeferredResponse(t *testing.T) {
+	subcommand := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    "syrus",
+			"options": []interface{}{map[string]interface{}{"name": name, "type": float64(1)}},
+		}
+	}
+
+	if body := syrusDeferredResponse(subcommand("intro")); body != `{"type": 5, "data": {"flags": 64}}` {
+		t.Errorf("Expected intro to be deferred ephemerally, got %s", body)
+	}
+	if body := syrusDeferredResponse(subcommand("declare")); body != `{"type": 5}` {
+		t.Errorf("Expected declare to be deferred publicly, got %s", body)
+	}
+	if body := syrusDeferredResponse(map[string]interface{}{"name": "syrus"}); body != `{"type": 5}` {
+		t.Errorf("Expected no options to be deferred publicly, got %s", body)
+	}
+	malformed := map[string]interface{}{"name": "syrus", "options": "intro"}
+	if body := syrusDeferredResponse(malformed); body != `{"type": 5}` {
+		t.Errorf("Expected malformed options to be deferred publicly, got %s", body)
+	}
+}
+
+func TestSyrusModelOverride(t *testing.T) {
+	declare := func(options ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    "syrus",
+			"options": []interface{}{map[string]interface{}{"name": "declare", "type": float64(1), "options": options}},
+		}
+	}
+	intent := map[string]interface{}{"name": "intent", "type": float64(3), "value": "I ring the bell"}
+
+	if got := syrusModelOverride(declare(intent, map[string]interface{}{"name": "model", "type": float64(3), "value": "sonnet"})); got != models.ModelSonnet {
+		t.Errorf("Expected the sonnet override, got %q", got)
+	}
+	if got := syrusModelOverride(declare(intent)); got != "" {
+		t.Errorf("Expected no override without the option, got %q", got)
+	}
+	if got := syrusModelOverride(map[string]interface{}{"name": "syrus", "options": "declare"}); got != "" {
+		t.Errorf("Expected no override from malformed options, got %q", got)
+	}
+}
+
+func TestSyrusInteractionResponse(t *testing.T) {
+	original := lookupCampaign
+	t.Cleanup(func() { lookupCampaign = original })
+
+	declare := map[string]interface{}{
+		"name":    "syrus",
+		"options": []interface{}{map[string]interface{}{"name": "declare", "type": float64(1)}},
+	}
+
+	tests := []struct {
+		name         string
+		lookup       func(string) (string, error)
+		wantBody     string
+		wantCampaign string
+		wantForward  bool
+	}{
+		{
+			name:         "campaign exists",
+			lookup:       func(string) (string, error) { return "campaign-1", nil },
+			wantBody:     `{"type": 5}`,
+			wantCampaign: "campaign-1",
+			wantForward:  true,
+		},
+		{
+			name:         "no campaign",
+			lookup:       func(string) (string, error) { return "", nil },
+			wantBody:     noCampaignResponse,
+			wantCampaign: "",
+			wantForward:  false,
+		},
+		{
+			name:         "lookup error falls through to play",
+			lookup:       func(string) (string, error) { return "", fmt.Errorf("throttled") },
+			wantBody:     `{"type": 5}`,
+			wantCampaign: "chan-1",
+			wantForward:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var looked string
+			lookupCampaign = func(channelID string) (string, error) {
+				looked = channelID
+				return tt.lookup(channelID)
+			}
+
+			body, campaignID, forward := syrusInteractionResponse("chan-1", declare)
+			if looked != "chan-1" {
+				t.Errorf("Expected lookup of chan-1, got %q", looked)
+			}
+			if body != tt.wantBody {
+				t.Errorf("Expected body %s, got %s", tt.wantBody, body)
+			}
+			if campaignID != tt.wantCampaign {
+				t.Errorf("Expected campaign %q, got %q", tt.wantCampaign, campaignID)
+			}
+			if forward != tt.wantForward {
+				t.Errorf("Expected forward=%v, got %v", tt.wantForward, forward)
+			}
+		})
+	}
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal([]byte(noCampaignResponse), &resp); err != nil {
+		t.Fatalf("noCampaignResponse is not valid JSON: %v", err)
+	}
+}
+
+func TestLatestChannelCampaign(t *testing.T) {
+	item := func(campaignID, createdAt string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+			"createdAt":  {S: aws.String(createdAt)},
+		}
+	}
+
+	items := []map[string]*dynamodb.AttributeValue{
+		item("ended", "2024-05-01T12:00:00Z"),
+		item("current", "2024-06-01T12:00:00.5Z"),
+		item("older", "2024-05-20T08:30:00Z"),
+	}
+	if got := latestChannelCampaign(items); got != "current" {
+		t.Errorf("Expected the most recent campaign, got %q", got)
+	}
+	if got := latestChannelCampaign(nil); got != "" {
+		t.Errorf("Expected no campaign, got %q", got)
+	}
+}
+
+func TestResolveCampaignID(t *testing.T) {
+	original := lookupCampaign
+	t.Cleanup(func() { lookupCampaign = original })
+
+	lookupCampaign = func(string) (string, error) { return "campaign-1", nil }
+	if got := resolveCampaignID("chan-1"); got != "campaign-1" {
+		t.Errorf("Expected campaign-1, got %q", got)
+	}
+
+	lookupCampaign = func(string) (string, error) { return "", fmt.Errorf("throttled") }
+	if got := resolveCampaignID("chan-1"); got != "chan-1" {
+		t.Errorf("Expected lookup failure to fall back to the channel, got %q", got)
+	}
+}
+
+func TestEphemeralMessageResponse(t *testing.T) {
+	var resp struct {
+		Type int `json:"type"`
+		Data struct {
+			Content string `json:"content"`
+			Flags   int    `json:"flags"`
+		} `json:"data"`
+	}
+	body := ephemeralMessageResponse(`*The "bell" tolls.*`)
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("Expected valid JSON, got %s: %v", body, err)
+	}
+	if resp.Type != 4 || resp.Data.Flags != 64 {
+		t.Errorf("Expected an ephemeral type 4 response, got %s", body)
+	}
+	if resp.Data.Content != `*The "bell" tolls.*` {
+		t.Errorf("Expected content to round-trip, got %q", resp.Data.Content)
+	}
+}
+
+func TestIsVoteInteraction(t *testing.T) {
+	tests := []struct {
+		name        string
+		interaction DiscordInteraction
+		expected    bool
+	}{
+		{"vote button", DiscordInteraction{Type: 3, Data: map[string]interface{}{"custom_id": "syrus_vote:1"}}, true},
+		{"other button", DiscordInteraction{Type: 3, Data: map[string]interface{}{"custom_id": "something_else"}}, false},
+		{"slash command", DiscordInteraction{Type: 2, Data: map[string]interface{}{"name": "syrus"}}, false},
+		{"no data", DiscordInteraction{Type: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isVoteInteraction(tt.interaction); got != tt.expected {
+				t.Errorf("isVoteInteraction() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsGuildAdmin(t *testing.T) {
+	member := func(permissions string) *DiscordMember {
+		return &DiscordMember{User: DiscordUser{ID: "user-1"}, Permissions: permissions}
+	}
+
+	tests := []struct {
+		name        string
+		interaction DiscordInteraction
+		expected    bool
+	}{
+		{"administrator", DiscordInteraction{GuildID: "guild-1", Member: member("8")}, true},
+		{"manage guild", DiscordInteraction{GuildID: "guild-1", Member: member("32")}, true},
+		{"send messages only", DiscordInteraction{GuildID: "guild-1", Member: member("2048")}, false},
+		{"malformed permissions", DiscordInteraction{GuildID: "guild-1", Member: member("lots")}, false},
+		{"direct message", DiscordInteraction{User: &DiscordUser{ID: "user-1"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isGuildAdmin(tt.interaction); got != tt.expected {
+				t.Errorf("isGuildAdmin() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -17,9 +17,9 @@ export function createCampaignsTable(scope: Construct, stageConfig: StageConfig)
       name: 'campaignId',
       type: dynamodb.AttributeType.STRING,
     },
-    // Note: No sort key for MVP - status is mutable and campaignId identifies
-    // the single current campaign per group/solo. We overwrite records when
-    // a new campaign starts.
+    // Note: No sort key for MVP - campaignId is a generated UUID. Campaigns created
+    // before IDs were generated used their channel ID as campaignId; the ByChannel
+    // index finds a channel's campaigns, with a fallback GetItem for those records.
     billingMode: dynamodb.BillingMode.PROVISIONED,
     readCapacity: stageConfig.tableCapacity.readCapacity,
     writeCapacity: stageConfig.tableCapacity.writeCapacity,
@@ -46,6 +46,28 @@ export function createCampaignsTable(scope: Construct, stageConfig: StageConfig)
     projectionType: dynamodb.ProjectionType.ALL,
   });
 
+  // Add GSI for counting a guild's campaigns against its concurrency cap
+  table.addGlobalSecondaryIndex({
+    indexName: 'ByGuild',
+    partitionKey: {
+      name: 'guildId',
+      type: dynamodb.AttributeType.STRING,
+    },
+    readCapacity: stageConfig.gsiCapacity.readCapacity,
+    writeCapacity: stageConfig.gsiCapacity.writeCapacity,
+    projectionType: dynamodb.ProjectionType.INCLUDE,
+    nonKeyAttributes: ['status'],
+  });
+
+  // Add GSI for finding the campaigns bound to a channel (DM or group).
+  // DynamoDB creates at most one GSI per table update, so a stage that predates
+  // ByGuild deploys once with `--context withholdChannelIndex=true` and again
+  // without it once ByGuild is ACTIVE (see README "Adding campaign indexes").
+  const withholdChannelIndex = scope.node.tryGetContext('withholdChannelIndex');
+  if (withholdChannelIndex !== true && withholdChannelIndex !== 'true') {
+    addChannelIndex(table, stageConfig);
+  }
+
   // Add tags
   Tags.of(table).add('App', 'Syrus');
   Tags.of(table).add('Service', 'DiscordBot');
@@ -54,6 +76,30 @@ export function createCampaignsTable(scope: Construct, stageConfig: StageConfig)
   return table;
 }
 
+/**
+ * Adds the ByChannel GSI for finding the campaigns bound to a channel (DM or group)
+ *
+ * @param table The campaigns table
+ * @param stageConfig Configuration for the deployment stage
+ */
+function addChannelIndex(table: dynamodb.Table, stageConfig: StageConfig): void {
+  table.addGlobalSecondaryIndex({
+    indexName: 'ByChannel',
+    partitionKey: {
+      name: 'channelId',
+      type: dynamodb.AttributeType.STRING,
+    },
+    sortKey: {
+      name: 'source',
+      type: dynamodb.AttributeType.STRING,
+    },
+    readCapacity: stageConfig.gsiCapacity.readCapacity,
+    writeCapacity: stageConfig.gsiCapacity.writeCapacity,
+    projectionType: dynamodb.ProjectionType.INCLUDE,
+    nonKeyAttributes: ['createdAt'],
+  });
+}
+
 /**
  * Creates the Hosts DynamoDB table for whitelisting Discord users
  *
@@ -17,6 +17,12 @@ export interface StageConfig {
         readCapacity: number;
         writeCapacity: number;
     };
+    /** Feature flags passed to Lambdas as SYRUS_FEATURES ('*' enables all) */
+    features: string[];
+    /** Max concurrent unended campaigns per Discord guild (0 = no cap); guild admins may exceed it */
+    guildCampaignCap: number;
+    /** Capture redacted prompt/response pairs to S3 for offline evaluation (SYRUS_CAPTURE_BUCKET) */
+    promptCapture: boolean;
 }
 /**
  * Stage configurations for dev and prod environments
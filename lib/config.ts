@@ -18,6 +18,12 @@ export interface StageConfig {
     readCapacity: number;
     writeCapacity: number;
   };
+  /** Feature flags passed to Lambdas as SYRUS_FEATURES ('*' enables all) */
+  features: string[];
+  /** Max concurrent unended campaigns per Discord guild (0 = no cap); guild admins may exceed it */
+  guildCampaignCap: number;
+  /** Capture redacted prompt/response pairs to S3 for offline evaluation (SYRUS_CAPTURE_BUCKET) */
+  promptCapture: boolean;
 }
 
 /**
@@ -35,6 +41,9 @@ export const STAGE_CONFIGS: Record<string, StageConfig> = {
       readCapacity: 1,
       writeCapacity: 1,
     },
+    features: ['*'],
+    guildCampaignCap: 0,
+    promptCapture: true,
   },
   prod: {
     stage: 'prod',
@@ -47,6 +56,9 @@ export const STAGE_CONFIGS: Record<string, StageConfig> = {
       readCapacity: 1,
       writeCapacity: 1,
     },
+    features: [],
+    guildCampaignCap: 5,
+    promptCapture: false,
   },
 };
 
@@ -0,0 +1,22 @@
+import { Construct } from 'constructs';
+import * as dynamodb from 'aws-cdk-lib/aws-dynamodb';
+import { RemovalPolicy } from 'aws-cdk-lib';
+export interface HostSelectionTableProps {
+    /** Deployment stage (dev/prod) */
+    stage: string;
+    /** Removal policy for the table */
+    removalPolicy: RemovalPolicy;
+}
+/**
+ * Creates a DynamoDB table for per-host seed selection history
+ *
+ * Schema:
+ * - Partition key: hostId (string)
+ *
+ * The birthing Lambda records each host's recent seed combinations and used
+ * terrain/threat categories here so consecutive campaigns avoid repeating them
+ */
+export declare class HostSelectionTable extends Construct {
+    readonly table: dynamodb.Table;
+    constructor(scope: Construct, id: string, props: HostSelectionTableProps);
+}
@@ -0,0 +1,46 @@
+import { Construct } from 'constructs';
+import * as dynamodb from 'aws-cdk-lib/aws-dynamodb';
+import { RemovalPolicy, Tags } from 'aws-cdk-lib';
+
+export interface HostSelectionTableProps {
+  /** Deployment stage (dev/prod) */
+  stage: string;
+  /** Removal policy for the table */
+  removalPolicy: RemovalPolicy;
+}
+
+/**
+ * Creates a DynamoDB table for per-host seed selection history
+ * 
+ * Schema:
+ * - Partition key: hostId (string)
+ * 
+ * The birthing Lambda records each host's recent seed combinations and used
+ * terrain/threat categories here so consecutive campaigns avoid repeating them
+ */
+export class HostSelectionTable extends Construct {
+  public readonly table: dynamodb.Table;
+
+  constructor(scope: Construct, id: string, props: HostSelectionTableProps) {
+    super(scope, id);
+
+    this.table = new dynamodb.Table(this, 'HostSelectionTable', {
+      tableName: `syrus-host-selection-${props.stage}`,
+      partitionKey: {
+        name: 'hostId',
+        type: dynamodb.AttributeType.STRING,
+      },
+      billingMode: dynamodb.BillingMode.PROVISIONED,
+      readCapacity: 5,
+      writeCapacity: 5,
+      removalPolicy: props.removalPolicy,
+      pointInTimeRecovery: false, // Disabled for cost control
+      deletionProtection: false,
+    });
+
+    // Add tags
+    Tags.of(this.table).add('App', 'Syrus');
+    Tags.of(this.table).add('Service', 'DiscordBot');
+    Tags.of(this.table).add('Stage', props.stage);
+  }
+}
@@ -0,0 +1,144 @@
+// Package capture writes model prompt/response pairs to the bucket named by SYRUS_CAPTURE_BUCKET,
+// building a dataset for offline evaluation and fine-tuning. Capture is opt-in: with the variable
+// unset, Write does nothing. Captured text is redacted of API keys and PII before it leaves the lambda.
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// bucketEnvVar names the environment variable holding the capture bucket name
+const bucketEnvVar = "SYRUS_CAPTURE_BUCKET"
+
+// Validation outcomes recorded with each exchange
+const (
+	OutcomeValid   = "valid"
+	OutcomeInvalid = "invalid"
+)
+
+// Record is a prompt/response pair written to the capture bucket
+type Record struct {
+	Kind              string `json:"kind"`
+	CampaignType      string `json:"campaignType"`
+	Model             string `json:"model"`
+	PromptVersion     string `json:"promptVersion,omitempty"`
+	ValidationOutcome string `json:"validationOutcome"`
+	ValidationError   string `json:"validationError,omitempty"`
+	CapturedAt        string `json:"capturedAt"`
+	SystemPrompt      string `json:"systemPrompt"`
+	Prompt            string `json:"prompt"`
+	Response          string `json:"response"`
+}
+
+var (
+	apiKeyPattern    = regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`)
+	emailPattern     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	snowflakePattern = regexp.MustCompile(`\b\d{17,20}\b`)
+)
+
+var (
+	clientOnce sync.Once
+	client     s3iface.S3API
+	clientErr  error
+
+	// now is overridden in tests
+	now = time.Now
+)
+
+// Enabled reports whether a capture bucket is configured
+func Enabled() bool {
+	return os.Getenv(bucketEnvVar) != ""
+}
+
+// Redact strips API keys and PII (emails, Discord user/channel IDs) from captured text
+func Redact(text string) string {
+	text = apiKeyPattern.ReplaceAllString(text, "[REDACTED_API_KEY]")
+	text = emailPattern.ReplaceAllString(text, "[REDACTED_EMAIL]")
+	text = snowflakePattern.ReplaceAllString(text, "[REDACTED_ID]")
+	return text
+}
+
+// Key builds the timestamped S3 key for a captured exchange.
+// Campaign IDs are deliberately left out of the key since older ones are Discord channel IDs.
+func Key(kind, campaignType, outcome string, capturedAt time.Time) string {
+	capturedAt = capturedAt.UTC()
+	return fmt.Sprintf("%s/%s/%s-%s-%s.json",
+		kind,
+		capturedAt.Format("2006/01/02"),
+		capturedAt.Format("20060102T150405.000000000Z"),
+		campaignType,
+		outcome,
+	)
+}
+
+// Outcome maps a validation error to the outcome recorded with an exchange
+func Outcome(validationErr error) string {
+	if validationErr != nil {
+		return OutcomeInvalid
+	}
+	return OutcomeValid
+}
+
+// Write redacts the record, stamps its capture time, and stores it in the capture bucket,
+// returning the key it was written to. It returns "" without writing when capture is disabled.
+func Write(record Record) (string, error) {
+	bucket := os.Getenv(bucketEnvVar)
+	if bucket == "" {
+		return "", nil
+	}
+
+	svc, err := resolve()
+	if err != nil {
+		return "", err
+	}
+
+	capturedAt := now().UTC()
+	record.CapturedAt = capturedAt.Format(time.RFC3339)
+	record.SystemPrompt = Redact(record.SystemPrompt)
+	record.Prompt = Redact(record.Prompt)
+	record.Response = Redact(record.Response)
+	record.ValidationError = Redact(record.ValidationError)
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal capture record: %w", err)
+	}
+
+	key := Key(record.Kind, record.CampaignType, record.ValidationOutcome, capturedAt)
+	if _, err := svc.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	}); err != nil {
+		return "", fmt.Errorf("failed to write capture %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// resolve returns an S3 client, creating it on first use
+func resolve() (s3iface.S3API, error) {
+	clientOnce.Do(func() {
+		if client != nil {
+			return
+		}
+		sess, err := session.NewSession()
+		if err != nil {
+			clientErr = fmt.Errorf("failed to create AWS session: %w", err)
+			return
+		}
+		client = s3.New(sess)
+	})
+	return client, clientErr
+}
@@ -0,0 +1,150 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3 records the objects put to it
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]byte
+}
+
+func (f *fakeS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[*input.Key] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func useFake(t *testing.T) *fakeS3 {
+	t.Helper()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	client = fake
+	clientErr = nil
+	clientOnce.Do(func() {})
+	t.Setenv(bucketEnvVar, "syrus-capture-test")
+	return fake
+}
+
+func TestKey(t *testing.T) {
+	capturedAt := time.Date(2025, 3, 7, 14, 5, 9, 123456789, time.UTC)
+
+	key := Key("blueprint", "short", OutcomeValid, capturedAt)
+	expected := "blueprint/2025/03/07/20250307T140509.123456789Z-short-valid.json"
+	if key != expected {
+		t.Errorf("Expected capture key %q, got %q", expected, key)
+	}
+
+	// Non-UTC times are normalized so keys sort consistently
+	local := capturedAt.In(time.FixedZone("UTC-5", -5*60*60))
+	if got := Key("blueprint", "short", OutcomeValid, local); got != expected {
+		t.Errorf("Expected non-UTC time to produce %q, got %q", expected, got)
+	}
+
+	invalidKey := Key("play", "epic", OutcomeInvalid, capturedAt)
+	if !strings.HasPrefix(invalidKey, "play/") || !strings.HasSuffix(invalidKey, "-epic-invalid.json") {
+		t.Errorf("Unexpected capture key for invalid play exchange: %s", invalidKey)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		forbidden  string
+		replacedBy string
+	}{
+		{"anthropic key", "key=sk-ant-REDACTED", "sk-ant-api03", "[REDACTED_API_KEY]"},
+		{"openai key", "Bearer sk-proj-ABCDEFGHIJKLMNOP1234", "sk-proj-ABCDEFGHIJKLMNOP1234", "[REDACTED_API_KEY]"},
+		{"email", "contact hero@example.com now", "hero@example.com", "[REDACTED_EMAIL]"},
+		{"discord user id", "\"userId\": \"1400583338720235591\"", "1400583338720235591", "[REDACTED_ID]"},
+		{"discord mention", "<@1400583338720235591> attacks", "1400583338720235591", "[REDACTED_ID]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := Redact(tt.input)
+			if strings.Contains(redacted, tt.forbidden) {
+				t.Errorf("Expected %q to be redacted, got %q", tt.forbidden, redacted)
+			}
+			if !strings.Contains(redacted, tt.replacedBy) {
+				t.Errorf("Expected redaction marker %q, got %q", tt.replacedBy, redacted)
+			}
+		})
+	}
+
+	// Ordinary numbers (beat counts, party size) must survive redaction
+	if got := Redact(`"partySize": 4, "acts": 3`); got != `"partySize": 4, "acts": 3` {
+		t.Errorf("Expected short numbers to be preserved, got %q", got)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	if got := Outcome(nil); got != OutcomeValid {
+		t.Errorf("Expected %q without an error, got %q", OutcomeValid, got)
+	}
+	if got := Outcome(errors.New("missing acts")); got != OutcomeInvalid {
+		t.Errorf("Expected %q with an error, got %q", OutcomeInvalid, got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	fake := useFake(t)
+	at := time.Date(2025, 3, 7, 14, 5, 9, 0, time.UTC)
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+
+	key, err := Write(Record{
+		Kind:              "play",
+		CampaignType:      "short",
+		Model:             "claude-haiku",
+		ValidationOutcome: OutcomeInvalid,
+		ValidationError:   "unexpected reply for <@1400583338720235591>",
+		SystemPrompt:      "Narrate for hero@example.com",
+		Prompt:            "<@1400583338720235591> opens the door",
+		Response:          "not json",
+	})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if key != "play/2025/03/07/20250307T140509.000000000Z-short-invalid.json" {
+		t.Errorf("Unexpected capture key %q", key)
+	}
+
+	var record Record
+	if err := json.Unmarshal(fake.objects[key], &record); err != nil {
+		t.Fatalf("Failed to unmarshal captured record: %v", err)
+	}
+	if record.CapturedAt != "2025-03-07T14:05:09Z" {
+		t.Errorf("Expected capture time stamped, got %q", record.CapturedAt)
+	}
+	for _, text := range []string{record.SystemPrompt, record.Prompt, record.ValidationError} {
+		if strings.Contains(text, "1400583338720235591") || strings.Contains(text, "hero@example.com") {
+			t.Errorf("Expected captured text redacted, got %q", text)
+		}
+	}
+}
+
+func TestWriteDisabled(t *testing.T) {
+	fake := useFake(t)
+	t.Setenv(bucketEnvVar, "")
+
+	key, err := Write(Record{Kind: "blueprint", Prompt: "prompt", Response: "response"})
+	if err != nil || key != "" {
+		t.Errorf("Expected a no-op without a capture bucket, got key %q, err %v", key, err)
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("Expected nothing written, got %d objects", len(fake.objects))
+	}
+}
@@ -0,0 +1,133 @@
+// Package commandopts parses the option tree of a Discord slash command interaction into its
+// subcommand and a flat map of option values.
+//
+// Discord nests options: a subcommand (or subcommand group) carries its own options list, and
+// value options carry a string, number, or boolean. Parsing fails on structural surprises
+// (non-object options, missing names, duplicates, a subcommand beside other options) rather
+// than silently treating the interaction as if an option were absent.
+package commandopts
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Discord application command option types that nest further options
+const (
+	optionTypeSubcommand      = 1
+	optionTypeSubcommandGroup = 2
+)
+
+// maxNesting is Discord's limit: a group holding a subcommand holding value options
+const maxNesting = 2
+
+// Parse extracts the subcommand and option values from interaction data (its "options" key).
+// Subcommands inside a group are joined with a space ("group sub"); a command invoked without
+// a subcommand returns "". Numbers are formatted without a trailing ".0", booleans as "true"/"false".
+func Parse(data map[string]interface{}) (string, map[string]string, error) {
+	raw, present := data["options"]
+	if !present || raw == nil {
+		return "", map[string]string{}, nil
+	}
+	options, ok := raw.([]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("options is %T, expected a list", raw)
+	}
+	return parseLevel(options, 0)
+}
+
+// ParseOptions parses an options list already lifted out of the interaction data,
+// as the webhook forwards it on the configuring queue
+func ParseOptions(options []map[string]interface{}) (string, map[string]string, error) {
+	list := make([]interface{}, len(options))
+	for i, option := range options {
+		list[i] = option
+	}
+	return parseLevel(list, 0)
+}
+
+func parseLevel(options []interface{}, depth int) (string, map[string]string, error) {
+	values := make(map[string]string, len(options))
+
+	for i, raw := range options {
+		option, ok := raw.(map[string]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("option %d is %T, expected an object", i, raw)
+		}
+		name, ok := option["name"].(string)
+		if !ok || name == "" {
+			return "", nil, fmt.Errorf("option %d has no name", i)
+		}
+
+		if isSubcommand(option) {
+			if len(options) != 1 {
+				return "", nil, fmt.Errorf("subcommand %q must be the only option at its level, found %d", name, len(options))
+			}
+			if depth >= maxNesting {
+				return "", nil, fmt.Errorf("subcommand %q is nested too deeply", name)
+			}
+			nested, err := nestedOptions(option)
+			if err != nil {
+				return "", nil, fmt.Errorf("subcommand %q: %w", name, err)
+			}
+			inner, innerValues, err := parseLevel(nested, depth+1)
+			if err != nil {
+				return "", nil, fmt.Errorf("subcommand %q: %w", name, err)
+			}
+			if inner != "" {
+				name = name + " " + inner
+			}
+			return name, innerValues, nil
+		}
+
+		if _, nested := option["options"]; nested {
+			return "", nil, fmt.Errorf("option %q has both a value and nested options", name)
+		}
+		if _, duplicate := values[name]; duplicate {
+			return "", nil, fmt.Errorf("option %q appears more than once", name)
+		}
+		value, err := formatValue(option["value"])
+		if err != nil {
+			return "", nil, fmt.Errorf("option %q: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	return "", values, nil
+}
+
+// isSubcommand identifies subcommands by type, or, when the type is omitted, by the absence of a value
+func isSubcommand(option map[string]interface{}) bool {
+	if optionType, ok := option["type"].(float64); ok {
+		return optionType == optionTypeSubcommand || optionType == optionTypeSubcommandGroup
+	}
+	_, hasValue := option["value"]
+	return !hasValue
+}
+
+func nestedOptions(option map[string]interface{}) ([]interface{}, error) {
+	raw, present := option["options"]
+	if !present || raw == nil {
+		return nil, nil
+	}
+	nested, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("options is %T, expected a list", raw)
+	}
+	return nested, nil
+}
+
+func formatValue(raw interface{}) (string, error) {
+	switch value := raw.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case nil:
+		return "", fmt.Errorf("missing value")
+	default:
+		return "", fmt.Errorf("unsupported value type %T", raw)
+	}
+}
@@ -0,0 +1,143 @@
+package commandopts
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decode(t testing.TB, raw string) map[string]interface{} {
+	t.Helper()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatalf("Invalid test payload %s: %v", raw, err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		subcommand  string
+		values      map[string]string
+		errContains string
+	}{
+		{
+			name:       "subcommand with typed options",
+			data:       `{"name":"campaign","options":[{"name":"start","type":1,"options":[{"name":"type","type":3,"value":"short"},{"name":"window","type":4,"value":30},{"name":"thread","type":5,"value":true}]}]}`,
+			subcommand: "start",
+			values:     map[string]string{"type": "short", "window": "30", "thread": "true"},
+		},
+		{
+			name:       "subcommand without options",
+			data:       `{"name":"syrus","options":[{"name":"reroll","type":1}]}`,
+			subcommand: "reroll",
+			values:     map[string]string{},
+		},
+		{
+			name:       "untyped subcommand is inferred from missing value",
+			data:       `{"options":[{"name":"end","options":[{"name":"confirm","value":true}]}]}`,
+			subcommand: "end",
+			values:     map[string]string{"confirm": "true"},
+		},
+		{
+			name:       "subcommand group",
+			data:       `{"options":[{"name":"admin","type":2,"options":[{"name":"cap","type":1,"options":[{"name":"limit","type":4,"value":5}]}]}]}`,
+			subcommand: "admin cap",
+			values:     map[string]string{"limit": "5"},
+		},
+		{
+			name:       "top-level value options without subcommand",
+			data:       `{"options":[{"name":"declare","value":"I attack the orc"},{"name":"debug","value":false}]}`,
+			subcommand: "",
+			values:     map[string]string{"declare": "I attack the orc", "debug": "false"},
+		},
+		{
+			name:   "fractional number",
+			data:   `{"options":[{"name":"temperature","type":10,"value":0.75}]}`,
+			values: map[string]string{"temperature": "0.75"},
+		},
+		{
+			name:   "no options",
+			data:   `{"name":"ping"}`,
+			values: map[string]string{},
+		},
+		{name: "options not a list", data: `{"options":{"name":"start"}}`, errContains: "expected a list"},
+		{name: "option not an object", data: `{"options":["start"]}`, errContains: "expected an object"},
+		{name: "option without name", data: `{"options":[{"type":1}]}`, errContains: "has no name"},
+		{name: "subcommand beside another option", data: `{"options":[{"name":"start","type":1},{"name":"debug","value":true}]}`, errContains: "only option"},
+		{name: "duplicate option", data: `{"options":[{"name":"start","type":1,"options":[{"name":"type","value":"short"},{"name":"type","value":"long"}]}]}`, errContains: "more than once"},
+		{name: "typed value option missing value", data: `{"options":[{"name":"start","type":1,"options":[{"name":"type","type":3}]}]}`, errContains: "missing value"},
+		{name: "value option with nested options", data: `{"options":[{"name":"type","value":"short","options":[]}]}`, errContains: "both a value and nested options"},
+		{name: "unsupported value", data: `{"options":[{"name":"lock","value":["map"]}]}`, errContains: "unsupported value type"},
+		{name: "nested options not a list", data: `{"options":[{"name":"start","type":1,"options":"type=short"}]}`, errContains: "expected a list"},
+		{name: "too deeply nested", data: `{"options":[{"name":"a","type":2,"options":[{"name":"b","type":2,"options":[{"name":"c","type":1}]}]}]}`, errContains: "nested too deeply"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subcommand, values, err := Parse(decode(t, tt.data))
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if subcommand != tt.subcommand {
+				t.Errorf("Expected subcommand %q, got %q", tt.subcommand, subcommand)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("Expected values %v, got %v", tt.values, values)
+			}
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	options := []map[string]interface{}{
+		{"name": "reroll", "options": []interface{}{map[string]interface{}{"name": "lock", "value": "map,objective"}}},
+	}
+
+	subcommand, values, err := ParseOptions(options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if subcommand != "reroll" || values["lock"] != "map,objective" {
+		t.Errorf("Expected reroll with lock option, got %q %v", subcommand, values)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		`{"options":[{"name":"start","type":1,"options":[{"name":"type","value":"short"}]}]}`,
+		`{"options":[{"name":"declare","value":"I attack"}]}`,
+		`{"options":[{"name":"a","type":2,"options":[{"name":"b","type":1}]}]}`,
+		`{"options":[null]}`,
+		`{"options":[{"name":1}]}`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		var data map[string]interface{}
+		if json.Unmarshal([]byte(raw), &data) != nil {
+			return
+		}
+		subcommand, values, err := Parse(data)
+		if err != nil {
+			return
+		}
+		if values == nil {
+			t.Fatalf("Expected non-nil values for %s", raw)
+		}
+		if strings.Count(subcommand, " ") >= maxNesting {
+			t.Fatalf("Subcommand %q nested beyond the limit for %s", subcommand, raw)
+		}
+	})
+}
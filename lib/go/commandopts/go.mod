@@ -0,0 +1,3 @@
+module loros/syrus-commandopts
+
+go 1.21
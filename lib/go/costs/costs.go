@@ -0,0 +1,208 @@
+// Package costs enforces a campaign's soft limits on paid model calls and records what each call
+// spent. Usage lives on the campaign item (costTracking) in the table named by SYRUS_CAMPAIGNS_TABLE,
+// and is incremented atomically so concurrent lambdas never lose a call.
+package costs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	models "loros/syrus-models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// tableEnvVar names the environment variable holding the campaigns table name
+const tableEnvVar = "SYRUS_CAMPAIGNS_TABLE"
+
+// Class groups models that share a soft limit and usage counter
+type Class string
+
+const (
+	ClassSonnet Class = "sonnet"
+	ClassHaiku  Class = "haiku"
+	ClassImage  Class = "image"
+)
+
+// Estimated cost of one call, in USD, for each class. These are averages for a typical
+// narration or blueprint exchange and a single standard 1024px image, not per-token billing.
+const (
+	SonnetCallCostUSD = 0.05
+	HaikuCallCostUSD  = 0.004
+	ImageCallCostUSD  = 0.04
+)
+
+// Estimated cost in USD of one DALL-E 3 image beyond the standard square ImageCallCostUSD
+const (
+	ImageWideCallCostUSD   = 0.08 // Standard quality, 1792px
+	ImageHDCallCostUSD     = 0.08 // HD quality, 1024px
+	ImageHDWideCallCostUSD = 0.12 // HD quality, 1792px
+)
+
+// ThinWeaveMessage is the in-character reply when a campaign has spent its budget for a call
+const ThinWeaveMessage = "*The weave grows thin.* This tale has drawn deeply on the loom's strength, and it must rest before more can be woven. Ask your host to seek the weaver's aid."
+
+var (
+	clientOnce sync.Once
+	client     dynamodbiface.DynamoDBAPI
+	clientErr  error
+)
+
+// ClassOf returns the cost class of a model; ok is false for models that are not billed per call
+func ClassOf(model models.Model) (Class, bool) {
+	switch model {
+	case models.ModelSonnet:
+		return ClassSonnet, true
+	case models.ModelHaiku:
+		return ClassHaiku, true
+	case models.ModelNanoBanana, models.ModelOpenAI:
+		return ClassImage, true
+	default:
+		return "", false
+	}
+}
+
+// CostUSD returns the estimated cost of one call in the class
+func CostUSD(class Class) float64 {
+	switch class {
+	case ClassSonnet:
+		return SonnetCallCostUSD
+	case ClassHaiku:
+		return HaikuCallCostUSD
+	case ClassImage:
+		return ImageCallCostUSD
+	default:
+		return 0
+	}
+}
+
+// ImageCostUSD returns the estimated cost of one DALL-E image with the given options; invalid options cost as the defaults
+func ImageCostUSD(options models.ImageOptions) float64 {
+	options = options.Normalized()
+	wide := options.Size != models.ImageSizeSquare
+	switch {
+	case options.Quality == models.ImageQualityHD && wide:
+		return ImageHDWideCallCostUSD
+	case options.Quality == models.ImageQualityHD:
+		return ImageHDCallCostUSD
+	case wide:
+		return ImageWideCallCostUSD
+	default:
+		return ImageCallCostUSD
+	}
+}
+
+// WithinLimit reports whether the campaign's soft limit allows another call in the class (zero limits are unbounded)
+func WithinLimit(tracking models.CostTracking, class Class) bool {
+	switch class {
+	case ClassSonnet:
+		return tracking.SoftLimits.SonnetCalls == 0 || tracking.Usage.SonnetCalls < tracking.SoftLimits.SonnetCalls
+	case ClassHaiku:
+		return tracking.SoftLimits.HaikuCalls == 0 || tracking.Usage.HaikuCalls < tracking.SoftLimits.HaikuCalls
+	case ClassImage:
+		return tracking.SoftLimits.ImageCalls == 0 || tracking.Usage.ImageCalls < tracking.SoftLimits.ImageCalls
+	default:
+		return true
+	}
+}
+
+// Choose returns the model to call in place of model: the model itself when within its limit,
+// Haiku when Sonnet's limit is spent but Haiku's is not, and ok=false when nothing affordable remains.
+func Choose(tracking models.CostTracking, model models.Model) (models.Model, bool) {
+	class, billed := ClassOf(model)
+	if !billed || WithinLimit(tracking, class) {
+		return model, true
+	}
+	if class == ClassSonnet && WithinLimit(tracking, ClassHaiku) {
+		return models.ModelHaiku, true
+	}
+	return "", false
+}
+
+// Record adds one call in the class to the campaign's usage and estimated cost
+func Record(campaignID string, class Class) error {
+	return RecordCost(campaignID, class, CostUSD(class))
+}
+
+// RecordCost adds one call in the class to the campaign's usage, estimating its cost at costUSD.
+// It is for calls whose cost depends on the request, such as DALL-E size and quality.
+func RecordCost(campaignID string, class Class, costUSD float64) error {
+	table, svc, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	input, err := buildRecordInput(table, campaignID, class, costUSD)
+	if err != nil {
+		return err
+	}
+	if _, err := svc.UpdateItem(input); err != nil {
+		return fmt.Errorf("failed to record %s usage: %w", class, err)
+	}
+	return nil
+}
+
+// buildRecordInput builds the atomic ADD that Record issues. ADD creates missing counters at zero,
+// and the condition keeps a record from creating a stub campaign item.
+func buildRecordInput(table, campaignID string, class Class, costUSD float64) (*dynamodb.UpdateItemInput, error) {
+	var counter string
+	switch class {
+	case ClassSonnet:
+		counter = "sonnetCalls"
+	case ClassHaiku:
+		counter = "haikuCalls"
+	case ClassImage:
+		counter = "imageCalls"
+	default:
+		return nil, fmt.Errorf("unknown cost class %q", class)
+	}
+
+	return &dynamodb.UpdateItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"campaignId": {S: aws.String(campaignID)},
+		},
+		UpdateExpression:    aws.String("ADD #costTracking.#usage.#counter :one, #costTracking.#estimatedCostUSD :cost"),
+		ConditionExpression: aws.String("attribute_exists(campaignId)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#costTracking":     aws.String("costTracking"),
+			"#usage":            aws.String("usage"),
+			"#counter":          aws.String(counter),
+			"#estimatedCostUSD": aws.String("estimatedCostUSD"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one":  {N: aws.String("1")},
+			":cost": {N: aws.String(strconv.FormatFloat(costUSD, 'f', -1, 64))},
+		},
+	}, nil
+}
+
+// resolve returns the campaigns table name and a DynamoDB client, creating the client on first use
+func resolve() (string, dynamodbiface.DynamoDBAPI, error) {
+	table := os.Getenv(tableEnvVar)
+	if table == "" {
+		return "", nil, fmt.Errorf("%s environment variable not set", tableEnvVar)
+	}
+
+	clientOnce.Do(func() {
+		if client != nil {
+			return
+		}
+		sess, err := session.NewSession()
+		if err != nil {
+			clientErr = fmt.Errorf("failed to create AWS session: %w", err)
+			return
+		}
+		client = dynamodb.New(sess)
+	})
+	if clientErr != nil {
+		return "", nil, clientErr
+	}
+
+	return table, client, nil
+}
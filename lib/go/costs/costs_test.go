@@ -0,0 +1,164 @@
+package costs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	models "loros/syrus-models"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo captures UpdateItem calls
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	updates []*dynamodb.UpdateItemInput
+	err     error
+}
+
+func (f *fakeDynamo) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.updates = append(f.updates, input)
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func useFake(t *testing.T) *fakeDynamo {
+	t.Helper()
+	fake := &fakeDynamo{}
+	client = fake
+	clientErr = nil
+	clientOnce.Do(func() {})
+	t.Setenv(tableEnvVar, "syrus-campaigns-test")
+	return fake
+}
+
+func tracking(sonnet, haiku, image int) models.CostTracking {
+	return models.CostTracking{
+		SoftLimits: models.SoftLimits{SonnetCalls: 10, HaikuCalls: 1000, ImageCalls: 10},
+		Usage:      models.Usage{SonnetCalls: sonnet, HaikuCalls: haiku, ImageCalls: image},
+	}
+}
+
+func TestWithinLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		tracking models.CostTracking
+		class    Class
+		expected bool
+	}{
+		{"sonnet under limit", tracking(9, 0, 0), ClassSonnet, true},
+		{"sonnet at limit", tracking(10, 0, 0), ClassSonnet, false},
+		{"haiku at limit", tracking(0, 1000, 0), ClassHaiku, false},
+		{"image over limit", tracking(0, 0, 11), ClassImage, false},
+		{"zero limit is unbounded", models.CostTracking{Usage: models.Usage{SonnetCalls: 500}}, ClassSonnet, true},
+		{"unknown class is unbounded", tracking(10, 1000, 10), Class("other"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinLimit(tt.tracking, tt.class); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestChoose(t *testing.T) {
+	tests := []struct {
+		name     string
+		tracking models.CostTracking
+		model    models.Model
+		expected models.Model
+		ok       bool
+	}{
+		{"sonnet within limit", tracking(0, 0, 0), models.ModelSonnet, models.ModelSonnet, true},
+		{"sonnet spent downgrades to haiku", tracking(10, 0, 0), models.ModelSonnet, models.ModelHaiku, true},
+		{"sonnet and haiku spent", tracking(10, 1000, 0), models.ModelSonnet, "", false},
+		{"haiku spent has no cheaper model", tracking(0, 1000, 0), models.ModelHaiku, "", false},
+		{"image spent", tracking(0, 0, 10), models.ModelOpenAI, "", false},
+		{"unbilled model passes", tracking(10, 1000, 10), models.Model("local"), models.Model("local"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Choose(tt.tracking, tt.model)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.expected, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	fake := useFake(t)
+
+	if err := Record("channel-1", ClassImage); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if len(fake.updates) != 1 {
+		t.Fatalf("Expected 1 update, got %d", len(fake.updates))
+	}
+	update := fake.updates[0]
+	if *update.TableName != "syrus-campaigns-test" || *update.Key["campaignId"].S != "channel-1" {
+		t.Errorf("Expected update of channel-1 in the campaigns table, got %s/%s", *update.TableName, *update.Key["campaignId"].S)
+	}
+	if !strings.HasPrefix(*update.UpdateExpression, "ADD ") {
+		t.Errorf("Expected an atomic ADD, got %s", *update.UpdateExpression)
+	}
+	if *update.ExpressionAttributeNames["#counter"] != "imageCalls" {
+		t.Errorf("Expected imageCalls counter, got %s", *update.ExpressionAttributeNames["#counter"])
+	}
+	if *update.ExpressionAttributeValues[":cost"].N != "0.04" {
+		t.Errorf("Expected cost 0.04, got %s", *update.ExpressionAttributeValues[":cost"].N)
+	}
+
+	if err := Record("channel-1", Class("other")); err == nil {
+		t.Error("Expected an error for an unknown class")
+	}
+
+	fake.err = errors.New("throttled")
+	if err := Record("channel-1", ClassHaiku); err == nil || !strings.Contains(err.Error(), "haiku") {
+		t.Errorf("Expected wrapped haiku error, got %v", err)
+	}
+}
+
+func TestImageCostUSD(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  models.ImageOptions
+		expected float64
+	}{
+		{"unset", models.ImageOptions{}, ImageCallCostUSD},
+		{"standard square", models.ImageOptions{Size: models.ImageSizeSquare, Quality: models.ImageQualityStandard}, ImageCallCostUSD},
+		{"standard wide", models.ImageOptions{Size: models.ImageSizeLandscape, Quality: models.ImageQualityStandard}, ImageWideCallCostUSD},
+		{"hd square", models.ImageOptions{Size: models.ImageSizeSquare, Quality: models.ImageQualityHD}, ImageHDCallCostUSD},
+		{"hd tall", models.ImageOptions{Size: models.ImageSizePortrait, Quality: models.ImageQualityHD}, ImageHDWideCallCostUSD},
+		{"invalid falls back", models.ImageOptions{Size: "512x512", Quality: "ultra"}, ImageCallCostUSD},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageCostUSD(tt.options); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRecordCost(t *testing.T) {
+	fake := useFake(t)
+
+	if err := RecordCost("channel-1", ClassImage, ImageHDWideCallCostUSD); err != nil {
+		t.Fatalf("RecordCost failed: %v", err)
+	}
+	if len(fake.updates) != 1 {
+		t.Fatalf("Expected 1 update, got %d", len(fake.updates))
+	}
+	if cost := *fake.updates[0].ExpressionAttributeValues[":cost"].N; cost != "0.12" {
+		t.Errorf("Expected cost 0.12, got %s", cost)
+	}
+}
@@ -0,0 +1,12 @@
+module loros/syrus-costs
+
+go 1.21
+
+replace loros/syrus-models => ../models
+
+require (
+	github.com/aws/aws-sdk-go v1.55.5
+	loros/syrus-models v0.0.0
+)
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
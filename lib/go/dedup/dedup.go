@@ -0,0 +1,225 @@
+// Package dedup records which SQS messages each lambda has already processed.
+// Records live in the table named by SYRUS_DEDUP_TABLE, keyed "<prefix>#<id>",
+// and expire via the table's expiresAt TTL attribute.
+//
+// Handlers Claim a message before processing it: the conditional write lets
+// exactly one concurrent delivery through without a separate read. A claim is
+// held for a short lease; Mark extends it once processing succeeds and Release
+// drops it on failure. An invocation that crashes or times out never releases
+// its claim, so each caller picks a lease that lapses before its queue's
+// visibility timeout redelivers the message. A longer lease would make the
+// redelivery look like a duplicate, and the message would be acked and lost.
+package dedup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DefaultTTL is how long a dedup record is kept when Mark is given a non-positive TTL
+const DefaultTTL = 24 * time.Hour
+
+// tableEnvVar names the environment variable holding the dedup table name
+const tableEnvVar = "SYRUS_DEDUP_TABLE"
+
+// valueAttribute holds the value kept by Store
+const valueAttribute = "value"
+
+var (
+	clientOnce sync.Once
+	client     dynamodbiface.DynamoDBAPI
+	clientErr  error
+
+	// now is overridden in tests
+	now = time.Now
+)
+
+// Key builds the dedup key for an id processed by the lambda identified by prefix
+func Key(prefix, id string) string {
+	return fmt.Sprintf("%s#%s", prefix, id)
+}
+
+// Check reports whether id has already been processed by the lambda identified by prefix
+func Check(prefix, id string) (bool, error) {
+	table, svc, err := resolve()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := svc.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"dedupKey": {S: aws.String(Key(prefix, id))},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check dedup table: %w", err)
+	}
+
+	return result.Item != nil, nil
+}
+
+// Mark records id as processed by the lambda identified by prefix for ttl (DefaultTTL if ttl <= 0)
+func Mark(prefix, id string, ttl time.Duration) error {
+	table, svc, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      buildItem(prefix, id, ttl, now()),
+	}); err != nil {
+		return fmt.Errorf("failed to write dedup record: %w", err)
+	}
+	return nil
+}
+
+// Store records id as processed by the lambda identified by prefix for ttl (DefaultTTL if ttl <= 0),
+// keeping value with the record so a redelivery can reuse the result of work it must not repeat
+func Store(prefix, id, value string, ttl time.Duration) error {
+	table, svc, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	item := buildItem(prefix, id, ttl, now())
+	item[valueAttribute] = &dynamodb.AttributeValue{S: aws.String(value)}
+	if _, err := svc.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("failed to write dedup record: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the value Stored for id by the lambda identified by prefix, reporting false when there is none
+func Lookup(prefix, id string) (string, bool, error) {
+	table, svc, err := resolve()
+	if err != nil {
+		return "", false, err
+	}
+
+	result, err := svc.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"dedupKey": {S: aws.String(Key(prefix, id))},
+		},
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read dedup table: %w", err)
+	}
+
+	value, ok := result.Item[valueAttribute]
+	if !ok || value.S == nil {
+		return "", false, nil
+	}
+	return *value.S, true, nil
+}
+
+// Claim atomically records id as in flight for the lambda identified by prefix.
+// It reports false when another delivery already holds an unexpired record, which
+// callers treat as already processed. The claim lasts lease, which must be positive
+// and at or below the caller's lambda timeout, and below its queue's visibility timeout.
+func Claim(prefix, id string, lease time.Duration) (bool, error) {
+	if lease <= 0 {
+		return false, fmt.Errorf("claim lease must be positive, got %s", lease)
+	}
+	table, svc, err := resolve()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := svc.PutItem(buildClaimInput(table, prefix, id, lease, now())); err != nil {
+		if IsConditionalCheckFailed(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim dedup record: %w", err)
+	}
+	return true, nil
+}
+
+// IsConditionalCheckFailed reports whether a DynamoDB write was rejected by its condition expression
+func IsConditionalCheckFailed(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+// Release drops the record for id so a redelivery of a failed message is processed again
+func Release(prefix, id string) error {
+	table, svc, err := resolve()
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"dedupKey": {S: aws.String(Key(prefix, id))},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to release dedup record: %w", err)
+	}
+	return nil
+}
+
+// buildClaimInput assembles the conditional write used by Claim. Records the TTL
+// sweeper has not yet removed are ignored once their expiresAt has passed.
+func buildClaimInput(table, prefix, id string, lease time.Duration, at time.Time) *dynamodb.PutItemInput {
+	return &dynamodb.PutItemInput{
+		TableName:           aws.String(table),
+		Item:                buildItem(prefix, id, lease, at),
+		ConditionExpression: aws.String("attribute_not_exists(dedupKey) OR expiresAt < :now"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now": {N: aws.String(strconv.FormatInt(at.Unix(), 10))},
+		},
+	}
+}
+
+// buildItem assembles the dedup record written by Mark and Claim
+func buildItem(prefix, id string, ttl time.Duration, at time.Time) map[string]*dynamodb.AttributeValue {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"dedupKey":    {S: aws.String(Key(prefix, id))},
+		"expiresAt":   {N: aws.String(strconv.FormatInt(at.Add(ttl).Unix(), 10))},
+		"processedAt": {S: aws.String(at.UTC().Format(time.RFC3339))},
+	}
+}
+
+// resolve returns the dedup table name and a DynamoDB client, creating the client on first use
+func resolve() (string, dynamodbiface.DynamoDBAPI, error) {
+	table := os.Getenv(tableEnvVar)
+	if table == "" {
+		return "", nil, fmt.Errorf("%s environment variable not set", tableEnvVar)
+	}
+
+	clientOnce.Do(func() {
+		if client != nil {
+			return
+		}
+		sess, err := session.NewSession()
+		if err != nil {
+			clientErr = fmt.Errorf("failed to create AWS session: %w", err)
+			return
+		}
+		client = dynamodb.New(sess)
+	})
+	if clientErr != nil {
+		return "", nil, clientErr
+	}
+
+	return table, client, nil
+}
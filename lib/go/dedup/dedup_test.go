@@ -0,0 +1,279 @@
+package dedup
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo stores items in memory keyed by dedupKey
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]map[string]*dynamodb.AttributeValue
+	err   error
+}
+
+func (f *fakeDynamo) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dynamodb.GetItemOutput{Item: f.items[*input.Key["dedupKey"].S]}, nil
+}
+
+func (f *fakeDynamo) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	key := *input.Item["dedupKey"].S
+	if input.ConditionExpression != nil {
+		if existing, ok := f.items[key]; ok {
+			expiresAt, _ := strconv.ParseInt(*existing["expiresAt"].N, 10, 64)
+			at, _ := strconv.ParseInt(*input.ExpressionAttributeValues[":now"].N, 10, 64)
+			if expiresAt >= at {
+				return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+			}
+		}
+	}
+	f.items[key] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	delete(f.items, *input.Key["dedupKey"].S)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func useFake(t *testing.T) *fakeDynamo {
+	t.Helper()
+	fake := &fakeDynamo{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	client = fake
+	clientErr = nil
+	clientOnce.Do(func() {})
+	t.Setenv(tableEnvVar, "syrus-dedup-test")
+	return fake
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		id       string
+		expected string
+	}{
+		{"birthing", "1234", "birthing#1234"},
+		{"blueprinting", "1234-thread", "blueprinting#1234-thread"},
+		{"imagegen", "1234-act1", "imagegen#1234-act1"},
+	}
+
+	for _, tt := range tests {
+		if got := Key(tt.prefix, tt.id); got != tt.expected {
+			t.Errorf("Key(%q, %q) = %q, expected %q", tt.prefix, tt.id, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildItemTTL(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		expected time.Time
+	}{
+		{"explicit ttl", time.Hour, at.Add(time.Hour)},
+		{"zero ttl uses default", 0, at.Add(DefaultTTL)},
+		{"negative ttl uses default", -time.Minute, at.Add(DefaultTTL)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := buildItem("play", "42", tt.ttl, at)
+			if got := *item["dedupKey"].S; got != "play#42" {
+				t.Errorf("Expected dedupKey play#42, got %s", got)
+			}
+			expiresAt, err := strconv.ParseInt(*item["expiresAt"].N, 10, 64)
+			if err != nil {
+				t.Fatalf("expiresAt is not numeric: %v", err)
+			}
+			if expiresAt != tt.expected.Unix() {
+				t.Errorf("Expected expiresAt %d, got %d", tt.expected.Unix(), expiresAt)
+			}
+			if got := *item["processedAt"].S; got != "2025-01-01T12:00:00Z" {
+				t.Errorf("Expected processedAt 2025-01-01T12:00:00Z, got %s", got)
+			}
+		})
+	}
+}
+
+func TestCheckAndMark(t *testing.T) {
+	useFake(t)
+
+	seen, err := Check("configuring", "abc")
+	if err != nil || seen {
+		t.Fatalf("Expected unseen before Mark, got seen=%v err=%v", seen, err)
+	}
+
+	if err := Mark("configuring", "abc", 0); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+
+	seen, err = Check("configuring", "abc")
+	if err != nil || !seen {
+		t.Errorf("Expected seen after Mark, got seen=%v err=%v", seen, err)
+	}
+
+	// Prefixes isolate lambdas processing the same interaction
+	seen, err = Check("birthing", "abc")
+	if err != nil || seen {
+		t.Errorf("Expected other prefix to be unseen, got seen=%v err=%v", seen, err)
+	}
+}
+
+func TestStoreAndLookup(t *testing.T) {
+	useFake(t)
+
+	if _, found, err := Lookup("messaging-thread", "abc"); err != nil || found {
+		t.Fatalf("Expected nothing stored before Store, got found=%v err=%v", found, err)
+	}
+
+	if err := Store("messaging-thread", "abc", "thread-1", 0); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	value, found, err := Lookup("messaging-thread", "abc")
+	if err != nil || !found || value != "thread-1" {
+		t.Errorf("Expected thread-1 after Store, got %q found=%v err=%v", value, found, err)
+	}
+	if seen, err := Check("messaging-thread", "abc"); err != nil || !seen {
+		t.Errorf("Expected a stored record to count as processed, got seen=%v err=%v", seen, err)
+	}
+
+	// A record written by Mark carries no value
+	if err := Mark("messaging-thread", "def", 0); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if _, found, err := Lookup("messaging-thread", "def"); err != nil || found {
+		t.Errorf("Expected no value for a marked record, got found=%v err=%v", found, err)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	fake := useFake(t)
+	fake.err = errors.New("throttled")
+
+	if _, err := Check("play", "1"); err == nil {
+		t.Error("Expected error when DynamoDB fails")
+	}
+	if err := Mark("play", "1", time.Hour); err == nil {
+		t.Error("Expected error when DynamoDB fails")
+	}
+	if err := Store("play", "1", "value", time.Hour); err == nil {
+		t.Error("Expected error when DynamoDB fails")
+	}
+	if _, _, err := Lookup("play", "1"); err == nil {
+		t.Error("Expected error when DynamoDB fails")
+	}
+
+	t.Setenv(tableEnvVar, "")
+	if _, err := Check("play", "1"); err == nil {
+		t.Error("Expected error when table is not configured")
+	}
+}
+
+func TestClaim(t *testing.T) {
+	useFake(t)
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+	lease := 5 * time.Minute
+
+	claimed, err := Claim("play", "42", lease)
+	if err != nil || !claimed {
+		t.Fatalf("Expected first claim to succeed, got claimed=%v err=%v", claimed, err)
+	}
+
+	claimed, err = Claim("play", "42", lease)
+	if err != nil || claimed {
+		t.Errorf("Expected duplicate delivery to be refused, got claimed=%v err=%v", claimed, err)
+	}
+
+	// Mark extends the claim past the lease once processing succeeds
+	if err := Mark("play", "42", 0); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	at = at.Add(lease + time.Second)
+	claimed, err = Claim("play", "42", lease)
+	if err != nil || claimed {
+		t.Errorf("Expected marked record to be refused, got claimed=%v err=%v", claimed, err)
+	}
+
+	if err := Release("play", "42"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	claimed, err = Claim("play", "42", lease)
+	if err != nil || !claimed {
+		t.Errorf("Expected released record to be claimable, got claimed=%v err=%v", claimed, err)
+	}
+}
+
+// TestClaimExpiredLeaseReclaimed covers an invocation that crashes without releasing its claim:
+// the queue redelivers once its visibility timeout passes, and by then the lease has lapsed
+func TestClaimExpiredLeaseReclaimed(t *testing.T) {
+	useFake(t)
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+	lease, visibilityTimeout := 5*time.Minute, 6*time.Minute
+
+	if claimed, err := Claim("play", "42", lease); err != nil || !claimed {
+		t.Fatalf("Expected first claim to succeed, got claimed=%v err=%v", claimed, err)
+	}
+
+	at = at.Add(lease)
+	if claimed, err := Claim("play", "42", lease); err != nil || claimed {
+		t.Errorf("Expected the claim held until its lease lapses, got claimed=%v err=%v", claimed, err)
+	}
+
+	at = at.Add(visibilityTimeout - lease)
+	if claimed, err := Claim("play", "42", lease); err != nil || !claimed {
+		t.Errorf("Expected the redelivery to reclaim the expired lease, got claimed=%v err=%v", claimed, err)
+	}
+}
+
+func TestIsConditionalCheckFailed(t *testing.T) {
+	conditionFailed := fmt.Errorf("failed to write: %w", awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil))
+	throttled := fmt.Errorf("failed to write: %w", awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil))
+
+	if !IsConditionalCheckFailed(conditionFailed) {
+		t.Error("Expected wrapped conditional check failure to be detected")
+	}
+	if IsConditionalCheckFailed(throttled) {
+		t.Error("Expected throughput error not to be a conditional check failure")
+	}
+	if IsConditionalCheckFailed(nil) {
+		t.Error("Expected nil not to be a conditional check failure")
+	}
+}
+
+func TestClaimErrors(t *testing.T) {
+	fake := useFake(t)
+	fake.err = errors.New("throttled")
+
+	if _, err := Claim("play", "1", time.Minute); err == nil {
+		t.Error("Expected error when DynamoDB fails")
+	}
+	if _, err := Claim("play", "1", 0); err == nil {
+		t.Error("Expected error for a non-positive lease")
+	}
+	if err := Release("play", "1"); err == nil {
+		t.Error("Expected error when DynamoDB fails")
+	}
+}
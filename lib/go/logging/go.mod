@@ -0,0 +1,3 @@
+module loros/syrus-logging
+
+go 1.21
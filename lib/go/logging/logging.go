@@ -0,0 +1,109 @@
+// Package logging writes structured JSON log lines that CloudWatch Logs Insights can query by field.
+//
+// Init installs a JSON handler as the process-wide default, so existing log.Printf calls become
+// JSON lines tagged with the lambda's name and a level inferred from their "Warning:"/"Failed"
+// prefixes. For returns a logger that also carries an interaction's correlation fields, so one
+// gameplay turn can be followed across the webhook, play, and messaging lambdas by interactionId.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Field names shared by every lambda, so queries line up across functions
+const (
+	FieldLambda        = "lambda"
+	FieldInteractionID = "interactionId"
+	FieldCampaignID    = "campaignId"
+)
+
+// Output receives log lines; swapped out in tests
+var Output io.Writer = os.Stdout
+
+// Init makes a JSON logger tagged with the lambda's name the default for both slog and the log package
+func Init(lambdaName string) {
+	slog.SetDefault(New(Output, lambdaName))
+}
+
+// New returns a JSON logger writing to w, tagged with the lambda's name
+func New(w io.Writer, lambdaName string) *slog.Logger {
+	return slog.New(levelHandler{slog.NewJSONHandler(w, nil)}).With(FieldLambda, lambdaName)
+}
+
+// Logger is a logger carrying correlation fields. Printf keeps log.Printf's call shape so handlers
+// can move call sites over without rewording them.
+type Logger struct {
+	*slog.Logger
+}
+
+// For returns the default logger with the given correlation fields; empty fields are left out
+func For(interactionID, campaignID string) Logger {
+	var attrs []any
+	if interactionID != "" {
+		attrs = append(attrs, FieldInteractionID, interactionID)
+	}
+	if campaignID != "" {
+		attrs = append(attrs, FieldCampaignID, campaignID)
+	}
+	return Logger{slog.Default().With(attrs...)}
+}
+
+// Printf logs a formatted message; its level is inferred from the message like log.Printf lines
+func (l Logger) Printf(format string, args ...any) {
+	l.Info(fmt.Sprintf(format, args...))
+}
+
+type contextKey struct{}
+
+// WithContext returns a context carrying l, for handlers that already thread a context through
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored by WithContext, or an uncorrelated default logger
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return For("", "")
+}
+
+// levelHandler raises info lines to the level their wording implies. The log package bridge
+// writes everything at info, and the handlers' messages already say "Warning:" or "Failed ...".
+type levelHandler struct {
+	slog.Handler
+}
+
+func (h levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelInfo {
+		r.Level = inferLevel(r.Message)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{h.Handler.WithGroup(name)}
+}
+
+// inferLevel maps the handlers' conventional message prefixes onto a level
+func inferLevel(msg string) slog.Level {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.HasPrefix(lower, "warning"):
+		return slog.LevelWarn
+	case strings.HasPrefix(lower, "error"), strings.HasPrefix(lower, "failed"):
+		return slog.LevelError
+	case strings.HasPrefix(lower, "debug"):
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+// captureDefault installs a logger writing to a buffer as the default for the test
+func captureDefault(t *testing.T, lambdaName string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := slog.Default()
+	flags := log.Flags()
+	slog.SetDefault(New(&buf, lambdaName))
+	t.Cleanup(func() {
+		slog.SetDefault(original)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+// lines decodes each JSON line written to buf
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, raw := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var line map[string]interface{}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			t.Fatalf("Log line is not JSON: %s (%v)", raw, err)
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestForAddsCorrelationFields(t *testing.T) {
+	buf := captureDefault(t, "play")
+
+	For("int-1", "campaign-1").Printf("Narrating batch of %d declarations", 2)
+
+	got := lines(t, buf)
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 line, got %d", len(got))
+	}
+	expected := map[string]interface{}{
+		"lambda":        "play",
+		"interactionId": "int-1",
+		"campaignId":    "campaign-1",
+		"level":         "INFO",
+		"msg":           "Narrating batch of 2 declarations",
+	}
+	for field, value := range expected {
+		if got[0][field] != value {
+			t.Errorf("Expected %s=%v, got %v", field, value, got[0][field])
+		}
+	}
+}
+
+func TestForOmitsEmptyFields(t *testing.T) {
+	buf := captureDefault(t, "messaging")
+
+	For("", "").Printf("Processing message")
+
+	line := lines(t, buf)[0]
+	if _, ok := line["interactionId"]; ok {
+		t.Errorf("Expected no interactionId, got %v", line["interactionId"])
+	}
+	if _, ok := line["campaignId"]; ok {
+		t.Errorf("Expected no campaignId, got %v", line["campaignId"])
+	}
+}
+
+func TestLogPackageIsBridged(t *testing.T) {
+	buf := captureDefault(t, "configuring")
+
+	log.Printf("Warning: failed to write to dedup table: %s", "throttled")
+	log.Printf("Failed to save campaign: %s", "boom")
+	log.Printf("Successfully saved campaign %s", "campaign-1")
+
+	got := lines(t, buf)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(got))
+	}
+	for i, level := range []string{"WARN", "ERROR", "INFO"} {
+		if got[i]["level"] != level {
+			t.Errorf("Line %d: expected level %s, got %v", i, level, got[i]["level"])
+		}
+		if got[i]["lambda"] != "configuring" {
+			t.Errorf("Line %d: expected lambda configuring, got %v", i, got[i]["lambda"])
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	buf := captureDefault(t, "messaging")
+
+	ctx := WithContext(context.Background(), For("int-2", ""))
+	FromContext(ctx).Printf("Successfully sent message")
+	FromContext(context.Background()).Printf("Processing message")
+
+	got := lines(t, buf)
+	if got[0]["interactionId"] != "int-2" {
+		t.Errorf("Expected context logger to carry int-2, got %v", got[0]["interactionId"])
+	}
+	if _, ok := got[1]["interactionId"]; ok {
+		t.Errorf("Expected bare context to log without interactionId, got %v", got[1]["interactionId"])
+	}
+}
+
+func TestInferLevel(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected slog.Level
+	}{
+		{"Warning: invalid SYRUS_GUILD_CAMPAIGN_CAP", slog.LevelWarn},
+		{"WARNING: Campaign stores channel", slog.LevelWarn},
+		{"Failed to send error message", slog.LevelError},
+		{"Error parsing body", slog.LevelError},
+		{"DEBUG: Campaign retrieved", slog.LevelDebug},
+		{"Processing message", slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := inferLevel(tt.msg); got != tt.expected {
+			t.Errorf("inferLevel(%q) = %v, expected %v", tt.msg, got, tt.expected)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // CampaignStatus represents the possible states of a campaign
 type CampaignStatus string
@@ -16,6 +21,25 @@ const (
 	CampaignStatusEnded CampaignStatus = "ended"
 )
 
+// campaignStatusTransitions is the allowed lifecycle graph: each status maps to the statuses it may move to
+var campaignStatusTransitions = map[CampaignStatus][]CampaignStatus{
+	CampaignStatusConfiguring: {CampaignStatusActive, CampaignStatusEnded},
+	CampaignStatusActive:      {CampaignStatusPlaying, CampaignStatusEnded},
+	CampaignStatusPlaying:     {CampaignStatusEnded},
+	CampaignStatusEnded:       {},
+}
+
+// CanTransition reports whether a campaign may move from one status to another.
+// Self-transitions are not transitions and return false.
+func CanTransition(from, to CampaignStatus) bool {
+	for _, allowed := range campaignStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 // CampaignType represents the scope and duration of a campaign
 type CampaignType string
 
@@ -28,6 +52,19 @@ const (
 	CampaignTypeEpic CampaignType = "epic"
 )
 
+// CampaignTypes lists every campaign type birthing has a length profile for
+var CampaignTypes = []CampaignType{CampaignTypeShort, CampaignTypeLong, CampaignTypeEpic}
+
+// IsValid reports whether t is one of the known campaign types
+func (t CampaignType) IsValid() bool {
+	for _, known := range CampaignTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 // DecisionModel represents who controls the decision-making in the campaign
 type DecisionModel string
 
@@ -40,16 +77,61 @@ const (
 	DecisionModelFlexible DecisionModel = "flexible"
 )
 
+// CampaignMode represents who the campaign is narrated for
+type CampaignMode string
+
+const (
+	// CampaignModeGroup narrates for a party gathered in a channel
+	CampaignModeGroup CampaignMode = "group"
+	// CampaignModeSolo narrates for a single player, addressed directly; the player makes every decision
+	CampaignModeSolo CampaignMode = "solo"
+)
+
+// IsValid reports whether m is a known campaign mode
+func (m CampaignMode) IsValid() bool {
+	return m == CampaignModeGroup || m == CampaignModeSolo
+}
+
+// DefaultCampaignMode returns the mode a campaign starts in when none is chosen. A WhatsApp
+// conversation is a direct message, so it is played solo.
+func DefaultCampaignMode(platform string) CampaignMode {
+	if platform == PlatformWhatsApp {
+		return CampaignModeSolo
+	}
+	return CampaignModeGroup
+}
+
+// PlayStyle represents how the party takes turns
+type PlayStyle string
+
+const (
+	// PlayStyleSynchronous narrates each declaration as it arrives (real-time, everyone present)
+	PlayStyleSynchronous PlayStyle = "synchronous"
+	// PlayStyleAsynchronous batches declarations over a window before narrating (play-by-post)
+	PlayStyleAsynchronous PlayStyle = "asynchronous"
+)
+
+// DefaultAsyncWindowMinutes is how long an asynchronous batch stays open when the campaign doesn't set one
+const DefaultAsyncWindowMinutes = 240
+
+// DefaultNudgeIntervalHours is how long an asynchronous campaign may sit idle before players are nudged
+const DefaultNudgeIntervalHours = 24
+
 // Campaign represents the complete campaign structure
 type Campaign struct {
 	CampaignID    string         `json:"campaignId" dynamodbav:"campaignId"`
 	CampaignType  CampaignType   `json:"campaignType" dynamodbav:"campaignType"`
 	DecisionModel DecisionModel  `json:"decisionModel" dynamodbav:"decisionModel"`
+	PlayStyle     PlayStyle      `json:"playStyle,omitempty" dynamodbav:"playStyle,omitempty"`                   // Empty means synchronous
+	AsyncWindow   int            `json:"asyncWindowMinutes,omitempty" dynamodbav:"asyncWindowMinutes,omitempty"` // Batch window in minutes for asynchronous play
+	NudgeInterval int            `json:"nudgeIntervalHours,omitempty" dynamodbav:"nudgeIntervalHours,omitempty"` // Idle hours before an asynchronous party is nudged
 	Status        CampaignStatus `json:"status" dynamodbav:"status"`
 	Lifecycle     Lifecycle      `json:"lifecycle" dynamodbav:"lifecycle"`
 	CreatedAt     time.Time      `json:"createdAt" dynamodbav:"createdAt"`
 	LastUpdatedAt time.Time      `json:"lastUpdatedAt" dynamodbav:"lastUpdatedAt"`
 	HostID        string         `json:"hostId" dynamodbav:"hostId"`
+	GuildID       string         `json:"guildId,omitempty" dynamodbav:"guildId,omitempty"`     // Top-level copy of Meta.GuildID for the ByGuild index
+	ChannelID     string         `json:"channelId,omitempty" dynamodbav:"channelId,omitempty"` // Top-level copy of Meta.ChannelID for the ByChannel index
 	Source        string         `json:"source" dynamodbav:"source"`
 	Meta          CampaignMeta   `json:"meta" dynamodbav:"meta"`
 	Party         Party          `json:"party" dynamodbav:"party"`
@@ -58,6 +140,75 @@ type Campaign struct {
 	Memory        Memory         `json:"memory" dynamodbav:"memory"`
 	CostTracking  CostTracking   `json:"costTracking" dynamodbav:"costTracking"`
 	ModelPolicy   ModelPolicy    `json:"modelPolicy" dynamodbav:"modelPolicy"`
+	SeedRerolls   int            `json:"seedRerolls,omitempty" dynamodbav:"seedRerolls,omitempty"` // Seed rerolls that re-ran blueprinting while configuring
+}
+
+// IsSolo reports whether the campaign is narrated for a single player
+func (c *Campaign) IsSolo() bool {
+	return c.Meta.Mode == CampaignModeSolo
+}
+
+// MessagingPlatform returns the platform the campaign's messages are delivered on, treating unset as Discord
+func (c *Campaign) MessagingPlatform() string {
+	if c.Source == PlatformWhatsApp {
+		return PlatformWhatsApp
+	}
+	return PlatformDiscord
+}
+
+// EffectivePlayStyle returns the campaign's play style, treating unset as synchronous
+func (c *Campaign) EffectivePlayStyle() PlayStyle {
+	if c.PlayStyle == PlayStyleAsynchronous {
+		return PlayStyleAsynchronous
+	}
+	return PlayStyleSynchronous
+}
+
+// AsyncWindowDuration returns how long an asynchronous batch stays open before it is narrated
+func (c *Campaign) AsyncWindowDuration() time.Duration {
+	if c.AsyncWindow <= 0 {
+		return DefaultAsyncWindowMinutes * time.Minute
+	}
+	return time.Duration(c.AsyncWindow) * time.Minute
+}
+
+// NudgeIntervalDuration returns how long an asynchronous campaign may sit idle between nudges
+func (c *Campaign) NudgeIntervalDuration() time.Duration {
+	if c.NudgeInterval <= 0 {
+		return DefaultNudgeIntervalHours * time.Hour
+	}
+	return time.Duration(c.NudgeInterval) * time.Hour
+}
+
+// ReplyChannelID returns the channel the campaign posts to. Campaigns created before IDs were
+// generated were keyed by their channel and may not record it separately.
+func (c *Campaign) ReplyChannelID() string {
+	if c.Meta.ChannelID != "" {
+		return c.Meta.ChannelID
+	}
+	return c.CampaignID
+}
+
+// NewCampaignID returns a random (version 4) UUID for a new campaign
+func NewCampaignID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate campaign ID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// ActiveWithin reports whether a player declared in the campaign within window of now
+func (c *Campaign) ActiveWithin(window time.Duration, now time.Time) bool {
+	last := c.Runtime.LastDeclarationAt
+	return last != nil && now.Sub(*last) <= window
+}
+
+// IsArchived reports whether the campaign has been retired to the archive
+func (c *Campaign) IsArchived() bool {
+	return c.Lifecycle.ArchivedAt != nil
 }
 
 // Lifecycle represents campaign lifecycle state
@@ -66,15 +217,18 @@ type Lifecycle struct {
 	EndedAt    *time.Time `json:"endedAt,omitempty" dynamodbav:"endedAt,omitempty"`
 	EndedState *string    `json:"endedState,omitempty" dynamodbav:"endedState,omitempty"`
 	ArchivedAt *time.Time `json:"archivedAt,omitempty" dynamodbav:"archivedAt,omitempty"`
+	ArchiveKey string     `json:"archiveKey,omitempty" dynamodbav:"archiveKey,omitempty"` // S3 key of the full campaign once archived
 }
 
 // CampaignMeta contains campaign metadata
 type CampaignMeta struct {
-	Mode          string  `json:"mode" dynamodbav:"mode"`
-	GuildID       *string `json:"guildId" dynamodbav:"guildId"`
-	ChannelID     string  `json:"channelId" dynamodbav:"channelId"`
-	EngineVersion string  `json:"engineVersion" dynamodbav:"engineVersion"`
-	Narrator      string  `json:"narrator" dynamodbav:"narrator"`
+	Mode            CampaignMode `json:"mode" dynamodbav:"mode"`
+	GuildID         *string      `json:"guildId" dynamodbav:"guildId"`
+	ChannelID       string       `json:"channelId" dynamodbav:"channelId"`                                 // Thread ID for thread-scoped campaigns; a WhatsApp number for WhatsApp campaigns
+	ParentChannelID string       `json:"parentChannelId,omitempty" dynamodbav:"parentChannelId,omitempty"` // Channel hosting the thread
+	EngineVersion   string       `json:"engineVersion" dynamodbav:"engineVersion"`
+	Narrator        string       `json:"narrator" dynamodbav:"narrator"`
+	PromptVersion   string       `json:"promptVersion,omitempty" dynamodbav:"promptVersion,omitempty"` // Blueprint prompt version: pins one when set, records the one used once blueprinted
 }
 
 // Party represents the party structure
@@ -94,25 +248,36 @@ type PartyMember struct {
 
 // Boons represents available boons
 type Boons struct {
-	Available []interface{} `json:"available" dynamodbav:"available"`
+	Available []BoonOption `json:"available" dynamodbav:"available"` // Boons awarded from the blueprint's BoonPlan
+}
+
+// Has reports whether the party already holds the boon with the given name
+func (b Boons) Has(name string) bool {
+	for _, boon := range b.Available {
+		if strings.EqualFold(boon.Name, name) {
+			return true
+		}
+	}
+	return false
 }
 
 // Blueprint represents the campaign blueprint
 type Blueprint struct {
-	Title             string                   `json:"title" dynamodbav:"title"`
-	Premise           string                   `json:"premise" dynamodbav:"premise"`
-	ThematicPillars   []string                 `json:"thematicPillars" dynamodbav:"thematicPillars"`
-	BeatQualification BeatQualification        `json:"beatQualification" dynamodbav:"beatQualification"`
-	IngredientBinding IngredientBinding        `json:"ingredientBinding" dynamodbav:"ingredientBinding"`
-	Acts              []Act                    `json:"acts" dynamodbav:"acts"`
-	MajorForces       map[string]MajorForce    `json:"majorForces" dynamodbav:"majorForces"`
-	NPCs              map[string]NPC           `json:"npcs" dynamodbav:"npcs"`
-	BoonPlan          []BoonPlanEntry          `json:"boonPlan" dynamodbav:"boonPlan"`
-	FailurePaths      []FailurePath            `json:"failurePaths" dynamodbav:"failurePaths"`
-	EndStates         EndStates                `json:"endStates" dynamodbav:"endStates"`
-	MemoryDirectives  MemoryDirectives         `json:"memoryDirectives" dynamodbav:"memoryDirectives"`
-	ImagePlan         ImagePlan                `json:"imagePlan" dynamodbav:"imagePlan"`
-	CombatConstraints CombatConstraints        `json:"combatConstraints" dynamodbav:"combatConstraints"`
+	Title             string                `json:"title" dynamodbav:"title"`
+	Premise           string                `json:"premise" dynamodbav:"premise"`
+	Introduction      string                `json:"introduction,omitempty" dynamodbav:"introduction,omitempty"` // The opening narration, kept so it can be re-sent
+	ThematicPillars   []string              `json:"thematicPillars" dynamodbav:"thematicPillars"`
+	BeatQualification BeatQualification     `json:"beatQualification" dynamodbav:"beatQualification"`
+	IngredientBinding IngredientBinding     `json:"ingredientBinding" dynamodbav:"ingredientBinding"`
+	Acts              []Act                 `json:"acts" dynamodbav:"acts"`
+	MajorForces       map[string]MajorForce `json:"majorForces" dynamodbav:"majorForces"`
+	NPCs              map[string]NPC        `json:"npcs" dynamodbav:"npcs"`
+	BoonPlan          []BoonPlanEntry       `json:"boonPlan" dynamodbav:"boonPlan"`
+	FailurePaths      []FailurePath         `json:"failurePaths" dynamodbav:"failurePaths"`
+	EndStates         EndStates             `json:"endStates" dynamodbav:"endStates"`
+	MemoryDirectives  MemoryDirectives      `json:"memoryDirectives" dynamodbav:"memoryDirectives"`
+	ImagePlan         ImagePlan             `json:"imagePlan" dynamodbav:"imagePlan"`
+	CombatConstraints CombatConstraints     `json:"combatConstraints" dynamodbav:"combatConstraints"`
 }
 
 // CombatConstraints defines combat guidance for narrative purposes
@@ -179,6 +344,15 @@ type Completion struct {
 	Options   []string `json:"options,omitempty" dynamodbav:"options,omitempty"`
 }
 
+// CompletionTypeChoice marks an act that ends when the party picks one of its completion options
+const CompletionTypeChoice = "choice"
+
+// IsChoice reports whether the act ends on a choice between options. Older blueprints name the
+// type "decision"; both are accepted.
+func (c Completion) IsChoice() bool {
+	return (c.Type == CompletionTypeChoice || c.Type == "decision") && len(c.Options) > 0
+}
+
 // Escalation represents act escalation rules
 type Escalation struct {
 	OnDelay   string   `json:"onDelay" dynamodbav:"onDelay"`
@@ -279,25 +453,58 @@ type ImagePlan struct {
 
 // RuntimeState represents the runtime state of the campaign
 type RuntimeState struct {
-	CurrentAct         int       `json:"currentAct" dynamodbav:"currentAct"`
-	CurrentBeat        int       `json:"currentBeat" dynamodbav:"currentBeat"`
-	TurnState          TurnState `json:"turnState" dynamodbav:"turnState"`
-	ActiveFailurePaths []string  `json:"activeFailurePaths" dynamodbav:"activeFailurePaths"`
-	Pressure           Pressure  `json:"pressure" dynamodbav:"pressure"`
+	CurrentAct         int        `json:"currentAct" dynamodbav:"currentAct"`
+	CurrentBeat        int        `json:"currentBeat" dynamodbav:"currentBeat"`
+	TurnState          TurnState  `json:"turnState" dynamodbav:"turnState"`
+	ActiveFailurePaths []string   `json:"activeFailurePaths" dynamodbav:"activeFailurePaths"`
+	Pressure           Pressure   `json:"pressure" dynamodbav:"pressure"`
+	LastNudgedAt       *time.Time `json:"lastNudgedAt,omitempty" dynamodbav:"lastNudgedAt,omitempty"`           // Last idle nudge for asynchronous play
+	LastDeclarationAt  *time.Time `json:"lastDeclarationAt,omitempty" dynamodbav:"lastDeclarationAt,omitempty"` // Heartbeat: last accepted player declaration
 }
 
 // TurnState represents the current turn state
 type TurnState struct {
-	Mode           string          `json:"mode" dynamodbav:"mode"`
-	ActiveDecision *ActiveDecision `json:"activeDecision" dynamodbav:"activeDecision"`
+	Mode                string               `json:"mode" dynamodbav:"mode"`
+	ActiveDecision      *ActiveDecision      `json:"activeDecision" dynamodbav:"activeDecision"`
+	PendingDeclarations []PendingDeclaration `json:"pendingDeclarations,omitempty" dynamodbav:"pendingDeclarations,omitempty"` // Asynchronous batch awaiting narration
+	BatchOpenedAt       *time.Time           `json:"batchOpenedAt,omitempty" dynamodbav:"batchOpenedAt,omitempty"`
+	LastNarration       *NarrationRecord     `json:"lastNarration,omitempty" dynamodbav:"lastNarration,omitempty"` // Most recent narration, kept so the host can reroll it
+}
+
+// NarrationRecord is the most recent narration posted to the channel.
+// A reroll replaces its message in place, so the story state it produced is left untouched.
+type NarrationRecord struct {
+	Declarations     []PendingDeclaration `json:"declarations" dynamodbav:"declarations"`
+	Narration        string               `json:"narration" dynamodbav:"narration"`
+	InteractionToken string               `json:"interactionToken" dynamodbav:"interactionToken"`               // Token whose original response holds the narration
+	InteractionID    string               `json:"interactionId,omitempty" dynamodbav:"interactionId,omitempty"` // Interaction that produced the narration; a redelivery re-sends it
+	Model            Model                `json:"model" dynamodbav:"model"`
+	Temperature      float64              `json:"temperature" dynamodbav:"temperature"`
+	NarratedAt       time.Time            `json:"narratedAt" dynamodbav:"narratedAt"`
+	Rerolls          int                  `json:"rerolls" dynamodbav:"rerolls"`
+}
+
+// PendingDeclaration is a declaration held in an asynchronous batch until the batch is narrated
+type PendingDeclaration struct {
+	UserID      string             `json:"userId" dynamodbav:"userId"`
+	Declaration string             `json:"declaration" dynamodbav:"declaration"`
+	DeclaredAt  time.Time          `json:"declaredAt" dynamodbav:"declaredAt"`
+	Intent      *DeclarationIntent `json:"intent,omitempty" dynamodbav:"intent,omitempty"` // set when intent parsing read the declaration
+}
+
+// DeclarationIntent is how intent parsing read a declaration: what kind of move it is, and who or what it targets
+type DeclarationIntent struct {
+	Category string   `json:"category" dynamodbav:"category"`
+	Targets  []string `json:"targets,omitempty" dynamodbav:"targets,omitempty"`
 }
 
 // ActiveDecision represents an active decision awaiting response
 type ActiveDecision struct {
-	Prompt    string    `json:"prompt" dynamodbav:"prompt"`
-	Type      string    `json:"type" dynamodbav:"type"`
-	Options   []string  `json:"options" dynamodbav:"options"`
-	ExpiresAt time.Time `json:"expiresAt" dynamodbav:"expiresAt"`
+	Prompt    string         `json:"prompt" dynamodbav:"prompt"`
+	Type      string         `json:"type" dynamodbav:"type"`
+	Options   []string       `json:"options" dynamodbav:"options"`
+	ExpiresAt time.Time      `json:"expiresAt" dynamodbav:"expiresAt"`
+	Votes     map[string]int `json:"votes,omitempty" dynamodbav:"votes,omitempty"` // User ID -> index into Options
 }
 
 // Pressure represents campaign pressure/urgency
@@ -370,9 +577,16 @@ const (
 
 // ModelPolicy represents model selection policy
 type ModelPolicy struct {
-	IntentParsing Model `json:"intentParsing" dynamodbav:"intentParsing"`
-	Narration     Model `json:"narration" dynamodbav:"narration"`
-	Cinematics    Model `json:"cinematics" dynamodbav:"cinematics"`
-	Blueprint     Model `json:"blueprint" dynamodbav:"blueprint"`
-	ImageGen      Model `json:"imageGen" dynamodbav:"imageGen"`
+	IntentParsing Model  `json:"intentParsing" dynamodbav:"intentParsing"`
+	Narration     Model  `json:"narration" dynamodbav:"narration"`
+	Cinematics    Model  `json:"cinematics" dynamodbav:"cinematics"`
+	Blueprint     Model  `json:"blueprint" dynamodbav:"blueprint"`
+	ImageGen      Model  `json:"imageGen" dynamodbav:"imageGen"`
+	ImageSize     string `json:"imageSize,omitempty" dynamodbav:"imageSize,omitempty"`       // DALL-E size; empty or unsupported means ImageSizeSquare
+	ImageQuality  string `json:"imageQuality,omitempty" dynamodbav:"imageQuality,omitempty"` // DALL-E quality; empty or unsupported means ImageQualityStandard
+}
+
+// ImageOptions returns the image size and quality the campaign requests, with invalid values replaced by defaults
+func (p ModelPolicy) ImageOptions() ImageOptions {
+	return ImageOptions{Size: p.ImageSize, Quality: p.ImageQuality}.Normalized()
 }
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	statuses := []CampaignStatus{
+		CampaignStatusConfiguring,
+		CampaignStatusActive,
+		CampaignStatusPlaying,
+		CampaignStatusEnded,
+	}
+
+	legal := map[CampaignStatus]map[CampaignStatus]bool{
+		CampaignStatusConfiguring: {CampaignStatusActive: true, CampaignStatusEnded: true},
+		CampaignStatusActive:      {CampaignStatusPlaying: true, CampaignStatusEnded: true},
+		CampaignStatusPlaying:     {CampaignStatusEnded: true},
+		CampaignStatusEnded:       {},
+	}
+
+	// Exhaustively check every pair so any new edge must be added deliberately
+	for _, from := range statuses {
+		for _, to := range statuses {
+			expected := legal[from][to]
+			if got := CanTransition(from, to); got != expected {
+				t.Errorf("CanTransition(%s, %s) = %v, expected %v", from, to, got, expected)
+			}
+		}
+	}
+}
+
+func TestCanTransitionUnknownStatus(t *testing.T) {
+	if CanTransition(CampaignStatus("paused"), CampaignStatusActive) {
+		t.Error("Expected transition from unknown status to be rejected")
+	}
+	if CanTransition(CampaignStatusActive, CampaignStatus("archived")) {
+		t.Error("Expected transition to unknown status to be rejected")
+	}
+	if CanTransition(CampaignStatus(""), CampaignStatusConfiguring) {
+		t.Error("Expected transition from empty status to be rejected")
+	}
+}
+
+func TestCampaignPlayStyleDefaults(t *testing.T) {
+	var campaign Campaign
+	if campaign.EffectivePlayStyle() != PlayStyleSynchronous {
+		t.Errorf("Expected unset play style to be synchronous, got %s", campaign.EffectivePlayStyle())
+	}
+	if campaign.AsyncWindowDuration() != DefaultAsyncWindowMinutes*time.Minute {
+		t.Errorf("Expected default async window, got %s", campaign.AsyncWindowDuration())
+	}
+
+	campaign.PlayStyle = PlayStyleAsynchronous
+	campaign.AsyncWindow = 30
+	if campaign.EffectivePlayStyle() != PlayStyleAsynchronous {
+		t.Errorf("Expected asynchronous play style, got %s", campaign.EffectivePlayStyle())
+	}
+	if campaign.AsyncWindowDuration() != 30*time.Minute {
+		t.Errorf("Expected 30m async window, got %s", campaign.AsyncWindowDuration())
+	}
+}
+
+func TestCampaignActiveWithin(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute)
+
+	var campaign Campaign
+	if campaign.ActiveWithin(time.Hour, now) {
+		t.Error("Expected a campaign with no declarations to be inactive")
+	}
+
+	campaign.Runtime.LastDeclarationAt = &recent
+	if !campaign.ActiveWithin(15*time.Minute, now) {
+		t.Error("Expected a declaration 10 minutes ago to count within 15 minutes")
+	}
+	if campaign.ActiveWithin(5*time.Minute, now) {
+		t.Error("Expected a declaration 10 minutes ago not to count within 5 minutes")
+	}
+}
+
+func TestCompletionIsChoice(t *testing.T) {
+	tests := []struct {
+		completion Completion
+		expected   bool
+	}{
+		{Completion{Type: CompletionTypeChoice, Options: []string{"Secure", "Lost"}}, true},
+		{Completion{Type: "decision", Options: []string{"Secure", "Lost"}}, true},
+		{Completion{Type: CompletionTypeChoice}, false},
+		{Completion{Type: "trigger", Options: []string{"Secure"}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.completion.IsChoice(); got != tt.expected {
+			t.Errorf("IsChoice() for %+v = %v, expected %v", tt.completion, got, tt.expected)
+		}
+	}
+}
+
+func TestCampaignTypeIsValid(t *testing.T) {
+	for _, campaignType := range CampaignTypes {
+		if !campaignType.IsValid() {
+			t.Errorf("IsValid() for %q = false, expected true", campaignType)
+		}
+	}
+	for _, campaignType := range []CampaignType{"", "saga", "Short"} {
+		if campaignType.IsValid() {
+			t.Errorf("IsValid() for %q = true, expected false", campaignType)
+		}
+	}
+}
+
+func TestCampaignMode(t *testing.T) {
+	if DefaultCampaignMode(PlatformWhatsApp) != CampaignModeSolo {
+		t.Errorf("Expected WhatsApp campaigns to default to solo")
+	}
+	for _, platform := range []string{"", PlatformDiscord} {
+		if DefaultCampaignMode(platform) != CampaignModeGroup {
+			t.Errorf("Expected platform %q to default to group", platform)
+		}
+	}
+	for _, mode := range []CampaignMode{"", "duo", "Solo"} {
+		if mode.IsValid() {
+			t.Errorf("IsValid() for %q = true, expected false", mode)
+		}
+	}
+
+	campaign := &Campaign{Source: PlatformDiscord, Meta: CampaignMeta{Mode: CampaignModeGroup}}
+	if campaign.IsSolo() || campaign.MessagingPlatform() != PlatformDiscord {
+		t.Errorf("Expected a group Discord campaign, got solo=%v platform=%s", campaign.IsSolo(), campaign.MessagingPlatform())
+	}
+	campaign = &Campaign{Source: PlatformWhatsApp, Meta: CampaignMeta{Mode: CampaignModeSolo}}
+	if !campaign.IsSolo() || campaign.MessagingPlatform() != PlatformWhatsApp {
+		t.Errorf("Expected a solo WhatsApp campaign, got solo=%v platform=%s", campaign.IsSolo(), campaign.MessagingPlatform())
+	}
+}
+
+func TestNewCampaignID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id, err := NewCampaignID()
+		if err != nil {
+			t.Fatalf("NewCampaignID() returned error: %v", err)
+		}
+		if !uuidPattern.MatchString(id) {
+			t.Errorf("NewCampaignID() = %q, expected a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Errorf("NewCampaignID() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestCampaignReplyChannelID(t *testing.T) {
+	campaign := Campaign{CampaignID: "3f0c9a52-1b7e-4c1d-9a8e-5d2f6b7c8e9a", Meta: CampaignMeta{ChannelID: "chan-1"}}
+	if got := campaign.ReplyChannelID(); got != "chan-1" {
+		t.Errorf("ReplyChannelID() = %q, expected chan-1", got)
+	}
+
+	legacy := Campaign{CampaignID: "chan-2"}
+	if got := legacy.ReplyChannelID(); got != "chan-2" {
+		t.Errorf("ReplyChannelID() for a channel-keyed campaign = %q, expected chan-2", got)
+	}
+}
+
+func TestBoonsHas(t *testing.T) {
+	boons := Boons{Available: []BoonOption{{Name: "Thread Rewound"}}}
+	if !boons.Has("thread rewound") {
+		t.Error("Has() should match a held boon regardless of case")
+	}
+	if boons.Has("Hand of Fate") {
+		t.Error("Has() should not match a boon the party does not hold")
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import "strings"
+
+// DefaultDebugUserID is the developer allowed to use debug features when no debug users are configured
+const DefaultDebugUserID = "1400583338720235591"
+
+// DebugUsers is the set of user IDs allowed to use debug features
+type DebugUsers map[string]bool
+
+// ParseDebugUsers parses a comma-separated list of user IDs (e.g. the SYRUS_DEBUG_USERS environment variable).
+// IDs are trimmed and empty entries ignored; an empty list allows DefaultDebugUserID alone.
+func ParseDebugUsers(raw string) DebugUsers {
+	users := DebugUsers{}
+	for _, id := range strings.Split(raw, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			users[id] = true
+		}
+	}
+	if len(users) == 0 {
+		users[DefaultDebugUserID] = true
+	}
+	return users
+}
+
+// Allows reports whether a user may use debug features
+func (d DebugUsers) Allows(userID string) bool {
+	return userID != "" && d[userID]
+}
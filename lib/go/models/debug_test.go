@@ -0,0 +1,21 @@
+package models
+
+import "testing"
+
+func TestParseDebugUsers(t *testing.T) {
+	users := ParseDebugUsers(" user-1 ,user-2,, ")
+	if len(users) != 2 || !users.Allows("user-1") || !users.Allows("user-2") {
+		t.Errorf("Unexpected debug users: %v", users)
+	}
+	if users.Allows(DefaultDebugUserID) {
+		t.Error("Expected the default debug user to be denied once users are configured")
+	}
+
+	defaults := ParseDebugUsers("")
+	if len(defaults) != 1 || !defaults.Allows(DefaultDebugUserID) {
+		t.Errorf("Expected default debug user when unset, got %v", defaults)
+	}
+	if defaults.Allows("player-1") || defaults.Allows("") {
+		t.Error("Expected other users to be denied when unset")
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "strings"
+
+// FeatureGroupVoting gates routing group-decision declarations into the voting flow
+const FeatureGroupVoting = "group_voting"
+
+// FeatureIntentParsing gates reading each declaration with the intent parsing model before it is narrated
+const FeatureIntentParsing = "intent_parsing"
+
+// FeatureAll enables every feature flag (used by dev stages)
+const FeatureAll = "*"
+
+// Features is the set of feature flags enabled for a deployment
+type Features map[string]bool
+
+// ParseFeatures parses a comma-separated flag list (e.g. the SYRUS_FEATURES environment variable).
+// Flags are trimmed and lowercased; empty entries are ignored.
+func ParseFeatures(raw string) Features {
+	features := Features{}
+	for _, flag := range strings.Split(raw, ",") {
+		flag = strings.ToLower(strings.TrimSpace(flag))
+		if flag != "" {
+			features[flag] = true
+		}
+	}
+	return features
+}
+
+// IsEnabled reports whether a flag is enabled, either explicitly or via the "*" wildcard
+func (f Features) IsEnabled(flag string) bool {
+	return f[FeatureAll] || f[strings.ToLower(flag)]
+}
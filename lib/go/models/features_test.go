@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestParseFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{"empty", "", []string{}},
+		{"single flag", "group_voting", []string{"group_voting"}},
+		{"multiple flags", "group_voting,combat,images", []string{"group_voting", "combat", "images"}},
+		{"whitespace and case", " Group_Voting , COMBAT ", []string{"group_voting", "combat"}},
+		{"empty entries", "group_voting,,", []string{"group_voting"}},
+		{"wildcard", "*", []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			features := ParseFeatures(tt.raw)
+			if len(features) != len(tt.expected) {
+				t.Errorf("Expected %d flags, got %d: %v", len(tt.expected), len(features), features)
+			}
+			for _, flag := range tt.expected {
+				if !features[flag] {
+					t.Errorf("Expected flag %q to be parsed, got %v", flag, features)
+				}
+			}
+		})
+	}
+}
+
+func TestFeaturesIsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		flag     string
+		expected bool
+	}{
+		{"unset disables everything", "", FeatureGroupVoting, false},
+		{"listed flag is enabled", "group_voting", FeatureGroupVoting, true},
+		{"unlisted flag is disabled", "combat", FeatureGroupVoting, false},
+		{"lookup is case-insensitive", "group_voting", "GROUP_VOTING", true},
+		{"wildcard enables everything", "*", "anything", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseFeatures(tt.raw).IsEnabled(tt.flag); got != tt.expected {
+				t.Errorf("ParseFeatures(%q).IsEnabled(%q) = %v, expected %v", tt.raw, tt.flag, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Image providers recorded on generated images
+const (
+	ImageProviderOpenAI = "openai" // OpenAI images API (DALL-E)
+	ImageProviderGoogle = "google" // Gemini image generation (Nano Banana)
+)
+
+// S3 user-metadata keys stored on generated images (sent as x-amz-meta-<key>)
+const (
+	ImageMetaProvider   = "provider"
+	ImageMetaModel      = "model"
+	ImageMetaSize       = "size"
+	ImageMetaPromptHash = "prompt-sha256"
+	ImageMetaCreatedAt  = "created-at"
+)
+
+// DALL-E 3 image sizes and qualities a campaign's ModelPolicy may request
+const (
+	ImageSizeSquare    = "1024x1024"
+	ImageSizeLandscape = "1792x1024"
+	ImageSizePortrait  = "1024x1792"
+
+	ImageQualityStandard = "standard"
+	ImageQualityHD       = "hd"
+)
+
+// ImageOptions is the size and quality requested from the image provider
+type ImageOptions struct {
+	Size    string `json:"size,omitempty"`
+	Quality string `json:"quality,omitempty"`
+}
+
+// DefaultImageOptions are cheap square images, used when a campaign sets nothing valid
+var DefaultImageOptions = ImageOptions{Size: ImageSizeSquare, Quality: ImageQualityStandard}
+
+// Normalized returns the options with unset or unsupported values replaced by the defaults
+func (o ImageOptions) Normalized() ImageOptions {
+	normalized := o
+	switch o.Size {
+	case ImageSizeSquare, ImageSizeLandscape, ImageSizePortrait:
+	default:
+		normalized.Size = DefaultImageOptions.Size
+	}
+	switch o.Quality {
+	case ImageQualityStandard, ImageQualityHD:
+	default:
+		normalized.Quality = DefaultImageOptions.Quality
+	}
+	return normalized
+}
+
+// ImageResult is a generated image plus the provenance stored alongside it, regardless of provider
+type ImageResult struct {
+	Data        []byte    `json:"-"`
+	ContentType string    `json:"contentType"`
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	Size        string    `json:"size"`       // Requested dimensions, e.g. "1024x1024"
+	PromptHash  string    `json:"promptHash"` // SHA-256 of the prompt; prompts themselves can exceed metadata limits
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// NewImageResult wraps image bytes from a provider, sniffing the content type from the data
+func NewImageResult(data []byte, provider, model, size, prompt string, createdAt time.Time) ImageResult {
+	return ImageResult{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		Provider:    provider,
+		Model:       model,
+		Size:        size,
+		PromptHash:  HashPrompt(prompt),
+		CreatedAt:   createdAt.UTC(),
+	}
+}
+
+// HashPrompt returns the hex SHA-256 of an image prompt
+func HashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// Metadata returns the S3 user metadata describing the image
+func (r ImageResult) Metadata() map[string]string {
+	return map[string]string{
+		ImageMetaProvider:   r.Provider,
+		ImageMetaModel:      r.Model,
+		ImageMetaSize:       r.Size,
+		ImageMetaPromptHash: r.PromptHash,
+		ImageMetaCreatedAt:  r.CreatedAt.Format(time.RFC3339),
+	}
+}
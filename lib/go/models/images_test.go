@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewImageResult(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+
+	result := NewImageResult(png, ImageProviderOpenAI, "dall-e-3", "1024x1024", "A misty harbor at dawn", createdAt)
+
+	if result.ContentType != "image/png" {
+		t.Errorf("Expected content type image/png, got %s", result.ContentType)
+	}
+	if result.PromptHash != HashPrompt("A misty harbor at dawn") || len(result.PromptHash) != 64 {
+		t.Errorf("Expected hex SHA-256 prompt hash, got %q", result.PromptHash)
+	}
+	if HashPrompt("A misty harbor at dusk") == result.PromptHash {
+		t.Error("Expected different prompts to hash differently")
+	}
+
+	metadata := result.Metadata()
+	expected := map[string]string{
+		ImageMetaProvider:   "openai",
+		ImageMetaModel:      "dall-e-3",
+		ImageMetaSize:       "1024x1024",
+		ImageMetaPromptHash: result.PromptHash,
+		ImageMetaCreatedAt:  "2025-06-01T17:00:00Z",
+	}
+	for key, value := range expected {
+		if metadata[key] != value {
+			t.Errorf("Expected metadata %s=%q, got %q", key, value, metadata[key])
+		}
+	}
+}
+
+func TestModelPolicyImageOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ModelPolicy
+		expected ImageOptions
+	}{
+		{"unset uses defaults", ModelPolicy{}, DefaultImageOptions},
+		{"hd landscape kept", ModelPolicy{ImageSize: ImageSizeLandscape, ImageQuality: ImageQualityHD}, ImageOptions{Size: ImageSizeLandscape, Quality: ImageQualityHD}},
+		{"unsupported size falls back", ModelPolicy{ImageSize: "512x512", ImageQuality: ImageQualityHD}, ImageOptions{Size: ImageSizeSquare, Quality: ImageQualityHD}},
+		{"unsupported quality falls back", ModelPolicy{ImageSize: ImageSizePortrait, ImageQuality: "HD"}, ImageOptions{Size: ImageSizePortrait, Quality: ImageQualityStandard}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ImageOptions(); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
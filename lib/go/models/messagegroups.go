@@ -0,0 +1,37 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// MessageOrdering says whether a messaging queue message must be delivered in order with the rest of
+// its channel. The messaging queue is FIFO, so everything sharing a group waits behind a message that
+// is stuck retrying (e.g. a large image upload).
+//
+// Ordered: narration, campaign introductions and their images, milestone images, nudges, the seeded
+// notice, configuring responses, and follow-ups that must land after the response they follow.
+// Unordered: standalone replies to a single interaction (ping, campaign info) and failure notices.
+type MessageOrdering int
+
+const (
+	// MessageOrdered keeps the message in its channel's group
+	MessageOrdered MessageOrdering = iota
+	// MessageUnordered lets the message skip past whatever the channel's group is waiting on
+	MessageUnordered
+)
+
+// UnorderedGroupSpread is how many groups a channel's unordered messages are spread across
+const UnorderedGroupSpread = 4
+
+// MessageGroupID returns the FIFO group for a messaging queue message. Ordered messages use the
+// channel ID. Unordered messages get one of UnorderedGroupSpread groups beside it, picked from the
+// deduplication ID so a resent message lands in the same group and is still deduplicated.
+func MessageGroupID(channelID, deduplicationID string, ordering MessageOrdering) string {
+	if ordering == MessageOrdered {
+		return channelID
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(deduplicationID))
+	return fmt.Sprintf("%s-unordered-%d", channelID, hash.Sum32()%UnorderedGroupSpread)
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMessageGroupID(t *testing.T) {
+	if got := MessageGroupID("chan-1", "int-1-play", MessageOrdered); got != "chan-1" {
+		t.Errorf("Expected ordered message to use the channel group, got %q", got)
+	}
+
+	first := MessageGroupID("chan-1", "int-1-webhook", MessageUnordered)
+	if first == "chan-1" || !strings.HasPrefix(first, "chan-1-unordered-") {
+		t.Errorf("Expected unordered message to leave the channel group, got %q", first)
+	}
+	if again := MessageGroupID("chan-1", "int-1-webhook", MessageUnordered); again != first {
+		t.Errorf("Expected a resent message to keep its group, got %q then %q", first, again)
+	}
+
+	groups := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		groups[MessageGroupID("chan-1", fmt.Sprintf("int-%d-webhook", i), MessageUnordered)] = true
+	}
+	if len(groups) < 2 || len(groups) > UnorderedGroupSpread {
+		t.Errorf("Expected unordered messages spread over 2-%d groups, got %d", UnorderedGroupSpread, len(groups))
+	}
+}
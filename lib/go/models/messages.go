@@ -2,12 +2,16 @@ package models
 
 // ConfiguringMessage represents a message sent to the configuring queue
 type ConfiguringMessage struct {
-	ChannelID        string                   `json:"channelId"`
+	ChannelID        string                   `json:"channelId"`                 // Thread ID for thread-scoped campaigns
+	ParentChannelID  string                   `json:"parentChannelId,omitempty"` // Set when the interaction came from a thread
 	HostID           string                   `json:"hostId"`
+	GuildID          string                   `json:"guildId,omitempty"`    // Empty for DMs
+	GuildAdmin       bool                     `json:"guildAdmin,omitempty"` // Invoker can manage the guild (set by the webhook from member permissions)
 	InteractionID    string                   `json:"interactionId"`
 	InteractionToken string                   `json:"interactionToken"`
 	CampaignType     CampaignType             `json:"campaignType,omitempty"` // Deprecated - use Options
 	Options          []map[string]interface{} `json:"options"`
+	Platform         string                   `json:"platform,omitempty"` // PlatformDiscord (default) or PlatformWhatsApp
 }
 
 // MessagingQueueMessage represents a message sent to the messaging queue
@@ -19,6 +23,22 @@ type MessagingQueueMessage struct {
 	InteractionToken string                   `json:"interactionToken,omitempty"`
 	Flags            int                      `json:"flags,omitempty"` // Discord message flags (e.g., 64 for ephemeral)
 	Attachments      []Attachment             `json:"attachments,omitempty"`
+	CreateThread     *ThreadRequest           `json:"createThread,omitempty"`  // Create a campaign thread before replaying configuration
+	Followup         bool                     `json:"followup,omitempty"`      // Post a new follow-up instead of editing the original interaction response
+	Platform         string                   `json:"platform,omitempty"`      // PlatformDiscord (default) or PlatformWhatsApp
+	InteractionID    string                   `json:"interactionId,omitempty"` // Correlates delivery logs with the interaction that caused the message
+}
+
+// Platforms the messaging lambda can deliver to; ChannelID is a Discord channel or a WhatsApp number
+const (
+	PlatformDiscord  = "discord"
+	PlatformWhatsApp = "whatsapp"
+)
+
+// ThreadRequest asks the messaging lambda to create a Discord thread and replay a configuring message into it
+type ThreadRequest struct {
+	Name   string             `json:"name"`
+	Replay ConfiguringMessage `json:"replay"` // channelId/parentChannelId are filled in by messaging
 }
 
 // Attachment represents a file attachment to send to Discord
@@ -30,15 +50,65 @@ type Attachment struct {
 
 // BirthingMessage represents a message sent to the birthing queue
 type BirthingMessage struct {
-	CampaignID    string `json:"campaignId"`
-	InteractionID string `json:"interactionId"`
-}
+	CampaignID    string         `json:"campaignId"`
+	ChannelID     string         `json:"channelId,omitempty"` // Where replies are posted
+	InteractionID string         `json:"interactionId"`
+	Preview       bool           `json:"preview,omitempty"`       // Post seeds for approval instead of blueprinting immediately
+	PreviewAction PreviewAction  `json:"previewAction,omitempty"` // Follow-up on a pending preview
+	Locked        []SeedCategory `json:"locked,omitempty"`        // Categories a reroll keeps from the pending seeds
+	ModelOverride Model          `json:"modelOverride,omitempty"` // Debug only - passed on to blueprinting, which honors it for debug users
+	RequestedBy   string         `json:"requestedBy,omitempty"`   // User ID that requested the override
+}
+
+// ReplyChannelID returns the channel birthing replies to. Messages queued before campaign IDs were
+// generated carry only the campaign ID, which was the channel.
+func (m BirthingMessage) ReplyChannelID() string {
+	if m.ChannelID != "" {
+		return m.ChannelID
+	}
+	return m.CampaignID
+}
+
+// SeedCategory names a group of seeds that can be locked across rerolls
+type SeedCategory string
+
+const (
+	SeedCategoryObjective   SeedCategory = "objective"
+	SeedCategoryTwists      SeedCategory = "twists"
+	SeedCategoryAntagonists SeedCategory = "antagonists"
+	SeedCategorySetPieces   SeedCategory = "setpieces"
+	SeedCategoryConstraints SeedCategory = "constraints"
+	SeedCategoryLocation    SeedCategory = "location"
+	SeedCategoryMap         SeedCategory = "map"      // Map and featured areas
+	SeedCategoryVariance    SeedCategory = "variance" // All variance injectors
+)
+
+// IsValid reports whether c is a known seed category
+func (c SeedCategory) IsValid() bool {
+	switch c {
+	case SeedCategoryObjective, SeedCategoryTwists, SeedCategoryAntagonists, SeedCategorySetPieces,
+		SeedCategoryConstraints, SeedCategoryLocation, SeedCategoryMap, SeedCategoryVariance:
+		return true
+	}
+	return false
+}
+
+// PreviewAction is a host's response to a pending seeds preview
+type PreviewAction string
+
+const (
+	PreviewActionConfirm PreviewAction = "confirm"
+	PreviewActionReroll  PreviewAction = "reroll"
+)
 
 // BlueprintMessage represents generated campaign seeds for blueprinting
 type BlueprintMessage struct {
 	CampaignID    string        `json:"campaignId"`
 	InteractionID string        `json:"interactionId"`
 	Seeds         CampaignSeeds `json:"seeds"`
+	ModelOverride Model         `json:"modelOverride,omitempty"` // Debug only - honored for debug users
+	RequestedBy   string        `json:"requestedBy,omitempty"`   // User ID that requested the override
+	Reroll        int           `json:"reroll,omitempty"`        // Seed reroll this blueprint answers; zero for the first weaving
 }
 
 // ImageGenMessage represents a message sent to the image generation queue
@@ -47,7 +117,14 @@ type ImageGenMessage struct {
 	InteractionID string `json:"interactionId"`
 	ImageID       string `json:"imageId"`
 	Prompt        string `json:"prompt"`
-	Model         string `json:"model"`
+	Model         string `json:"model"`             // The campaign's ModelPolicy.ImageGen; older messages carry an OpenAI model name
+	Size          string `json:"size,omitempty"`    // The campaign's ModelPolicy.ImageSize; empty means the default
+	Quality       string `json:"quality,omitempty"` // The campaign's ModelPolicy.ImageQuality; empty means the default
+
+	// Delivery - set when the image should be posted to a channel once generated.
+	// Blueprint pre-generation leaves ChannelID empty and only caches the image.
+	ChannelID       string `json:"channelId,omitempty"`
+	DeliveryTrigger string `json:"deliveryTrigger,omitempty"` // The sendWhen/imageTrigger that released the image
 }
 
 // CampaignSeeds contains the randomly selected blueprint elements
@@ -72,6 +149,9 @@ type CampaignSeeds struct {
 	EnvironmentalOddity  string            `json:"environmentalOddity,omitempty"`
 	ExcludedMotifs       []string          `json:"excludedMotifs"`
 	ExpectationViolation *ExpectationBreak `json:"expectationViolation,omitempty"`
+
+	// RandomSeed is the RNG seed the selection was drawn from, kept so a roll can be reproduced
+	RandomSeed int64 `json:"randomSeed,omitempty"`
 }
 
 // MapSeed represents a selected map
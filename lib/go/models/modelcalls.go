@@ -0,0 +1,61 @@
+package models
+
+// CallType names a kind of text model call. Each matches the ModelPolicy field that picks its model.
+type CallType string
+
+const (
+	// CallBlueprint generates a campaign blueprint
+	CallBlueprint CallType = "blueprint"
+	// CallIntentParsing reads a player's declaration before it is narrated
+	CallIntentParsing CallType = "intentParsing"
+	// CallNarration narrates the party's declarations
+	CallNarration CallType = "narration"
+	// CallCinematics narrates set-piece moments such as act transitions and the finale
+	CallCinematics CallType = "cinematics"
+)
+
+// CallSettings tunes the sampling temperature and output budget of a model call
+type CallSettings struct {
+	Temperature float64
+	MaxTokens   int
+}
+
+// Default call settings. Blueprints run cool so the model keeps to the schema, and intent parsing runs
+// cold and short since it only classifies. Narration and cinematics run warmer for flavor; narration's
+// temperature is further tuned per beat by the play lambda.
+const (
+	DefaultBlueprintTemperature     = 0.4
+	DefaultBlueprintMaxTokens       = 16000
+	DefaultIntentParsingTemperature = 0.0
+	DefaultIntentParsingMaxTokens   = 256
+	DefaultNarrationTemperature     = 0.8
+	DefaultNarrationMaxTokens       = 1024
+	DefaultCinematicsTemperature    = 0.9
+	DefaultCinematicsMaxTokens      = 2048
+)
+
+// defaultCallSettings holds the defaults above by call type
+var defaultCallSettings = map[CallType]CallSettings{
+	CallBlueprint:     {Temperature: DefaultBlueprintTemperature, MaxTokens: DefaultBlueprintMaxTokens},
+	CallIntentParsing: {Temperature: DefaultIntentParsingTemperature, MaxTokens: DefaultIntentParsingMaxTokens},
+	CallNarration:     {Temperature: DefaultNarrationTemperature, MaxTokens: DefaultNarrationMaxTokens},
+	CallCinematics:    {Temperature: DefaultCinematicsTemperature, MaxTokens: DefaultCinematicsMaxTokens},
+}
+
+// modelMaxOutputTokens caps MaxTokens for models whose output limit is below a call type's budget
+var modelMaxOutputTokens = map[Model]int{
+	ModelHaiku: 8000, // Haiku's limit is 8192; leave some headroom
+}
+
+// CallSettingsFor returns the settings for a call type made with model. An unknown call type gets the
+// narration defaults, and the token budget never exceeds what the model can produce.
+func CallSettingsFor(callType CallType, model Model) CallSettings {
+	settings, ok := defaultCallSettings[callType]
+	if !ok {
+		settings = defaultCallSettings[CallNarration]
+	}
+	if limit, ok := modelMaxOutputTokens[model]; ok && settings.MaxTokens > limit {
+		settings.MaxTokens = limit
+	}
+	return settings
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestCallSettingsFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		callType CallType
+		model    Model
+		expected CallSettings
+	}{
+		{"blueprint on sonnet", CallBlueprint, ModelSonnet, CallSettings{Temperature: DefaultBlueprintTemperature, MaxTokens: DefaultBlueprintMaxTokens}},
+		{"blueprint capped on haiku", CallBlueprint, ModelHaiku, CallSettings{Temperature: DefaultBlueprintTemperature, MaxTokens: 8000}},
+		{"intent parsing", CallIntentParsing, ModelHaiku, CallSettings{Temperature: DefaultIntentParsingTemperature, MaxTokens: DefaultIntentParsingMaxTokens}},
+		{"narration", CallNarration, ModelHaiku, CallSettings{Temperature: DefaultNarrationTemperature, MaxTokens: DefaultNarrationMaxTokens}},
+		{"cinematics", CallCinematics, ModelSonnet, CallSettings{Temperature: DefaultCinematicsTemperature, MaxTokens: DefaultCinematicsMaxTokens}},
+		{"unknown call type", CallType("epilogue"), ModelSonnet, CallSettings{Temperature: DefaultNarrationTemperature, MaxTokens: DefaultNarrationMaxTokens}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CallSettingsFor(tt.callType, tt.model); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package sqsbatch processes the records of an SQS batch with bounded concurrency and
+// collects per-message failures for the partial batch response (reportBatchItemFailures).
+//
+// Every Syrus queue is FIFO, so records sharing a MessageGroupId are processed in order
+// by a single worker, and once one fails the rest of its group is reported as failed
+// without being processed. Independent groups run in parallel.
+package sqsbatch
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ConcurrencyEnvVar names the environment variable holding the worker count
+const ConcurrencyEnvVar = "SYRUS_SQS_CONCURRENCY"
+
+// DefaultConcurrency keeps processing sequential unless a function opts in
+const DefaultConcurrency = 1
+
+// MaxConcurrency bounds the worker pool; larger batches simply queue behind the workers
+const MaxConcurrency = 10
+
+// messageGroupIDAttribute is the SQS system attribute carrying a FIFO record's group
+const messageGroupIDAttribute = "MessageGroupId"
+
+// Handler processes a single record; a non-nil error reports the record as failed
+type Handler func(ctx context.Context, record events.SQSMessage) error
+
+// ConcurrencyFromEnv reads the worker count from SYRUS_SQS_CONCURRENCY
+func ConcurrencyFromEnv() int {
+	return ParseConcurrency(os.Getenv(ConcurrencyEnvVar))
+}
+
+// ParseConcurrency parses a worker count, falling back to DefaultConcurrency when unset or
+// invalid and clamping to MaxConcurrency
+func ParseConcurrency(raw string) int {
+	if raw == "" {
+		return DefaultConcurrency
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		log.Printf("Warning: invalid %s %q; using %d", ConcurrencyEnvVar, raw, DefaultConcurrency)
+		return DefaultConcurrency
+	}
+	if value > MaxConcurrency {
+		return MaxConcurrency
+	}
+	return value
+}
+
+// Process runs handle over every record using at most concurrency workers and returns the
+// failed records in their original batch order. handle must be safe to call concurrently.
+func Process(ctx context.Context, records []events.SQSMessage, concurrency int, handle Handler) []events.SQSBatchItemFailure {
+	groups := groupRecords(records)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(groups) {
+		concurrency = len(groups)
+	}
+
+	failed := make([]bool, len(records))
+	work := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range work {
+				processGroup(ctx, records, group, failed, handle)
+			}
+		}()
+	}
+	for _, group := range groups {
+		work <- group
+	}
+	close(work)
+	wg.Wait()
+
+	var failures []events.SQSBatchItemFailure
+	for i, record := range records {
+		if failed[i] {
+			failures = append(failures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+		}
+	}
+	return failures
+}
+
+// processGroup handles one message group in order. Each worker writes only the failed
+// entries for its own group's indices, so no locking is needed.
+func processGroup(ctx context.Context, records []events.SQSMessage, group []int, failed []bool, handle Handler) {
+	for n, i := range group {
+		if err := handle(ctx, records[i]); err != nil {
+			log.Printf("Failed to process message %s: %v", records[i].MessageId, err)
+			// FIFO ordering: later messages in the group must wait for this one's retry
+			for _, j := range group[n:] {
+				failed[j] = true
+			}
+			if skipped := len(group) - n - 1; skipped > 0 {
+				log.Printf("Deferring %d later message(s) in group %s", skipped, records[i].Attributes[messageGroupIDAttribute])
+			}
+			return
+		}
+	}
+}
+
+// groupRecords splits record indices by MessageGroupId, preserving batch order within and
+// across groups. Records without a group id are independent of each other.
+func groupRecords(records []events.SQSMessage) [][]int {
+	var groups [][]int
+	byID := map[string]int{}
+	for i, record := range records {
+		groupID := record.Attributes[messageGroupIDAttribute]
+		if groupID == "" {
+			groups = append(groups, []int{i})
+			continue
+		}
+		if g, ok := byID[groupID]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		byID[groupID] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
@@ -0,0 +1,120 @@
+package sqsbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func record(id, group string) events.SQSMessage {
+	message := events.SQSMessage{MessageId: id, Body: id}
+	if group != "" {
+		message.Attributes = map[string]string{messageGroupIDAttribute: group}
+	}
+	return message
+}
+
+func failureIDs(failures []events.SQSBatchItemFailure) []string {
+	ids := []string{}
+	for _, failure := range failures {
+		ids = append(ids, failure.ItemIdentifier)
+	}
+	return ids
+}
+
+func TestProcessConcurrentlyReportsEachFailure(t *testing.T) {
+	var records []events.SQSMessage
+	for i := 0; i < 10; i++ {
+		records = append(records, record(fmt.Sprintf("m%d", i), fmt.Sprintf("campaign-%d", i)))
+	}
+
+	var active, peak int32
+	failures := Process(context.Background(), records, 4, func(ctx context.Context, r events.SQSMessage) error {
+		current := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		switch r.MessageId {
+		case "m1", "m4", "m9":
+			return errors.New("model call failed")
+		}
+		return nil
+	})
+
+	if got := failureIDs(failures); fmt.Sprint(got) != "[m1 m4 m9]" {
+		t.Errorf("Expected failures [m1 m4 m9] in batch order, got %v", got)
+	}
+	if peak < 2 || peak > 4 {
+		t.Errorf("Expected between 2 and 4 concurrent handlers, saw %d", peak)
+	}
+}
+
+func TestProcessKeepsMessageGroupsInOrder(t *testing.T) {
+	records := []events.SQSMessage{
+		record("a1", "campaign-a"),
+		record("b1", "campaign-b"),
+		record("a2", "campaign-a"),
+		record("b2", "campaign-b"),
+		record("a3", "campaign-a"),
+		record("c1", ""),
+	}
+
+	var mu sync.Mutex
+	processed := map[string][]string{}
+	failures := Process(context.Background(), records, 3, func(ctx context.Context, r events.SQSMessage) error {
+		group := r.Attributes[messageGroupIDAttribute]
+		mu.Lock()
+		processed[group] = append(processed[group], r.MessageId)
+		mu.Unlock()
+		if r.MessageId == "a2" {
+			return errors.New("campaign not found")
+		}
+		return nil
+	})
+
+	// a3 must not run ahead of a2's retry, so it is reported failed without being processed
+	if got := failureIDs(failures); fmt.Sprint(got) != "[a2 a3]" {
+		t.Errorf("Expected failures [a2 a3], got %v", got)
+	}
+	if fmt.Sprint(processed["campaign-a"]) != "[a1 a2]" || fmt.Sprint(processed["campaign-b"]) != "[b1 b2]" || len(processed[""]) != 1 {
+		t.Errorf("Unexpected processing order: %v", processed)
+	}
+}
+
+func TestProcessEmptyBatch(t *testing.T) {
+	failures := Process(context.Background(), nil, 4, func(ctx context.Context, r events.SQSMessage) error {
+		t.Fatal("Handler should not be called")
+		return nil
+	})
+	if len(failures) != 0 {
+		t.Errorf("Expected no failures, got %v", failures)
+	}
+}
+
+func TestParseConcurrency(t *testing.T) {
+	tests := map[string]int{
+		"":     DefaultConcurrency,
+		"4":    4,
+		"0":    DefaultConcurrency,
+		"-2":   DefaultConcurrency,
+		"many": DefaultConcurrency,
+		"50":   MaxConcurrency,
+	}
+	for raw, expected := range tests {
+		if got := ParseConcurrency(raw); got != expected {
+			t.Errorf("ParseConcurrency(%q) = %d, expected %d", raw, got, expected)
+		}
+	}
+}
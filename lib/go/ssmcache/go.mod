@@ -0,0 +1,7 @@
+module loros/syrus-ssmcache
+
+go 1.21
+
+require github.com/aws/aws-sdk-go v1.55.5
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
@@ -0,0 +1,113 @@
+// Package ssmcache reads SSM parameters through an in-memory cache, so warm lambda containers
+// reuse secrets such as the Discord bot token and Anthropic API key instead of fetching them on
+// every message. Entries expire after DefaultTTL, or the duration in SYRUS_SSM_CACHE_TTL.
+package ssmcache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// DefaultTTL is how long a fetched parameter is reused when SYRUS_SSM_CACHE_TTL is unset
+const DefaultTTL = 5 * time.Minute
+
+// ttlEnvVar names the environment variable overriding DefaultTTL (a Go duration such as "90s")
+const ttlEnvVar = "SYRUS_SSM_CACHE_TTL"
+
+// cacheKey separates decrypted and raw reads of the same parameter
+type cacheKey struct {
+	name           string
+	withDecryption bool
+}
+
+type cacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+var (
+	clientOnce sync.Once
+	client     ssmiface.SSMAPI
+	clientErr  error
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries = map[cacheKey]cacheEntry{}
+
+	// now is overridden in tests
+	now = time.Now
+)
+
+// Get returns the value of the named parameter, fetching it from SSM when it is not cached or has expired
+func Get(name string, withDecryption bool) (string, error) {
+	svc, err := resolve()
+	if err != nil {
+		return "", err
+	}
+
+	key := cacheKey{name: name, withDecryption: withDecryption}
+	mu.Lock()
+	entry, ok := entries[key]
+	mu.Unlock()
+	if ok && now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	result, err := svc.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(withDecryption),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get parameter %s: %w", name, err)
+	}
+	if result.Parameter == nil || result.Parameter.Value == nil {
+		return "", fmt.Errorf("parameter %s not found or has no value", name)
+	}
+
+	value := *result.Parameter.Value
+	mu.Lock()
+	entries[key] = cacheEntry{value: value, expiresAt: now().Add(ttl)}
+	mu.Unlock()
+	return value, nil
+}
+
+// parseTTL reads the cache TTL override, falling back to DefaultTTL when unset or invalid
+func parseTTL(raw string) time.Duration {
+	if raw == "" {
+		return DefaultTTL
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil || parsed < 0 {
+		log.Printf("Invalid %s %q, using %s", ttlEnvVar, raw, DefaultTTL)
+		return DefaultTTL
+	}
+	return parsed
+}
+
+// resolve returns an SSM client, creating it (and reading the TTL) on first use
+func resolve() (ssmiface.SSMAPI, error) {
+	clientOnce.Do(func() {
+		ttl = parseTTL(os.Getenv(ttlEnvVar))
+		if client != nil {
+			return
+		}
+		sess, err := session.NewSession()
+		if err != nil {
+			clientErr = fmt.Errorf("failed to create AWS session: %w", err)
+			return
+		}
+		client = ssm.New(sess)
+	})
+	if clientErr != nil {
+		return nil, clientErr
+	}
+	return client, nil
+}
@@ -0,0 +1,113 @@
+package ssmcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// fakeSSM serves parameters from memory and counts fetches
+type fakeSSM struct {
+	ssmiface.SSMAPI
+	values  map[string]string
+	fetches []ssm.GetParameterInput
+	err     error
+}
+
+func (f *fakeSSM) GetParameter(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	f.fetches = append(f.fetches, *input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	value := f.values[*input.Name]
+	if *input.WithDecryption {
+		value = "decrypted:" + value
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{Value: aws.String(value)}}, nil
+}
+
+func useFake(t *testing.T, values map[string]string) (*fakeSSM, *time.Time) {
+	t.Helper()
+	fake := &fakeSSM{values: values}
+	client = fake
+	clientErr = nil
+	clientOnce.Do(func() {})
+	ttl = DefaultTTL
+	entries = map[cacheKey]cacheEntry{}
+
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+	return fake, &clock
+}
+
+func TestGetCachesUntilExpiry(t *testing.T) {
+	fake, clock := useFake(t, map[string]string{"/syrus/dev/discord/bot-token": "token"})
+
+	for i := 0; i < 3; i++ {
+		value, err := Get("/syrus/dev/discord/bot-token", true)
+		if err != nil || value != "decrypted:token" {
+			t.Fatalf("Expected decrypted token, got %q (%v)", value, err)
+		}
+	}
+	if len(fake.fetches) != 1 {
+		t.Errorf("Expected 1 fetch while warm, got %d", len(fake.fetches))
+	}
+
+	*clock = clock.Add(DefaultTTL)
+	if _, err := Get("/syrus/dev/discord/bot-token", true); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.fetches) != 2 {
+		t.Errorf("Expected a refetch after the TTL, got %d fetches", len(fake.fetches))
+	}
+}
+
+func TestGetKeysOnDecryption(t *testing.T) {
+	fake, _ := useFake(t, map[string]string{"/syrus/dev/discord/app-id": "123"})
+
+	raw, _ := Get("/syrus/dev/discord/app-id", false)
+	decrypted, _ := Get("/syrus/dev/discord/app-id", true)
+	if raw != "123" || decrypted != "decrypted:123" {
+		t.Errorf("Expected separate raw and decrypted values, got %q and %q", raw, decrypted)
+	}
+	if len(fake.fetches) != 2 {
+		t.Errorf("Expected one fetch per decryption mode, got %d", len(fake.fetches))
+	}
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	fake, _ := useFake(t, map[string]string{"/syrus/dev/anthropic/api-key": "key"})
+	fake.err = errors.New("throttled")
+
+	if _, err := Get("/syrus/dev/anthropic/api-key", true); err == nil {
+		t.Fatal("Expected the SSM error")
+	}
+	fake.err = nil
+	if value, err := Get("/syrus/dev/anthropic/api-key", true); err != nil || value != "decrypted:key" {
+		t.Errorf("Expected a fresh fetch after an error, got %q (%v)", value, err)
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected time.Duration
+	}{
+		{"", DefaultTTL},
+		{"90s", 90 * time.Second},
+		{"0", 0},
+		{"soon", DefaultTTL},
+		{"-1m", DefaultTTL},
+	}
+
+	for _, tt := range tests {
+		if got := parseTTL(tt.raw); got != tt.expected {
+			t.Errorf("parseTTL(%q) = %s, expected %s", tt.raw, got, tt.expected)
+		}
+	}
+}
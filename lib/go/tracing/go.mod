@@ -0,0 +1,3 @@
+module loros/syrus-tracing
+
+go 1.21
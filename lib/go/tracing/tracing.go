@@ -0,0 +1,127 @@
+// Package tracing times the phases of handling one interaction and logs a single structured
+// summary line per interaction, giving a latency breakdown without X-Ray.
+//
+// The summary is a JSON object keyed by the interaction's correlation ID (its Discord
+// interaction ID), so it can be queried in CloudWatch Logs Insights alongside the handler's logs.
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Phase names shared by the handlers, so summaries line up across functions
+const (
+	PhaseSSMFetch     = "ssm_fetch"
+	PhaseDynamoDBRead = "dynamodb_read"
+	PhaseModelCall    = "model_call"
+	PhaseValidation   = "validation"
+	PhaseSend         = "send"
+)
+
+// summaryType tags summary lines for log queries
+const summaryType = "interaction_trace"
+
+// Output receives summary lines; swapped out in tests
+var Output io.Writer = os.Stdout
+
+// Trace accumulates phase durations for one interaction. It is safe for concurrent use.
+type Trace struct {
+	handler       string
+	correlationID string
+	now           func() time.Time
+	started       time.Time
+
+	mu     sync.Mutex
+	order  []string
+	phases map[string]time.Duration
+}
+
+// Summary is the structured line logged when an interaction finishes. Phase durations are in
+// milliseconds; a phase entered more than once (e.g. several sends) reports its total.
+type Summary struct {
+	Type          string           `json:"type"`
+	Handler       string           `json:"handler"`
+	CorrelationID string           `json:"correlationId"`
+	Outcome       string           `json:"outcome"`
+	Error         string           `json:"error,omitempty"`
+	TotalMs       int64            `json:"totalMs"`
+	PhasesMs      map[string]int64 `json:"phasesMs"`
+	PhaseOrder    []string         `json:"phaseOrder"`
+}
+
+// New starts a trace for one interaction
+func New(handler, correlationID string) *Trace {
+	return newTrace(handler, correlationID, time.Now)
+}
+
+func newTrace(handler, correlationID string, now func() time.Time) *Trace {
+	return &Trace{
+		handler:       handler,
+		correlationID: correlationID,
+		now:           now,
+		started:       now(),
+		phases:        map[string]time.Duration{},
+	}
+}
+
+// Start begins timing a phase and returns the function that ends it:
+//
+//	done := trace.Start(tracing.PhaseModelCall)
+//	response, err := callModel(...)
+//	done()
+func (t *Trace) Start(phase string) func() {
+	began := t.now()
+	return func() {
+		t.Record(phase, t.now().Sub(began))
+	}
+}
+
+// Record adds a measured duration to a phase
+func (t *Trace) Record(phase string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, seen := t.phases[phase]; !seen {
+		t.order = append(t.order, phase)
+	}
+	t.phases[phase] += duration
+}
+
+// Summary builds the summary for the interaction so far; err is the handler's outcome
+func (t *Trace) Summary(err error) Summary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := Summary{
+		Type:          summaryType,
+		Handler:       t.handler,
+		CorrelationID: t.correlationID,
+		Outcome:       "ok",
+		TotalMs:       t.now().Sub(t.started).Milliseconds(),
+		PhasesMs:      make(map[string]int64, len(t.phases)),
+		PhaseOrder:    append([]string{}, t.order...),
+	}
+	if err != nil {
+		summary.Outcome = "error"
+		summary.Error = err.Error()
+	}
+	for phase, duration := range t.phases {
+		summary.PhasesMs[phase] = duration.Milliseconds()
+	}
+	return summary
+}
+
+// Log writes the summary line for the interaction; call it once, when the handler returns
+func (t *Trace) Log(err error) {
+	line, marshalErr := json.Marshal(t.Summary(err))
+	if marshalErr != nil {
+		log.Printf("Warning: failed to marshal trace summary: %v", marshalErr)
+		return
+	}
+	fmt.Fprintln(Output, string(line))
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock advances by a fixed step every time it is read
+type fakeClock struct {
+	at   time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.at = c.at.Add(c.step)
+	return c.at
+}
+
+func TestTraceSummaryIncludesAllPhases(t *testing.T) {
+	var buf bytes.Buffer
+	original := Output
+	Output = &buf
+	defer func() { Output = original }()
+
+	clock := &fakeClock{at: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), step: 10 * time.Millisecond}
+	trace := newTrace("blueprinting", "interaction-123", clock.now)
+
+	phases := []string{PhaseDynamoDBRead, PhaseSSMFetch, PhaseModelCall, PhaseValidation, PhaseSend}
+	for _, phase := range phases {
+		trace.Start(phase)()
+	}
+	// A repeated phase accumulates rather than overwriting
+	trace.Start(PhaseSend)()
+
+	trace.Log(nil)
+
+	var summary Summary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("Expected a single JSON summary line, got %q: %v", buf.String(), err)
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("Expected exactly one summary line, got %q", buf.String())
+	}
+
+	if summary.Type != summaryType || summary.Handler != "blueprinting" || summary.CorrelationID != "interaction-123" {
+		t.Errorf("Unexpected summary identity: %+v", summary)
+	}
+	if summary.Outcome != "ok" || summary.Error != "" {
+		t.Errorf("Expected ok outcome, got %q (%q)", summary.Outcome, summary.Error)
+	}
+	if !reflect.DeepEqual(summary.PhaseOrder, phases) {
+		t.Errorf("Expected phase order %v, got %v", phases, summary.PhaseOrder)
+	}
+	for _, phase := range phases {
+		expected := int64(10)
+		if phase == PhaseSend {
+			expected = 20
+		}
+		if got, ok := summary.PhasesMs[phase]; !ok || got != expected {
+			t.Errorf("Expected %s=%dms, got %d (present %v)", phase, expected, got, ok)
+		}
+	}
+	// Construction plus two reads per phase entry, plus the final read
+	if summary.TotalMs != 130 {
+		t.Errorf("Expected total 130ms, got %d", summary.TotalMs)
+	}
+}
+
+func TestTraceSummaryRecordsError(t *testing.T) {
+	trace := New("blueprinting", "interaction-456")
+	trace.Record(PhaseDynamoDBRead, 5*time.Millisecond)
+
+	summary := trace.Summary(errors.New("failed to get campaign"))
+	if summary.Outcome != "error" || summary.Error != "failed to get campaign" {
+		t.Errorf("Expected error outcome, got %q (%q)", summary.Outcome, summary.Error)
+	}
+	if summary.PhasesMs[PhaseDynamoDBRead] != 5 {
+		t.Errorf("Expected dynamodb_read=5ms, got %d", summary.PhasesMs[PhaseDynamoDBRead])
+	}
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	models "loros/syrus-models"
+)
+
+// IngredientUsage records whether a single seeded ingredient made it into the blueprint
+type IngredientUsage struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Bound   bool   `json:"bound"`   // Listed in the blueprint's ingredientBinding
+	Appears bool   `json:"appears"` // Referenced in act or major force text
+}
+
+// Used reports whether the ingredient was bound or referenced anywhere
+func (u IngredientUsage) Used() bool {
+	return u.Bound || u.Appears
+}
+
+// IngredientCoverage reports which seeds a generated blueprint used
+type IngredientCoverage struct {
+	Objective   IngredientUsage   `json:"objective"`
+	Twists      []IngredientUsage `json:"twists"`
+	Antagonists []IngredientUsage `json:"antagonists"`
+	SetPieces   []IngredientUsage `json:"setPieces"`
+}
+
+// Missing returns the IDs of all seeded ingredients the blueprint ignored
+func (c IngredientCoverage) Missing() []string {
+	missing := make([]string, 0)
+	all := append([]IngredientUsage{c.Objective}, c.Twists...)
+	all = append(all, c.Antagonists...)
+	all = append(all, c.SetPieces...)
+	for _, usage := range all {
+		if usage.ID != "" && !usage.Used() {
+			missing = append(missing, usage.ID)
+		}
+	}
+	return missing
+}
+
+// Summary renders the coverage report as a single log line
+func (c IngredientCoverage) Summary() string {
+	countUsed := func(usages []IngredientUsage) string {
+		used := 0
+		for _, usage := range usages {
+			if usage.Used() {
+				used++
+			}
+		}
+		return fmt.Sprintf("%d/%d", used, len(usages))
+	}
+
+	return fmt.Sprintf("objective=%t twists=%s antagonists=%s setPieces=%s missing=%v",
+		c.Objective.Used(), countUsed(c.Twists), countUsed(c.Antagonists), countUsed(c.SetPieces), c.Missing())
+}
+
+// ComputeCoverage checks each seeded ingredient against the blueprint's ingredientBinding
+// and the text of its acts and major forces
+func ComputeCoverage(blueprint *models.Blueprint, seeds models.CampaignSeeds) IngredientCoverage {
+	// Gather all act and force text once for name/ID lookups
+	var textParts []string
+	for _, act := range blueprint.Acts {
+		textParts = append(textParts, act.Name, act.PrimaryArea, act.PrimaryDanger, act.NarrativePurpose, act.BeatGuidance.Purpose)
+		textParts = append(textParts, act.BeatGuidance.ExpectedProgression...)
+	}
+	for forceID, force := range blueprint.MajorForces {
+		textParts = append(textParts, forceID, force.InitialPresence.Description)
+		for _, escalation := range force.Escalations {
+			textParts = append(textParts, escalation.Description)
+		}
+	}
+	blueprintText := strings.ToLower(strings.Join(textParts, " "))
+
+	usage := func(id, name string, bound []string) IngredientUsage {
+		u := IngredientUsage{ID: id, Name: name}
+		for _, b := range bound {
+			if strings.EqualFold(b, id) {
+				u.Bound = true
+				break
+			}
+		}
+		if id != "" && strings.Contains(blueprintText, strings.ToLower(id)) {
+			u.Appears = true
+		}
+		if name != "" && strings.Contains(blueprintText, strings.ToLower(name)) {
+			u.Appears = true
+		}
+		return u
+	}
+
+	binding := blueprint.IngredientBinding
+	coverage := IngredientCoverage{
+		Objective:   usage(seeds.Objective.ObjectiveID, seeds.Objective.Name, []string{binding.ObjectiveSeed}),
+		Twists:      make([]IngredientUsage, 0, len(seeds.Twists)),
+		Antagonists: make([]IngredientUsage, 0, len(seeds.Antagonists)),
+		SetPieces:   make([]IngredientUsage, 0, len(seeds.SetPieces)),
+	}
+	for _, twist := range seeds.Twists {
+		coverage.Twists = append(coverage.Twists, usage(twist.TwistID, twist.Name, binding.Twists))
+	}
+	for _, antagonist := range seeds.Antagonists {
+		coverage.Antagonists = append(coverage.Antagonists, usage(antagonist.AntagonistID, antagonist.Name, binding.Antagonists))
+	}
+	for _, setPiece := range seeds.SetPieces {
+		coverage.SetPieces = append(coverage.SetPieces, usage(setPiece.SetPieceID, setPiece.Name, binding.SetPieces))
+	}
+
+	return coverage
+}
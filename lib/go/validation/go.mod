@@ -0,0 +1,7 @@
+module loros/syrus-validation
+
+go 1.21
+
+replace loros/syrus-models => ../models
+
+require loros/syrus-models v0.0.0
@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed blueprint.schema.json
+var blueprintSchemaJSON []byte
+
+// maxSchemaErrors caps how many schema violations are reported in one validation error
+const maxSchemaErrors = 5
+
+// jsonSchema is the subset of JSON Schema used by blueprint.schema.json:
+// type (single or list), required, properties, additionalProperties (map values), items and enum.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []string               `json:"enum"`
+}
+
+// schemaTypes accepts both "type": "object" and "type": ["object", "null"]
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("schema type must be a string or list of strings: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+var (
+	blueprintSchemaOnce sync.Once
+	blueprintSchema     *jsonSchema
+	blueprintSchemaErr  error
+)
+
+// loadBlueprintSchema parses the embedded blueprint schema once
+func loadBlueprintSchema() (*jsonSchema, error) {
+	blueprintSchemaOnce.Do(func() {
+		var schema jsonSchema
+		if err := json.Unmarshal(blueprintSchemaJSON, &schema); err != nil {
+			blueprintSchemaErr = fmt.Errorf("failed to parse blueprint schema: %w", err)
+			return
+		}
+		blueprintSchema = &schema
+	})
+	return blueprintSchema, blueprintSchemaErr
+}
+
+// ValidateSchema checks the raw blueprint JSON against the blueprint schema,
+// returning field-pathed violations (e.g. "blueprint.acts[1].actNumber: expected integer, got string")
+func ValidateSchema(rawJSON []byte) error {
+	schema, err := loadBlueprintSchema()
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if len(bytes.TrimSpace(rawJSON)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(rawJSON))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return newError(OutcomeInvalidBlueprint, "failed to parse blueprint JSON: %v", err)
+		}
+	}
+
+	violations := checkSchema(schema, value, "blueprint", nil)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	message := strings.Join(violations, "; ")
+	if len(violations) > maxSchemaErrors {
+		message = fmt.Sprintf("%s; and %d more", strings.Join(violations[:maxSchemaErrors], "; "), len(violations)-maxSchemaErrors)
+	}
+	schemaErr := newError(OutcomeSchemaViolation, "blueprint does not match schema: %s", message)
+	schemaErr.Violations = violations
+	return schemaErr
+}
+
+// checkSchema appends every violation of schema by value at path to violations
+func checkSchema(schema *jsonSchema, value interface{}, path string, violations []string) []string {
+	if schema == nil {
+		return violations
+	}
+
+	actual := jsonTypeOf(value)
+	if len(schema.Type) > 0 && !schemaAllowsType(schema.Type, actual) {
+		return append(violations, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(schema.Type, " or "), actual))
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := typed[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%s.%s: required field missing", path, name))
+			}
+		}
+
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if property, ok := schema.Properties[key]; ok {
+				violations = checkSchema(property, typed[key], path+"."+key, violations)
+			} else if schema.AdditionalProperties != nil {
+				violations = checkSchema(schema.AdditionalProperties, typed[key], path+"."+key, violations)
+			}
+		}
+	case []interface{}:
+		for i, item := range typed {
+			violations = checkSchema(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	case string:
+		if len(schema.Enum) > 0 && !containsString(schema.Enum, typed) {
+			violations = append(violations, fmt.Sprintf("%s: must be one of %s, got %q", path, strings.Join(schema.Enum, ", "), typed))
+		}
+	}
+
+	return violations
+}
+
+// jsonTypeOf names the JSON Schema type of a value decoded with UseNumber
+func jsonTypeOf(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaAllowsType reports whether actual satisfies one of the allowed schema types
+func schemaAllowsType(allowed schemaTypes, actual string) bool {
+	for _, t := range allowed {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
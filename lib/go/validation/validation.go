@@ -0,0 +1,454 @@
+// Package validation checks generated campaign blueprints: the JSON shape against the embedded
+// blueprint schema, then the semantic rules (required fields, act count against the seeds, references
+// to featured areas, acts, and boons) and soft adventure-content warnings. The blueprinting lambda
+// and the validate-blueprint command share it.
+package validation
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	models "loros/syrus-models"
+)
+
+// Blueprint validation outcomes, emitted as the Outcome metric dimension
+const (
+	OutcomeSuccess            = "success"
+	OutcomeInvalidJSON        = "invalid_json"
+	OutcomeInvalidBlueprint   = "invalid_blueprint_json"
+	OutcomeMissingTitle       = "missing_title"
+	OutcomeMissingPremise     = "missing_premise"
+	OutcomePillarCount        = "pillar_count"
+	OutcomeEmptyPillar        = "empty_pillar"
+	OutcomeMissingEndState    = "missing_end_state"
+	OutcomeFailurePath        = "failure_path"
+	OutcomeMissingIntroImage  = "missing_intro_image"
+	OutcomeIntroImageSendWhen = "intro_image_send_when"
+	OutcomeActCount           = "act_count"
+	OutcomeSchemaViolation    = "schema_violation"
+	OutcomeUnknownArea        = "unknown_area"
+	OutcomeNPCAct             = "npc_act"
+	OutcomeUnknownBoon        = "unknown_boon"
+	OutcomeUnknown            = "unknown"
+)
+
+// boonsJSON mirrors lambda/blueprinting/assets/boons.json, the boon map given to the model
+//
+//go:embed boons.json
+var boonsJSON []byte
+
+// validBoons holds the lowercased boonId and name of every boon in boons.json
+var validBoons = parseBoonNames(boonsJSON)
+
+// parseBoonNames builds the set of boon identifiers a blueprint may reference
+func parseBoonNames(data []byte) map[string]bool {
+	var catalog struct {
+		Boons []struct {
+			BoonID string `json:"boonId"`
+			Name   string `json:"name"`
+		} `json:"boons"`
+	}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		panic(fmt.Sprintf("invalid embedded boons.json: %v", err))
+	}
+	names := make(map[string]bool, 2*len(catalog.Boons))
+	for _, boon := range catalog.Boons {
+		names[strings.ToLower(boon.BoonID)] = true
+		names[strings.ToLower(boon.Name)] = true
+	}
+	return names
+}
+
+// Error is a validation failure tagged with a stable outcome code
+type Error struct {
+	Code       string
+	Message    string
+	Violations []string // Field-level schema violations, when Code is OutcomeSchemaViolation
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Outcome maps a parse/validate result to its metric outcome code
+func Outcome(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	var validationErr *Error
+	if errors.As(err, &validationErr) {
+		return validationErr.Code
+	}
+	return OutcomeUnknown
+}
+
+// ParseResponse parses a model response of the form {"blueprint": {...}, "intro": "..."} and validates the blueprint
+func ParseResponse(response string, seeds models.CampaignSeeds) (*models.Blueprint, string, error) {
+	log.Printf("Parsing Claude response (length: %d chars)", len(response))
+
+	// Parse the JSON response from Claude
+	var claudeResponse struct {
+		Blueprint json.RawMessage `json:"blueprint"`
+		Intro     string          `json:"intro"`
+	}
+
+	if err := json.Unmarshal([]byte(response), &claudeResponse); err != nil {
+		// Log the first 500 chars for debugging
+		previewLen := 500
+		if len(response) < previewLen {
+			previewLen = len(response)
+		}
+		log.Printf("Failed to parse JSON. Response preview: %s", response[:previewLen])
+		return nil, "", newError(OutcomeInvalidJSON, "failed to parse JSON response: %v", err)
+	}
+
+	blueprint, err := ParseBlueprint(claudeResponse.Blueprint, seeds)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return blueprint, claudeResponse.Intro, nil
+}
+
+// ParseBlueprint runs the full pipeline on raw blueprint JSON: schema check, decode, then semantic validation
+func ParseBlueprint(rawJSON []byte, seeds models.CampaignSeeds) (*models.Blueprint, error) {
+	// Check structure and field types before the struct-level checks
+	if err := ValidateSchema(rawJSON); err != nil {
+		return nil, err
+	}
+
+	// Parse the blueprint
+	var blueprint models.Blueprint
+	if err := json.Unmarshal(rawJSON, &blueprint); err != nil {
+		return nil, newError(OutcomeInvalidBlueprint, "failed to parse blueprint JSON: %v", err)
+	}
+
+	// Validate blueprint
+	if err := Validate(&blueprint, seeds); err != nil {
+		return nil, fmt.Errorf("blueprint validation failed: %w", err)
+	}
+
+	log.Printf("Successfully parsed and validated blueprint: %s", blueprint.Title)
+
+	// Report which seeded ingredients the generated blueprint actually used (informs prompt tuning)
+	coverage := ComputeCoverage(&blueprint, seeds)
+	log.Printf("Ingredient coverage: %s", coverage.Summary())
+
+	return &blueprint, nil
+}
+
+// Validate applies the semantic checks that the schema cannot express. Adventure-content checks only log warnings.
+func Validate(blueprint *models.Blueprint, seeds models.CampaignSeeds) error {
+	// Required fields
+	if blueprint.Title == "" {
+		return newError(OutcomeMissingTitle, "missing required field: title")
+	}
+	if blueprint.Premise == "" {
+		return newError(OutcomeMissingPremise, "missing required field: premise")
+	}
+	if len(blueprint.ThematicPillars) != 3 {
+		return newError(OutcomePillarCount, "thematicPillars must have exactly 3 elements, got %d", len(blueprint.ThematicPillars))
+	}
+	for i, pillar := range blueprint.ThematicPillars {
+		if strings.TrimSpace(pillar) == "" {
+			return newError(OutcomeEmptyPillar, "thematicPillars[%d] must not be empty", i)
+		}
+	}
+
+	// IntroImage validation (REQUIRED)
+	if blueprint.ImagePlan.IntroImage.Prompt == "" {
+		return newError(OutcomeMissingIntroImage, "missing required field: imagePlan.introImage.prompt")
+	}
+	if blueprint.ImagePlan.IntroImage.SendWhen != "campaign_start" {
+		return newError(OutcomeIntroImageSendWhen, "imagePlan.introImage.sendWhen must be 'campaign_start', got '%s'", blueprint.ImagePlan.IntroImage.SendWhen)
+	}
+
+	// Acts validation
+	expectedActs := seeds.BeatProfile.Acts
+	if len(blueprint.Acts) != expectedActs {
+		return newError(OutcomeActCount, "acts count mismatch: expected %d, got %d", expectedActs, len(blueprint.Acts))
+	}
+
+	// D&D Sanity Check: Ensure at least one act has physical danger
+	// Check for: named monsters, physical lairs, or direct combat opportunities
+	hasFightableContent := false
+	for _, act := range blueprint.Acts {
+		primaryArea := strings.ToLower(act.PrimaryArea)
+		primaryDanger := strings.ToLower(act.PrimaryDanger)
+		narrativePurpose := strings.ToLower(act.NarrativePurpose)
+
+		// Check for physical locations/dangers
+		physicalKeywords := []string{
+			"lair", "ruins", "tomb", "dungeon", "stronghold", "tower", "cave",
+			"monster", "beast", "dragon", "undead", "creature", "guardian",
+			"fight", "combat", "assault", "siege", "defend", "battle",
+		}
+
+		actText := primaryArea + " " + primaryDanger + " " + narrativePurpose
+		for _, keyword := range physicalKeywords {
+			if strings.Contains(actText, keyword) {
+				hasFightableContent = true
+				break
+			}
+		}
+
+		if hasFightableContent {
+			break
+		}
+	}
+
+	// Check major forces for physical antagonists
+	if !hasFightableContent && len(blueprint.MajorForces) > 0 {
+		for range blueprint.MajorForces {
+			// Look for creature/monster types in the force data
+			// This is a simple check - we're looking for any indication of a physical threat
+			hasFightableContent = true // If there are major forces defined, assume they're physical
+			break
+		}
+	}
+
+	if !hasFightableContent {
+		log.Printf("WARNING: Blueprint may lack physical threats - no monsters, lairs, or direct combat opportunities found")
+		// Note: We log a warning but don't fail validation to allow flexibility
+		// This is a sanity check, not a hard requirement
+	}
+
+	// Adventure Content Validation (soft checks - log warnings for monitoring)
+	adventureScore := 0
+	hasMonsterInEarlyActs := false
+	hasPhysicalLocation := false
+	hasCombatOpportunity := false
+	hasTangibleStakes := false
+
+	for i, act := range blueprint.Acts {
+		actText := strings.ToLower(act.PrimaryArea + " " + act.PrimaryDanger + " " + act.NarrativePurpose)
+
+		// Check for physical locations
+		locationKeywords := []string{"lair", "ruins", "tomb", "dungeon", "stronghold", "tower", "cave", "wilderness", "temple", "crypt", "vault", "barrow", "fortress", "citadel"}
+		for _, kw := range locationKeywords {
+			if strings.Contains(actText, kw) {
+				hasPhysicalLocation = true
+				adventureScore++
+				break
+			}
+		}
+
+		// Check for monsters/enemies (especially in acts 1-2)
+		monsterKeywords := []string{"monster", "beast", "dragon", "undead", "creature", "guardian", "enemy", "raiders", "cultists", "demon", "aberration", "wyrm", "lich", "necromancer", "goblin", "orc", "troll", "giant", "elemental"}
+		for _, kw := range monsterKeywords {
+			if strings.Contains(actText, kw) {
+				if i < 2 {
+					hasMonsterInEarlyActs = true
+				}
+				adventureScore++
+				break
+			}
+		}
+
+		// Check for action/danger
+		actionKeywords := []string{"fight", "combat", "battle", "chase", "escape", "siege", "assault", "infiltrate", "hunt", "pursue", "ambush", "defend", "attack", "confront"}
+		for _, kw := range actionKeywords {
+			if strings.Contains(actText, kw) {
+				hasCombatOpportunity = true
+				adventureScore++
+				break
+			}
+		}
+	}
+
+	// Check for tangible stakes in premise
+	premiseText := strings.ToLower(blueprint.Premise)
+	stakeKeywords := []string{"treasure", "power", "territory", "artifact", "relic", "destroy", "kill", "stop", "save", "claim", "steal", "recover", "reclaim"}
+	for _, kw := range stakeKeywords {
+		if strings.Contains(premiseText, kw) {
+			hasTangibleStakes = true
+			adventureScore++
+			break
+		}
+	}
+
+	// Log warnings if adventure content is weak
+	if adventureScore < 3 {
+		log.Printf("WARNING: Low adventure score (%d/5) - campaign may lack physical danger and tangible threats", adventureScore)
+	}
+	if !hasMonsterInEarlyActs {
+		log.Printf("WARNING: No monsters detected in acts 1-2 - campaign may start slowly")
+	}
+	if !hasPhysicalLocation {
+		log.Printf("WARNING: No hostile physical locations detected - campaign may be too abstract")
+	}
+	if !hasCombatOpportunity {
+		log.Printf("WARNING: No combat/action opportunities detected - players may lack clear danger")
+	}
+	if !hasTangibleStakes {
+		log.Printf("WARNING: No tangible stakes detected in premise - goals may be too abstract")
+	}
+
+	// Log success for high adventure content
+	if adventureScore >= 4 {
+		log.Printf("✓ Strong adventure content detected (score: %d/5)", adventureScore)
+	}
+
+	// Area variety: acts shouldn't share a primary area when enough featured areas exist
+	if reused := findReusedPrimaryAreas(blueprint, seeds); len(reused) > 0 {
+		log.Printf("WARNING: Acts reuse primary areas %v despite %d featured areas for %d acts - campaign may feel samey",
+			reused, len(seeds.FeaturedAreas), len(blueprint.Acts))
+	}
+
+	// These are soft warnings - don't fail validation, just log for monitoring
+
+	// Acts and final confrontations must take place in the seeded areas, so the narration matches the map
+	if unknown := findUnknownAreas(blueprint, seeds.FeaturedAreas); len(unknown) > 0 {
+		return newError(OutcomeUnknownArea, "areas do not match any featured area: %s", strings.Join(unknown, ", "))
+	}
+
+	// NPCs must first appear in an act that exists (omitted means unspecified)
+	for _, key := range sortedNPCKeys(blueprint.NPCs) {
+		act := blueprint.NPCs[key].FirstAppearanceAct
+		if act != 0 && (act < 1 || act > len(blueprint.Acts)) {
+			return newError(OutcomeNPCAct, "npcs[%q].firstAppearanceAct %d is outside acts 1-%d", key, act, len(blueprint.Acts))
+		}
+	}
+
+	// Boon grants may only reference boons from the boon map
+	for i, entry := range blueprint.BoonPlan {
+		for j, boon := range entry.Boons {
+			if !validBoons[strings.ToLower(strings.TrimSpace(boon.Name))] {
+				return newError(OutcomeUnknownBoon, "boonPlan[%d].boons[%d].name %q is not an available boon", i, j, boon.Name)
+			}
+		}
+	}
+
+	// Every end state is needed to conclude the campaign
+	endStates := []struct {
+		field string
+		value string
+	}{
+		{"success", blueprint.EndStates.Success},
+		{"compromised", blueprint.EndStates.Compromised},
+		{"failure", blueprint.EndStates.Failure},
+	}
+	for _, endState := range endStates {
+		if strings.TrimSpace(endState.value) == "" {
+			return newError(OutcomeMissingEndState, "missing required field: endStates.%s", endState.field)
+		}
+	}
+
+	// Failure paths are activated by ID, so IDs must be unique and each needs a trigger
+	failurePathIDs := make(map[string]bool, len(blueprint.FailurePaths))
+	for i, path := range blueprint.FailurePaths {
+		id := strings.TrimSpace(path.ID)
+		if id == "" {
+			return newError(OutcomeFailurePath, "failurePaths[%d].id must not be empty", i)
+		}
+		if failurePathIDs[id] {
+			return newError(OutcomeFailurePath, "failurePaths[%d].id %q is a duplicate", i, path.ID)
+		}
+		failurePathIDs[id] = true
+		if strings.TrimSpace(path.Trigger) == "" {
+			return newError(OutcomeFailurePath, "failurePaths[%d].trigger must not be empty", i)
+		}
+	}
+
+	return nil
+}
+
+// normalizeAreaName lowercases an area name and folds punctuation and underscores to single spaces,
+// so "tower_lower_levels" and "Tower Lower-Levels" compare equal
+func normalizeAreaName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// matchesFeaturedArea reports whether a primary area names one of the featured areas. The area may
+// qualify the name (e.g. "Old Barrow tomb" for the featured area "Old Barrow").
+func matchesFeaturedArea(primaryArea string, featured []models.AreaSeed) bool {
+	area := normalizeAreaName(primaryArea)
+	if area == "" {
+		return false
+	}
+	for _, seed := range featured {
+		name := normalizeAreaName(seed.Name)
+		if name != "" && (area == name || strings.Contains(" "+area+" ", " "+name+" ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// findUnknownAreas returns every act primary area and final confrontation location that names no
+// featured area, each prefixed with its field. Nothing is checked when no areas were featured.
+func findUnknownAreas(blueprint *models.Blueprint, featured []models.AreaSeed) []string {
+	unknown := make([]string, 0)
+	if len(featured) == 0 {
+		return unknown
+	}
+
+	for i, act := range blueprint.Acts {
+		if !matchesFeaturedArea(act.PrimaryArea, featured) {
+			unknown = append(unknown, fmt.Sprintf("acts[%d].primaryArea %q", i, act.PrimaryArea))
+		}
+	}
+
+	forces := make([]string, 0, len(blueprint.MajorForces))
+	for key := range blueprint.MajorForces {
+		forces = append(forces, key)
+	}
+	sort.Strings(forces)
+	for _, key := range forces {
+		confrontation := blueprint.MajorForces[key].FinalConfrontation
+		// The confrontation is optional, and so is its location
+		if confrontation == nil || strings.TrimSpace(confrontation.Location) == "" {
+			continue
+		}
+		if !matchesFeaturedArea(confrontation.Location, featured) {
+			unknown = append(unknown, fmt.Sprintf("majorForces[%q].finalConfrontation.location %q", key, confrontation.Location))
+		}
+	}
+
+	return unknown
+}
+
+// sortedNPCKeys returns the NPC map keys in order, so the first reported error is deterministic
+func sortedNPCKeys(npcs map[string]models.NPC) []string {
+	keys := make([]string, 0, len(npcs))
+	for key := range npcs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// findReusedPrimaryAreas returns primary areas used by more than one act, but only when there were
+// at least as many featured areas as acts (so every act could have had its own area)
+func findReusedPrimaryAreas(blueprint *models.Blueprint, seeds models.CampaignSeeds) []string {
+	reused := make([]string, 0)
+	if len(blueprint.Acts) < 2 || len(seeds.FeaturedAreas) < len(blueprint.Acts) {
+		return reused
+	}
+
+	counts := make(map[string]int)
+	for _, act := range blueprint.Acts {
+		area := strings.ToLower(strings.TrimSpace(act.PrimaryArea))
+		if area == "" {
+			continue
+		}
+		counts[area]++
+		if counts[area] == 2 {
+			reused = append(reused, act.PrimaryArea)
+		}
+	}
+
+	return reused
+}
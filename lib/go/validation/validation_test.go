@@ -0,0 +1,431 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	models "loros/syrus-models"
+)
+
+func TestValidateBlueprint(t *testing.T) {
+	seeds := models.CampaignSeeds{
+		BeatProfile: models.BeatProfile{
+			Acts: 4,
+			BeatsPerAct: models.MinMaxRange{
+				Min: 8,
+				Max: 12,
+			},
+			AvgMinutesPerBeat: 5,
+		},
+	}
+
+	t.Run("valid blueprint", func(t *testing.T) {
+		blueprint := &models.Blueprint{
+			Title:   "Test Campaign",
+			Premise: "A test premise for validation",
+			ThematicPillars: []string{
+				"Pillar One",
+				"Pillar Two",
+				"Pillar Three",
+			},
+			Acts: []models.Act{
+				{ActNumber: 1, Name: "Act One"},
+				{ActNumber: 2, Name: "Act Two"},
+				{ActNumber: 3, Name: "Act Three"},
+				{ActNumber: 4, Name: "Act Four"},
+			},
+			ImagePlan: models.ImagePlan{
+				IntroImage: models.ImagePlanItem{
+					Prompt:   "Test intro image prompt",
+					SendWhen: "campaign_start",
+				},
+			},
+			EndStates: models.EndStates{
+				Success:     "The bell rings true",
+				Compromised: "The bell rings cracked",
+				Failure:     "The bell stays drowned",
+			},
+		}
+
+		err := Validate(blueprint, seeds)
+		if err != nil {
+			t.Errorf("Expected valid blueprint to pass validation, got error: %v", err)
+		}
+	})
+
+	t.Run("missing title", func(t *testing.T) {
+		blueprint := &models.Blueprint{
+			Title:   "",
+			Premise: "A test premise",
+			ThematicPillars: []string{
+				"Pillar One",
+				"Pillar Two",
+				"Pillar Three",
+			},
+			Acts: []models.Act{
+				{ActNumber: 1},
+				{ActNumber: 2},
+				{ActNumber: 3},
+				{ActNumber: 4},
+			},
+		}
+
+		err := Validate(blueprint, seeds)
+		if err == nil {
+			t.Error("Expected error for missing title")
+		}
+	})
+
+	t.Run("wrong number of thematic pillars", func(t *testing.T) {
+		blueprint := &models.Blueprint{
+			Title:           "Test Campaign",
+			Premise:         "A test premise",
+			ThematicPillars: []string{"Only One"},
+			Acts: []models.Act{
+				{ActNumber: 1},
+				{ActNumber: 2},
+				{ActNumber: 3},
+				{ActNumber: 4},
+			},
+		}
+
+		err := Validate(blueprint, seeds)
+		if err == nil {
+			t.Error("Expected error for wrong number of thematic pillars")
+		}
+	})
+
+	t.Run("wrong number of acts", func(t *testing.T) {
+		blueprint := &models.Blueprint{
+			Title:   "Test Campaign",
+			Premise: "A test premise",
+			ThematicPillars: []string{
+				"Pillar One",
+				"Pillar Two",
+				"Pillar Three",
+			},
+			Acts: []models.Act{
+				{ActNumber: 1},
+				{ActNumber: 2},
+			},
+		}
+
+		err := Validate(blueprint, seeds)
+		if err == nil {
+			t.Error("Expected error for wrong number of acts")
+		}
+	})
+}
+
+func TestValidationOutcome(t *testing.T) {
+	wrapped := fmt.Errorf("blueprint validation failed: %w", newError(OutcomeActCount, "acts count mismatch"))
+	if got := Outcome(wrapped); got != OutcomeActCount {
+		t.Errorf("Expected %s for wrapped validation error, got %s", OutcomeActCount, got)
+	}
+	if got := Outcome(errors.New("boom")); got != OutcomeUnknown {
+		t.Errorf("Expected %s for untyped error, got %s", OutcomeUnknown, got)
+	}
+	if got := Outcome(nil); got != OutcomeSuccess {
+		t.Errorf("Expected %s for nil error, got %s", OutcomeSuccess, got)
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	seeds := models.CampaignSeeds{BeatProfile: models.BeatProfile{Acts: 1}}
+	newBlueprint := func() *models.Blueprint {
+		return &models.Blueprint{
+			Title:           "Test Campaign",
+			Premise:         "A test premise",
+			ThematicPillars: []string{"One", "Two", "Three"},
+			Acts:            []models.Act{{ActNumber: 1}},
+			FailurePaths: []models.FailurePath{
+				{ID: "bell_lost", Trigger: "The bell sinks", Consequence: "The tide rises"},
+				{ID: "keeper_dies", Trigger: "Orla falls", Consequence: "The barrow seals"},
+			},
+			EndStates: models.EndStates{Success: "The bell rings true", Compromised: "The bell rings cracked", Failure: "The bell stays drowned"},
+			ImagePlan: models.ImagePlan{
+				IntroImage: models.ImagePlanItem{Prompt: "A drowned bell", SendWhen: "campaign_start"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*models.Blueprint)
+		outcome string
+		field   string
+	}{
+		{"valid structure", func(*models.Blueprint) {}, OutcomeSuccess, ""},
+		{"empty pillar", func(b *models.Blueprint) { b.ThematicPillars[1] = "" }, OutcomeEmptyPillar, "thematicPillars[1]"},
+		{"blank pillar", func(b *models.Blueprint) { b.ThematicPillars[2] = "   " }, OutcomeEmptyPillar, "thematicPillars[2]"},
+		{"missing success", func(b *models.Blueprint) { b.EndStates.Success = "" }, OutcomeMissingEndState, "endStates.success"},
+		{"missing compromised", func(b *models.Blueprint) { b.EndStates.Compromised = " " }, OutcomeMissingEndState, "endStates.compromised"},
+		{"missing failure", func(b *models.Blueprint) { b.EndStates.Failure = "" }, OutcomeMissingEndState, "endStates.failure"},
+		{"failure path without id", func(b *models.Blueprint) { b.FailurePaths[0].ID = "" }, OutcomeFailurePath, "failurePaths[0].id"},
+		{"duplicate failure path id", func(b *models.Blueprint) { b.FailurePaths[1].ID = "bell_lost" }, OutcomeFailurePath, `failurePaths[1].id "bell_lost"`},
+		{"failure path without trigger", func(b *models.Blueprint) { b.FailurePaths[1].Trigger = "" }, OutcomeFailurePath, "failurePaths[1].trigger"},
+		{"no failure paths", func(b *models.Blueprint) { b.FailurePaths = nil }, OutcomeSuccess, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprint := newBlueprint()
+			tt.mutate(blueprint)
+
+			err := Validate(blueprint, seeds)
+			if got := Outcome(err); got != tt.outcome {
+				t.Fatalf("Expected outcome %s, got %s (%v)", tt.outcome, got, err)
+			}
+			if tt.field != "" && !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("Expected error to name %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestFindReusedPrimaryAreas(t *testing.T) {
+	areas := func(n int) []models.AreaSeed {
+		result := make([]models.AreaSeed, n)
+		for i := range result {
+			result[i] = models.AreaSeed{AreaID: i + 1, Name: fmt.Sprintf("Area %d", i+1)}
+		}
+		return result
+	}
+	acts := func(primaryAreas ...string) []models.Act {
+		result := make([]models.Act, len(primaryAreas))
+		for i, area := range primaryAreas {
+			result[i] = models.Act{ActNumber: i + 1, PrimaryArea: area}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		acts          []models.Act
+		featuredAreas int
+		expected      []string
+	}{
+		{"distinct areas", acts("Old Barrow", "Ashen Ford", "Sunken Keep"), 4, []string{}},
+		{"reuse with enough areas", acts("Old Barrow", "Ashen Ford", "old barrow"), 3, []string{"old barrow"}},
+		{"every act in one area", acts("Old Barrow", "Old Barrow", "Old Barrow", "Old Barrow"), 6, []string{"Old Barrow"}},
+		{"reuse tolerated when areas are scarce", acts("Old Barrow", "Ashen Ford", "Old Barrow"), 2, []string{}},
+		{"empty areas ignored", acts("", "", "Ashen Ford"), 3, []string{}},
+		{"single act", acts("Old Barrow"), 3, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprint := &models.Blueprint{Acts: tt.acts}
+			seeds := models.CampaignSeeds{FeaturedAreas: areas(tt.featuredAreas)}
+
+			got := findReusedPrimaryAreas(blueprint, seeds)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	seeds := models.CampaignSeeds{
+		BeatProfile: models.BeatProfile{Acts: 2},
+		FeaturedAreas: []models.AreaSeed{
+			{AreaID: 1, Name: "Old Barrow"},
+			{AreaID: 2, Name: "Tower Lower Levels"},
+		},
+	}
+	newBlueprint := func() *models.Blueprint {
+		return &models.Blueprint{
+			Title:           "Test Campaign",
+			Premise:         "A test premise",
+			ThematicPillars: []string{"One", "Two", "Three"},
+			Acts: []models.Act{
+				{ActNumber: 1, PrimaryArea: "Old Barrow tomb"},
+				{ActNumber: 2, PrimaryArea: "tower_lower_levels"},
+			},
+			MajorForces: map[string]models.MajorForce{
+				"drowned_choir": {FinalConfrontation: &models.Confrontation{Act: 2, Location: "Tower Lower Levels belfry"}},
+			},
+			NPCs: map[string]models.NPC{
+				"orla":   {Name: "Keeper Orla", FirstAppearanceAct: 1},
+				"hidden": {Name: "The Stranger"},
+			},
+			BoonPlan: []models.BoonPlanEntry{
+				{Trigger: "recover_the_bell", Boons: []models.BoonOption{{Name: "Thread Rewound"}, {Name: "hand_of_fate"}}},
+			},
+			ImagePlan: models.ImagePlan{
+				IntroImage: models.ImagePlanItem{Prompt: "A drowned bell", SendWhen: "campaign_start"},
+			},
+			EndStates: models.EndStates{Success: "The bell rings true", Compromised: "The bell rings cracked", Failure: "The bell stays drowned"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*models.Blueprint)
+		outcome string
+		field   string
+	}{
+		{"valid references", func(*models.Blueprint) {}, OutcomeSuccess, ""},
+		{"unknown area", func(b *models.Blueprint) { b.Acts[1].PrimaryArea = "Sunken Keep" }, OutcomeUnknownArea, `acts[1].primaryArea "Sunken Keep"`},
+		{"partial word is not a match", func(b *models.Blueprint) { b.Acts[0].PrimaryArea = "Old Barrowmere" }, OutcomeUnknownArea, "acts[0].primaryArea"},
+		{"invented confrontation location", func(b *models.Blueprint) {
+			b.MajorForces["drowned_choir"].FinalConfrontation.Location = "Sunken Keep"
+		}, OutcomeUnknownArea, `majorForces["drowned_choir"].finalConfrontation.location "Sunken Keep"`},
+		{"confrontation without a location", func(b *models.Blueprint) {
+			b.MajorForces["drowned_choir"] = models.MajorForce{FinalConfrontation: &models.Confrontation{Act: 2}}
+		}, OutcomeSuccess, ""},
+		{"every unknown area is listed", func(b *models.Blueprint) {
+			b.Acts[0].PrimaryArea = "Glass Marsh"
+			b.MajorForces["drowned_choir"].FinalConfrontation.Location = "Sunken Keep"
+		}, OutcomeUnknownArea, `acts[0].primaryArea "Glass Marsh", majorForces["drowned_choir"].finalConfrontation.location "Sunken Keep"`},
+		{"npc appears in act zero", func(b *models.Blueprint) { b.NPCs["orla"] = models.NPC{Name: "Keeper Orla", FirstAppearanceAct: -1} }, OutcomeNPCAct, `npcs["orla"].firstAppearanceAct -1`},
+		{"npc appears after the last act", func(b *models.Blueprint) { b.NPCs["orla"] = models.NPC{Name: "Keeper Orla", FirstAppearanceAct: 3} }, OutcomeNPCAct, "outside acts 1-2"},
+		{"invented boon", func(b *models.Blueprint) { b.BoonPlan[0].Boons[1].Name = "Silver Blade" }, OutcomeUnknownBoon, `boonPlan[0].boons[1].name "Silver Blade"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprint := newBlueprint()
+			tt.mutate(blueprint)
+
+			err := Validate(blueprint, seeds)
+			if got := Outcome(err); got != tt.outcome {
+				t.Fatalf("Expected outcome %s, got %s (%v)", tt.outcome, got, err)
+			}
+			if tt.field != "" && !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("Expected error to name %s, got %v", tt.field, err)
+			}
+		})
+	}
+
+	t.Run("areas unchecked without featured areas", func(t *testing.T) {
+		blueprint := newBlueprint()
+		blueprint.Acts[0].PrimaryArea = "Anywhere"
+		if err := Validate(blueprint, models.CampaignSeeds{BeatProfile: seeds.BeatProfile}); err != nil {
+			t.Errorf("Expected no area check without featured areas, got %v", err)
+		}
+	})
+}
+
+func TestComputeIngredientCoverage(t *testing.T) {
+	seeds := models.CampaignSeeds{
+		Objective: models.ObjectiveSeed{ObjectiveID: "break_the_curse", Name: "Break the Curse"},
+		Twists: []models.TwistSeed{
+			{TwistID: "guardian_was_containment", Name: "The Guardian Was Containment"},
+			{TwistID: "time_pressure", Name: "Time Pressure"},
+		},
+		Antagonists: []models.AntagonistSeed{
+			{AntagonistID: "barrow_king", Name: "The Barrow King"},
+		},
+		SetPieces: []models.SetPieceSeed{
+			{SetPieceID: "collapsing_ruins", Name: "Collapsing Ruins"},
+			{SetPieceID: "forced_split", Name: "Forced Split"},
+		},
+	}
+
+	blueprint := &models.Blueprint{
+		IngredientBinding: models.IngredientBinding{
+			ObjectiveSeed: "break_the_curse",
+			Twists:        []string{"guardian_was_containment"},
+		},
+		Acts: []models.Act{
+			{ActNumber: 1, Name: "The Barrow Road", NarrativePurpose: "Reach the tomb before the collapsing ruins seal it"},
+		},
+		MajorForces: map[string]models.MajorForce{
+			"barrow_king": {InitialPresence: models.Presence{Act: 1, Description: "Whispers from below"}},
+		},
+	}
+
+	coverage := ComputeCoverage(blueprint, seeds)
+
+	if !coverage.Objective.Bound || !coverage.Objective.Used() {
+		t.Errorf("Expected objective to be bound, got %+v", coverage.Objective)
+	}
+	if !coverage.Twists[0].Bound {
+		t.Errorf("Expected first twist to be bound, got %+v", coverage.Twists[0])
+	}
+	if coverage.Twists[1].Used() {
+		t.Errorf("Expected second twist to be unused, got %+v", coverage.Twists[1])
+	}
+	if coverage.Antagonists[0].Bound || !coverage.Antagonists[0].Appears {
+		t.Errorf("Expected antagonist to appear via major forces without binding, got %+v", coverage.Antagonists[0])
+	}
+	if !coverage.SetPieces[0].Appears {
+		t.Errorf("Expected set piece to appear by name in act text, got %+v", coverage.SetPieces[0])
+	}
+
+	missing := coverage.Missing()
+	expectedMissing := []string{"time_pressure", "forced_split"}
+	if len(missing) != len(expectedMissing) {
+		t.Fatalf("Expected missing %v, got %v", expectedMissing, missing)
+	}
+	for i := range expectedMissing {
+		if missing[i] != expectedMissing[i] {
+			t.Errorf("Expected missing %v, got %v", expectedMissing, missing)
+		}
+	}
+
+	summary := coverage.Summary()
+	if !strings.Contains(summary, "twists=1/2") || !strings.Contains(summary, "setPieces=1/2") || !strings.Contains(summary, "antagonists=1/1") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	valid := `{
+		"title": "The Drowned Bell",
+		"premise": "A bell tolls beneath the harbor",
+		"thematicPillars": ["Dread", "Salvage", "Tide"],
+		"acts": [{"actNumber": 1, "name": "Low Tide", "primaryArea": "harbor", "narrativePurpose": "Establish the bell", "expectedBeats": 5}],
+		"npcs": {"mira": {"name": "Mira", "role": "diver", "identityHidden": false}},
+		"imagePlan": {"introImage": {"prompt": "A sunken bell tower at dusk", "sendWhen": "campaign_start"}},
+		"majorForces": null
+	}`
+
+	tests := []struct {
+		name        string
+		payload     string
+		expectError string
+	}{
+		{"valid blueprint", valid, ""},
+		{"missing blueprint", ``, "blueprint: expected object, got null"},
+		{"blueprint is an array", `[]`, "blueprint: expected object, got array"},
+		{"missing required fields", `{"title": "Only a title"}`, "blueprint.premise: required field missing"},
+		{"title wrong type", strings.Replace(valid, `"The Drowned Bell"`, `42`, 1), "blueprint.title: expected string, got integer"},
+		{"act number as string", strings.Replace(valid, `"actNumber": 1`, `"actNumber": "one"`, 1), "blueprint.acts[0].actNumber: expected integer, got string"},
+		{"fractional beats", strings.Replace(valid, `"expectedBeats": 5`, `"expectedBeats": 5.5`, 1), "blueprint.acts[0].expectedBeats: expected integer, got number"},
+		{"act missing name", strings.Replace(valid, `"name": "Low Tide", `, ``, 1), "blueprint.acts[0].name: required field missing"},
+		{"pillar not a string", strings.Replace(valid, `"Dread"`, `{"name": "Dread"}`, 1), "blueprint.thematicPillars[0]: expected string, got object"},
+		{"npc flag wrong type", strings.Replace(valid, `"identityHidden": false`, `"identityHidden": "no"`, 1), "blueprint.npcs.mira.identityHidden: expected boolean, got string"},
+		{"intro image missing prompt", strings.Replace(valid, `"prompt": "A sunken bell tower at dusk", `, ``, 1), "blueprint.imagePlan.introImage.prompt: required field missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchema([]byte(tt.payload))
+			if tt.expectError == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got none", tt.expectError)
+			}
+			if !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %q", tt.expectError, err.Error())
+			}
+			if got := Outcome(err); got != OutcomeSchemaViolation {
+				t.Errorf("Expected outcome %s, got %s", OutcomeSchemaViolation, got)
+			}
+		})
+	}
+}
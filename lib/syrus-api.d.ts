@@ -7,6 +7,7 @@ export interface WebhookApiProps {
     stageConfig: StageConfig;
     customDomain?: boolean;
     hostsTableName?: string;
+    campaignsTableName?: string;
     messagingQueue?: sqs.IQueue;
     configuringQueue?: sqs.IQueue;
     playQueue?: sqs.IQueue;
@@ -19,6 +19,7 @@ export interface WebhookApiProps {
   stageConfig: StageConfig;
   customDomain?: boolean;
   hostsTableName?: string;
+  campaignsTableName?: string;
   messagingQueue?: sqs.IQueue;
   configuringQueue?: sqs.IQueue;
   playQueue?: sqs.IQueue;
@@ -32,7 +33,7 @@ export class SyrusApi extends Construct {
   constructor(scope: Construct, id: string, props: WebhookApiProps) {
     super(scope, id);
 
-    const { stageConfig, customDomain = false, hostsTableName, messagingQueue, configuringQueue, playQueue } = props;
+    const { stageConfig, customDomain = false, hostsTableName, campaignsTableName, messagingQueue, configuringQueue, playQueue } = props;
 
     // Custom domain setup
     const domainName = 'webhooks.syrus.chat';
@@ -56,6 +57,7 @@ export class SyrusApi extends Construct {
         SYRUS_DISCORD_PUBLIC_KEY_PARAM: `/syrus/${stageConfig.stage}/discord/public-key`,
         SYRUS_DISCORD_APP_ID_PARAM: `/syrus/${stageConfig.stage}/discord/app-id`,
         SYRUS_HOSTS_TABLE: hostsTableName || `syrus-${stageConfig.stage}-hosts`,
+        SYRUS_CAMPAIGNS_TABLE: campaignsTableName || `syrus-${stageConfig.stage}-campaigns`,
         SYRUS_STAGE: stageConfig.stage,
         ...(messagingQueue ? { SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queueUrl } : {}),
         ...(configuringQueue ? { SYRUS_CONFIGURING_QUEUE_URL: configuringQueue.queueUrl } : {}),
@@ -75,6 +77,23 @@ export class SyrusApi extends Construct {
       resources: [`arn:aws:dynamodb:${Stack.of(this).region}:${Stack.of(this).account}:table/${actualHostsTableName}`],
     }));
 
+    // Add DynamoDB permissions to resolve a channel's campaign before queueing /syrus commands
+    // (GetItem covers campaigns created before IDs were generated, which are keyed by channel)
+    const actualCampaignsTableName = campaignsTableName || `syrus-${stageConfig.stage}-campaigns`;
+    const campaignsTableArn = `arn:aws:dynamodb:${Stack.of(this).region}:${Stack.of(this).account}:table/${actualCampaignsTableName}`;
+    this.lambdaFunction.addToRolePolicy(new iam.PolicyStatement({
+      actions: [
+        'dynamodb:GetItem',
+      ],
+      resources: [campaignsTableArn],
+    }));
+    this.lambdaFunction.addToRolePolicy(new iam.PolicyStatement({
+      actions: [
+        'dynamodb:Query',
+      ],
+      resources: [`${campaignsTableArn}/index/ByChannel`],
+    }));
+
     // Add SSM permissions for Discord public key and app ID access
     this.lambdaFunction.addToRolePolicy(new iam.PolicyStatement({
       actions: [
@@ -4,6 +4,9 @@ import * as path from 'path';
 import * as lambda from 'aws-cdk-lib/aws-lambda';
 import * as lambdaEventSources from 'aws-cdk-lib/aws-lambda-event-sources';
 import * as iam from 'aws-cdk-lib/aws-iam';
+import * as cloudwatch from 'aws-cdk-lib/aws-cloudwatch';
+import * as events from 'aws-cdk-lib/aws-events';
+import * as eventsTargets from 'aws-cdk-lib/aws-events-targets';
 import * as s3 from 'aws-cdk-lib/aws-s3';
 import * as ssm from 'aws-cdk-lib/aws-ssm';
 import { createCampaignsTable, createHostsTable } from './campaigns-table';
@@ -12,6 +15,7 @@ import { SyrusApi } from './syrus-api';
 import { SqsFifoWithDlq } from './constructs/sqs-fifo-with-dlq';
 import { DedupTable } from './constructs/dedup-table';
 import { ConfirmationsTable } from './constructs/confirmations-table';
+import { HostSelectionTable } from './constructs/host-selection-table';
 
 interface SyrusMvpStackProps extends StackProps {
   stage: string;
@@ -83,6 +87,40 @@ export class SyrusMvpStack extends Stack {
       autoDeleteObjects: stageConfig.removalPolicy === RemovalPolicy.DESTROY,
     });
 
+    // Prompt and content overrides bucket - lets prompt engineers and content designers iterate without a deploy.
+    // Objects live under {stage}/ (e.g. dev/blueprintPrompt.txt, dev/arcanos_maps.json); absent objects fall back
+    // to the embedded defaults.
+    const promptBucket = new s3.Bucket(this, 'PromptBucket', {
+      bucketName: `syrus-prompts-${props.stage}`,
+      encryption: s3.BucketEncryption.S3_MANAGED,
+      versioned: true, // Keep prompt history so a bad edit can be rolled back
+      removalPolicy: stageConfig.removalPolicy,
+      autoDeleteObjects: stageConfig.removalPolicy === RemovalPolicy.DESTROY,
+    });
+
+    // Archive bucket - ended campaigns retired with /campaign archive keep their full blueprint and memory here,
+    // under campaigns/{campaignId}/, while the table keeps only a pointer
+    const archiveBucket = new s3.Bucket(this, 'ArchiveBucket', {
+      bucketName: `syrus-archives-${props.stage}`,
+      encryption: s3.BucketEncryption.S3_MANAGED,
+      versioned: false,
+      removalPolicy: stageConfig.removalPolicy,
+      autoDeleteObjects: stageConfig.removalPolicy === RemovalPolicy.DESTROY,
+    });
+
+    // Capture bucket - stages with promptCapture keep redacted blueprint and play prompt/response pairs here,
+    // under {kind}/{yyyy}/{mm}/{dd}/, as a dataset for offline evaluation
+    const captureBucket = stageConfig.promptCapture ? new s3.Bucket(this, 'CaptureBucket', {
+      bucketName: `syrus-captures-${props.stage}`,
+      encryption: s3.BucketEncryption.S3_MANAGED,
+      versioned: false,
+      removalPolicy: stageConfig.removalPolicy,
+      autoDeleteObjects: stageConfig.removalPolicy === RemovalPolicy.DESTROY,
+    }) : undefined;
+    const captureEnvironment: Record<string, string> = captureBucket
+      ? { SYRUS_CAPTURE_BUCKET: captureBucket.bucketName }
+      : {};
+
     // Note: Anthropic API key must be created manually in SSM as SecureString
     // Parameter name: /syrus/{stage}/anthropic/api-key
     // CDK cannot create SecureString parameters due to CloudFormation limitations
@@ -92,6 +130,7 @@ export class SyrusMvpStack extends Stack {
       stageConfig,
       customDomain: true,
       hostsTableName: hostsTable.tableName,
+      campaignsTableName: campaignsTable.tableName,
       messagingQueue: messagingQueue.queue,
       configuringQueue: configuringQueue.queue,
       playQueue: playQueue.queue,
@@ -134,6 +173,12 @@ export class SyrusMvpStack extends Stack {
       removalPolicy: stageConfig.removalPolicy,
     });
 
+    // Create host selection history table (seed anti-sameness across a host's campaigns)
+    const hostSelectionTable = new HostSelectionTable(this, 'HostSelectionTable', {
+      stage: props.stage,
+      removalPolicy: stageConfig.removalPolicy,
+    });
+
     // Create messaging Lambda function
     const messagingFunction = new lambda.Function(this, 'MessagingFunction', {
       runtime: lambda.Runtime.PROVIDED_AL2023,
@@ -143,12 +188,20 @@ export class SyrusMvpStack extends Stack {
         SYRUS_DISCORD_BOT_TOKEN_PARAM: `/syrus/${stageConfig.stage}/discord/bot-token`,
         SYRUS_STAGE: stageConfig.stage,
         SYRUS_MODEL_CACHE_BUCKET: modelCacheBucket.bucketName,
+        SYRUS_CONFIGURING_QUEUE_URL: configuringQueue.queue.queueUrl,
+        SYRUS_DEDUP_TABLE: dedupTable.table.tableName,
       },
       timeout: Duration.seconds(30),
       memorySize: 256,
     });
 
-    // Add SSM permissions for Discord bot token and app ID access
+    // Messaging replays /campaign start into newly created campaign threads
+    configuringQueue.queue.grantSendMessages(messagingFunction);
+
+    // Messaging records each delivered part of a split message so retries resume where they stopped
+    dedupTable.table.grantReadWriteData(messagingFunction);
+
+    // Add SSM permissions for Discord bot token and app ID, and the WhatsApp sender credentials
     messagingFunction.addToRolePolicy(new iam.PolicyStatement({
       actions: [
         'ssm:GetParameter',
@@ -156,6 +209,8 @@ export class SyrusMvpStack extends Stack {
       resources: [
         `arn:aws:ssm:${Stack.of(this).region}:${Stack.of(this).account}:parameter/syrus/${stageConfig.stage}/discord/bot-token`,
         `arn:aws:ssm:${Stack.of(this).region}:${Stack.of(this).account}:parameter/syrus/${stageConfig.stage}/discord/app-id`,
+        `arn:aws:ssm:${Stack.of(this).region}:${Stack.of(this).account}:parameter/syrus/${stageConfig.stage}/whatsapp/access-token`,
+        `arn:aws:ssm:${Stack.of(this).region}:${Stack.of(this).account}:parameter/syrus/${stageConfig.stage}/whatsapp/phone-number-id`,
       ],
     }));
 
@@ -191,7 +246,9 @@ export class SyrusMvpStack extends Stack {
         SYRUS_CONFIRMATIONS_TABLE: confirmationsTable.table.tableName,
         SYRUS_BIRTHING_QUEUE_URL: birthingQueue.queue.queueUrl,
         SYRUS_MODEL_CACHE_BUCKET: modelCacheBucket.bucketName,
+        SYRUS_ARCHIVE_BUCKET: archiveBucket.bucketName,
         SYRUS_STAGE: stageConfig.stage,
+        SYRUS_GUILD_CAMPAIGN_CAP: String(stageConfig.guildCampaignCap),
       },
       timeout: Duration.seconds(30),
       memorySize: 256,
@@ -209,10 +266,22 @@ export class SyrusMvpStack extends Stack {
       actions: [
         'dynamodb:GetItem',
         'dynamodb:PutItem',
+        'dynamodb:UpdateItem',
       ],
       resources: [campaignsTable.tableArn],
     }));
 
+    // Count a guild's campaigns for the concurrency cap, and find a channel's campaign
+    configuringFunction.addToRolePolicy(new iam.PolicyStatement({
+      actions: [
+        'dynamodb:Query',
+      ],
+      resources: [
+        `${campaignsTable.tableArn}/index/ByGuild`,
+        `${campaignsTable.tableArn}/index/ByChannel`,
+      ],
+    }));
+
     // Add SQS permissions for configuring queue (read) and messaging queue (write)
     configuringFunction.addToRolePolicy(new iam.PolicyStatement({
       actions: [
@@ -235,6 +304,7 @@ export class SyrusMvpStack extends Stack {
       actions: [
         'dynamodb:GetItem',
         'dynamodb:PutItem',
+        'dynamodb:DeleteItem',
       ],
       resources: [dedupTable.table.tableArn],
     }));
@@ -264,6 +334,12 @@ export class SyrusMvpStack extends Stack {
       resources: [modelCacheBucket.bucketArn],
     }));
 
+    // Grant configuring Lambda permission to archive ended campaigns
+    archiveBucket.grantPut(configuringFunction);
+
+    // Grant configuring Lambda permission to stage campaign exports for messaging to attach
+    modelCacheBucket.grantPut(configuringFunction);
+
     // Add SQS event source mapping for configuring queue
     configuringFunction.addEventSource(new lambdaEventSources.SqsEventSource(configuringQueue.queue, {
       batchSize: configuringQueue.defaultBatchSize,
@@ -280,6 +356,9 @@ export class SyrusMvpStack extends Stack {
         SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queue.queueUrl,
         SYRUS_DEDUP_TABLE: dedupTable.table.tableName,
         SYRUS_BLUEPRINTING_QUEUE_URL: blueprintingQueue.queue.queueUrl,
+        SYRUS_CONFIRMATIONS_TABLE: confirmationsTable.table.tableName,
+        SYRUS_HOST_SELECTION_TABLE: hostSelectionTable.table.tableName,
+        SYRUS_PROMPT_BUCKET: promptBucket.bucketName, // Stage overrides for seeds, maps, and config
         SYRUS_STAGE: stageConfig.stage,
       },
       timeout: Duration.seconds(30),
@@ -287,10 +366,13 @@ export class SyrusMvpStack extends Stack {
     });
 
     // Grant birthing Lambda permissions
-    campaignsTable.grantReadData(birthingFunction);
+    campaignsTable.grantReadWriteData(birthingFunction); // Counts seed rerolls
     dedupTable.table.grantReadWriteData(birthingFunction);
+    confirmationsTable.table.grantReadWriteData(birthingFunction); // Pending seeds previews
+    hostSelectionTable.table.grantReadWriteData(birthingFunction); // Recent seed combinations per host
     messagingQueue.queue.grantSendMessages(birthingFunction);
     blueprintingQueue.queue.grantSendMessages(birthingFunction);
+    promptBucket.grantRead(birthingFunction);
 
     // Grant birthing Lambda read/delete permissions for its queue (event source)
     birthingFunction.addToRolePolicy(new iam.PolicyStatement({
@@ -318,7 +400,10 @@ export class SyrusMvpStack extends Stack {
         SYRUS_DEDUP_TABLE: dedupTable.table.tableName,
         SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queue.queueUrl,
         SYRUS_MODEL_CACHE_BUCKET: modelCacheBucket.bucketName,
+        SYRUS_OPENAI_IMAGE_FORMAT: 'b64_json', // Inline image bytes; 'url' restores the download round-trip
+        SYRUS_PROMPT_BUCKET: promptBucket.bucketName,
         SYRUS_STAGE: stageConfig.stage,
+        ...captureEnvironment,
       },
       timeout: Duration.minutes(5), // Claude calls can be slow
       memorySize: 512,
@@ -329,6 +414,10 @@ export class SyrusMvpStack extends Stack {
     dedupTable.table.grantReadWriteData(blueprintingFunction);
     messagingQueue.queue.grantSendMessages(blueprintingFunction);
     modelCacheBucket.grantReadWrite(blueprintingFunction);
+    promptBucket.grantRead(blueprintingFunction);
+    if (captureBucket) {
+      captureBucket.grantPut(blueprintingFunction); // Prompt/response capture
+    }
 
     // Grant blueprinting Lambda SSM access for Anthropic API key
     blueprintingFunction.addToRolePolicy(new iam.PolicyStatement({
@@ -339,11 +428,13 @@ export class SyrusMvpStack extends Stack {
     }));
 
     // Grant blueprinting Lambda read/delete permissions for its queue
+    // (ChangeMessageVisibility delays retries when Anthropic is overloaded)
     blueprintingFunction.addToRolePolicy(new iam.PolicyStatement({
       actions: [
         'sqs:ReceiveMessage',
         'sqs:DeleteMessage',
         'sqs:GetQueueAttributes',
+        'sqs:ChangeMessageVisibility',
       ],
       resources: [blueprintingQueue.queue.queueArn],
     }));
@@ -354,6 +445,24 @@ export class SyrusMvpStack extends Stack {
       reportBatchItemFailures: true,
     }));
 
+    // Alarm when blueprint validation failures spike (prompt change or model regression).
+    // The blueprinting Lambda emits BlueprintValidationFailed (0/1) via EMF, so its Average is the failure rate.
+    new cloudwatch.Alarm(this, 'BlueprintValidationFailureAlarm', {
+      alarmName: `syrus-blueprint-validation-failures-${stageConfig.stage}`,
+      alarmDescription: 'More than 25% of generated blueprints failed validation in the last hour',
+      metric: new cloudwatch.Metric({
+        namespace: 'Syrus/Blueprinting',
+        metricName: 'BlueprintValidationFailed',
+        dimensionsMap: { Stage: stageConfig.stage },
+        statistic: cloudwatch.Stats.AVERAGE,
+        period: Duration.hours(1),
+      }),
+      threshold: 0.25,
+      evaluationPeriods: 1,
+      comparisonOperator: cloudwatch.ComparisonOperator.GREATER_THAN_THRESHOLD,
+      treatMissingData: cloudwatch.TreatMissingData.NOT_BREACHING,
+    });
+
     // ImageGen Infrastructure
     // Create SQS FIFO queue for image generation
     const imageGenQueue = new SqsFifoWithDlq(this, 'ImageGenQueue', {
@@ -371,9 +480,11 @@ export class SyrusMvpStack extends Stack {
         SYRUS_CAMPAIGNS_TABLE: campaignsTable.tableName,
         SYRUS_DEDUP_TABLE: dedupTable.table.tableName,
         SYRUS_MODEL_CACHE_BUCKET: modelCacheBucket.bucketName,
+        SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queue.queueUrl,
+        SYRUS_SQS_CONCURRENCY: '5', // Generate a batch's images in parallel (one worker per message group)
         SYRUS_STAGE: stageConfig.stage,
       },
-      timeout: Duration.minutes(2), // OpenAI API calls can take time
+      timeout: Duration.minutes(2), // Image API calls can take time
       memorySize: 512,
     });
 
@@ -383,11 +494,12 @@ export class SyrusMvpStack extends Stack {
     messagingQueue.queue.grantSendMessages(imageGenFunction);
     modelCacheBucket.grantReadWrite(imageGenFunction);
 
-    // Grant imageGen Lambda SSM access for OpenAI API key
+    // Grant imageGen Lambda SSM access for the OpenAI and Google (Nano Banana) API keys
     imageGenFunction.addToRolePolicy(new iam.PolicyStatement({
       actions: ['ssm:GetParameter'],
       resources: [
         `arn:aws:ssm:${Stack.of(this).region}:${Stack.of(this).account}:parameter/syrus/${stageConfig.stage}/openai/api-key`,
+        `arn:aws:ssm:${Stack.of(this).region}:${Stack.of(this).account}:parameter/syrus/${stageConfig.stage}/google/api-key`,
       ],
     }));
 
@@ -433,8 +545,11 @@ export class SyrusMvpStack extends Stack {
         SYRUS_CAMPAIGNS_TABLE: campaignsTable.tableName,
         SYRUS_DEDUP_TABLE: dedupTable.table.tableName,
         SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queue.queueUrl,
+        SYRUS_BIRTHING_QUEUE_URL: birthingQueue.queue.queueUrl,
         SYRUS_MODEL_CACHE_BUCKET: modelCacheBucket.bucketName,
         SYRUS_STAGE: stageConfig.stage,
+        SYRUS_FEATURES: stageConfig.features.join(','),
+        ...captureEnvironment,
       },
       timeout: Duration.minutes(5), // Model calls can be slow
       memorySize: 512,
@@ -444,7 +559,11 @@ export class SyrusMvpStack extends Stack {
     campaignsTable.grantReadWriteData(playFunction);
     dedupTable.table.grantReadWriteData(playFunction);
     messagingQueue.queue.grantSendMessages(playFunction);
+    birthingQueue.queue.grantSendMessages(playFunction); // Re-trigger blueprinting for stalled campaigns
     modelCacheBucket.grantReadWrite(playFunction);
+    if (captureBucket) {
+      captureBucket.grantPut(playFunction); // Prompt/response capture
+    }
 
     // Grant play Lambda SSM access for Anthropic API key
     playFunction.addToRolePolicy(new iam.PolicyStatement({
@@ -470,6 +589,92 @@ export class SyrusMvpStack extends Stack {
       reportBatchItemFailures: true,
     }));
 
+    // Nudge Infrastructure
+    // Scheduled sweep that reminds idle asynchronous (play-by-post) parties; it never changes campaign state
+    const nudgeFunction = new lambda.Function(this, 'NudgeFunction', {
+      runtime: lambda.Runtime.PROVIDED_AL2023,
+      code: lambda.Code.fromAsset(path.join(__dirname, '../lambda/nudge')),
+      handler: 'bootstrap',
+      environment: {
+        SYRUS_CAMPAIGNS_TABLE: campaignsTable.tableName,
+        SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queue.queueUrl,
+        SYRUS_STAGE: stageConfig.stage,
+      },
+      timeout: Duration.minutes(1),
+      memorySize: 256,
+    });
+
+    // Grant nudge Lambda permissions (scan campaigns, record lastNudgedAt, post reminders)
+    campaignsTable.grantReadWriteData(nudgeFunction);
+    messagingQueue.queue.grantSendMessages(nudgeFunction);
+
+    // Hourly is fine-grained enough for nudge intervals measured in hours
+    new events.Rule(this, 'NudgeSchedule', {
+      ruleName: `syrus-nudge-${stageConfig.stage}`,
+      description: 'Nudge idle asynchronous Syrus campaigns',
+      schedule: events.Schedule.rate(Duration.hours(1)),
+      targets: [new eventsTargets.LambdaFunction(nudgeFunction)],
+    });
+
+    // Activity Infrastructure
+    // Scheduled heartbeat sweep that publishes how many campaigns are being played right now (Syrus/Activity).
+    // Operators can also invoke it directly with {"windowMinutes": N} to list campaigns active in the last N minutes.
+    const activityFunction = new lambda.Function(this, 'ActivityFunction', {
+      runtime: lambda.Runtime.PROVIDED_AL2023,
+      code: lambda.Code.fromAsset(path.join(__dirname, '../lambda/activity')),
+      handler: 'bootstrap',
+      environment: {
+        SYRUS_CAMPAIGNS_TABLE: campaignsTable.tableName,
+        SYRUS_ACTIVE_WINDOW_MINUTES: '15',
+        SYRUS_STAGE: stageConfig.stage,
+      },
+      timeout: Duration.minutes(1),
+      memorySize: 256,
+    });
+
+    // Grant activity Lambda read-only access to campaigns
+    campaignsTable.grantReadData(activityFunction);
+
+    new events.Rule(this, 'ActivitySchedule', {
+      ruleName: `syrus-activity-${stageConfig.stage}`,
+      description: 'Publish the number of actively played Syrus campaigns',
+      schedule: events.Schedule.rate(Duration.minutes(5)),
+      targets: [new eventsTargets.LambdaFunction(activityFunction)],
+    });
+
+    new CfnOutput(this, 'ActivityLambdaArn', {
+      value: activityFunction.functionArn,
+      description: 'ARN of the activity Lambda function (invoke with {"windowMinutes": N} to list active campaigns)',
+      exportName: `SyrusActivityLambdaArn-${props.stage}`,
+    });
+
+    // Dead Letter Infrastructure
+    // Consumes the user-facing pipelines' DLQs and tells the channel its request was lost, so players aren't
+    // left watching a deferred "thinking" response forever. Each dead letter's body is logged before it's consumed.
+    const deadLetterFunction = new lambda.Function(this, 'DeadLetterFunction', {
+      runtime: lambda.Runtime.PROVIDED_AL2023,
+      code: lambda.Code.fromAsset(path.join(__dirname, '../lambda/deadLetter')),
+      handler: 'bootstrap',
+      environment: {
+        SYRUS_CAMPAIGNS_TABLE: campaignsTable.tableName,
+        SYRUS_MESSAGING_QUEUE_URL: messagingQueue.queue.queueUrl,
+        SYRUS_STAGE: stageConfig.stage,
+      },
+      timeout: Duration.seconds(20), // Under the DLQs' default 30s visibility timeout
+      memorySize: 256,
+    });
+
+    // Grant dead letter Lambda permissions (find a campaign's channel, post the notice)
+    campaignsTable.grantReadData(deadLetterFunction);
+    messagingQueue.queue.grantSendMessages(deadLetterFunction);
+
+    for (const pipeline of [playQueue, blueprintingQueue, configuringQueue, birthingQueue]) {
+      deadLetterFunction.addEventSource(new lambdaEventSources.SqsEventSource(pipeline.dlq, {
+        batchSize: 1,
+        reportBatchItemFailures: true,
+      }));
+    }
+
     // CloudFormation outputs for Messaging Infrastructure
     new CfnOutput(this, 'MessagingQueueUrl', {
       value: messagingQueue.queue.queueUrl,
@@ -587,6 +792,12 @@ export class SyrusMvpStack extends Stack {
       exportName: `SyrusConfirmationsTableArn-${props.stage}`,
     });
 
+    new CfnOutput(this, 'HostSelectionTableName', {
+      value: hostSelectionTable.table.tableName,
+      description: 'Name of the DynamoDB host selection history table',
+      exportName: `SyrusHostSelectionTableName-${props.stage}`,
+    });
+
     // CloudFormation outputs for Blueprinting Infrastructure
     new CfnOutput(this, 'BlueprintingQueueUrl', {
       value: blueprintingQueue.queue.queueUrl,
@@ -624,6 +835,18 @@ export class SyrusMvpStack extends Stack {
       exportName: `SyrusModelCacheBucketName-${props.stage}`,
     });
 
+    new CfnOutput(this, 'ArchiveBucketName', {
+      value: archiveBucket.bucketName,
+      description: 'Name of the S3 bucket holding archived campaigns',
+      exportName: `SyrusArchiveBucketName-${props.stage}`,
+    });
+
+    new CfnOutput(this, 'PromptBucketName', {
+      value: promptBucket.bucketName,
+      description: 'Name of the S3 bucket holding stage prompt and content overrides',
+      exportName: `SyrusPromptBucketName-${props.stage}`,
+    });
+
     // CloudFormation outputs for ImageGen Infrastructure
     new CfnOutput(this, 'ImageGenQueueUrl', {
       value: imageGenQueue.queue.queueUrl,